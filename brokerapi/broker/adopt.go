@@ -0,0 +1,172 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/cloudfoundry/cloud-service-broker/dbservice/models"
+	"github.com/cloudfoundry/cloud-service-broker/internal/paramparser"
+	"github.com/cloudfoundry/cloud-service-broker/internal/storage"
+	"github.com/cloudfoundry/cloud-service-broker/pkg/broker"
+	"github.com/cloudfoundry/cloud-service-broker/utils/correlation"
+)
+
+// AdoptOptions describe the existing resources that Adopt brings under the management of the broker,
+// and the service instance that they become.
+type AdoptOptions struct {
+	// Service and Plan are the name or ID of the service offering and plan
+	Service string
+	Plan    string
+
+	// InstanceGUID is the ID of the new service instance
+	InstanceGUID string
+
+	OrganizationGUID string
+	SpaceGUID        string
+
+	// Params are the provision parameters, as they would be given to `cf create-service`
+	Params map[string]interface{}
+
+	// Resources maps the Terraform address of each resource in the provision template to the ID of the
+	// existing resource that is imported there
+	Resources map[string]string
+}
+
+// errAdoptionCancelled is recorded in the audit log when the changes are not confirmed
+var errAdoptionCancelled = errors.New("adoption cancelled")
+
+// Adopt brings existing resources under the management of the broker by importing them into a new
+// deployment built from the provision template of the service. The changes that Terraform would make to the
+// resources are passed to confirm, and only when it returns true are they made and the service instance
+// registered in the database. It returns whether the service instance was registered.
+func (broker *ServiceBroker) Adopt(ctx context.Context, opts AdoptOptions, confirm func(plan string) bool) (bool, error) {
+	params, err := auditParams(opts.Params)
+	if err != nil {
+		return false, err
+	}
+
+	err = broker.auditor.Audit(ctx, models.AdoptOperationType, opts.InstanceGUID, params, func() (bool, error) {
+		return false, broker.adopt(ctx, opts, confirm)
+	})
+	switch {
+	case err == errAdoptionCancelled:
+		return false, nil
+	case err != nil:
+		return false, err
+	default:
+		return true, nil
+	}
+}
+
+func (broker *ServiceBroker) adopt(ctx context.Context, opts AdoptOptions, confirm func(plan string) bool) error {
+	broker.Logger.Info("Adopting", correlation.ID(ctx), lager.Data{
+		"instance_id": opts.InstanceGUID,
+		"service":     opts.Service,
+		"plan":        opts.Plan,
+		"resources":   opts.Resources,
+	})
+
+	if opts.InstanceGUID == "" {
+		return errors.New("an instance ID is required")
+	}
+
+	exists, err := broker.store.ExistsServiceInstanceDetails(opts.InstanceGUID)
+	switch {
+	case err != nil:
+		return fmt.Errorf("database error checking for existing instance: %w", err)
+	case exists:
+		return fmt.Errorf("service instance %q already exists", opts.InstanceGUID)
+	}
+
+	serviceDefinition, err := broker.findService(opts.Service)
+	if err != nil {
+		return err
+	}
+
+	plan, err := findPlan(serviceDefinition, opts.Plan)
+	if err != nil {
+		return err
+	}
+
+	params := make(map[string]interface{})
+	for k, v := range opts.Params {
+		params[k] = v
+	}
+
+	maintenanceWindow, _, err := extractMaintenanceWindow(params)
+	if err != nil {
+		return err
+	}
+
+	if err := validateProvisionParameters(params, serviceDefinition.ProvisionInputVariables, nil, plan); err != nil {
+		return err
+	}
+
+	details := paramparser.ProvisionDetails{
+		ServiceID:        serviceDefinition.ID,
+		PlanID:           plan.ID,
+		OrganizationGUID: opts.OrganizationGUID,
+		SpaceGUID:        opts.SpaceGUID,
+		RequestParams:    params,
+	}
+	vars, err := serviceDefinition.ProvisionVariables(opts.InstanceGUID, details, *plan, nil)
+	if err != nil {
+		return err
+	}
+
+	serviceProvider := serviceDefinition.ProviderBuilder(broker.Logger, broker.store)
+	adopted, err := serviceProvider.Adopt(ctx, vars, opts.Resources, confirm)
+	switch {
+	case err != nil:
+		return err
+	case !adopted:
+		return errAdoptionCancelled
+	}
+
+	outputs, err := serviceProvider.GetTerraformOutputs(ctx, opts.InstanceGUID)
+	if err != nil {
+		return fmt.Errorf("error getting instance outputs: %w", err)
+	}
+
+	instanceDetails := storage.ServiceInstanceDetails{
+		GUID:              opts.InstanceGUID,
+		Outputs:           outputs,
+		ServiceGUID:       serviceDefinition.ID,
+		PlanGUID:          plan.ID,
+		SpaceGUID:         opts.SpaceGUID,
+		OrganizationGUID:  opts.OrganizationGUID,
+		OperationType:     models.ClearOperationType,
+		MaintenanceWindow: maintenanceWindow,
+	}
+	if err := broker.store.StoreServiceInstanceDetails(instanceDetails); err != nil {
+		return fmt.Errorf("error saving instance details to database: %w", err)
+	}
+
+	if err := broker.store.StoreProvisionRequestDetails(opts.InstanceGUID, params); err != nil {
+		return fmt.Errorf("error saving provision request details to database: %w", err)
+	}
+
+	return nil
+}
+
+// findService finds a service offering by name or ID
+func (broker *ServiceBroker) findService(nameOrID string) (*broker.ServiceDefinition, error) {
+	for _, svc := range broker.registry.GetAllServices() {
+		if svc.Name == nameOrID || svc.ID == nameOrID {
+			return svc, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown service: %q", nameOrID)
+}
+
+// findPlan finds a plan of a service offering by name or ID
+func findPlan(svc *broker.ServiceDefinition, nameOrID string) (*broker.ServicePlan, error) {
+	for _, plan := range svc.Plans {
+		if plan.Name == nameOrID || plan.ID == nameOrID {
+			return &plan, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown plan %q of service %q", nameOrID, svc.Name)
+}
@@ -0,0 +1,201 @@
+package broker_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"code.cloudfoundry.org/lager"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cf/brokerapi/v8/domain"
+
+	"github.com/cloudfoundry/cloud-service-broker/brokerapi/broker"
+	"github.com/cloudfoundry/cloud-service-broker/brokerapi/broker/brokerfakes"
+	"github.com/cloudfoundry/cloud-service-broker/brokerapi/broker/decider"
+	"github.com/cloudfoundry/cloud-service-broker/internal/storage"
+	pkgBroker "github.com/cloudfoundry/cloud-service-broker/pkg/broker"
+	pkgBrokerFakes "github.com/cloudfoundry/cloud-service-broker/pkg/broker/brokerfakes"
+	"github.com/cloudfoundry/cloud-service-broker/pkg/varcontext"
+	"github.com/cloudfoundry/cloud-service-broker/utils"
+)
+
+var _ = Describe("Adopt", func() {
+	const (
+		offeringID = "test-service-id"
+		planID     = "test-plan-id"
+		instanceID = "test-instance-id"
+	)
+
+	var (
+		serviceBroker       *broker.ServiceBroker
+		fakeStorage         *brokerfakes.FakeStorage
+		fakeServiceProvider *pkgBrokerFakes.FakeServiceProvider
+		fakeAuditor         *brokerfakes.FakeAuditor
+		opts                broker.AdoptOptions
+		confirm             = func(string) bool { return true }
+	)
+
+	BeforeEach(func() {
+		fakeServiceProvider = &pkgBrokerFakes.FakeServiceProvider{}
+		fakeServiceProvider.AdoptStub = func(_ context.Context, _ *varcontext.VarContext, _ map[string]string, confirm func(string) bool) (bool, error) {
+			return confirm("the plan"), nil
+		}
+		fakeServiceProvider.GetTerraformOutputsReturns(storage.JSONObject{"hostname": "db.example.com"}, nil)
+
+		fakeAuditor = &brokerfakes.FakeAuditor{}
+		fakeAuditor.AuditStub = func(_ context.Context, _, _ string, _ json.RawMessage, run func() (bool, error)) error {
+			_, err := run()
+			return err
+		}
+
+		brokerConfig := &broker.BrokerConfig{
+			Auditor: fakeAuditor,
+			Registry: pkgBroker.BrokerRegistry{
+				"test-service": &pkgBroker.ServiceDefinition{
+					ID:   offeringID,
+					Name: "test-service",
+					Plans: []pkgBroker.ServicePlan{
+						{ServicePlan: domain.ServicePlan{ID: planID, Name: "test-plan"}},
+					},
+					ProvisionInputVariables: []pkgBroker.BrokerVariable{
+						{FieldName: "size", Type: pkgBroker.JSONTypeString, Details: "the size"},
+					},
+					ProviderBuilder: func(logger lager.Logger, store pkgBroker.ServiceProviderStorage) pkgBroker.ServiceProvider {
+						return fakeServiceProvider
+					},
+				},
+			},
+		}
+
+		fakeStorage = &brokerfakes.FakeStorage{}
+
+		var err error
+		serviceBroker, err = broker.New(brokerConfig, fakeStorage, decider.Decider{}, utils.NewLogger("brokers-test"))
+		Expect(err).ToNot(HaveOccurred())
+
+		opts = broker.AdoptOptions{
+			Service:          "test-service",
+			Plan:             "test-plan",
+			InstanceGUID:     instanceID,
+			OrganizationGUID: "test-org-id",
+			SpaceGUID:        "test-space-id",
+			Params:           map[string]interface{}{"size": "large", "maintenance_window": "sunday 02:00-04:00"},
+			Resources:        map[string]string{"aws_db_instance.db": "existing-db"},
+		}
+	})
+
+	It("adopts the resources and registers the service instance", func() {
+		var confirmedPlan string
+		adopted, err := serviceBroker.Adopt(context.TODO(), opts, func(plan string) bool {
+			confirmedPlan = plan
+			return true
+		})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(adopted).To(BeTrue())
+		Expect(confirmedPlan).To(Equal("the plan"))
+
+		By("adopting the resources with the provision variables")
+		Expect(fakeServiceProvider.AdoptCallCount()).To(Equal(1))
+		_, vars, resources, _ := fakeServiceProvider.AdoptArgsForCall(0)
+		Expect(resources).To(Equal(opts.Resources))
+		Expect(vars.GetString("size")).To(Equal("large"))
+
+		By("registering the service instance")
+		Expect(fakeStorage.StoreServiceInstanceDetailsCallCount()).To(Equal(1))
+		Expect(fakeStorage.StoreServiceInstanceDetailsArgsForCall(0)).To(Equal(storage.ServiceInstanceDetails{
+			GUID:              instanceID,
+			Outputs:           storage.JSONObject{"hostname": "db.example.com"},
+			ServiceGUID:       offeringID,
+			PlanGUID:          planID,
+			SpaceGUID:         "test-space-id",
+			OrganizationGUID:  "test-org-id",
+			MaintenanceWindow: "sunday 02:00-04:00",
+		}))
+		Expect(fakeStorage.StoreProvisionRequestDetailsCallCount()).To(Equal(1))
+		actualInstanceID, actualParams := fakeStorage.StoreProvisionRequestDetailsArgsForCall(0)
+		Expect(actualInstanceID).To(Equal(instanceID))
+		Expect(actualParams).To(Equal(storage.JSONObject{"size": "large"}))
+	})
+
+	It("records an audit event", func() {
+		_, err := serviceBroker.Adopt(context.TODO(), opts, confirm)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(fakeAuditor.AuditCallCount()).To(Equal(1))
+		_, operation, actualInstanceID, params, _ := fakeAuditor.AuditArgsForCall(0)
+		Expect(operation).To(Equal("adopt"))
+		Expect(actualInstanceID).To(Equal(instanceID))
+		Expect(params).To(MatchJSON(`{"size":"large","maintenance_window":"sunday 02:00-04:00"}`))
+	})
+
+	It("records a cancelled adoption as failed in the audit log", func() {
+		var auditedErr error
+		fakeAuditor.AuditStub = func(_ context.Context, _, _ string, _ json.RawMessage, run func() (bool, error)) error {
+			_, auditedErr = run()
+			return auditedErr
+		}
+
+		adopted, err := serviceBroker.Adopt(context.TODO(), opts, func(string) bool { return false })
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(adopted).To(BeFalse())
+		Expect(auditedErr).To(MatchError("adoption cancelled"))
+	})
+
+	It("finds the service and plan by ID", func() {
+		opts.Service = offeringID
+		opts.Plan = planID
+
+		adopted, err := serviceBroker.Adopt(context.TODO(), opts, confirm)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(adopted).To(BeTrue())
+	})
+
+	It("does not register the service instance when the plan is not confirmed", func() {
+		adopted, err := serviceBroker.Adopt(context.TODO(), opts, func(string) bool { return false })
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(adopted).To(BeFalse())
+		Expect(fakeStorage.StoreServiceInstanceDetailsCallCount()).To(BeZero())
+	})
+
+	It("fails when the service instance already exists", func() {
+		fakeStorage.ExistsServiceInstanceDetailsReturns(true, nil)
+
+		_, err := serviceBroker.Adopt(context.TODO(), opts, confirm)
+
+		Expect(err).To(MatchError(`service instance "test-instance-id" already exists`))
+		Expect(fakeServiceProvider.AdoptCallCount()).To(BeZero())
+	})
+
+	It("fails when the service or plan is unknown", func() {
+		opts.Plan = "unknown-plan"
+		_, err := serviceBroker.Adopt(context.TODO(), opts, confirm)
+		Expect(err).To(MatchError(`unknown plan "unknown-plan" of service "test-service"`))
+
+		opts.Service = "unknown-service"
+		_, err = serviceBroker.Adopt(context.TODO(), opts, confirm)
+		Expect(err).To(MatchError(`unknown service: "unknown-service"`))
+	})
+
+	It("fails when the parameters are not valid", func() {
+		opts.Params = map[string]interface{}{"color": "red"}
+
+		_, err := serviceBroker.Adopt(context.TODO(), opts, confirm)
+
+		Expect(err).To(MatchError(ContainSubstring("additional properties are not allowed: color")))
+	})
+
+	It("fails when the adoption fails", func() {
+		fakeServiceProvider.AdoptReturns(false, errors.New("cannot import"))
+		fakeServiceProvider.AdoptStub = nil
+
+		_, err := serviceBroker.Adopt(context.TODO(), opts, confirm)
+
+		Expect(err).To(MatchError("cannot import"))
+		Expect(fakeStorage.StoreServiceInstanceDetailsCallCount()).To(BeZero())
+	})
+})
@@ -0,0 +1,30 @@
+package broker
+
+import (
+	"context"
+	"encoding/json"
+)
+
+//counterfeiter:generate . Auditor
+
+// Auditor records the operations on service instances that the broker starts itself, such as upgrades,
+// instance actions and adoptions. Requests to the OSBAPI endpoints are audited by wrapping the broker instead.
+type Auditor interface {
+	Audit(ctx context.Context, operation, instanceID string, params json.RawMessage, run func() (isAsync bool, err error)) error
+}
+
+// noAuditor runs operations without recording them
+type noAuditor struct{}
+
+func (noAuditor) Audit(_ context.Context, _, _ string, _ json.RawMessage, run func() (bool, error)) error {
+	_, err := run()
+	return err
+}
+
+// auditParams encodes request parameters for the audit log, which records a hash of them
+func auditParams(params map[string]interface{}) (json.RawMessage, error) {
+	if len(params) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(params)
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/cloudfoundry/cloud-service-broker/pkg/varcontext"
 
@@ -12,6 +13,7 @@ import (
 	"github.com/cloudfoundry/cloud-service-broker/internal/paramparser"
 	"github.com/cloudfoundry/cloud-service-broker/internal/storage"
 	"github.com/cloudfoundry/cloud-service-broker/pkg/broker"
+	"github.com/cloudfoundry/cloud-service-broker/pkg/credstore"
 	"github.com/cloudfoundry/cloud-service-broker/utils/correlation"
 	"github.com/cloudfoundry/cloud-service-broker/utils/request"
 	"github.com/pivotal-cf/brokerapi/v8/domain"
@@ -39,7 +41,7 @@ func (broker *ServiceBroker) Bind(ctx context.Context, instanceID, bindingID str
 	case err != nil:
 		return domain.Binding{}, fmt.Errorf("error checking for existing binding: %w", err)
 	case exists:
-		return domain.Binding{}, apiresponses.ErrBindingAlreadyExists
+		return broker.bindExistingBinding(instanceID, bindingID, details)
 	}
 
 	// get existing service instance details
@@ -55,7 +57,7 @@ func (broker *ServiceBroker) Bind(ctx context.Context, instanceID, bindingID str
 
 	err = serviceProvider.CheckUpgradeAvailable(generateTFInstanceID(instanceID))
 	if err != nil {
-		return domain.Binding{}, fmt.Errorf("failed to bind: %s", err.Error())
+		return domain.Binding{}, fmt.Errorf("failed to bind: %s%s", err.Error(), nextMaintenanceWindow(instanceRecord, time.Now()))
 	}
 
 	parsedDetails, err := paramparser.ParseBindDetails(details)
@@ -74,9 +76,11 @@ func (broker *ServiceBroker) Bind(ctx context.Context, instanceID, bindingID str
 		return domain.Binding{}, err
 	}
 
+	instanceState := getInstanceState(ctx, broker.Logger, serviceProvider, instanceID)
+
 	// validate parameters meet the service's schema and merge the plan's vars with
 	// the user's
-	vars, err := serviceDefinition.BindVariables(instanceRecord, bindingID, parsedDetails, plan, request.DecodeOriginatingIdentityHeader(ctx))
+	vars, err := serviceDefinition.BindVariables(instanceRecord, instanceState, bindingID, parsedDetails, plan, request.DecodeOriginatingIdentityHeader(ctx))
 	if err != nil {
 		return domain.Binding{}, fmt.Errorf("error generating bind variables: %w", err)
 	}
@@ -103,6 +107,10 @@ func (broker *ServiceBroker) Bind(ctx context.Context, instanceID, bindingID str
 		ServiceInstanceGUID: instanceID,
 		ServiceBindingGUID:  bindingID,
 		RequestDetails:      parsedDetails.RequestParams,
+		RequestContext:      parsedDetails.RequestContext,
+		PlanGUID:            parsedDetails.PlanID,
+		AppGUID:             parsedDetails.AppGUID,
+		BindResource:        parsedDetails.BindResource,
 	}
 
 	if err := broker.store.StoreBindRequestDetails(bindRequest); err != nil {
@@ -117,7 +125,7 @@ func (broker *ServiceBroker) Bind(ctx context.Context, instanceID, bindingID str
 	if broker.Credstore != nil {
 		credentialName := getCredentialName(broker.getServiceName(serviceDefinition), bindingID)
 
-		_, err := broker.Credstore.Put(credentialName, binding.Credentials)
+		_, err := credstore.PutWithContext(broker.Credstore, credentialName, binding.Credentials, parsedDetails.RequestContext)
 		if err != nil {
 			return domain.Binding{}, fmt.Errorf("bind failure: unable to put credentials in Credstore: %w", err)
 		}
@@ -127,14 +135,84 @@ func (broker *ServiceBroker) Bind(ctx context.Context, instanceID, bindingID str
 			return domain.Binding{}, fmt.Errorf("bind failure: unable to add Credstore permissions to app: %w", err)
 		}
 
-		binding.Credentials = map[string]interface{}{
-			"credhub-ref": credentialName,
-		}
+		binding.Credentials = credstore.Reference(broker.Credstore, credentialName)
+	}
+
+	return *binding, nil
+}
+
+// bindExistingBinding answers a bind request for a binding that already exists. A repeated
+// identical request returns the existing credentials, while a request that differs from the
+// original conflicts with it.
+func (broker *ServiceBroker) bindExistingBinding(instanceID, bindingID string, details domain.BindDetails) (domain.Binding, error) {
+	parsedDetails, err := paramparser.ParseBindDetails(details)
+	if err != nil {
+		return domain.Binding{}, ErrInvalidUserInput
+	}
+
+	storedCreds, err := broker.store.GetServiceBindingCredentials(bindingID, instanceID)
+	if err != nil {
+		return domain.Binding{}, fmt.Errorf("error retrieving binding credentials: %w", err)
+	}
+
+	if storedCreds.ServiceGUID != parsedDetails.ServiceID {
+		return domain.Binding{}, apiresponses.ErrBindingAlreadyExists
+	}
+
+	storedRequest, err := broker.store.GetBindRequest(bindingID, instanceID)
+	if err != nil {
+		return domain.Binding{}, fmt.Errorf("error retrieving bind request details for %q: %w", bindingID, err)
+	}
+
+	if !sameParameters(storedRequest.RequestDetails, parsedDetails.RequestParams) {
+		return domain.Binding{}, apiresponses.ErrBindingAlreadyExists
 	}
 
+	// The plan, app and bind resource were not recorded for bindings created by older brokers
+	if storedRequest.PlanGUID != "" &&
+		(storedRequest.PlanGUID != parsedDetails.PlanID ||
+			storedRequest.AppGUID != parsedDetails.AppGUID ||
+			!sameParameters(storedRequest.BindResource, parsedDetails.BindResource)) {
+		return domain.Binding{}, apiresponses.ErrBindingAlreadyExists
+	}
+
+	instanceRecord, err := broker.store.GetServiceInstanceDetails(instanceID)
+	if err != nil {
+		return domain.Binding{}, fmt.Errorf("error retrieving service instance details: %w", err)
+	}
+
+	serviceDefinition, err := broker.registry.GetServiceByID(instanceRecord.ServiceGUID)
+	if err != nil {
+		return domain.Binding{}, fmt.Errorf("error retrieving service definition: %w", err)
+	}
+
+	binding, err := buildInstanceCredentials(storedCreds.Credentials, instanceRecord.Outputs)
+	if err != nil {
+		return domain.Binding{}, fmt.Errorf("error building credentials: %w", err)
+	}
+
+	if broker.Credstore != nil {
+		binding.Credentials = credstore.Reference(broker.Credstore, getCredentialName(broker.getServiceName(serviceDefinition), bindingID))
+	}
+
+	binding.AlreadyExists = true
 	return *binding, nil
 }
 
+// getInstanceState gets the view of the Terraform state of an instance that bindings use as `instance.state`.
+// The state may be absent, for example when the instance was provisioned by an older broker, or unreadable,
+// and this must not stop bindings from being created or removed, so an empty view is used instead.
+func getInstanceState(ctx context.Context, logger lager.Logger, serviceProvider broker.ServiceProvider, instanceID string) storage.JSONObject {
+	instanceState, err := serviceProvider.GetInstanceState(ctx, instanceID)
+	if err != nil {
+		logger.Error("retrieving-service-instance-state", err, correlation.ID(ctx), lager.Data{
+			"instance_id": instanceID,
+		})
+		return storage.JSONObject{}
+	}
+	return instanceState
+}
+
 func validateBindParameters(params map[string]interface{}, validUserInputFields []broker.BrokerVariable) error {
 	if len(params) == 0 {
 		return nil
@@ -156,9 +156,42 @@ var _ = Describe("Bind", func() {
 				RequestDetails: map[string]interface{}{
 					"bind_field_1": "bind_value_1",
 				},
+				PlanGUID: planID,
+				AppGUID:  appGUID,
 			}))
 		})
 
+		It("stores the request context", func() {
+			bindDetails.RawContext = json.RawMessage(`{"platform":"kubernetes","namespace":"app-namespace"}`)
+
+			_, err := serviceBroker.Bind(context.TODO(), instanceID, bindingID, bindDetails, false)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fakeStorage.StoreBindRequestDetailsCallCount()).To(Equal(1))
+			Expect(fakeStorage.StoreBindRequestDetailsArgsForCall(0).RequestContext).To(Equal(storage.JSONObject{
+				"platform":  "kubernetes",
+				"namespace": "app-namespace",
+			}))
+		})
+
+		When("the credstore refers to credentials in its own way", func() {
+			BeforeEach(func() {
+				brokerConfig.Credstore = vaultCredStore{FakeCredStore: fakeCredStore}
+				var err error
+				serviceBroker, err = broker.New(brokerConfig, fakeStorage, decider.Decider{}, utils.NewLogger("bind-test-vault-credstore"))
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("returns the reference from the credstore", func() {
+				response, err := serviceBroker.Bind(context.TODO(), instanceID, bindingID, bindDetails, false)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(response.Credentials).To(Equal(map[string]interface{}{
+					"vault-ref": "/c/csb/test-service/test-binding-id/secrets-and-services",
+				}))
+				Expect(fakeCredStore.PutCallCount()).To(Equal(1))
+			})
+		})
+
 		When("credstore disabled", func() {
 			BeforeEach(func() {
 				brokerConfig.Credstore = nil
@@ -208,8 +241,140 @@ var _ = Describe("Bind", func() {
 
 				Expect(actualVars.GetString("copyOriginatingIdentity")).To(Equal(`{"platform":"cloudfoundry","value":{"user_id":"683ea748-3092-4ff4-b656-39cacc4d5360"}}`))
 			})
+
+			It("makes the state of the instance available to computed variables", func() {
+				brokerConfig.Registry["test-service"].BindComputedVariables = []varcontext.DefaultVariable{
+					{Name: "endpoint", Default: `${instance.state["aws_db_instance.db.endpoint"]}`, Overwrite: true},
+				}
+				fakeServiceProvider.GetInstanceStateReturns(storage.JSONObject{"aws_db_instance.db.endpoint": "db.example.com"}, nil)
+
+				_, err := serviceBroker.Bind(context.TODO(), instanceID, bindingID, bindDetails, true)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeServiceProvider.GetInstanceStateCallCount()).To(Equal(1))
+				_, actualInstanceID := fakeServiceProvider.GetInstanceStateArgsForCall(0)
+				Expect(actualInstanceID).To(Equal(instanceID))
+
+				_, actualVars := fakeServiceProvider.BindArgsForCall(0)
+				Expect(actualVars.GetString("endpoint")).To(Equal("db.example.com"))
+			})
+		})
+
+	})
+
+	Describe("repeated bind", func() {
+		BeforeEach(func() {
+			fakeStorage.ExistsServiceBindingCredentialsReturns(true, nil)
+			fakeStorage.GetServiceBindingCredentialsReturns(storage.ServiceBindingCredentials{
+				ServiceGUID:         offeringID,
+				ServiceInstanceGUID: instanceID,
+				BindingGUID:         bindingID,
+				Credentials:         map[string]interface{}{"fakeOutput": "fakeValue"},
+			}, nil)
+			fakeStorage.GetBindRequestReturns(storage.BindRequestDetails{
+				RequestDetails: storage.JSONObject{"bind_field_1": "bind_value_1"},
+				PlanGUID:       planID,
+				AppGUID:        appGUID,
+			}, nil)
+		})
+
+		It("returns the existing binding without binding again", func() {
+			response, err := serviceBroker.Bind(context.TODO(), instanceID, bindingID, bindDetails, false)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(response).To(Equal(domain.Binding{
+				AlreadyExists: true,
+				Credentials: map[string]interface{}{
+					"credhub-ref": "/c/csb/test-service/test-binding-id/secrets-and-services",
+				},
+			}))
+
+			actualBindingID, actualInstanceID := fakeStorage.GetBindRequestArgsForCall(0)
+			Expect(actualBindingID).To(Equal(bindingID))
+			Expect(actualInstanceID).To(Equal(instanceID))
+
+			Expect(fakeServiceProvider.BindCallCount()).To(BeZero())
+			Expect(fakeStorage.CreateServiceBindingCredentialsCallCount()).To(BeZero())
+			Expect(fakeCredStore.PutCallCount()).To(BeZero())
+		})
+
+		It("conflicts with a bind request for a different plan", func() {
+			bindDetails.PlanID = "other-plan-id"
+
+			_, err := serviceBroker.Bind(context.TODO(), instanceID, bindingID, bindDetails, false)
+
+			Expect(err).To(MatchError(apiresponses.ErrBindingAlreadyExists))
+			Expect(fakeServiceProvider.BindCallCount()).To(BeZero())
+		})
+
+		It("conflicts with a bind request for a different app", func() {
+			bindDetails.AppGUID = "other-app-guid"
+
+			_, err := serviceBroker.Bind(context.TODO(), instanceID, bindingID, bindDetails, false)
+
+			Expect(err).To(MatchError(apiresponses.ErrBindingAlreadyExists))
+			Expect(fakeServiceProvider.BindCallCount()).To(BeZero())
+		})
+
+		It("conflicts with a bind request for a different bind resource", func() {
+			bindDetails.BindResource = &domain.BindResource{AppGuid: appGUID, Route: "other.example.com"}
+
+			_, err := serviceBroker.Bind(context.TODO(), instanceID, bindingID, bindDetails, false)
+
+			Expect(err).To(MatchError(apiresponses.ErrBindingAlreadyExists))
+		})
+
+		It("returns the existing binding when the bind resource is the same", func() {
+			bindDetails.BindResource = &domain.BindResource{AppGuid: appGUID}
+			fakeStorage.GetBindRequestReturns(storage.BindRequestDetails{
+				RequestDetails: storage.JSONObject{"bind_field_1": "bind_value_1"},
+				PlanGUID:       planID,
+				AppGUID:        appGUID,
+				BindResource:   storage.JSONObject{"app_guid": appGUID},
+			}, nil)
+
+			response, err := serviceBroker.Bind(context.TODO(), instanceID, bindingID, bindDetails, false)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.AlreadyExists).To(BeTrue())
+		})
+
+		When("the binding was created before the plan and app were recorded", func() {
+			BeforeEach(func() {
+				fakeStorage.GetBindRequestReturns(storage.BindRequestDetails{
+					RequestDetails: storage.JSONObject{"bind_field_1": "bind_value_1"},
+				}, nil)
+			})
+
+			It("compares only the service and parameters", func() {
+				bindDetails.AppGUID = "other-app-guid"
+
+				response, err := serviceBroker.Bind(context.TODO(), instanceID, bindingID, bindDetails, false)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(response.AlreadyExists).To(BeTrue())
+			})
 		})
 
+		When("credstore disabled", func() {
+			BeforeEach(func() {
+				brokerConfig.Credstore = nil
+				var err error
+				serviceBroker, err = broker.New(brokerConfig, fakeStorage, decider.Decider{}, utils.NewLogger("bind-test"))
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("returns the existing credentials", func() {
+				response, err := serviceBroker.Bind(context.TODO(), instanceID, bindingID, bindDetails, false)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(response.AlreadyExists).To(BeTrue())
+				Expect(response.Credentials).To(Equal(map[string]interface{}{
+					"fakeInstanceOutput": "fakeInstanceValue",
+					"fakeOutput":         "fakeValue",
+				}))
+			})
+		})
 	})
 
 	Describe("unsuccessful bind", func() {
@@ -225,9 +390,26 @@ var _ = Describe("Bind", func() {
 			})
 		})
 
-		When("the service binding credentials already exist", func() {
+		When("the service binding credentials already exist with different parameters", func() {
+			BeforeEach(func() {
+				fakeStorage.ExistsServiceBindingCredentialsReturns(true, nil)
+				fakeStorage.GetServiceBindingCredentialsReturns(storage.ServiceBindingCredentials{ServiceGUID: offeringID}, nil)
+				fakeStorage.GetBindRequestReturns(storage.BindRequestDetails{RequestDetails: storage.JSONObject{"bind_field_1": "other_value"}}, nil)
+			})
+
+			It("should error", func() {
+				_, err := serviceBroker.Bind(context.TODO(), instanceID, bindingID, bindDetails, false)
+
+				Expect(err).To(MatchError(apiresponses.ErrBindingAlreadyExists))
+				Expect(fakeServiceProvider.BindCallCount()).To(BeZero())
+			})
+		})
+
+		When("the service binding credentials already exist for a different service", func() {
 			BeforeEach(func() {
 				fakeStorage.ExistsServiceBindingCredentialsReturns(true, nil)
+				fakeStorage.GetServiceBindingCredentialsReturns(storage.ServiceBindingCredentials{ServiceGUID: "other-service-id"}, nil)
+				fakeStorage.GetBindRequestReturns(storage.BindRequestDetails{RequestDetails: storage.JSONObject{"bind_field_1": "bind_value_1"}}, nil)
 			})
 
 			It("should error", func() {
@@ -249,6 +431,20 @@ var _ = Describe("Bind", func() {
 			})
 		})
 
+		When("error retrieving the service instance state", func() {
+			BeforeEach(func() {
+				fakeServiceProvider.GetInstanceStateReturns(nil, fmt.Errorf("error"))
+			})
+
+			It("binds with an empty instance state", func() {
+				_, err := serviceBroker.Bind(context.TODO(), instanceID, bindingID, bindDetails, false)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fakeServiceProvider.BindCallCount()).To(Equal(1))
+				Expect(fakeStorage.StoreBindRequestDetailsCallCount()).To(Equal(1))
+			})
+		})
+
 		When("error validating the service exists", func() {
 			const nonExistentService = "non-existent-service"
 
@@ -381,3 +577,12 @@ var _ = Describe("Bind", func() {
 		})
 	})
 })
+
+// vaultCredStore is a credstore that refers to credentials in the same way as Vault
+type vaultCredStore struct {
+	*credstorefakes.FakeCredStore
+}
+
+func (vaultCredStore) Reference(key string) map[string]interface{} {
+	return map[string]interface{}{"vault-ref": key}
+}
@@ -44,7 +44,11 @@ type ServiceBroker struct {
 
 	store   Storage
 	decider Decider
+	quotas  []Quota
+	auditor Auditor
 	Logger  lager.Logger
+
+	upgradeAllJobs upgradeAllJobs
 }
 
 type TFDeploymentGUID string
@@ -52,12 +56,19 @@ type TFDeploymentGUID string
 // New creates a ServiceBroker.
 // Exactly one of ServiceBroker or error will be nil when returned.
 func New(cfg *BrokerConfig, store Storage, decider Decider, logger lager.Logger) (*ServiceBroker, error) {
+	var auditor Auditor = noAuditor{}
+	if cfg.Auditor != nil {
+		auditor = cfg.Auditor
+	}
+
 	return &ServiceBroker{
 		registry:  cfg.Registry,
 		Credstore: cfg.Credstore,
 		Logger:    logger,
 		store:     store,
 		decider:   decider,
+		quotas:    cfg.Quotas,
+		auditor:   auditor,
 	}, nil
 }
 
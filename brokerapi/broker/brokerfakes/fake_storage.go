@@ -9,6 +9,19 @@ import (
 )
 
 type FakeStorage struct {
+	CountServiceInstanceDetailsStub        func(storage.ServiceInstanceFilter) (int64, error)
+	countServiceInstanceDetailsMutex       sync.RWMutex
+	countServiceInstanceDetailsArgsForCall []struct {
+		arg1 storage.ServiceInstanceFilter
+	}
+	countServiceInstanceDetailsReturns struct {
+		result1 int64
+		result2 error
+	}
+	countServiceInstanceDetailsReturnsOnCall map[int]struct {
+		result1 int64
+		result2 error
+	}
 	CreateServiceBindingCredentialsStub        func(storage.ServiceBindingCredentials) error
 	createServiceBindingCredentialsMutex       sync.RWMutex
 	createServiceBindingCredentialsArgsForCall []struct {
@@ -66,6 +79,28 @@ type FakeStorage struct {
 	deleteServiceInstanceDetailsReturnsOnCall map[int]struct {
 		result1 error
 	}
+	DeleteTerraformDeploymentStub        func(string) error
+	deleteTerraformDeploymentMutex       sync.RWMutex
+	deleteTerraformDeploymentArgsForCall []struct {
+		arg1 string
+	}
+	deleteTerraformDeploymentReturns struct {
+		result1 error
+	}
+	deleteTerraformDeploymentReturnsOnCall map[int]struct {
+		result1 error
+	}
+	DeleteTerraformStateStub        func(string) error
+	deleteTerraformStateMutex       sync.RWMutex
+	deleteTerraformStateArgsForCall []struct {
+		arg1 string
+	}
+	deleteTerraformStateReturns struct {
+		result1 error
+	}
+	deleteTerraformStateReturnsOnCall map[int]struct {
+		result1 error
+	}
 	ExistsServiceBindingCredentialsStub        func(string, string) (bool, error)
 	existsServiceBindingCredentialsMutex       sync.RWMutex
 	existsServiceBindingCredentialsArgsForCall []struct {
@@ -106,6 +141,45 @@ type FakeStorage struct {
 		result1 bool
 		result2 error
 	}
+	FinishOperationStub        func(storage.Operation) error
+	finishOperationMutex       sync.RWMutex
+	finishOperationArgsForCall []struct {
+		arg1 storage.Operation
+	}
+	finishOperationReturns struct {
+		result1 error
+	}
+	finishOperationReturnsOnCall map[int]struct {
+		result1 error
+	}
+	GetBindRequestStub        func(string, string) (storage.BindRequestDetails, error)
+	getBindRequestMutex       sync.RWMutex
+	getBindRequestArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	getBindRequestReturns struct {
+		result1 storage.BindRequestDetails
+		result2 error
+	}
+	getBindRequestReturnsOnCall map[int]struct {
+		result1 storage.BindRequestDetails
+		result2 error
+	}
+	GetBindRequestContextStub        func(string, string) (storage.JSONObject, error)
+	getBindRequestContextMutex       sync.RWMutex
+	getBindRequestContextArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	getBindRequestContextReturns struct {
+		result1 storage.JSONObject
+		result2 error
+	}
+	getBindRequestContextReturnsOnCall map[int]struct {
+		result1 storage.JSONObject
+		result2 error
+	}
 	GetBindRequestDetailsStub        func(string, string) (storage.JSONObject, error)
 	getBindRequestDetailsMutex       sync.RWMutex
 	getBindRequestDetailsArgsForCall []struct {
@@ -120,6 +194,19 @@ type FakeStorage struct {
 		result1 storage.JSONObject
 		result2 error
 	}
+	GetOperationsStub        func(storage.OperationFilter) ([]storage.Operation, error)
+	getOperationsMutex       sync.RWMutex
+	getOperationsArgsForCall []struct {
+		arg1 storage.OperationFilter
+	}
+	getOperationsReturns struct {
+		result1 []storage.Operation
+		result2 error
+	}
+	getOperationsReturnsOnCall map[int]struct {
+		result1 []storage.Operation
+		result2 error
+	}
 	GetProvisionRequestDetailsStub        func(string) (storage.JSONObject, error)
 	getProvisionRequestDetailsMutex       sync.RWMutex
 	getProvisionRequestDetailsArgsForCall []struct {
@@ -160,6 +247,19 @@ type FakeStorage struct {
 		result1 storage.ServiceInstanceDetails
 		result2 error
 	}
+	GetServiceInstanceGUIDsStub        func(storage.ServiceInstanceFilter) ([]string, error)
+	getServiceInstanceGUIDsMutex       sync.RWMutex
+	getServiceInstanceGUIDsArgsForCall []struct {
+		arg1 storage.ServiceInstanceFilter
+	}
+	getServiceInstanceGUIDsReturns struct {
+		result1 []string
+		result2 error
+	}
+	getServiceInstanceGUIDsReturnsOnCall map[int]struct {
+		result1 []string
+		result2 error
+	}
 	GetTerraformDeploymentStub        func(string) (storage.TerraformDeployment, error)
 	getTerraformDeploymentMutex       sync.RWMutex
 	getTerraformDeploymentArgsForCall []struct {
@@ -173,6 +273,19 @@ type FakeStorage struct {
 		result1 storage.TerraformDeployment
 		result2 error
 	}
+	GetTerraformStateStub        func(string) ([]byte, error)
+	getTerraformStateMutex       sync.RWMutex
+	getTerraformStateArgsForCall []struct {
+		arg1 string
+	}
+	getTerraformStateReturns struct {
+		result1 []byte
+		result2 error
+	}
+	getTerraformStateReturnsOnCall map[int]struct {
+		result1 []byte
+		result2 error
+	}
 	StoreBindRequestDetailsStub        func(storage.BindRequestDetails) error
 	storeBindRequestDetailsMutex       sync.RWMutex
 	storeBindRequestDetailsArgsForCall []struct {
@@ -184,6 +297,17 @@ type FakeStorage struct {
 	storeBindRequestDetailsReturnsOnCall map[int]struct {
 		result1 error
 	}
+	StoreOperationStub        func(storage.Operation) error
+	storeOperationMutex       sync.RWMutex
+	storeOperationArgsForCall []struct {
+		arg1 storage.Operation
+	}
+	storeOperationReturns struct {
+		result1 error
+	}
+	storeOperationReturnsOnCall map[int]struct {
+		result1 error
+	}
 	StoreProvisionRequestDetailsStub        func(string, storage.JSONObject) error
 	storeProvisionRequestDetailsMutex       sync.RWMutex
 	storeProvisionRequestDetailsArgsForCall []struct {
@@ -218,10 +342,109 @@ type FakeStorage struct {
 	storeTerraformDeploymentReturnsOnCall map[int]struct {
 		result1 error
 	}
+	StoreTerraformStateStub        func(string, []byte) error
+	storeTerraformStateMutex       sync.RWMutex
+	storeTerraformStateArgsForCall []struct {
+		arg1 string
+		arg2 []byte
+	}
+	storeTerraformStateReturns struct {
+		result1 error
+	}
+	storeTerraformStateReturnsOnCall map[int]struct {
+		result1 error
+	}
+	StoreWebhookDeliveryStub        func(storage.WebhookDelivery) error
+	storeWebhookDeliveryMutex       sync.RWMutex
+	storeWebhookDeliveryArgsForCall []struct {
+		arg1 storage.WebhookDelivery
+	}
+	storeWebhookDeliveryReturns struct {
+		result1 error
+	}
+	storeWebhookDeliveryReturnsOnCall map[int]struct {
+		result1 error
+	}
+	UnlockTerraformStateStub        func(string, string) error
+	unlockTerraformStateMutex       sync.RWMutex
+	unlockTerraformStateArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	unlockTerraformStateReturns struct {
+		result1 error
+	}
+	unlockTerraformStateReturnsOnCall map[int]struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeStorage) CountServiceInstanceDetails(arg1 storage.ServiceInstanceFilter) (int64, error) {
+	fake.countServiceInstanceDetailsMutex.Lock()
+	ret, specificReturn := fake.countServiceInstanceDetailsReturnsOnCall[len(fake.countServiceInstanceDetailsArgsForCall)]
+	fake.countServiceInstanceDetailsArgsForCall = append(fake.countServiceInstanceDetailsArgsForCall, struct {
+		arg1 storage.ServiceInstanceFilter
+	}{arg1})
+	stub := fake.CountServiceInstanceDetailsStub
+	fakeReturns := fake.countServiceInstanceDetailsReturns
+	fake.recordInvocation("CountServiceInstanceDetails", []interface{}{arg1})
+	fake.countServiceInstanceDetailsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeStorage) CountServiceInstanceDetailsCallCount() int {
+	fake.countServiceInstanceDetailsMutex.RLock()
+	defer fake.countServiceInstanceDetailsMutex.RUnlock()
+	return len(fake.countServiceInstanceDetailsArgsForCall)
+}
+
+func (fake *FakeStorage) CountServiceInstanceDetailsCalls(stub func(storage.ServiceInstanceFilter) (int64, error)) {
+	fake.countServiceInstanceDetailsMutex.Lock()
+	defer fake.countServiceInstanceDetailsMutex.Unlock()
+	fake.CountServiceInstanceDetailsStub = stub
+}
+
+func (fake *FakeStorage) CountServiceInstanceDetailsArgsForCall(i int) storage.ServiceInstanceFilter {
+	fake.countServiceInstanceDetailsMutex.RLock()
+	defer fake.countServiceInstanceDetailsMutex.RUnlock()
+	argsForCall := fake.countServiceInstanceDetailsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeStorage) CountServiceInstanceDetailsReturns(result1 int64, result2 error) {
+	fake.countServiceInstanceDetailsMutex.Lock()
+	defer fake.countServiceInstanceDetailsMutex.Unlock()
+	fake.CountServiceInstanceDetailsStub = nil
+	fake.countServiceInstanceDetailsReturns = struct {
+		result1 int64
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeStorage) CountServiceInstanceDetailsReturnsOnCall(i int, result1 int64, result2 error) {
+	fake.countServiceInstanceDetailsMutex.Lock()
+	defer fake.countServiceInstanceDetailsMutex.Unlock()
+	fake.CountServiceInstanceDetailsStub = nil
+	if fake.countServiceInstanceDetailsReturnsOnCall == nil {
+		fake.countServiceInstanceDetailsReturnsOnCall = make(map[int]struct {
+			result1 int64
+			result2 error
+		})
+	}
+	fake.countServiceInstanceDetailsReturnsOnCall[i] = struct {
+		result1 int64
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeStorage) CreateServiceBindingCredentials(arg1 storage.ServiceBindingCredentials) error {
 	fake.createServiceBindingCredentialsMutex.Lock()
 	ret, specificReturn := fake.createServiceBindingCredentialsReturnsOnCall[len(fake.createServiceBindingCredentialsArgsForCall)]
@@ -529,6 +752,128 @@ func (fake *FakeStorage) DeleteServiceInstanceDetailsReturnsOnCall(i int, result
 	}{result1}
 }
 
+func (fake *FakeStorage) DeleteTerraformDeployment(arg1 string) error {
+	fake.deleteTerraformDeploymentMutex.Lock()
+	ret, specificReturn := fake.deleteTerraformDeploymentReturnsOnCall[len(fake.deleteTerraformDeploymentArgsForCall)]
+	fake.deleteTerraformDeploymentArgsForCall = append(fake.deleteTerraformDeploymentArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.DeleteTerraformDeploymentStub
+	fakeReturns := fake.deleteTerraformDeploymentReturns
+	fake.recordInvocation("DeleteTerraformDeployment", []interface{}{arg1})
+	fake.deleteTerraformDeploymentMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeStorage) DeleteTerraformDeploymentCallCount() int {
+	fake.deleteTerraformDeploymentMutex.RLock()
+	defer fake.deleteTerraformDeploymentMutex.RUnlock()
+	return len(fake.deleteTerraformDeploymentArgsForCall)
+}
+
+func (fake *FakeStorage) DeleteTerraformDeploymentCalls(stub func(string) error) {
+	fake.deleteTerraformDeploymentMutex.Lock()
+	defer fake.deleteTerraformDeploymentMutex.Unlock()
+	fake.DeleteTerraformDeploymentStub = stub
+}
+
+func (fake *FakeStorage) DeleteTerraformDeploymentArgsForCall(i int) string {
+	fake.deleteTerraformDeploymentMutex.RLock()
+	defer fake.deleteTerraformDeploymentMutex.RUnlock()
+	argsForCall := fake.deleteTerraformDeploymentArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeStorage) DeleteTerraformDeploymentReturns(result1 error) {
+	fake.deleteTerraformDeploymentMutex.Lock()
+	defer fake.deleteTerraformDeploymentMutex.Unlock()
+	fake.DeleteTerraformDeploymentStub = nil
+	fake.deleteTerraformDeploymentReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeStorage) DeleteTerraformDeploymentReturnsOnCall(i int, result1 error) {
+	fake.deleteTerraformDeploymentMutex.Lock()
+	defer fake.deleteTerraformDeploymentMutex.Unlock()
+	fake.DeleteTerraformDeploymentStub = nil
+	if fake.deleteTerraformDeploymentReturnsOnCall == nil {
+		fake.deleteTerraformDeploymentReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteTerraformDeploymentReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeStorage) DeleteTerraformState(arg1 string) error {
+	fake.deleteTerraformStateMutex.Lock()
+	ret, specificReturn := fake.deleteTerraformStateReturnsOnCall[len(fake.deleteTerraformStateArgsForCall)]
+	fake.deleteTerraformStateArgsForCall = append(fake.deleteTerraformStateArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.DeleteTerraformStateStub
+	fakeReturns := fake.deleteTerraformStateReturns
+	fake.recordInvocation("DeleteTerraformState", []interface{}{arg1})
+	fake.deleteTerraformStateMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeStorage) DeleteTerraformStateCallCount() int {
+	fake.deleteTerraformStateMutex.RLock()
+	defer fake.deleteTerraformStateMutex.RUnlock()
+	return len(fake.deleteTerraformStateArgsForCall)
+}
+
+func (fake *FakeStorage) DeleteTerraformStateCalls(stub func(string) error) {
+	fake.deleteTerraformStateMutex.Lock()
+	defer fake.deleteTerraformStateMutex.Unlock()
+	fake.DeleteTerraformStateStub = stub
+}
+
+func (fake *FakeStorage) DeleteTerraformStateArgsForCall(i int) string {
+	fake.deleteTerraformStateMutex.RLock()
+	defer fake.deleteTerraformStateMutex.RUnlock()
+	argsForCall := fake.deleteTerraformStateArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeStorage) DeleteTerraformStateReturns(result1 error) {
+	fake.deleteTerraformStateMutex.Lock()
+	defer fake.deleteTerraformStateMutex.Unlock()
+	fake.DeleteTerraformStateStub = nil
+	fake.deleteTerraformStateReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeStorage) DeleteTerraformStateReturnsOnCall(i int, result1 error) {
+	fake.deleteTerraformStateMutex.Lock()
+	defer fake.deleteTerraformStateMutex.Unlock()
+	fake.DeleteTerraformStateStub = nil
+	if fake.deleteTerraformStateReturnsOnCall == nil {
+		fake.deleteTerraformStateReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteTerraformStateReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeStorage) ExistsServiceBindingCredentials(arg1 string, arg2 string) (bool, error) {
 	fake.existsServiceBindingCredentialsMutex.Lock()
 	ret, specificReturn := fake.existsServiceBindingCredentialsReturnsOnCall[len(fake.existsServiceBindingCredentialsArgsForCall)]
@@ -722,6 +1067,132 @@ func (fake *FakeStorage) ExistsTerraformDeploymentReturnsOnCall(i int, result1 b
 	}{result1, result2}
 }
 
+func (fake *FakeStorage) FinishOperation(arg1 storage.Operation) error {
+	fake.finishOperationMutex.Lock()
+	ret, specificReturn := fake.finishOperationReturnsOnCall[len(fake.finishOperationArgsForCall)]
+	fake.finishOperationArgsForCall = append(fake.finishOperationArgsForCall, struct {
+		arg1 storage.Operation
+	}{arg1})
+	stub := fake.FinishOperationStub
+	fakeReturns := fake.finishOperationReturns
+	fake.recordInvocation("FinishOperation", []interface{}{arg1})
+	fake.finishOperationMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeStorage) FinishOperationCallCount() int {
+	fake.finishOperationMutex.RLock()
+	defer fake.finishOperationMutex.RUnlock()
+	return len(fake.finishOperationArgsForCall)
+}
+
+func (fake *FakeStorage) FinishOperationCalls(stub func(storage.Operation) error) {
+	fake.finishOperationMutex.Lock()
+	defer fake.finishOperationMutex.Unlock()
+	fake.FinishOperationStub = stub
+}
+
+func (fake *FakeStorage) FinishOperationArgsForCall(i int) storage.Operation {
+	fake.finishOperationMutex.RLock()
+	defer fake.finishOperationMutex.RUnlock()
+	argsForCall := fake.finishOperationArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeStorage) FinishOperationReturns(result1 error) {
+	fake.finishOperationMutex.Lock()
+	defer fake.finishOperationMutex.Unlock()
+	fake.FinishOperationStub = nil
+	fake.finishOperationReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeStorage) FinishOperationReturnsOnCall(i int, result1 error) {
+	fake.finishOperationMutex.Lock()
+	defer fake.finishOperationMutex.Unlock()
+	fake.FinishOperationStub = nil
+	if fake.finishOperationReturnsOnCall == nil {
+		fake.finishOperationReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.finishOperationReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeStorage) GetBindRequestContext(arg1 string, arg2 string) (storage.JSONObject, error) {
+	fake.getBindRequestContextMutex.Lock()
+	ret, specificReturn := fake.getBindRequestContextReturnsOnCall[len(fake.getBindRequestContextArgsForCall)]
+	fake.getBindRequestContextArgsForCall = append(fake.getBindRequestContextArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.GetBindRequestContextStub
+	fakeReturns := fake.getBindRequestContextReturns
+	fake.recordInvocation("GetBindRequestContext", []interface{}{arg1, arg2})
+	fake.getBindRequestContextMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeStorage) GetBindRequestContextCallCount() int {
+	fake.getBindRequestContextMutex.RLock()
+	defer fake.getBindRequestContextMutex.RUnlock()
+	return len(fake.getBindRequestContextArgsForCall)
+}
+
+func (fake *FakeStorage) GetBindRequestContextCalls(stub func(string, string) (storage.JSONObject, error)) {
+	fake.getBindRequestContextMutex.Lock()
+	defer fake.getBindRequestContextMutex.Unlock()
+	fake.GetBindRequestContextStub = stub
+}
+
+func (fake *FakeStorage) GetBindRequestContextArgsForCall(i int) (string, string) {
+	fake.getBindRequestContextMutex.RLock()
+	defer fake.getBindRequestContextMutex.RUnlock()
+	argsForCall := fake.getBindRequestContextArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeStorage) GetBindRequestContextReturns(result1 storage.JSONObject, result2 error) {
+	fake.getBindRequestContextMutex.Lock()
+	defer fake.getBindRequestContextMutex.Unlock()
+	fake.GetBindRequestContextStub = nil
+	fake.getBindRequestContextReturns = struct {
+		result1 storage.JSONObject
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeStorage) GetBindRequestContextReturnsOnCall(i int, result1 storage.JSONObject, result2 error) {
+	fake.getBindRequestContextMutex.Lock()
+	defer fake.getBindRequestContextMutex.Unlock()
+	fake.GetBindRequestContextStub = nil
+	if fake.getBindRequestContextReturnsOnCall == nil {
+		fake.getBindRequestContextReturnsOnCall = make(map[int]struct {
+			result1 storage.JSONObject
+			result2 error
+		})
+	}
+	fake.getBindRequestContextReturnsOnCall[i] = struct {
+		result1 storage.JSONObject
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeStorage) GetBindRequestDetails(arg1 string, arg2 string) (storage.JSONObject, error) {
 	fake.getBindRequestDetailsMutex.Lock()
 	ret, specificReturn := fake.getBindRequestDetailsReturnsOnCall[len(fake.getBindRequestDetailsArgsForCall)]
@@ -787,6 +1258,135 @@ func (fake *FakeStorage) GetBindRequestDetailsReturnsOnCall(i int, result1 stora
 	}{result1, result2}
 }
 
+func (fake *FakeStorage) GetBindRequest(arg1 string, arg2 string) (storage.BindRequestDetails, error) {
+	fake.getBindRequestMutex.Lock()
+	ret, specificReturn := fake.getBindRequestReturnsOnCall[len(fake.getBindRequestArgsForCall)]
+	fake.getBindRequestArgsForCall = append(fake.getBindRequestArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.GetBindRequestStub
+	fakeReturns := fake.getBindRequestReturns
+	fake.recordInvocation("GetBindRequest", []interface{}{arg1, arg2})
+	fake.getBindRequestMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeStorage) GetBindRequestCallCount() int {
+	fake.getBindRequestMutex.RLock()
+	defer fake.getBindRequestMutex.RUnlock()
+	return len(fake.getBindRequestArgsForCall)
+}
+
+func (fake *FakeStorage) GetBindRequestCalls(stub func(string, string) (storage.BindRequestDetails, error)) {
+	fake.getBindRequestMutex.Lock()
+	defer fake.getBindRequestMutex.Unlock()
+	fake.GetBindRequestStub = stub
+}
+
+func (fake *FakeStorage) GetBindRequestArgsForCall(i int) (string, string) {
+	fake.getBindRequestMutex.RLock()
+	defer fake.getBindRequestMutex.RUnlock()
+	argsForCall := fake.getBindRequestArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeStorage) GetBindRequestReturns(result1 storage.BindRequestDetails, result2 error) {
+	fake.getBindRequestMutex.Lock()
+	defer fake.getBindRequestMutex.Unlock()
+	fake.GetBindRequestStub = nil
+	fake.getBindRequestReturns = struct {
+		result1 storage.BindRequestDetails
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeStorage) GetBindRequestReturnsOnCall(i int, result1 storage.BindRequestDetails, result2 error) {
+	fake.getBindRequestMutex.Lock()
+	defer fake.getBindRequestMutex.Unlock()
+	fake.GetBindRequestStub = nil
+	if fake.getBindRequestReturnsOnCall == nil {
+		fake.getBindRequestReturnsOnCall = make(map[int]struct {
+			result1 storage.BindRequestDetails
+			result2 error
+		})
+	}
+	fake.getBindRequestReturnsOnCall[i] = struct {
+		result1 storage.BindRequestDetails
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeStorage) GetOperations(arg1 storage.OperationFilter) ([]storage.Operation, error) {
+	fake.getOperationsMutex.Lock()
+	ret, specificReturn := fake.getOperationsReturnsOnCall[len(fake.getOperationsArgsForCall)]
+	fake.getOperationsArgsForCall = append(fake.getOperationsArgsForCall, struct {
+		arg1 storage.OperationFilter
+	}{arg1})
+	stub := fake.GetOperationsStub
+	fakeReturns := fake.getOperationsReturns
+	fake.recordInvocation("GetOperations", []interface{}{arg1})
+	fake.getOperationsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeStorage) GetOperationsCallCount() int {
+	fake.getOperationsMutex.RLock()
+	defer fake.getOperationsMutex.RUnlock()
+	return len(fake.getOperationsArgsForCall)
+}
+
+func (fake *FakeStorage) GetOperationsCalls(stub func(storage.OperationFilter) ([]storage.Operation, error)) {
+	fake.getOperationsMutex.Lock()
+	defer fake.getOperationsMutex.Unlock()
+	fake.GetOperationsStub = stub
+}
+
+func (fake *FakeStorage) GetOperationsArgsForCall(i int) storage.OperationFilter {
+	fake.getOperationsMutex.RLock()
+	defer fake.getOperationsMutex.RUnlock()
+	argsForCall := fake.getOperationsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeStorage) GetOperationsReturns(result1 []storage.Operation, result2 error) {
+	fake.getOperationsMutex.Lock()
+	defer fake.getOperationsMutex.Unlock()
+	fake.GetOperationsStub = nil
+	fake.getOperationsReturns = struct {
+		result1 []storage.Operation
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeStorage) GetOperationsReturnsOnCall(i int, result1 []storage.Operation, result2 error) {
+	fake.getOperationsMutex.Lock()
+	defer fake.getOperationsMutex.Unlock()
+	fake.GetOperationsStub = nil
+	if fake.getOperationsReturnsOnCall == nil {
+		fake.getOperationsReturnsOnCall = make(map[int]struct {
+			result1 []storage.Operation
+			result2 error
+		})
+	}
+	fake.getOperationsReturnsOnCall[i] = struct {
+		result1 []storage.Operation
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeStorage) GetProvisionRequestDetails(arg1 string) (storage.JSONObject, error) {
 	fake.getProvisionRequestDetailsMutex.Lock()
 	ret, specificReturn := fake.getProvisionRequestDetailsReturnsOnCall[len(fake.getProvisionRequestDetailsArgsForCall)]
@@ -980,6 +1580,70 @@ func (fake *FakeStorage) GetServiceInstanceDetailsReturnsOnCall(i int, result1 s
 	}{result1, result2}
 }
 
+func (fake *FakeStorage) GetServiceInstanceGUIDs(arg1 storage.ServiceInstanceFilter) ([]string, error) {
+	fake.getServiceInstanceGUIDsMutex.Lock()
+	ret, specificReturn := fake.getServiceInstanceGUIDsReturnsOnCall[len(fake.getServiceInstanceGUIDsArgsForCall)]
+	fake.getServiceInstanceGUIDsArgsForCall = append(fake.getServiceInstanceGUIDsArgsForCall, struct {
+		arg1 storage.ServiceInstanceFilter
+	}{arg1})
+	stub := fake.GetServiceInstanceGUIDsStub
+	fakeReturns := fake.getServiceInstanceGUIDsReturns
+	fake.recordInvocation("GetServiceInstanceGUIDs", []interface{}{arg1})
+	fake.getServiceInstanceGUIDsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeStorage) GetServiceInstanceGUIDsCallCount() int {
+	fake.getServiceInstanceGUIDsMutex.RLock()
+	defer fake.getServiceInstanceGUIDsMutex.RUnlock()
+	return len(fake.getServiceInstanceGUIDsArgsForCall)
+}
+
+func (fake *FakeStorage) GetServiceInstanceGUIDsCalls(stub func(storage.ServiceInstanceFilter) ([]string, error)) {
+	fake.getServiceInstanceGUIDsMutex.Lock()
+	defer fake.getServiceInstanceGUIDsMutex.Unlock()
+	fake.GetServiceInstanceGUIDsStub = stub
+}
+
+func (fake *FakeStorage) GetServiceInstanceGUIDsArgsForCall(i int) storage.ServiceInstanceFilter {
+	fake.getServiceInstanceGUIDsMutex.RLock()
+	defer fake.getServiceInstanceGUIDsMutex.RUnlock()
+	argsForCall := fake.getServiceInstanceGUIDsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeStorage) GetServiceInstanceGUIDsReturns(result1 []string, result2 error) {
+	fake.getServiceInstanceGUIDsMutex.Lock()
+	defer fake.getServiceInstanceGUIDsMutex.Unlock()
+	fake.GetServiceInstanceGUIDsStub = nil
+	fake.getServiceInstanceGUIDsReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeStorage) GetServiceInstanceGUIDsReturnsOnCall(i int, result1 []string, result2 error) {
+	fake.getServiceInstanceGUIDsMutex.Lock()
+	defer fake.getServiceInstanceGUIDsMutex.Unlock()
+	fake.GetServiceInstanceGUIDsStub = nil
+	if fake.getServiceInstanceGUIDsReturnsOnCall == nil {
+		fake.getServiceInstanceGUIDsReturnsOnCall = make(map[int]struct {
+			result1 []string
+			result2 error
+		})
+	}
+	fake.getServiceInstanceGUIDsReturnsOnCall[i] = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeStorage) GetTerraformDeployment(arg1 string) (storage.TerraformDeployment, error) {
 	fake.getTerraformDeploymentMutex.Lock()
 	ret, specificReturn := fake.getTerraformDeploymentReturnsOnCall[len(fake.getTerraformDeploymentArgsForCall)]
@@ -1044,6 +1708,70 @@ func (fake *FakeStorage) GetTerraformDeploymentReturnsOnCall(i int, result1 stor
 	}{result1, result2}
 }
 
+func (fake *FakeStorage) GetTerraformState(arg1 string) ([]byte, error) {
+	fake.getTerraformStateMutex.Lock()
+	ret, specificReturn := fake.getTerraformStateReturnsOnCall[len(fake.getTerraformStateArgsForCall)]
+	fake.getTerraformStateArgsForCall = append(fake.getTerraformStateArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.GetTerraformStateStub
+	fakeReturns := fake.getTerraformStateReturns
+	fake.recordInvocation("GetTerraformState", []interface{}{arg1})
+	fake.getTerraformStateMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeStorage) GetTerraformStateCallCount() int {
+	fake.getTerraformStateMutex.RLock()
+	defer fake.getTerraformStateMutex.RUnlock()
+	return len(fake.getTerraformStateArgsForCall)
+}
+
+func (fake *FakeStorage) GetTerraformStateCalls(stub func(string) ([]byte, error)) {
+	fake.getTerraformStateMutex.Lock()
+	defer fake.getTerraformStateMutex.Unlock()
+	fake.GetTerraformStateStub = stub
+}
+
+func (fake *FakeStorage) GetTerraformStateArgsForCall(i int) string {
+	fake.getTerraformStateMutex.RLock()
+	defer fake.getTerraformStateMutex.RUnlock()
+	argsForCall := fake.getTerraformStateArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeStorage) GetTerraformStateReturns(result1 []byte, result2 error) {
+	fake.getTerraformStateMutex.Lock()
+	defer fake.getTerraformStateMutex.Unlock()
+	fake.GetTerraformStateStub = nil
+	fake.getTerraformStateReturns = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeStorage) GetTerraformStateReturnsOnCall(i int, result1 []byte, result2 error) {
+	fake.getTerraformStateMutex.Lock()
+	defer fake.getTerraformStateMutex.Unlock()
+	fake.GetTerraformStateStub = nil
+	if fake.getTerraformStateReturnsOnCall == nil {
+		fake.getTerraformStateReturnsOnCall = make(map[int]struct {
+			result1 []byte
+			result2 error
+		})
+	}
+	fake.getTerraformStateReturnsOnCall[i] = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeStorage) StoreBindRequestDetails(arg1 storage.BindRequestDetails) error {
 	fake.storeBindRequestDetailsMutex.Lock()
 	ret, specificReturn := fake.storeBindRequestDetailsReturnsOnCall[len(fake.storeBindRequestDetailsArgsForCall)]
@@ -1105,6 +1833,67 @@ func (fake *FakeStorage) StoreBindRequestDetailsReturnsOnCall(i int, result1 err
 	}{result1}
 }
 
+func (fake *FakeStorage) StoreOperation(arg1 storage.Operation) error {
+	fake.storeOperationMutex.Lock()
+	ret, specificReturn := fake.storeOperationReturnsOnCall[len(fake.storeOperationArgsForCall)]
+	fake.storeOperationArgsForCall = append(fake.storeOperationArgsForCall, struct {
+		arg1 storage.Operation
+	}{arg1})
+	stub := fake.StoreOperationStub
+	fakeReturns := fake.storeOperationReturns
+	fake.recordInvocation("StoreOperation", []interface{}{arg1})
+	fake.storeOperationMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeStorage) StoreOperationCallCount() int {
+	fake.storeOperationMutex.RLock()
+	defer fake.storeOperationMutex.RUnlock()
+	return len(fake.storeOperationArgsForCall)
+}
+
+func (fake *FakeStorage) StoreOperationCalls(stub func(storage.Operation) error) {
+	fake.storeOperationMutex.Lock()
+	defer fake.storeOperationMutex.Unlock()
+	fake.StoreOperationStub = stub
+}
+
+func (fake *FakeStorage) StoreOperationArgsForCall(i int) storage.Operation {
+	fake.storeOperationMutex.RLock()
+	defer fake.storeOperationMutex.RUnlock()
+	argsForCall := fake.storeOperationArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeStorage) StoreOperationReturns(result1 error) {
+	fake.storeOperationMutex.Lock()
+	defer fake.storeOperationMutex.Unlock()
+	fake.StoreOperationStub = nil
+	fake.storeOperationReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeStorage) StoreOperationReturnsOnCall(i int, result1 error) {
+	fake.storeOperationMutex.Lock()
+	defer fake.storeOperationMutex.Unlock()
+	fake.StoreOperationStub = nil
+	if fake.storeOperationReturnsOnCall == nil {
+		fake.storeOperationReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.storeOperationReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeStorage) StoreProvisionRequestDetails(arg1 string, arg2 storage.JSONObject) error {
 	fake.storeProvisionRequestDetailsMutex.Lock()
 	ret, specificReturn := fake.storeProvisionRequestDetailsReturnsOnCall[len(fake.storeProvisionRequestDetailsArgsForCall)]
@@ -1289,9 +2078,201 @@ func (fake *FakeStorage) StoreTerraformDeploymentReturnsOnCall(i int, result1 er
 	}{result1}
 }
 
+func (fake *FakeStorage) StoreTerraformState(arg1 string, arg2 []byte) error {
+	var arg2Copy []byte
+	if arg2 != nil {
+		arg2Copy = make([]byte, len(arg2))
+		copy(arg2Copy, arg2)
+	}
+	fake.storeTerraformStateMutex.Lock()
+	ret, specificReturn := fake.storeTerraformStateReturnsOnCall[len(fake.storeTerraformStateArgsForCall)]
+	fake.storeTerraformStateArgsForCall = append(fake.storeTerraformStateArgsForCall, struct {
+		arg1 string
+		arg2 []byte
+	}{arg1, arg2Copy})
+	stub := fake.StoreTerraformStateStub
+	fakeReturns := fake.storeTerraformStateReturns
+	fake.recordInvocation("StoreTerraformState", []interface{}{arg1, arg2Copy})
+	fake.storeTerraformStateMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeStorage) StoreTerraformStateCallCount() int {
+	fake.storeTerraformStateMutex.RLock()
+	defer fake.storeTerraformStateMutex.RUnlock()
+	return len(fake.storeTerraformStateArgsForCall)
+}
+
+func (fake *FakeStorage) StoreTerraformStateCalls(stub func(string, []byte) error) {
+	fake.storeTerraformStateMutex.Lock()
+	defer fake.storeTerraformStateMutex.Unlock()
+	fake.StoreTerraformStateStub = stub
+}
+
+func (fake *FakeStorage) StoreTerraformStateArgsForCall(i int) (string, []byte) {
+	fake.storeTerraformStateMutex.RLock()
+	defer fake.storeTerraformStateMutex.RUnlock()
+	argsForCall := fake.storeTerraformStateArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeStorage) StoreTerraformStateReturns(result1 error) {
+	fake.storeTerraformStateMutex.Lock()
+	defer fake.storeTerraformStateMutex.Unlock()
+	fake.StoreTerraformStateStub = nil
+	fake.storeTerraformStateReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeStorage) StoreTerraformStateReturnsOnCall(i int, result1 error) {
+	fake.storeTerraformStateMutex.Lock()
+	defer fake.storeTerraformStateMutex.Unlock()
+	fake.StoreTerraformStateStub = nil
+	if fake.storeTerraformStateReturnsOnCall == nil {
+		fake.storeTerraformStateReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.storeTerraformStateReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeStorage) StoreWebhookDelivery(arg1 storage.WebhookDelivery) error {
+	fake.storeWebhookDeliveryMutex.Lock()
+	ret, specificReturn := fake.storeWebhookDeliveryReturnsOnCall[len(fake.storeWebhookDeliveryArgsForCall)]
+	fake.storeWebhookDeliveryArgsForCall = append(fake.storeWebhookDeliveryArgsForCall, struct {
+		arg1 storage.WebhookDelivery
+	}{arg1})
+	stub := fake.StoreWebhookDeliveryStub
+	fakeReturns := fake.storeWebhookDeliveryReturns
+	fake.recordInvocation("StoreWebhookDelivery", []interface{}{arg1})
+	fake.storeWebhookDeliveryMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeStorage) StoreWebhookDeliveryCallCount() int {
+	fake.storeWebhookDeliveryMutex.RLock()
+	defer fake.storeWebhookDeliveryMutex.RUnlock()
+	return len(fake.storeWebhookDeliveryArgsForCall)
+}
+
+func (fake *FakeStorage) StoreWebhookDeliveryCalls(stub func(storage.WebhookDelivery) error) {
+	fake.storeWebhookDeliveryMutex.Lock()
+	defer fake.storeWebhookDeliveryMutex.Unlock()
+	fake.StoreWebhookDeliveryStub = stub
+}
+
+func (fake *FakeStorage) StoreWebhookDeliveryArgsForCall(i int) storage.WebhookDelivery {
+	fake.storeWebhookDeliveryMutex.RLock()
+	defer fake.storeWebhookDeliveryMutex.RUnlock()
+	argsForCall := fake.storeWebhookDeliveryArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeStorage) StoreWebhookDeliveryReturns(result1 error) {
+	fake.storeWebhookDeliveryMutex.Lock()
+	defer fake.storeWebhookDeliveryMutex.Unlock()
+	fake.StoreWebhookDeliveryStub = nil
+	fake.storeWebhookDeliveryReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeStorage) StoreWebhookDeliveryReturnsOnCall(i int, result1 error) {
+	fake.storeWebhookDeliveryMutex.Lock()
+	defer fake.storeWebhookDeliveryMutex.Unlock()
+	fake.StoreWebhookDeliveryStub = nil
+	if fake.storeWebhookDeliveryReturnsOnCall == nil {
+		fake.storeWebhookDeliveryReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.storeWebhookDeliveryReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeStorage) UnlockTerraformState(arg1 string, arg2 string) error {
+	fake.unlockTerraformStateMutex.Lock()
+	ret, specificReturn := fake.unlockTerraformStateReturnsOnCall[len(fake.unlockTerraformStateArgsForCall)]
+	fake.unlockTerraformStateArgsForCall = append(fake.unlockTerraformStateArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.UnlockTerraformStateStub
+	fakeReturns := fake.unlockTerraformStateReturns
+	fake.recordInvocation("UnlockTerraformState", []interface{}{arg1, arg2})
+	fake.unlockTerraformStateMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeStorage) UnlockTerraformStateCallCount() int {
+	fake.unlockTerraformStateMutex.RLock()
+	defer fake.unlockTerraformStateMutex.RUnlock()
+	return len(fake.unlockTerraformStateArgsForCall)
+}
+
+func (fake *FakeStorage) UnlockTerraformStateCalls(stub func(string, string) error) {
+	fake.unlockTerraformStateMutex.Lock()
+	defer fake.unlockTerraformStateMutex.Unlock()
+	fake.UnlockTerraformStateStub = stub
+}
+
+func (fake *FakeStorage) UnlockTerraformStateArgsForCall(i int) (string, string) {
+	fake.unlockTerraformStateMutex.RLock()
+	defer fake.unlockTerraformStateMutex.RUnlock()
+	argsForCall := fake.unlockTerraformStateArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeStorage) UnlockTerraformStateReturns(result1 error) {
+	fake.unlockTerraformStateMutex.Lock()
+	defer fake.unlockTerraformStateMutex.Unlock()
+	fake.UnlockTerraformStateStub = nil
+	fake.unlockTerraformStateReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeStorage) UnlockTerraformStateReturnsOnCall(i int, result1 error) {
+	fake.unlockTerraformStateMutex.Lock()
+	defer fake.unlockTerraformStateMutex.Unlock()
+	fake.UnlockTerraformStateStub = nil
+	if fake.unlockTerraformStateReturnsOnCall == nil {
+		fake.unlockTerraformStateReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.unlockTerraformStateReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeStorage) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.countServiceInstanceDetailsMutex.RLock()
+	defer fake.countServiceInstanceDetailsMutex.RUnlock()
 	fake.createServiceBindingCredentialsMutex.RLock()
 	defer fake.createServiceBindingCredentialsMutex.RUnlock()
 	fake.deleteBindRequestDetailsMutex.RLock()
@@ -1302,30 +2283,54 @@ func (fake *FakeStorage) Invocations() map[string][][]interface{} {
 	defer fake.deleteServiceBindingCredentialsMutex.RUnlock()
 	fake.deleteServiceInstanceDetailsMutex.RLock()
 	defer fake.deleteServiceInstanceDetailsMutex.RUnlock()
+	fake.deleteTerraformDeploymentMutex.RLock()
+	defer fake.deleteTerraformDeploymentMutex.RUnlock()
+	fake.deleteTerraformStateMutex.RLock()
+	defer fake.deleteTerraformStateMutex.RUnlock()
 	fake.existsServiceBindingCredentialsMutex.RLock()
 	defer fake.existsServiceBindingCredentialsMutex.RUnlock()
 	fake.existsServiceInstanceDetailsMutex.RLock()
 	defer fake.existsServiceInstanceDetailsMutex.RUnlock()
 	fake.existsTerraformDeploymentMutex.RLock()
 	defer fake.existsTerraformDeploymentMutex.RUnlock()
+	fake.finishOperationMutex.RLock()
+	defer fake.finishOperationMutex.RUnlock()
+	fake.getBindRequestMutex.RLock()
+	defer fake.getBindRequestMutex.RUnlock()
+	fake.getBindRequestContextMutex.RLock()
+	defer fake.getBindRequestContextMutex.RUnlock()
 	fake.getBindRequestDetailsMutex.RLock()
 	defer fake.getBindRequestDetailsMutex.RUnlock()
+	fake.getOperationsMutex.RLock()
+	defer fake.getOperationsMutex.RUnlock()
 	fake.getProvisionRequestDetailsMutex.RLock()
 	defer fake.getProvisionRequestDetailsMutex.RUnlock()
 	fake.getServiceBindingCredentialsMutex.RLock()
 	defer fake.getServiceBindingCredentialsMutex.RUnlock()
 	fake.getServiceInstanceDetailsMutex.RLock()
 	defer fake.getServiceInstanceDetailsMutex.RUnlock()
+	fake.getServiceInstanceGUIDsMutex.RLock()
+	defer fake.getServiceInstanceGUIDsMutex.RUnlock()
 	fake.getTerraformDeploymentMutex.RLock()
 	defer fake.getTerraformDeploymentMutex.RUnlock()
+	fake.getTerraformStateMutex.RLock()
+	defer fake.getTerraformStateMutex.RUnlock()
 	fake.storeBindRequestDetailsMutex.RLock()
 	defer fake.storeBindRequestDetailsMutex.RUnlock()
+	fake.storeOperationMutex.RLock()
+	defer fake.storeOperationMutex.RUnlock()
 	fake.storeProvisionRequestDetailsMutex.RLock()
 	defer fake.storeProvisionRequestDetailsMutex.RUnlock()
 	fake.storeServiceInstanceDetailsMutex.RLock()
 	defer fake.storeServiceInstanceDetailsMutex.RUnlock()
 	fake.storeTerraformDeploymentMutex.RLock()
 	defer fake.storeTerraformDeploymentMutex.RUnlock()
+	fake.storeTerraformStateMutex.RLock()
+	defer fake.storeTerraformStateMutex.RUnlock()
+	fake.storeWebhookDeliveryMutex.RLock()
+	defer fake.storeWebhookDeliveryMutex.RUnlock()
+	fake.unlockTerraformStateMutex.RLock()
+	defer fake.unlockTerraformStateMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
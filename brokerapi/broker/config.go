@@ -28,6 +28,10 @@ import (
 type BrokerConfig struct {
 	Registry  broker.BrokerRegistry
 	Credstore credstore.CredStore
+	Quotas    []Quota
+
+	// Auditor records the operations that the broker starts itself. When nil, they are not recorded.
+	Auditor Auditor
 }
 
 func NewBrokerConfigFromEnv(logger lager.Logger) (*BrokerConfig, error) {
@@ -41,18 +45,42 @@ func NewBrokerConfigFromEnv(logger lager.Logger) (*BrokerConfig, error) {
 		return nil, fmt.Errorf("failed loading config: %v", err)
 	}
 
-	var cs credstore.CredStore
+	cs, err := newCredStore(config, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed creating credstore: %v", err)
+	}
 
-	if config.CredStoreConfig.HasCredHubConfig() {
-		var err error
-		cs, err = credstore.NewCredhubStore(&config.CredStoreConfig, logger)
-		if err != nil {
-			return nil, fmt.Errorf("failed creating credstore: %v", err)
-		}
+	quotas, err := ParseQuotas()
+	if err != nil {
+		return nil, fmt.Errorf("failed loading quotas: %v", err)
 	}
 
 	return &BrokerConfig{
 		Registry:  registry,
 		Credstore: cs,
+		Quotas:    quotas,
 	}, nil
 }
+
+// newCredStore creates the credential store that is configured, if any
+func newCredStore(c *config.Config, logger lager.Logger) (credstore.CredStore, error) {
+	configured := 0
+	for _, ok := range []bool{c.CredStoreConfig.HasCredHubConfig(), c.VaultConfig.HasVaultConfig(), c.KubernetesConfig.HasKubernetesConfig()} {
+		if ok {
+			configured++
+		}
+	}
+
+	switch {
+	case configured > 1:
+		return nil, fmt.Errorf("more than one of CredHub, Vault and Kubernetes is configured, configure only one")
+	case c.CredStoreConfig.HasCredHubConfig():
+		return credstore.NewCredhubStore(&c.CredStoreConfig, logger)
+	case c.VaultConfig.HasVaultConfig():
+		return credstore.NewVaultStore(&c.VaultConfig, logger)
+	case c.KubernetesConfig.HasKubernetesConfig():
+		return credstore.NewKubernetesStore(&c.KubernetesConfig, logger)
+	default:
+		return nil, nil
+	}
+}
@@ -18,6 +18,8 @@ import (
 	"testing"
 
 	"code.cloudfoundry.org/lager"
+
+	"github.com/cloudfoundry/cloud-service-broker/pkg/config"
 )
 
 func TestNewBrokerConfigFromEnv(t *testing.T) {
@@ -26,3 +28,26 @@ func TestNewBrokerConfigFromEnv(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestNewCredStoreWithMoreThanOneConfigured(t *testing.T) {
+	c := &config.Config{
+		VaultConfig:      config.VaultConfig{Enabled: true, Address: "https://vault.example.com", Token: "my-token"},
+		KubernetesConfig: config.KubernetesConfig{Enabled: true},
+	}
+
+	_, err := newCredStore(c, lager.NewLogger("test"))
+	if err == nil || err.Error() != "more than one of CredHub, Vault and Kubernetes is configured, configure only one" {
+		t.Fatalf("expected error for more than one credstore, got: %v", err)
+	}
+}
+
+func TestNewCredStoreIgnoresVaultAddressWhenNotEnabled(t *testing.T) {
+	c := &config.Config{
+		VaultConfig: config.VaultConfig{Address: "https://vault.example.com", Token: "my-token"},
+	}
+
+	cs, err := newCredStore(c, lager.NewLogger("test"))
+	if err != nil || cs != nil {
+		t.Fatalf("expected no credstore when Vault is not enabled, got: %v, %v", cs, err)
+	}
+}
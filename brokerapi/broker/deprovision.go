@@ -3,10 +3,12 @@ package broker
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"code.cloudfoundry.org/lager"
 	"github.com/cloudfoundry/cloud-service-broker/dbservice/models"
 	"github.com/cloudfoundry/cloud-service-broker/internal/paramparser"
+	"github.com/cloudfoundry/cloud-service-broker/internal/storage"
 	"github.com/cloudfoundry/cloud-service-broker/utils/correlation"
 	"github.com/cloudfoundry/cloud-service-broker/utils/request"
 	"github.com/pivotal-cf/brokerapi/v8/domain"
@@ -46,9 +48,25 @@ func (broker *ServiceBroker) Deprovision(ctx context.Context, instanceID string,
 		return response, err
 	}
 
+	// a repeated request for a deprovision that is already underway must not start another one
+	if instance.OperationType == models.DeprovisionOperationType {
+		done, _, err := serviceProvider.PollInstance(ctx, instanceID)
+		switch {
+		case err == nil && !done:
+			response.IsAsync = true
+			response.OperationData = instance.OperationGUID
+			return response, nil
+		case err == nil && done:
+			if err := broker.updateStateOnOperationCompletion(ctx, serviceProvider, instance.OperationType, instanceID); err != nil {
+				return response, err
+			}
+			return response, apiresponses.ErrInstanceDoesNotExist
+		}
+	}
+
 	err = serviceProvider.CheckUpgradeAvailable(generateTFInstanceID(instanceID))
 	if err != nil {
-		return response, fmt.Errorf("failed to delete: %s", err.Error())
+		return response, fmt.Errorf("failed to delete: %s%s", err.Error(), nextMaintenanceWindow(instance, time.Now()))
 	}
 
 	// verify the service exists and the plan exists
@@ -96,9 +114,11 @@ func (broker *ServiceBroker) Deprovision(ctx context.Context, instanceID string,
 	response.IsAsync = true
 	response.OperationData = *operationID
 
-	instance.OperationType = models.DeprovisionOperationType
-	instance.OperationGUID = *operationID
-	if err := broker.store.StoreServiceInstanceDetails(instance); err != nil {
+	err = broker.storeServiceInstanceChange(instance, func(instance *storage.ServiceInstanceDetails) {
+		instance.OperationType = models.DeprovisionOperationType
+		instance.OperationGUID = *operationID
+	})
+	if err != nil {
 		return response, fmt.Errorf("error saving instance details to database: %s. WARNING: this instance will remain visible in cf. Contact your operator for cleanup", err)
 	}
 	return response, nil
@@ -18,6 +18,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/pivotal-cf/brokerapi/v8/domain"
+	"github.com/pivotal-cf/brokerapi/v8/domain/apiresponses"
 	"github.com/pivotal-cf/brokerapi/v8/middlewares"
 	"golang.org/x/net/context"
 
@@ -204,6 +205,58 @@ var _ = Describe("Deprovision", func() {
 		})
 	})
 
+	Describe("repeated deletion", func() {
+		BeforeEach(func() {
+			fakeStorage.GetServiceInstanceDetailsReturns(storage.ServiceInstanceDetails{
+				ServiceGUID:   offeringID,
+				PlanGUID:      planID,
+				GUID:          instanceToDeleteID,
+				OperationType: models.DeprovisionOperationType,
+				OperationGUID: operationID,
+			}, nil)
+		})
+
+		When("the deprovision is in progress", func() {
+			It("should return the original operation", func() {
+				response, err := serviceBroker.Deprovision(context.TODO(), instanceToDeleteID, deprovisionDetails, true)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(response.IsAsync).To(BeTrue())
+				Expect(response.OperationData).To(Equal(operationID))
+				Expect(fakeServiceProvider.DeprovisionCallCount()).To(BeZero())
+			})
+		})
+
+		When("the deprovision has completed", func() {
+			BeforeEach(func() {
+				fakeServiceProvider.PollInstanceReturns(true, "", nil)
+			})
+
+			It("should clean up and report that the instance is gone", func() {
+				_, err := serviceBroker.Deprovision(context.TODO(), instanceToDeleteID, deprovisionDetails, true)
+				Expect(err).To(MatchError(apiresponses.ErrInstanceDoesNotExist))
+
+				Expect(fakeServiceProvider.DeprovisionCallCount()).To(BeZero())
+				Expect(fakeStorage.DeleteServiceInstanceDetailsCallCount()).To(Equal(1))
+				Expect(fakeStorage.DeleteProvisionRequestDetailsCallCount()).To(Equal(1))
+			})
+		})
+
+		When("the deprovision failed", func() {
+			BeforeEach(func() {
+				fakeServiceProvider.PollInstanceReturns(true, "", errors.New("deprovision failed"))
+			})
+
+			It("should try again", func() {
+				response, err := serviceBroker.Deprovision(context.TODO(), instanceToDeleteID, deprovisionDetails, true)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(response.IsAsync).To(BeTrue())
+				Expect(fakeServiceProvider.DeprovisionCallCount()).To(Equal(1))
+			})
+		})
+	})
+
 	When("provider deprovision errors", func() {
 		BeforeEach(func() {
 			fakeServiceProvider.DeprovisionReturns(nil, errors.New("cannot deprovision right now"))
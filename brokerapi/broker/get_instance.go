@@ -2,26 +2,104 @@ package broker
 
 import (
 	"context"
-	"errors"
-	"net/http"
+	"encoding/json"
+	"fmt"
+	"time"
 
 	"code.cloudfoundry.org/lager"
+	"github.com/cloudfoundry/cloud-service-broker/dbservice/models"
+	"github.com/cloudfoundry/cloud-service-broker/internal/storage"
 	"github.com/cloudfoundry/cloud-service-broker/utils/correlation"
 	"github.com/pivotal-cf/brokerapi/v8/domain"
 	"github.com/pivotal-cf/brokerapi/v8/domain/apiresponses"
 )
 
+// operationsAttribute is the metadata attribute that holds the history of operations on
+// the service instance and its bindings, as a JSON list
+const operationsAttribute = "operations"
+
 // GetInstance fetches information about a service instance
 // GET /v2/service_instances/{instance_id}
-//
-// NOTE: This functionality is not implemented.
 func (broker *ServiceBroker) GetInstance(ctx context.Context, instanceID string, details domain.FetchInstanceDetails) (domain.GetInstanceDetailsSpec, error) {
 	broker.Logger.Info("GetInstance", correlation.ID(ctx), lager.Data{
 		"instance_id": instanceID,
 	})
 
-	return domain.GetInstanceDetailsSpec{}, apiresponses.NewFailureResponse(
-		errors.New("the service_instances endpoint is unsupported"),
-		http.StatusBadRequest,
-		"unsupported")
+	exists, err := broker.store.ExistsServiceInstanceDetails(instanceID)
+	switch {
+	case err != nil:
+		return domain.GetInstanceDetailsSpec{}, fmt.Errorf("database error checking for existing instance: %w", err)
+	case !exists:
+		return domain.GetInstanceDetailsSpec{}, apiresponses.ErrInstanceDoesNotExist
+	}
+
+	instance, err := broker.store.GetServiceInstanceDetails(instanceID)
+	if err != nil {
+		return domain.GetInstanceDetailsSpec{}, fmt.Errorf("error retrieving service instance details: %w", err)
+	}
+
+	// The OSB API treats an instance that is being provisioned as not existing yet, and one
+	// that is being updated as not being available to fetch
+	switch instance.OperationType {
+	case models.ProvisionOperationType:
+		return domain.GetInstanceDetailsSpec{}, apiresponses.ErrInstanceDoesNotExist
+	case models.UpdateOperationType:
+		return domain.GetInstanceDetailsSpec{}, apiresponses.ErrConcurrentInstanceAccess
+	}
+
+	params, err := broker.store.GetProvisionRequestDetails(instanceID)
+	if err != nil {
+		return domain.GetInstanceDetailsSpec{}, fmt.Errorf("error retrieving provision request details: %w", err)
+	}
+
+	operations, err := broker.store.GetOperations(storage.OperationFilter{ServiceInstanceGUID: instanceID})
+	if err != nil {
+		return domain.GetInstanceDetailsSpec{}, fmt.Errorf("error retrieving operation history: %w", err)
+	}
+
+	history, err := json.Marshal(operationHistory(operations))
+	if err != nil {
+		return domain.GetInstanceDetailsSpec{}, fmt.Errorf("error encoding operation history: %w", err)
+	}
+
+	return domain.GetInstanceDetailsSpec{
+		ServiceID:  instance.ServiceGUID,
+		PlanID:     instance.PlanGUID,
+		Parameters: params,
+		Metadata: domain.InstanceMetadata{
+			Attributes: map[string]string{operationsAttribute: string(history)},
+		},
+	}, nil
+}
+
+type operationHistoryEntry struct {
+	DeploymentID     string     `json:"deployment_id"`
+	Type             string     `json:"type"`
+	StartedAt        time.Time  `json:"started_at"`
+	FinishedAt       *time.Time `json:"finished_at,omitempty"`
+	TerraformVersion string     `json:"terraform_version,omitempty"`
+	BrokerpakVersion string     `json:"brokerpak_version,omitempty"`
+	State            string     `json:"state"`
+	Message          string     `json:"message"`
+}
+
+func operationHistory(operations []storage.Operation) []operationHistoryEntry {
+	result := make([]operationHistoryEntry, 0, len(operations))
+	for _, o := range operations {
+		entry := operationHistoryEntry{
+			DeploymentID:     o.DeploymentID,
+			Type:             o.Type,
+			StartedAt:        o.StartedAt,
+			TerraformVersion: o.TerraformVersion,
+			BrokerpakVersion: o.BrokerpakVersion,
+			State:            o.State,
+			Message:          o.Message,
+		}
+		if !o.FinishedAt.IsZero() {
+			finishedAt := o.FinishedAt
+			entry.FinishedAt = &finishedAt
+		}
+		result = append(result, entry)
+	}
+	return result
 }
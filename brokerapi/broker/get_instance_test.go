@@ -1,24 +1,129 @@
 package broker_test
 
 import (
+	"errors"
+	"time"
+
 	"github.com/cloudfoundry/cloud-service-broker/brokerapi/broker/brokerfakes"
 	"github.com/cloudfoundry/cloud-service-broker/brokerapi/broker/decider"
+	"github.com/cloudfoundry/cloud-service-broker/dbservice/models"
+	"github.com/cloudfoundry/cloud-service-broker/internal/storage"
 	"github.com/cloudfoundry/cloud-service-broker/utils"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/pivotal-cf/brokerapi/v8/domain"
+	"github.com/pivotal-cf/brokerapi/v8/domain/apiresponses"
 	"golang.org/x/net/context"
 
 	"github.com/cloudfoundry/cloud-service-broker/brokerapi/broker"
 )
 
 var _ = Describe("GetInstance", func() {
-	It("is not implemented", func() {
-		serviceBroker, err := broker.New(&broker.BrokerConfig{}, &brokerfakes.FakeStorage{}, decider.Decider{}, utils.NewLogger("brokers-test"))
+	const instanceID = "test-instance-id"
+
+	var (
+		serviceBroker *broker.ServiceBroker
+		fakeStorage   *brokerfakes.FakeStorage
+		started       time.Time
+	)
+
+	BeforeEach(func() {
+		started = time.Date(2022, 4, 1, 10, 0, 0, 0, time.UTC)
+
+		fakeStorage = &brokerfakes.FakeStorage{}
+		fakeStorage.ExistsServiceInstanceDetailsReturns(true, nil)
+		fakeStorage.GetServiceInstanceDetailsReturns(storage.ServiceInstanceDetails{
+			GUID:        instanceID,
+			ServiceGUID: "test-service-id",
+			PlanGUID:    "test-plan-id",
+		}, nil)
+		fakeStorage.GetProvisionRequestDetailsReturns(storage.JSONObject{"foo": "bar"}, nil)
+		fakeStorage.GetOperationsReturns([]storage.Operation{
+			{
+				DeploymentID:     "tf:test-instance-id:",
+				Type:             "upgrade",
+				StartedAt:        started,
+				FinishedAt:       started.Add(time.Minute),
+				TerraformVersion: "1.1.6",
+				BrokerpakVersion: "1.2.3",
+				State:            "failed",
+				Message:          "upgrade failed: boom",
+			},
+			{
+				DeploymentID:     "tf:test-instance-id:test-binding-id",
+				Type:             "bind",
+				StartedAt:        started.Add(time.Hour),
+				BrokerpakVersion: "1.2.3",
+				State:            "in progress",
+				Message:          "bind in progress",
+			},
+		}, nil)
+
+		var err error
+		serviceBroker, err = broker.New(&broker.BrokerConfig{}, fakeStorage, decider.Decider{}, utils.NewLogger("brokers-test"))
 		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("returns the service instance with its operation history", func() {
+		spec, err := serviceBroker.GetInstance(context.TODO(), instanceID, domain.FetchInstanceDetails{})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(spec.ServiceID).To(Equal("test-service-id"))
+		Expect(spec.PlanID).To(Equal("test-plan-id"))
+		Expect(spec.Parameters).To(Equal(storage.JSONObject{"foo": "bar"}))
+		Expect(spec.Metadata.Attributes).To(HaveKeyWithValue("operations", MatchJSON(`[
+			{
+				"deployment_id": "tf:test-instance-id:",
+				"type": "upgrade",
+				"started_at": "2022-04-01T10:00:00Z",
+				"finished_at": "2022-04-01T10:01:00Z",
+				"terraform_version": "1.1.6",
+				"brokerpak_version": "1.2.3",
+				"state": "failed",
+				"message": "upgrade failed: boom"
+			},
+			{
+				"deployment_id": "tf:test-instance-id:test-binding-id",
+				"type": "bind",
+				"started_at": "2022-04-01T11:00:00Z",
+				"brokerpak_version": "1.2.3",
+				"state": "in progress",
+				"message": "bind in progress"
+			}
+		]`)))
+
+		Expect(fakeStorage.GetOperationsArgsForCall(0)).To(Equal(storage.OperationFilter{ServiceInstanceGUID: instanceID}))
+	})
+
+	It("fails when the service instance does not exist", func() {
+		fakeStorage.ExistsServiceInstanceDetailsReturns(false, nil)
+
+		_, err := serviceBroker.GetInstance(context.TODO(), instanceID, domain.FetchInstanceDetails{})
+
+		Expect(err).To(MatchError(apiresponses.ErrInstanceDoesNotExist))
+	})
+
+	It("fails when the service instance is being provisioned", func() {
+		fakeStorage.GetServiceInstanceDetailsReturns(storage.ServiceInstanceDetails{OperationType: models.ProvisionOperationType}, nil)
+
+		_, err := serviceBroker.GetInstance(context.TODO(), instanceID, domain.FetchInstanceDetails{})
+
+		Expect(err).To(MatchError(apiresponses.ErrInstanceDoesNotExist))
+	})
+
+	It("fails when the service instance is being updated", func() {
+		fakeStorage.GetServiceInstanceDetailsReturns(storage.ServiceInstanceDetails{OperationType: models.UpdateOperationType}, nil)
+
+		_, err := serviceBroker.GetInstance(context.TODO(), instanceID, domain.FetchInstanceDetails{})
+
+		Expect(err).To(MatchError(apiresponses.ErrConcurrentInstanceAccess))
+	})
+
+	It("fails when the operation history cannot be read", func() {
+		fakeStorage.GetOperationsReturns(nil, errors.New("boom"))
 
-		_, err = serviceBroker.GetInstance(context.TODO(), "instance-id", domain.FetchInstanceDetails{})
+		_, err := serviceBroker.GetInstance(context.TODO(), instanceID, domain.FetchInstanceDetails{})
 
-		Expect(err).To(MatchError("the service_instances endpoint is unsupported"))
+		Expect(err).To(MatchError("error retrieving operation history: boom"))
 	})
 })
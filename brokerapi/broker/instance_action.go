@@ -0,0 +1,158 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/cloudfoundry/cloud-service-broker/dbservice/models"
+	"github.com/cloudfoundry/cloud-service-broker/internal/storage"
+	"github.com/cloudfoundry/cloud-service-broker/utils/correlation"
+	"github.com/cloudfoundry/cloud-service-broker/utils/request"
+	"github.com/pivotal-cf/brokerapi/v8/domain"
+	"github.com/pivotal-cf/brokerapi/v8/domain/apiresponses"
+)
+
+// ErrInstanceNotFound is returned by the extensions endpoints when the service instance does not exist
+var ErrInstanceNotFound = apiresponses.NewFailureResponse(errors.New("instance does not exist"), http.StatusNotFound, "instance-not-found")
+
+// ErrActionDoesNotExist is returned when a service instance does not have the requested action
+var ErrActionDoesNotExist = apiresponses.NewFailureResponse(errors.New("action does not exist"), http.StatusNotFound, "action-does-not-exist")
+
+// InstanceActions lists the actions that can be run on a service instance.
+// It is bound to the `GET /v2/service_instances/:instance_id/extensions` endpoint.
+func (broker *ServiceBroker) InstanceActions(ctx context.Context, instanceID string) ([]map[string]interface{}, error) {
+	broker.Logger.Info("InstanceActions", correlation.ID(ctx), lager.Data{
+		"instance_id": instanceID,
+	})
+
+	instance, err := broker.store.GetServiceInstanceDetails(instanceID)
+	if err != nil {
+		return nil, ErrInstanceNotFound
+	}
+
+	serviceDefinition, err := broker.registry.GetServiceByID(instance.ServiceGUID)
+	if err != nil {
+		return nil, err
+	}
+
+	return serviceDefinition.ExtensionsMetadata(), nil
+}
+
+// RunInstanceAction starts an action on a service instance, and returns the operation that can be polled.
+// It is bound to the `POST /v2/service_instances/:instance_id/extensions/:action` endpoint.
+func (broker *ServiceBroker) RunInstanceAction(ctx context.Context, instanceID, actionName string, params map[string]interface{}) (string, error) {
+	broker.Logger.Info("RunInstanceAction", correlation.ID(ctx), lager.Data{
+		"instance_id": instanceID,
+		"action":      actionName,
+	})
+
+	encodedParams, err := auditParams(params)
+	if err != nil {
+		return "", apiresponses.NewFailureResponse(err, http.StatusBadRequest, "invalid-action-parameters")
+	}
+
+	var operationID string
+	err = broker.auditor.Audit(ctx, fmt.Sprintf("%s:%s", models.ActionOperationType, actionName), instanceID, encodedParams, func() (bool, error) {
+		id, err := broker.runInstanceAction(ctx, instanceID, actionName, params)
+		operationID = id
+		return true, err
+	})
+	return operationID, err
+}
+
+func (broker *ServiceBroker) runInstanceAction(ctx context.Context, instanceID, actionName string, params map[string]interface{}) (string, error) {
+
+	instance, err := broker.store.GetServiceInstanceDetails(instanceID)
+	if err != nil {
+		return "", ErrInstanceNotFound
+	}
+
+	serviceDefinition, serviceProvider, err := broker.getDefinitionAndProvider(instance.ServiceGUID)
+	if err != nil {
+		return "", err
+	}
+
+	action, err := serviceDefinition.GetActionByName(actionName)
+	if err != nil {
+		return "", ErrActionDoesNotExist
+	}
+
+	if err := serviceProvider.CheckUpgradeAvailable(generateTFInstanceID(instanceID)); err != nil {
+		return "", apiresponses.NewFailureResponse(
+			fmt.Errorf("failed to run action: %s%s", err.Error(), nextMaintenanceWindow(instance, time.Now())),
+			http.StatusUnprocessableEntity,
+			"upgrade-required",
+		)
+	}
+
+	plan, err := serviceDefinition.GetPlanByID(instance.PlanGUID)
+	if err != nil {
+		return "", err
+	}
+
+	if err := validateDefinedParams(params, action.InputVariables, nil); err != nil {
+		return "", apiresponses.NewFailureResponse(err, http.StatusBadRequest, "invalid-action-parameters")
+	}
+
+	vars, err := serviceDefinition.ActionVariables(instance, *action, params, plan, request.DecodeOriginatingIdentityHeader(ctx))
+	if err != nil {
+		return "", apiresponses.NewFailureResponse(err, http.StatusBadRequest, "invalid-action-parameters")
+	}
+
+	operationID, err := serviceProvider.RunAction(ctx, instanceID, actionName, vars)
+	if err != nil {
+		return "", apiresponses.NewFailureResponse(fmt.Errorf("error running action: %w", err), http.StatusUnprocessableEntity, "action-failed")
+	}
+
+	return operationID, nil
+}
+
+// LastInstanceActionOperation fetches the state of the last run of an action on a service instance.
+// It is bound to the `GET /v2/service_instances/:instance_id/extensions/:action/last_operation` endpoint.
+func (broker *ServiceBroker) LastInstanceActionOperation(ctx context.Context, instanceID, actionName string) (domain.LastOperation, error) {
+	broker.Logger.Info("LastInstanceActionOperation", correlation.ID(ctx), lager.Data{
+		"instance_id": instanceID,
+		"action":      actionName,
+	})
+
+	instance, err := broker.store.GetServiceInstanceDetails(instanceID)
+	if err != nil {
+		return domain.LastOperation{}, ErrInstanceNotFound
+	}
+
+	serviceDefinition, serviceProvider, err := broker.getDefinitionAndProvider(instance.ServiceGUID)
+	if err != nil {
+		return domain.LastOperation{}, err
+	}
+
+	if _, err := serviceDefinition.GetActionByName(actionName); err != nil {
+		return domain.LastOperation{}, ErrActionDoesNotExist
+	}
+
+	done, message, err := serviceProvider.PollAction(ctx, instanceID, actionName)
+	switch {
+	case err != nil:
+		return domain.LastOperation{State: domain.Failed, Description: err.Error()}, nil
+	case !done:
+		return domain.LastOperation{State: domain.InProgress, Description: message}, nil
+	}
+
+	// An action may re-create resources of the instance, so the outputs are refreshed
+	outs, err := serviceProvider.GetTerraformOutputs(ctx, instanceID)
+	if err != nil {
+		return domain.LastOperation{}, fmt.Errorf("error getting new instance details: %w", err)
+	}
+
+	err = broker.storeServiceInstanceChange(instance, func(instance *storage.ServiceInstanceDetails) {
+		instance.Outputs = outs
+	})
+	if err != nil {
+		return domain.LastOperation{}, fmt.Errorf("error saving instance details to database: %w", err)
+	}
+
+	return domain.LastOperation{State: domain.Succeeded, Description: message}, nil
+}
@@ -0,0 +1,86 @@
+package broker
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/pivotal-cf/brokerapi/v8/domain/apiresponses"
+	"github.com/pivotal-cf/brokerapi/v8/middlewares"
+)
+
+// ExtensionsHandler serves the OSBAPI extensions endpoints that list and run the actions of
+// a service instance. The handler does not authenticate requests, so it must be wrapped by the caller.
+func (broker *ServiceBroker) ExtensionsHandler() http.Handler {
+	router := mux.NewRouter()
+	router.Use(middlewares.AddOriginatingIdentityToContext)
+
+	router.HandleFunc("/v2/service_instances/{instance_id}/extensions", broker.listActions).Methods(http.MethodGet)
+	router.HandleFunc("/v2/service_instances/{instance_id}/extensions/{action}", broker.runAction).Methods(http.MethodPost)
+	router.HandleFunc("/v2/service_instances/{instance_id}/extensions/{action}/last_operation", broker.lastActionOperation).Methods(http.MethodGet)
+
+	return router
+}
+
+func (broker *ServiceBroker) listActions(w http.ResponseWriter, req *http.Request) {
+	actions, err := broker.InstanceActions(req.Context(), mux.Vars(req)["instance_id"])
+	if err != nil {
+		broker.writeActionError(w, err)
+		return
+	}
+
+	if actions == nil {
+		actions = []map[string]interface{}{}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"extensions": actions})
+}
+
+func (broker *ServiceBroker) runAction(w http.ResponseWriter, req *http.Request) {
+	var body struct {
+		Parameters map[string]interface{} `json:"parameters"`
+	}
+	if req.ContentLength != 0 {
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			writeJSON(w, http.StatusBadRequest, apiresponses.ErrorResponse{Description: invalidUserInputMsg})
+			return
+		}
+	}
+
+	vars := mux.Vars(req)
+	operation, err := broker.RunInstanceAction(req.Context(), vars["instance_id"], vars["action"], body.Parameters)
+	if err != nil {
+		broker.writeActionError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, apiresponses.ProvisioningResponse{OperationData: operation})
+}
+
+func (broker *ServiceBroker) lastActionOperation(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	lastOperation, err := broker.LastInstanceActionOperation(req.Context(), vars["instance_id"], vars["action"])
+	if err != nil {
+		broker.writeActionError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, apiresponses.LastOperationResponse{State: lastOperation.State, Description: lastOperation.Description})
+}
+
+func (broker *ServiceBroker) writeActionError(w http.ResponseWriter, err error) {
+	var failure *apiresponses.FailureResponse
+	if errors.As(err, &failure) {
+		writeJSON(w, failure.ValidatedStatusCode(broker.Logger), failure.ErrorResponse())
+		return
+	}
+
+	broker.Logger.Error("instance-action", err)
+	writeJSON(w, http.StatusInternalServerError, apiresponses.ErrorResponse{Description: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
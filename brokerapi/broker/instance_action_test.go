@@ -0,0 +1,214 @@
+package broker_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"code.cloudfoundry.org/lager"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cf/brokerapi/v8/domain"
+
+	"github.com/cloudfoundry/cloud-service-broker/brokerapi/broker"
+	"github.com/cloudfoundry/cloud-service-broker/brokerapi/broker/brokerfakes"
+	"github.com/cloudfoundry/cloud-service-broker/internal/storage"
+	pkgBroker "github.com/cloudfoundry/cloud-service-broker/pkg/broker"
+	pkgBrokerFakes "github.com/cloudfoundry/cloud-service-broker/pkg/broker/brokerfakes"
+	"github.com/cloudfoundry/cloud-service-broker/pkg/varcontext"
+	"github.com/cloudfoundry/cloud-service-broker/utils"
+)
+
+var _ = Describe("Instance actions", func() {
+	const (
+		offeringID = "test-service-id"
+		planID     = "test-plan-id"
+		instanceID = "test-instance-id"
+		basePath   = "/v2/service_instances/test-instance-id/extensions"
+	)
+
+	var (
+		handler             http.Handler
+		fakeStorage         *brokerfakes.FakeStorage
+		fakeServiceProvider *pkgBrokerFakes.FakeServiceProvider
+		fakeAuditor         *brokerfakes.FakeAuditor
+	)
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec
+	}
+
+	BeforeEach(func() {
+		fakeServiceProvider = &pkgBrokerFakes.FakeServiceProvider{}
+		fakeServiceProvider.RunActionReturns("tf:test-instance-id:action:snapshot", nil)
+
+		fakeAuditor = &brokerfakes.FakeAuditor{}
+		fakeAuditor.AuditStub = func(_ context.Context, _, _ string, _ json.RawMessage, run func() (bool, error)) error {
+			_, err := run()
+			return err
+		}
+
+		brokerConfig := &broker.BrokerConfig{
+			Auditor: fakeAuditor,
+			Registry: pkgBroker.BrokerRegistry{
+				"test-service": &pkgBroker.ServiceDefinition{
+					ID:   offeringID,
+					Name: "test-service",
+					Plans: []pkgBroker.ServicePlan{
+						{
+							ServicePlan:       domain.ServicePlan{ID: planID, Name: "test-plan"},
+							ServiceProperties: map[string]interface{}{"tier": "small"},
+						},
+					},
+					Actions: []pkgBroker.InstanceAction{
+						{
+							Name:        "snapshot",
+							Description: "Takes a snapshot",
+							InputVariables: []pkgBroker.BrokerVariable{
+								{FieldName: "snapshot_name", Type: pkgBroker.JSONTypeString, Details: "name", Required: true},
+							},
+							ComputedVariables: []varcontext.DefaultVariable{
+								{Name: "tf_id", Default: "tf:${request.instance_id}:action:snapshot", Overwrite: true},
+							},
+						},
+					},
+					ProviderBuilder: func(logger lager.Logger, store pkgBroker.ServiceProviderStorage) pkgBroker.ServiceProvider {
+						return fakeServiceProvider
+					},
+				},
+			},
+		}
+
+		fakeStorage = &brokerfakes.FakeStorage{}
+		fakeStorage.GetServiceInstanceDetailsReturns(storage.ServiceInstanceDetails{
+			GUID:        instanceID,
+			ServiceGUID: offeringID,
+			PlanGUID:    planID,
+		}, nil)
+
+		serviceBroker, err := broker.New(brokerConfig, fakeStorage, nil, utils.NewLogger("brokers-test"))
+		Expect(err).ToNot(HaveOccurred())
+		handler = serviceBroker.ExtensionsHandler()
+	})
+
+	Describe("listing actions", func() {
+		It("lists the actions of the instance", func() {
+			rec := serve(http.MethodGet, basePath, "")
+
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			var body struct {
+				Extensions []map[string]interface{} `json:"extensions"`
+			}
+			Expect(json.Unmarshal(rec.Body.Bytes(), &body)).To(Succeed())
+			Expect(body.Extensions).To(HaveLen(1))
+			Expect(body.Extensions[0]).To(HaveKeyWithValue("name", "snapshot"))
+			Expect(body.Extensions[0]).To(HaveKeyWithValue("path", "/v2/service_instances/:instance_id/extensions/snapshot"))
+		})
+
+		It("returns not found when the instance does not exist", func() {
+			fakeStorage.GetServiceInstanceDetailsReturns(storage.ServiceInstanceDetails{}, errors.New("not found"))
+
+			Expect(serve(http.MethodGet, basePath, "").Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Describe("running an action", func() {
+		It("starts the action and returns the operation", func() {
+			rec := serve(http.MethodPost, basePath+"/snapshot", `{"parameters":{"snapshot_name":"nightly"}}`)
+
+			Expect(rec.Code).To(Equal(http.StatusAccepted))
+			Expect(rec.Body.String()).To(MatchJSON(`{"operation":"tf:test-instance-id:action:snapshot"}`))
+
+			Expect(fakeServiceProvider.RunActionCallCount()).To(Equal(1))
+			_, actualInstanceID, actualAction, vars := fakeServiceProvider.RunActionArgsForCall(0)
+			Expect(actualInstanceID).To(Equal(instanceID))
+			Expect(actualAction).To(Equal("snapshot"))
+			Expect(vars.GetString("snapshot_name")).To(Equal("nightly"))
+			Expect(vars.GetString("tf_id")).To(Equal("tf:test-instance-id:action:snapshot"))
+		})
+
+		It("records an audit event", func() {
+			serve(http.MethodPost, basePath+"/snapshot", `{"parameters":{"snapshot_name":"nightly"}}`)
+
+			Expect(fakeAuditor.AuditCallCount()).To(Equal(1))
+			_, operation, actualInstanceID, params, _ := fakeAuditor.AuditArgsForCall(0)
+			Expect(operation).To(Equal("action:snapshot"))
+			Expect(actualInstanceID).To(Equal(instanceID))
+			Expect(params).To(MatchJSON(`{"snapshot_name":"nightly"}`))
+		})
+
+		It("returns not found when the action does not exist", func() {
+			rec := serve(http.MethodPost, basePath+"/restart", "")
+
+			Expect(rec.Code).To(Equal(http.StatusNotFound))
+			Expect(rec.Body.String()).To(MatchJSON(`{"description":"action does not exist"}`))
+			Expect(fakeServiceProvider.RunActionCallCount()).To(BeZero())
+		})
+
+		It("rejects parameters that are not defined", func() {
+			rec := serve(http.MethodPost, basePath+"/snapshot", `{"parameters":{"snapshot_name":"nightly","size":3}}`)
+
+			Expect(rec.Code).To(Equal(http.StatusBadRequest))
+			Expect(rec.Body.String()).To(MatchJSON(`{"description":"additional properties are not allowed: size"}`))
+		})
+
+		It("rejects a body that is not JSON", func() {
+			Expect(serve(http.MethodPost, basePath+"/snapshot", `snapshot`).Code).To(Equal(http.StatusBadRequest))
+		})
+
+		It("refuses to run when the instance needs an upgrade", func() {
+			fakeServiceProvider.CheckUpgradeAvailableReturns(errors.New("terraform version check failed"))
+
+			rec := serve(http.MethodPost, basePath+"/snapshot", `{"parameters":{"snapshot_name":"nightly"}}`)
+
+			Expect(rec.Code).To(Equal(http.StatusUnprocessableEntity))
+			Expect(rec.Body.String()).To(MatchJSON(`{"description":"failed to run action: terraform version check failed"}`))
+			Expect(fakeServiceProvider.RunActionCallCount()).To(BeZero())
+		})
+
+		It("reports when the action cannot be started", func() {
+			fakeServiceProvider.RunActionReturns("", errors.New("update operation is in progress"))
+
+			rec := serve(http.MethodPost, basePath+"/snapshot", `{"parameters":{"snapshot_name":"nightly"}}`)
+
+			Expect(rec.Code).To(Equal(http.StatusUnprocessableEntity))
+			Expect(rec.Body.String()).To(MatchJSON(`{"description":"error running action: update operation is in progress"}`))
+		})
+	})
+
+	Describe("polling an action", func() {
+		It("reports an action in progress", func() {
+			fakeServiceProvider.PollActionReturns(false, "action in progress", nil)
+
+			rec := serve(http.MethodGet, basePath+"/snapshot/last_operation", "")
+
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(rec.Body.String()).To(MatchJSON(`{"state":"in progress","description":"action in progress"}`))
+			Expect(fakeStorage.StoreServiceInstanceDetailsCallCount()).To(BeZero())
+		})
+
+		It("reports a failed action", func() {
+			fakeServiceProvider.PollActionReturns(true, "", errors.New("action failed: boom"))
+
+			rec := serve(http.MethodGet, basePath+"/snapshot/last_operation", "")
+
+			Expect(rec.Body.String()).To(MatchJSON(`{"state":"failed","description":"action failed: boom"}`))
+		})
+
+		It("refreshes the instance outputs when the action succeeds", func() {
+			fakeServiceProvider.PollActionReturns(true, "action succeeded", nil)
+			fakeServiceProvider.GetTerraformOutputsReturns(storage.JSONObject{"password": "new"}, nil)
+
+			rec := serve(http.MethodGet, basePath+"/snapshot/last_operation", "")
+
+			Expect(rec.Body.String()).To(MatchJSON(`{"state":"succeeded","description":"action succeeded"}`))
+			Expect(fakeStorage.StoreServiceInstanceDetailsCallCount()).To(Equal(1))
+			Expect(fakeStorage.StoreServiceInstanceDetailsArgsForCall(0).Outputs).To(Equal(storage.JSONObject{"password": "new"}))
+		})
+	})
+})
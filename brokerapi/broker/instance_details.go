@@ -0,0 +1,26 @@
+package broker
+
+import (
+	"github.com/cloudfoundry/cloud-service-broker/internal/storage"
+)
+
+// maxStoreAttempts is the number of times that a change to the service instance details is made
+// before giving up, when the details keep being changed concurrently
+const maxStoreAttempts = 3
+
+// storeServiceInstanceChange makes the change to the service instance details and stores them.
+// When the details were changed since they were read, they are read again and the change is made
+// again, so that neither change is lost.
+func (broker *ServiceBroker) storeServiceInstanceChange(instance storage.ServiceInstanceDetails, change func(*storage.ServiceInstanceDetails)) error {
+	for attempt := 1; ; attempt++ {
+		change(&instance)
+		err := broker.store.StoreServiceInstanceDetails(instance)
+		if !storage.IsConflict(err) || attempt == maxStoreAttempts {
+			return err
+		}
+
+		if instance, err = broker.store.GetServiceInstanceDetails(instance.GUID); err != nil {
+			return err
+		}
+	}
+}
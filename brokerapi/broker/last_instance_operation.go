@@ -6,6 +6,7 @@ import (
 
 	"code.cloudfoundry.org/lager"
 	"github.com/cloudfoundry/cloud-service-broker/dbservice/models"
+	"github.com/cloudfoundry/cloud-service-broker/internal/storage"
 	"github.com/cloudfoundry/cloud-service-broker/pkg/broker"
 	"github.com/cloudfoundry/cloud-service-broker/utils/correlation"
 	"github.com/pivotal-cf/brokerapi/v8/domain"
@@ -77,11 +78,13 @@ func (broker *ServiceBroker) updateStateOnOperationCompletion(ctx context.Contex
 		return fmt.Errorf("error getting new instance details from GCP: %v", err)
 	}
 
-	details.Outputs = outs
-	details.OperationGUID = ""
-	details.OperationType = models.ClearOperationType
-	if err := broker.store.StoreServiceInstanceDetails(details); err != nil {
-		return fmt.Errorf("error saving instance details to database %v", err)
+	err = broker.storeServiceInstanceChange(details, func(details *storage.ServiceInstanceDetails) {
+		details.Outputs = outs
+		details.OperationGUID = ""
+		details.OperationType = models.ClearOperationType
+	})
+	if err != nil {
+		return fmt.Errorf("error saving instance details to database %w", err)
 	}
 
 	return nil
@@ -250,6 +250,40 @@ var _ = Describe("LastInstanceOperation", func() {
 			})
 		})
 
+		Context("service instance details are changed concurrently", func() {
+			BeforeEach(func() {
+				fakeStorage.StoreServiceInstanceDetailsReturnsOnCall(0, &storage.ConflictError{Kind: "service instance details", ID: instanceID, Version: 1})
+				fakeStorage.GetServiceInstanceDetailsReturnsOnCall(2, storage.ServiceInstanceDetails{
+					GUID:              instanceID,
+					OperationType:     models.ProvisionOperationType,
+					OperationGUID:     operationID,
+					ServiceGUID:       offeringID,
+					MaintenanceWindow: "sunday 02:00-04:00",
+					Version:           2,
+				}, nil)
+			})
+
+			It("reads the details again and stores the change over them", func() {
+				_, err := serviceBroker.LastOperation(context.TODO(), instanceID, pollDetails)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeStorage.StoreServiceInstanceDetailsCallCount()).To(Equal(2))
+				stored := fakeStorage.StoreServiceInstanceDetailsArgsForCall(1)
+				Expect(stored.Version).To(Equal(2))
+				Expect(stored.MaintenanceWindow).To(Equal("sunday 02:00-04:00"))
+				Expect(stored.OperationType).To(Equal(models.ClearOperationType))
+				Expect(stored.Outputs).To(Equal(expectedTFOutput))
+			})
+
+			It("gives up when the details keep being changed", func() {
+				fakeStorage.StoreServiceInstanceDetailsReturns(&storage.ConflictError{Kind: "service instance details", ID: instanceID, Version: 2})
+
+				_, err := serviceBroker.LastOperation(context.TODO(), instanceID, pollDetails)
+				Expect(storage.IsConflict(err)).To(BeTrue())
+				Expect(fakeStorage.StoreServiceInstanceDetailsCallCount()).To(Equal(3))
+			})
+		})
+
 		Context("storage errors when deleting service instance details", func() {
 			BeforeEach(func() {
 				fakeStorage.GetServiceInstanceDetailsReturns(
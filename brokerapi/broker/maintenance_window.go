@@ -0,0 +1,80 @@
+package broker
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cloudfoundry/cloud-service-broker/internal/maintenancewindow"
+	"github.com/cloudfoundry/cloud-service-broker/internal/storage"
+	"github.com/pivotal-cf/brokerapi/v8/domain/apiresponses"
+)
+
+// maintenanceWindowParameter is a request parameter that is handled by the broker
+// rather than being passed on to the brokerpak
+const maintenanceWindowParameter = "maintenance_window"
+
+// extractMaintenanceWindow removes the maintenance window from the request parameters and
+// returns it in canonical form. It also reports whether the parameter was specified, because
+// on update a null or empty value removes the window.
+func extractMaintenanceWindow(params map[string]interface{}) (window string, specified bool, err error) {
+	value, ok := params[maintenanceWindowParameter]
+	if !ok {
+		return "", false, nil
+	}
+	delete(params, maintenanceWindowParameter)
+
+	switch v := value.(type) {
+	case nil:
+		return "", true, nil
+	case string:
+		if strings.TrimSpace(v) == "" {
+			return "", true, nil
+		}
+		w, err := maintenancewindow.Parse(v)
+		if err != nil {
+			return "", false, invalidMaintenanceWindow(err)
+		}
+		return w.String(), true, nil
+	default:
+		return "", false, invalidMaintenanceWindow(fmt.Errorf("%s must be a string such as \"sunday 02:00-04:00\"", maintenanceWindowParameter))
+	}
+}
+
+func invalidMaintenanceWindow(err error) error {
+	return apiresponses.NewFailureResponse(err, http.StatusBadRequest, "invalid-maintenance-window")
+}
+
+// checkMaintenanceWindow returns an error if the instance has a maintenance window that
+// does not contain the current time, as upgrades are only allowed during the window
+func checkMaintenanceWindow(instance storage.ServiceInstanceDetails, now time.Time) error {
+	if instance.MaintenanceWindow == "" {
+		return nil
+	}
+
+	w, err := maintenancewindow.Parse(instance.MaintenanceWindow)
+	if err != nil {
+		return fmt.Errorf("error reading maintenance window of service instance %q: %w", instance.GUID, err)
+	}
+
+	if w.Contains(now) {
+		return nil
+	}
+
+	return apiresponses.NewFailureResponse(
+		fmt.Errorf("upgrades of this service instance are only allowed during its maintenance window (%s UTC), the next window starts at %s", w, w.Next(now).Format(time.RFC3339)),
+		http.StatusUnprocessableEntity,
+		"outside-maintenance-window",
+	)
+}
+
+// nextMaintenanceWindow describes when the instance may next be upgraded, so that it can be
+// added to messages saying that an upgrade is required. It is empty when there is no window.
+func nextMaintenanceWindow(instance storage.ServiceInstanceDetails, now time.Time) string {
+	w, err := maintenancewindow.Parse(instance.MaintenanceWindow)
+	if instance.MaintenanceWindow == "" || err != nil {
+		return ""
+	}
+	return fmt.Sprintf("; the next maintenance window for upgrades starts at %s", w.Next(now).Format(time.RFC3339))
+}
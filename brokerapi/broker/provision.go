@@ -17,8 +17,10 @@ package broker
 import (
 	"context"
 	"fmt"
+	"reflect"
 
 	"code.cloudfoundry.org/lager"
+	"github.com/cloudfoundry/cloud-service-broker/dbservice/models"
 	"github.com/cloudfoundry/cloud-service-broker/internal/paramparser"
 	"github.com/cloudfoundry/cloud-service-broker/utils/correlation"
 	"github.com/cloudfoundry/cloud-service-broker/utils/request"
@@ -45,7 +47,7 @@ func (broker *ServiceBroker) Provision(ctx context.Context, instanceID string, d
 	case err != nil:
 		return domain.ProvisionedServiceSpec{}, fmt.Errorf("database error checking for existing instance: %s", err)
 	case exists:
-		return domain.ProvisionedServiceSpec{}, apiresponses.ErrInstanceAlreadyExists
+		return broker.provisionExistingInstance(ctx, instanceID, details)
 	}
 
 	parsedDetails, err := paramparser.ParseProvisionDetails(details)
@@ -53,6 +55,11 @@ func (broker *ServiceBroker) Provision(ctx context.Context, instanceID string, d
 		return domain.ProvisionedServiceSpec{}, ErrInvalidUserInput
 	}
 
+	maintenanceWindow, _, err := extractMaintenanceWindow(parsedDetails.RequestParams)
+	if err != nil {
+		return domain.ProvisionedServiceSpec{}, err
+	}
+
 	serviceDefinition, serviceProvider, err := broker.getDefinitionAndProvider(parsedDetails.ServiceID)
 	if err != nil {
 		return domain.ProvisionedServiceSpec{}, err
@@ -73,6 +80,10 @@ func (broker *ServiceBroker) Provision(ctx context.Context, instanceID string, d
 		return domain.ProvisionedServiceSpec{}, err
 	}
 
+	if err := broker.checkQuotas(parsedDetails.OrganizationGUID, parsedDetails.SpaceGUID, parsedDetails.ServiceID, parsedDetails.PlanID, ""); err != nil {
+		return domain.ProvisionedServiceSpec{}, err
+	}
+
 	// validate parameters meet the service's schema and merge the user vars with
 	// the plan's
 	vars, err := serviceDefinition.ProvisionVariables(instanceID, parsedDetails, *plan, request.DecodeOriginatingIdentityHeader(ctx))
@@ -92,6 +103,7 @@ func (broker *ServiceBroker) Provision(ctx context.Context, instanceID string, d
 	instanceDetails.PlanGUID = parsedDetails.PlanID
 	instanceDetails.SpaceGUID = parsedDetails.SpaceGUID
 	instanceDetails.OrganizationGUID = parsedDetails.OrganizationGUID
+	instanceDetails.MaintenanceWindow = maintenanceWindow
 
 	if err := broker.store.StoreServiceInstanceDetails(instanceDetails); err != nil {
 		return domain.ProvisionedServiceSpec{}, fmt.Errorf("error saving instance details to database: %s. WARNING: this instance cannot be deprovisioned through cf. Contact your operator for cleanup", err)
@@ -104,3 +116,68 @@ func (broker *ServiceBroker) Provision(ctx context.Context, instanceID string, d
 
 	return domain.ProvisionedServiceSpec{IsAsync: true, DashboardURL: "", OperationData: instanceDetails.OperationGUID}, nil
 }
+
+// provisionExistingInstance answers a provision request for an instance that already exists.
+// The OSBAPI spec requires a repeated identical request to be accepted, so that the platform
+// can safely retry, while a request that differs from the original conflicts with it.
+func (broker *ServiceBroker) provisionExistingInstance(ctx context.Context, instanceID string, details domain.ProvisionDetails) (domain.ProvisionedServiceSpec, error) {
+	parsedDetails, err := paramparser.ParseProvisionDetails(details)
+	if err != nil {
+		return domain.ProvisionedServiceSpec{}, ErrInvalidUserInput
+	}
+
+	maintenanceWindow, _, err := extractMaintenanceWindow(parsedDetails.RequestParams)
+	if err != nil {
+		return domain.ProvisionedServiceSpec{}, err
+	}
+
+	instance, err := broker.store.GetServiceInstanceDetails(instanceID)
+	if err != nil {
+		return domain.ProvisionedServiceSpec{}, fmt.Errorf("database error getting existing instance: %s", err)
+	}
+
+	if instance.ServiceGUID != parsedDetails.ServiceID ||
+		instance.PlanGUID != parsedDetails.PlanID ||
+		instance.OrganizationGUID != parsedDetails.OrganizationGUID ||
+		instance.SpaceGUID != parsedDetails.SpaceGUID ||
+		instance.MaintenanceWindow != maintenanceWindow {
+		return domain.ProvisionedServiceSpec{}, apiresponses.ErrInstanceAlreadyExists
+	}
+
+	storedParams, err := broker.store.GetProvisionRequestDetails(instanceID)
+	if err != nil {
+		return domain.ProvisionedServiceSpec{}, fmt.Errorf("error retrieving provision request details for %q: %w", instanceID, err)
+	}
+
+	if !sameParameters(storedParams, parsedDetails.RequestParams) {
+		return domain.ProvisionedServiceSpec{}, apiresponses.ErrInstanceAlreadyExists
+	}
+
+	if instance.OperationType != models.ProvisionOperationType {
+		return domain.ProvisionedServiceSpec{AlreadyExists: true}, nil
+	}
+
+	_, serviceProvider, err := broker.getDefinitionAndProvider(instance.ServiceGUID)
+	if err != nil {
+		return domain.ProvisionedServiceSpec{}, err
+	}
+
+	done, _, err := serviceProvider.PollInstance(ctx, instanceID)
+	switch {
+	case err != nil:
+		// The original provision failed, so this is not the instance that was asked for
+		return domain.ProvisionedServiceSpec{}, apiresponses.ErrInstanceAlreadyExists
+	case !done:
+		return domain.ProvisionedServiceSpec{IsAsync: true, OperationData: instance.OperationGUID}, nil
+	default:
+		return domain.ProvisionedServiceSpec{AlreadyExists: true}, nil
+	}
+}
+
+// sameParameters compares request parameters, treating absent and empty parameters as equal
+func sameParameters(stored, requested map[string]interface{}) bool {
+	if len(stored) == 0 && len(requested) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(stored, requested)
+}
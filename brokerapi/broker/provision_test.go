@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
 
 	"github.com/cloudfoundry/cloud-service-broker/brokerapi/broker/decider"
 
@@ -22,6 +23,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/pivotal-cf/brokerapi/v8/domain"
+	"github.com/pivotal-cf/brokerapi/v8/domain/apiresponses"
 	"github.com/pivotal-cf/brokerapi/v8/middlewares"
 	"github.com/spf13/viper"
 )
@@ -38,6 +40,7 @@ var _ = Describe("Provision", func() {
 
 	var (
 		serviceBroker    *broker.ServiceBroker
+		brokerConfig     *broker.BrokerConfig
 		provisionDetails domain.ProvisionDetails
 
 		fakeStorage         *brokerfakes.FakeStorage
@@ -54,7 +57,7 @@ var _ = Describe("Provision", func() {
 		providerBuilder := func(logger lager.Logger, store pkgBroker.ServiceProviderStorage) pkgBroker.ServiceProvider {
 			return fakeServiceProvider
 		}
-		brokerConfig := &broker.BrokerConfig{
+		brokerConfig = &broker.BrokerConfig{
 			Registry: pkgBroker.BrokerRegistry{
 				"test-service": &pkgBroker.ServiceDefinition{
 					ID:   offeringID,
@@ -179,6 +182,19 @@ var _ = Describe("Provision", func() {
 			Expect(actualParams).To(Equal(expectedParams))
 		})
 
+		It("should store the maintenance window with the instance", func() {
+			provisionDetails.RawParameters = json.RawMessage(`{"foo":"something","maintenance_window":"SAT 23:00-01:00"}`)
+
+			_, err := serviceBroker.Provision(context.TODO(), newInstanceID, provisionDetails, true)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fakeStorage.StoreServiceInstanceDetailsArgsForCall(0).MaintenanceWindow).To(Equal("saturday 23:00-01:00"))
+			_, actualVars := fakeServiceProvider.ProvisionArgsForCall(0)
+			Expect(actualVars.ToMap()).NotTo(HaveKey("maintenance_window"))
+			_, actualParams := fakeStorage.StoreProvisionRequestDetailsArgsForCall(0)
+			Expect(actualParams).To(Equal(storage.JSONObject{"foo": "something"}))
+		})
+
 		Describe("provision variables", func() {
 			It("passes plan provided service properties", func() {
 				_, err := serviceBroker.Provision(context.TODO(), newInstanceID, provisionDetails, true)
@@ -257,6 +273,60 @@ var _ = Describe("Provision", func() {
 		})
 	})
 
+	Describe("quotas", func() {
+		BeforeEach(func() {
+			brokerConfig.Quotas = []broker.Quota{
+				{OrganizationGUID: "other-org-id", ServiceGUID: offeringID, Limit: 0},
+				{SpaceGUID: spaceID, PlanGUID: planID, Limit: 2},
+			}
+
+			var err error
+			serviceBroker, err = broker.New(brokerConfig, fakeStorage, decider.Decider{}, utils.NewLogger("brokers-test"))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("counts the instances that match the quotas that apply", func() {
+			fakeStorage.CountServiceInstanceDetailsReturns(1, nil)
+
+			_, err := serviceBroker.Provision(context.TODO(), newInstanceID, provisionDetails, true)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fakeStorage.CountServiceInstanceDetailsCallCount()).To(Equal(1))
+			Expect(fakeStorage.CountServiceInstanceDetailsArgsForCall(0)).To(Equal(storage.ServiceInstanceFilter{
+				SpaceGUID: spaceID,
+				PlanGUID:  planID,
+			}))
+			Expect(fakeServiceProvider.ProvisionCallCount()).To(Equal(1))
+		})
+
+		When("the quota has been reached", func() {
+			BeforeEach(func() {
+				fakeStorage.CountServiceInstanceDetailsReturns(2, nil)
+			})
+
+			It("should error with an unprocessable entity response", func() {
+				_, err := serviceBroker.Provision(context.TODO(), newInstanceID, provisionDetails, true)
+				Expect(err).To(MatchError(`quota exceeded: 2 of 2 instances of plan "test-plan-id" in space "test-space-id" are already in use`))
+				Expect(err).To(BeAssignableToTypeOf(&apiresponses.FailureResponse{}))
+				Expect(err.(*apiresponses.FailureResponse).ValidatedStatusCode(nil)).To(Equal(http.StatusUnprocessableEntity))
+
+				Expect(fakeServiceProvider.ProvisionCallCount()).To(BeZero())
+				Expect(fakeStorage.StoreServiceInstanceDetailsCallCount()).To(BeZero())
+			})
+		})
+
+		When("storage errors when counting instances", func() {
+			BeforeEach(func() {
+				fakeStorage.CountServiceInstanceDetailsReturns(0, errors.New("failed to count"))
+			})
+
+			It("should error", func() {
+				_, err := serviceBroker.Provision(context.TODO(), newInstanceID, provisionDetails, true)
+				Expect(err).To(MatchError("database error checking quota usage: failed to count"))
+			})
+		})
+	})
+
 	When("provider provision errors", func() {
 		BeforeEach(func() {
 			fakeServiceProvider.ProvisionReturns(storage.ServiceInstanceDetails{}, errors.New("cannot provision right now"))
@@ -271,11 +341,119 @@ var _ = Describe("Provision", func() {
 	When("instance already exists", func() {
 		BeforeEach(func() {
 			fakeStorage.ExistsServiceInstanceDetailsReturns(true, nil)
+			fakeStorage.GetServiceInstanceDetailsReturns(storage.ServiceInstanceDetails{
+				GUID:             newInstanceID,
+				ServiceGUID:      offeringID,
+				PlanGUID:         planID,
+				SpaceGUID:        spaceID,
+				OrganizationGUID: orgID,
+				OperationType:    models.ProvisionOperationType,
+				OperationGUID:    operationID,
+			}, nil)
+		})
+
+		When("the request is identical and the provision is in progress", func() {
+			It("should return the original operation", func() {
+				response, err := serviceBroker.Provision(context.TODO(), newInstanceID, provisionDetails, true)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(response).To(Equal(domain.ProvisionedServiceSpec{IsAsync: true, OperationData: operationID}))
+				Expect(fakeServiceProvider.PollInstanceCallCount()).To(Equal(1))
+				Expect(fakeServiceProvider.ProvisionCallCount()).To(BeZero())
+			})
+		})
+
+		When("the request is identical and the provision has completed", func() {
+			BeforeEach(func() {
+				fakeServiceProvider.PollInstanceReturns(true, "", nil)
+			})
+
+			It("should report that the instance already exists", func() {
+				response, err := serviceBroker.Provision(context.TODO(), newInstanceID, provisionDetails, true)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(response).To(Equal(domain.ProvisionedServiceSpec{AlreadyExists: true}))
+				Expect(fakeServiceProvider.ProvisionCallCount()).To(BeZero())
+			})
+		})
+
+		When("the request is identical and no operation is outstanding", func() {
+			BeforeEach(func() {
+				fakeStorage.GetServiceInstanceDetailsReturns(storage.ServiceInstanceDetails{
+					GUID:             newInstanceID,
+					ServiceGUID:      offeringID,
+					PlanGUID:         planID,
+					SpaceGUID:        spaceID,
+					OrganizationGUID: orgID,
+				}, nil)
+			})
+
+			It("should report that the instance already exists", func() {
+				response, err := serviceBroker.Provision(context.TODO(), newInstanceID, provisionDetails, true)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(response).To(Equal(domain.ProvisionedServiceSpec{AlreadyExists: true}))
+				Expect(fakeServiceProvider.PollInstanceCallCount()).To(BeZero())
+			})
+		})
+
+		When("the request is identical but the provision failed", func() {
+			BeforeEach(func() {
+				fakeServiceProvider.PollInstanceReturns(true, "", errors.New("provision failed"))
+			})
+
+			It("should error", func() {
+				_, err := serviceBroker.Provision(context.TODO(), newInstanceID, provisionDetails, true)
+				Expect(err).To(MatchError("instance already exists"))
+			})
+		})
+
+		When("the request has different parameters", func() {
+			It("should error", func() {
+				provisionDetails.RawParameters = json.RawMessage(`{"foo":"something"}`)
+
+				_, err := serviceBroker.Provision(context.TODO(), newInstanceID, provisionDetails, true)
+				Expect(err).To(MatchError("instance already exists"))
+			})
 		})
 
+		When("the request has a different maintenance window", func() {
+			It("should error", func() {
+				provisionDetails.RawParameters = json.RawMessage(`{"maintenance_window":"sunday 02:00-04:00"}`)
+
+				_, err := serviceBroker.Provision(context.TODO(), newInstanceID, provisionDetails, true)
+				Expect(err).To(MatchError("instance already exists"))
+			})
+		})
+
+		When("the request has a different plan", func() {
+			It("should error", func() {
+				provisionDetails.PlanID = "other-plan-id"
+
+				_, err := serviceBroker.Provision(context.TODO(), newInstanceID, provisionDetails, true)
+				Expect(err).To(MatchError("instance already exists"))
+			})
+		})
+
+		When("storage errors when getting provision parameters", func() {
+			BeforeEach(func() {
+				fakeStorage.GetProvisionRequestDetailsReturns(nil, errors.New("failed to get params"))
+			})
+
+			It("should error", func() {
+				_, err := serviceBroker.Provision(context.TODO(), newInstanceID, provisionDetails, true)
+				Expect(err).To(MatchError(`error retrieving provision request details for "test-instance-id": failed to get params`))
+			})
+		})
+	})
+
+	When("the maintenance window is invalid", func() {
 		It("should error", func() {
-			_, err := serviceBroker.Provision(context.TODO(), "new-instance", provisionDetails, true)
-			Expect(err).To(MatchError("instance already exists"))
+			provisionDetails.RawParameters = json.RawMessage(`{"maintenance_window":"sunday 02:00"}`)
+
+			_, err := serviceBroker.Provision(context.TODO(), newInstanceID, provisionDetails, true)
+			Expect(err).To(MatchError(ContainSubstring(`invalid maintenance window "sunday 02:00"`)))
+			Expect(fakeServiceProvider.ProvisionCallCount()).To(BeZero())
 		})
 	})
 
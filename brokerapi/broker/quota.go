@@ -0,0 +1,129 @@
+package broker
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/cloudfoundry/cloud-service-broker/internal/storage"
+	"github.com/pivotal-cf/brokerapi/v8/domain/apiresponses"
+	"github.com/spf13/viper"
+)
+
+const quotasProp = "quotas"
+
+func init() {
+	viper.BindEnv(quotasProp, "CSB_QUOTAS")
+}
+
+// Quota limits the number of service instances of a service or plan that may exist in
+// a platform organization or space. Empty GUIDs match any value, but a quota must be
+// scoped to an organization or space, and must target a service or plan.
+type Quota struct {
+	OrganizationGUID string `json:"organization_guid"`
+	SpaceGUID        string `json:"space_guid"`
+	ServiceGUID      string `json:"service_id"`
+	PlanGUID         string `json:"plan_id"`
+	Limit            int64  `json:"limit"`
+}
+
+func (q Quota) validate() error {
+	switch {
+	case q.OrganizationGUID == "" && q.SpaceGUID == "":
+		return fmt.Errorf("quota must specify an organization_guid or space_guid: %+v", q)
+	case q.ServiceGUID == "" && q.PlanGUID == "":
+		return fmt.Errorf("quota must specify a service_id or plan_id: %+v", q)
+	case q.Limit < 0:
+		return fmt.Errorf("quota limit must not be negative: %+v", q)
+	default:
+		return nil
+	}
+}
+
+func (q Quota) applies(orgGUID, spaceGUID, serviceGUID, planGUID string) bool {
+	return matchesIfSet(q.OrganizationGUID, orgGUID) &&
+		matchesIfSet(q.SpaceGUID, spaceGUID) &&
+		matchesIfSet(q.ServiceGUID, serviceGUID) &&
+		matchesIfSet(q.PlanGUID, planGUID)
+}
+
+func (q Quota) filter() storage.ServiceInstanceFilter {
+	return storage.ServiceInstanceFilter{
+		OrganizationGUID: q.OrganizationGUID,
+		SpaceGUID:        q.SpaceGUID,
+		ServiceGUID:      q.ServiceGUID,
+		PlanGUID:         q.PlanGUID,
+	}
+}
+
+func (q Quota) String() string {
+	var target, scope string
+	switch q.PlanGUID {
+	case "":
+		target = fmt.Sprintf("service %q", q.ServiceGUID)
+	default:
+		target = fmt.Sprintf("plan %q", q.PlanGUID)
+	}
+	switch q.SpaceGUID {
+	case "":
+		scope = fmt.Sprintf("organization %q", q.OrganizationGUID)
+	default:
+		scope = fmt.Sprintf("space %q", q.SpaceGUID)
+	}
+	return fmt.Sprintf("%s in %s", target, scope)
+}
+
+func matchesIfSet(want, got string) bool {
+	return want == "" || want == got
+}
+
+// ParseQuotas reads the JSON list of quotas from the broker configuration.
+func ParseQuotas() ([]Quota, error) {
+	config := viper.GetString(quotasProp)
+	if config == "" {
+		return nil, nil
+	}
+
+	var quotas []Quota
+	if err := json.Unmarshal([]byte(config), &quotas); err != nil {
+		return nil, fmt.Errorf("failed unmarshaling config value %s: %w", quotasProp, err)
+	}
+
+	for _, q := range quotas {
+		if err := q.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return quotas, nil
+}
+
+// checkQuotas returns an error if creating an instance of the target service plan
+// would exceed a quota. When an existing instance changes plan, its current service
+// plan is passed as the source so that quotas it already counts towards are skipped.
+// The check is best-effort: usage is not locked, so concurrent requests may both pass.
+func (broker *ServiceBroker) checkQuotas(orgGUID, spaceGUID, serviceGUID, targetPlanGUID, sourcePlanGUID string) error {
+	for _, q := range broker.quotas {
+		if !q.applies(orgGUID, spaceGUID, serviceGUID, targetPlanGUID) {
+			continue
+		}
+		if sourcePlanGUID != "" && q.applies(orgGUID, spaceGUID, serviceGUID, sourcePlanGUID) {
+			continue
+		}
+
+		count, err := broker.store.CountServiceInstanceDetails(q.filter())
+		if err != nil {
+			return fmt.Errorf("database error checking quota usage: %w", err)
+		}
+
+		if count >= q.Limit {
+			return apiresponses.NewFailureResponse(
+				fmt.Errorf("quota exceeded: %d of %d instances of %s are already in use", count, q.Limit, q),
+				http.StatusUnprocessableEntity,
+				"quota-exceeded",
+			)
+		}
+	}
+
+	return nil
+}
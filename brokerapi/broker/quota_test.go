@@ -0,0 +1,41 @@
+package broker_test
+
+import (
+	"github.com/cloudfoundry/cloud-service-broker/brokerapi/broker"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/viper"
+)
+
+var _ = Describe("ParseQuotas", func() {
+	AfterEach(func() {
+		viper.Set("quotas", "")
+	})
+
+	It("returns no quotas when none are configured", func() {
+		Expect(broker.ParseQuotas()).To(BeEmpty())
+	})
+
+	It("parses the configured quotas", func() {
+		viper.Set("quotas", `[{"organization_guid":"org-guid","service_id":"service-guid","limit":3},{"space_guid":"space-guid","plan_id":"plan-guid","limit":1}]`)
+
+		Expect(broker.ParseQuotas()).To(Equal([]broker.Quota{
+			{OrganizationGUID: "org-guid", ServiceGUID: "service-guid", Limit: 3},
+			{SpaceGUID: "space-guid", PlanGUID: "plan-guid", Limit: 1},
+		}))
+	})
+
+	DescribeTable(
+		"invalid quotas",
+		func(config, expectedError string) {
+			viper.Set("quotas", config)
+
+			_, err := broker.ParseQuotas()
+			Expect(err).To(MatchError(ContainSubstring(expectedError)))
+		},
+		Entry("invalid JSON", `{`, "failed unmarshaling config value quotas"),
+		Entry("no organization or space", `[{"service_id":"service-guid","limit":1}]`, "quota must specify an organization_guid or space_guid"),
+		Entry("no service or plan", `[{"space_guid":"space-guid","limit":1}]`, "quota must specify a service_id or plan_id"),
+		Entry("negative limit", `[{"space_guid":"space-guid","plan_id":"plan-guid","limit":-1}]`, "quota limit must not be negative"),
+	)
+})
@@ -18,6 +18,8 @@ type Storage interface {
 	DeleteServiceBindingCredentials(bindingID, serviceInstanceID string) error
 	StoreBindRequestDetails(bindRequestDetails storage.BindRequestDetails) error
 	GetBindRequestDetails(bindingID, instanceID string) (storage.JSONObject, error)
+	GetBindRequest(bindingID, instanceID string) (storage.BindRequestDetails, error)
+	GetBindRequestContext(bindingID, instanceID string) (storage.JSONObject, error)
 	DeleteBindRequestDetails(bindingID, instanceID string) error
 	StoreProvisionRequestDetails(serviceInstanceID string, details storage.JSONObject) error
 	GetProvisionRequestDetails(serviceInstanceID string) (storage.JSONObject, error)
@@ -25,5 +27,8 @@ type Storage interface {
 	StoreServiceInstanceDetails(d storage.ServiceInstanceDetails) error
 	GetServiceInstanceDetails(guid string) (storage.ServiceInstanceDetails, error)
 	ExistsServiceInstanceDetails(guid string) (bool, error)
+	CountServiceInstanceDetails(f storage.ServiceInstanceFilter) (int64, error)
+	GetServiceInstanceGUIDs(f storage.ServiceInstanceFilter) ([]string, error)
 	DeleteServiceInstanceDetails(guid string) error
+	GetOperations(f storage.OperationFilter) ([]storage.Operation, error)
 }
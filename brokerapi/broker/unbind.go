@@ -6,6 +6,7 @@ import (
 
 	"code.cloudfoundry.org/lager"
 	"github.com/cloudfoundry/cloud-service-broker/internal/paramparser"
+	"github.com/cloudfoundry/cloud-service-broker/pkg/credstore"
 	"github.com/cloudfoundry/cloud-service-broker/utils/correlation"
 	"github.com/cloudfoundry/cloud-service-broker/utils/request"
 	"github.com/pivotal-cf/brokerapi/v8/domain"
@@ -27,6 +28,15 @@ func (broker *ServiceBroker) Unbind(ctx context.Context, instanceID, bindingID s
 		return domain.UnbindSpec{}, err
 	}
 
+	// validate existence of binding
+	exists, err := broker.store.ExistsServiceBindingCredentials(bindingID, instanceID)
+	switch {
+	case err != nil:
+		return domain.UnbindSpec{}, fmt.Errorf("error locating service binding: %w", err)
+	case !exists:
+		return domain.UnbindSpec{}, apiresponses.ErrBindingDoesNotExist
+	}
+
 	err = serviceProvider.CheckUpgradeAvailable(generateTFBindingID(instanceID, bindingID))
 	if err != nil {
 		return domain.UnbindSpec{}, fmt.Errorf("failed to unbind: %s", err.Error())
@@ -37,15 +47,6 @@ func (broker *ServiceBroker) Unbind(ctx context.Context, instanceID, bindingID s
 		return domain.UnbindSpec{}, err
 	}
 
-	// validate existence of binding
-	exists, err := broker.store.ExistsServiceBindingCredentials(bindingID, instanceID)
-	switch {
-	case err != nil:
-		return domain.UnbindSpec{}, fmt.Errorf("error locating service binding: %w", err)
-	case !exists:
-		return domain.UnbindSpec{}, apiresponses.ErrBindingDoesNotExist
-	}
-
 	// get existing service instance details
 	instance, err := broker.store.GetServiceInstanceDetails(instanceID)
 	if err != nil {
@@ -64,7 +65,9 @@ func (broker *ServiceBroker) Unbind(ctx context.Context, instanceID, bindingID s
 		RequestParams: storedParams,
 	}
 
-	vars, err := serviceDefinition.BindVariables(instance, bindingID, parsedDetails, plan, request.DecodeOriginatingIdentityHeader(ctx))
+	instanceState := getInstanceState(ctx, broker.Logger, serviceProvider, instanceID)
+
+	vars, err := serviceDefinition.BindVariables(instance, instanceState, bindingID, parsedDetails, plan, request.DecodeOriginatingIdentityHeader(ctx))
 	if err != nil {
 		return domain.UnbindSpec{}, err
 	}
@@ -83,8 +86,13 @@ func (broker *ServiceBroker) Unbind(ctx context.Context, instanceID, bindingID s
 			broker.Logger.Error(fmt.Sprintf("fail to delete permissions on the key %s", credentialName), err)
 		}
 
-		err := broker.Credstore.Delete(credentialName)
+		// the context tells credential stores such as Kubernetes where the credentials were stored
+		requestContext, err := broker.store.GetBindRequestContext(bindingID, instanceID)
 		if err != nil {
+			return domain.UnbindSpec{}, fmt.Errorf("error retrieving bind request context for %q: %w", bindingID, err)
+		}
+
+		if err := credstore.DeleteWithContext(broker.Credstore, credentialName, requestContext); err != nil {
 			return domain.UnbindSpec{}, err
 		}
 	}
@@ -159,6 +159,32 @@ var _ = Describe("Unbind", func() {
 			Expect(actualInstanceID).To(Equal(instanceID))
 		})
 
+		When("the credstore deletes credentials from the location given by the bind request context", func() {
+			var contextCredStore *kubernetesCredStore
+
+			BeforeEach(func() {
+				fakeStorage.GetBindRequestContextReturns(storage.JSONObject{"namespace": "app-namespace"}, nil)
+				contextCredStore = &kubernetesCredStore{FakeCredStore: fakeCredStore}
+				brokerConfig.Credstore = contextCredStore
+				var err error
+				serviceBroker, err = broker.New(brokerConfig, fakeStorage, decider.Decider{}, utils.NewLogger("unbind-test-kubernetes-credstore"))
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("deletes the credentials with the stored context", func() {
+				_, err := serviceBroker.Unbind(context.TODO(), instanceID, bindingID, unbindDetails, false)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(fakeStorage.GetBindRequestContextCallCount()).To(Equal(1))
+				actualBindingID, actualInstanceID := fakeStorage.GetBindRequestContextArgsForCall(0)
+				Expect(actualBindingID).To(Equal(bindingID))
+				Expect(actualInstanceID).To(Equal(instanceID))
+
+				Expect(contextCredStore.deletedContexts).To(Equal([]map[string]interface{}{{"namespace": "app-namespace"}}))
+				Expect(fakeCredStore.DeleteCallCount()).To(Equal(0))
+			})
+		})
+
 		When("credstore disabled", func() {
 			BeforeEach(func() {
 				brokerConfig.Credstore = nil
@@ -252,12 +278,14 @@ var _ = Describe("Unbind", func() {
 		When("the service binding credentials do not exist", func() {
 			BeforeEach(func() {
 				fakeStorage.ExistsServiceBindingCredentialsReturns(false, nil)
+				fakeServiceProvider.CheckUpgradeAvailableReturns(fmt.Errorf("could not find terraform deployment"))
 			})
 
-			It("should error", func() {
+			It("should report that the binding is gone", func() {
 				_, err := serviceBroker.Unbind(context.TODO(), instanceID, bindingID, unbindDetails, false)
 
 				Expect(err).To(MatchError(apiresponses.ErrBindingDoesNotExist))
+				Expect(fakeServiceProvider.UnbindCallCount()).To(BeZero())
 			})
 		})
 
@@ -297,6 +325,20 @@ var _ = Describe("Unbind", func() {
 			})
 		})
 
+		When("error retrieving the service instance state", func() {
+			BeforeEach(func() {
+				fakeServiceProvider.GetInstanceStateReturns(nil, fmt.Errorf("error"))
+			})
+
+			It("unbinds with an empty instance state", func() {
+				_, err := serviceBroker.Unbind(context.TODO(), instanceID, bindingID, unbindDetails, false)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fakeServiceProvider.UnbindCallCount()).To(Equal(1))
+				Expect(fakeStorage.DeleteServiceBindingCredentialsCallCount()).To(Equal(1))
+			})
+		})
+
 		When("provider unbind fails", func() {
 			BeforeEach(func() {
 				fakeServiceProvider.UnbindReturns(fmt.Errorf("unbind-error"))
@@ -337,6 +379,19 @@ var _ = Describe("Unbind", func() {
 			})
 		})
 
+		When("fails to get binding request context", func() {
+			BeforeEach(func() {
+				fakeStorage.GetBindRequestContextReturns(nil, fmt.Errorf("context-error"))
+			})
+
+			It("should error", func() {
+				_, err := serviceBroker.Unbind(context.TODO(), instanceID, bindingID, unbindDetails, false)
+
+				Expect(err).To(MatchError(fmt.Sprintf(`error retrieving bind request context for %q: context-error`, bindingID)))
+				Expect(fakeCredStore.DeleteCallCount()).To(Equal(0))
+			})
+		})
+
 		When("credstore fails to delete key", func() {
 			BeforeEach(func() {
 				fakeCredStore.DeleteReturns(fmt.Errorf("credstore-error"))
@@ -350,3 +405,14 @@ var _ = Describe("Unbind", func() {
 		})
 	})
 })
+
+// kubernetesCredStore is a credstore that deletes credentials from the namespace of the bind request context
+type kubernetesCredStore struct {
+	*credstorefakes.FakeCredStore
+	deletedContexts []map[string]interface{}
+}
+
+func (k *kubernetesCredStore) DeleteWithContext(key string, requestContext map[string]interface{}) error {
+	k.deletedContexts = append(k.deletedContexts, requestContext)
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/cloudfoundry/cloud-service-broker/brokerapi/broker/decider"
 	"github.com/cloudfoundry/cloud-service-broker/internal/storage"
@@ -54,6 +55,11 @@ func (broker *ServiceBroker) Update(ctx context.Context, instanceID string, deta
 		return domain.UpdateServiceSpec{}, ErrInvalidUserInput
 	}
 
+	maintenanceWindow, maintenanceWindowSpecified, err := extractMaintenanceWindow(parsedDetails.RequestParams)
+	if err != nil {
+		return domain.UpdateServiceSpec{}, err
+	}
+
 	// verify the service exists and the plan exists
 	plan, err := serviceDefinition.GetPlanByID(parsedDetails.PlanID)
 	if err != nil {
@@ -73,6 +79,12 @@ func (broker *ServiceBroker) Update(ctx context.Context, instanceID string, deta
 		return domain.UpdateServiceSpec{}, ErrNonUpdatableParameter
 	}
 
+	if instance.PlanGUID != parsedDetails.PlanID {
+		if err := broker.checkQuotas(instance.OrganizationGUID, instance.SpaceGUID, instance.ServiceGUID, parsedDetails.PlanID, instance.PlanGUID); err != nil {
+			return domain.UpdateServiceSpec{}, err
+		}
+	}
+
 	provisionDetails, err := broker.store.GetProvisionRequestDetails(instanceID)
 	if err != nil {
 		return domain.UpdateServiceSpec{}, fmt.Errorf("error retrieving provision request details for %q: %w", instanceID, err)
@@ -98,9 +110,15 @@ func (broker *ServiceBroker) Update(ctx context.Context, instanceID string, deta
 	case err != nil:
 		return domain.UpdateServiceSpec{}, fmt.Errorf("error deciding update path: %w", err)
 	case operation == decider.Upgrade:
+		if err := checkMaintenanceWindow(instance, time.Now()); err != nil {
+			return domain.UpdateServiceSpec{}, err
+		}
 		return broker.doUpgrade(ctx, serviceProvider, vars)
 	default:
-		return broker.doUpdate(ctx, serviceProvider, instance, vars, parsedDetails, mergedDetails)
+		if !maintenanceWindowSpecified {
+			maintenanceWindow = instance.MaintenanceWindow
+		}
+		return broker.doUpdate(ctx, serviceProvider, instance, vars, parsedDetails, mergedDetails, maintenanceWindow)
 	}
 }
 
@@ -116,10 +134,10 @@ func (broker *ServiceBroker) doUpgrade(ctx context.Context, serviceProvider brok
 	}, nil
 }
 
-func (broker *ServiceBroker) doUpdate(ctx context.Context, serviceProvider broker.ServiceProvider, instance storage.ServiceInstanceDetails, vars *varcontext.VarContext, parsedDetails paramparser.UpdateDetails, mergedDetails map[string]interface{}) (domain.UpdateServiceSpec, error) {
+func (broker *ServiceBroker) doUpdate(ctx context.Context, serviceProvider broker.ServiceProvider, instance storage.ServiceInstanceDetails, vars *varcontext.VarContext, parsedDetails paramparser.UpdateDetails, mergedDetails map[string]interface{}, maintenanceWindow string) (domain.UpdateServiceSpec, error) {
 	err := serviceProvider.CheckUpgradeAvailable(generateTFInstanceID(instance.GUID))
 	if err != nil {
-		return domain.UpdateServiceSpec{}, fmt.Errorf("terraform version check failed: %s", err.Error())
+		return domain.UpdateServiceSpec{}, fmt.Errorf("terraform version check failed: %s%s", err.Error(), nextMaintenanceWindow(instance, time.Now()))
 	}
 
 	instanceDetails, err := serviceProvider.Update(ctx, vars)
@@ -127,10 +145,13 @@ func (broker *ServiceBroker) doUpdate(ctx context.Context, serviceProvider broke
 		return domain.UpdateServiceSpec{}, err
 	}
 
-	// save instance plan change
-	if instance.PlanGUID != parsedDetails.PlanID {
-		instance.PlanGUID = parsedDetails.PlanID
-		if err := broker.store.StoreServiceInstanceDetails(instance); err != nil {
+	// save instance plan and maintenance window changes
+	if instance.PlanGUID != parsedDetails.PlanID || instance.MaintenanceWindow != maintenanceWindow {
+		err := broker.storeServiceInstanceChange(instance, func(instance *storage.ServiceInstanceDetails) {
+			instance.PlanGUID = parsedDetails.PlanID
+			instance.MaintenanceWindow = maintenanceWindow
+		})
+		if err != nil {
 			return domain.UpdateServiceSpec{}, fmt.Errorf("error saving instance details to database: %s. WARNING: this instance cannot be deprovisioned through cf. Contact your operator for cleanup", err)
 		}
 	}
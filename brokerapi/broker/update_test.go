@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"code.cloudfoundry.org/lager"
 	"github.com/cloudfoundry/cloud-service-broker/brokerapi/broker/brokerfakes"
@@ -37,6 +39,7 @@ var _ = Describe("Update", func() {
 
 	var (
 		serviceBroker *broker.ServiceBroker
+		brokerConfig  *broker.BrokerConfig
 		updateDetails domain.UpdateDetails
 
 		fakeStorage         *brokerfakes.FakeStorage
@@ -50,7 +53,7 @@ var _ = Describe("Update", func() {
 		providerBuilder := func(logger lager.Logger, store pkgBroker.ServiceProviderStorage) pkgBroker.ServiceProvider {
 			return fakeServiceProvider
 		}
-		brokerConfig := &broker.BrokerConfig{
+		brokerConfig = &broker.BrokerConfig{
 			Registry: pkgBroker.BrokerRegistry{
 				"test-service": &pkgBroker.ServiceDefinition{
 					ID:   offeringID,
@@ -225,6 +228,42 @@ var _ = Describe("Update", func() {
 				Expect(actualSIDetails.SpaceGUID).To(Equal(spaceID))
 				Expect(actualSIDetails.OrganizationGUID).To(Equal(orgID))
 			})
+
+			Describe("quotas", func() {
+				BeforeEach(func() {
+					brokerConfig.Quotas = []broker.Quota{
+						{OrganizationGUID: orgID, ServiceGUID: offeringID, Limit: 1},
+						{SpaceGUID: spaceID, PlanGUID: newPlanID, Limit: 3},
+					}
+
+					var err error
+					serviceBroker, err = broker.New(brokerConfig, fakeStorage, fakeDecider, utils.NewLogger("brokers-test"))
+					Expect(err).ToNot(HaveOccurred())
+				})
+
+				It("only checks the quotas of the new plan that the instance does not already count towards", func() {
+					fakeStorage.CountServiceInstanceDetailsReturns(2, nil)
+
+					_, err := serviceBroker.Update(context.TODO(), instanceID, updateDetails, true)
+					Expect(err).ToNot(HaveOccurred())
+
+					Expect(fakeStorage.CountServiceInstanceDetailsCallCount()).To(Equal(1))
+					Expect(fakeStorage.CountServiceInstanceDetailsArgsForCall(0)).To(Equal(storage.ServiceInstanceFilter{
+						SpaceGUID: spaceID,
+						PlanGUID:  newPlanID,
+					}))
+				})
+
+				When("the quota of the new plan has been reached", func() {
+					It("should error", func() {
+						fakeStorage.CountServiceInstanceDetailsReturns(3, nil)
+
+						_, err := serviceBroker.Update(context.TODO(), instanceID, updateDetails, true)
+						Expect(err).To(MatchError(`quota exceeded: 3 of 3 instances of plan "new-test-plan-id" in space "test-space-id" are already in use`))
+						Expect(fakeServiceProvider.UpdateCallCount()).To(BeZero())
+					})
+				})
+			})
 		})
 
 		When("parameter change is requested", func() {
@@ -298,6 +337,63 @@ var _ = Describe("Update", func() {
 				Expect(fakeServiceProvider.UpdateCallCount()).To(Equal(0))
 			})
 		})
+
+		Describe("maintenance window", func() {
+			BeforeEach(func() {
+				fakeServiceProvider.UpdateReturns(models.ServiceInstanceDetails{OperationID: updateOperationID}, nil)
+				updateDetails.RawParameters = json.RawMessage(`{"foo":"quz","maintenance_window":"Sun 02:00-04:00"}`)
+			})
+
+			It("stores the window with the instance rather than passing it to the brokerpak", func() {
+				_, err := serviceBroker.Update(context.TODO(), instanceID, updateDetails, true)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(fakeStorage.StoreServiceInstanceDetailsCallCount()).To(Equal(1))
+				Expect(fakeStorage.StoreServiceInstanceDetailsArgsForCall(0).MaintenanceWindow).To(Equal("sunday 02:00-04:00"))
+
+				_, actualVars := fakeServiceProvider.UpdateArgsForCall(0)
+				Expect(actualVars.ToMap()).NotTo(HaveKey("maintenance_window"))
+				_, actualRequestVars := fakeStorage.StoreProvisionRequestDetailsArgsForCall(0)
+				Expect(actualRequestVars).To(Equal(storage.JSONObject{"foo": "quz"}))
+			})
+
+			It("removes the window when it is null", func() {
+				fakeStorage.GetServiceInstanceDetailsReturns(storage.ServiceInstanceDetails{
+					GUID:              instanceID,
+					ServiceGUID:       offeringID,
+					PlanGUID:          originalPlanID,
+					MaintenanceWindow: "sunday 02:00-04:00",
+				}, nil)
+				updateDetails.RawParameters = json.RawMessage(`{"maintenance_window":null}`)
+
+				_, err := serviceBroker.Update(context.TODO(), instanceID, updateDetails, true)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(fakeStorage.StoreServiceInstanceDetailsCallCount()).To(Equal(1))
+				Expect(fakeStorage.StoreServiceInstanceDetailsArgsForCall(0).MaintenanceWindow).To(BeEmpty())
+			})
+
+			It("rejects an invalid window", func() {
+				updateDetails.RawParameters = json.RawMessage(`{"maintenance_window":"someday 02:00-04:00"}`)
+
+				_, err := serviceBroker.Update(context.TODO(), instanceID, updateDetails, true)
+				Expect(err).To(MatchError(ContainSubstring(`invalid maintenance window "someday 02:00-04:00"`)))
+				Expect(fakeServiceProvider.UpdateCallCount()).To(Equal(0))
+			})
+
+			It("reports the next window when an upgrade is required", func() {
+				fakeStorage.GetServiceInstanceDetailsReturns(storage.ServiceInstanceDetails{
+					GUID:              instanceID,
+					ServiceGUID:       offeringID,
+					PlanGUID:          originalPlanID,
+					MaintenanceWindow: "sunday 02:00-04:00",
+				}, nil)
+				fakeServiceProvider.CheckUpgradeAvailableReturns(errors.New("cannot use this tf version"))
+
+				_, err := serviceBroker.Update(context.TODO(), instanceID, updateDetails, true)
+				Expect(err).To(MatchError(MatchRegexp(`^terraform version check failed: cannot use this tf version; the next maintenance window for upgrades starts at \d{4}-\d{2}-\d{2}T02:00:00Z$`)))
+			})
+		})
 	})
 
 	Describe("upgrade", func() {
@@ -359,6 +455,37 @@ var _ = Describe("Update", func() {
 				Expect(err).To(MatchError("cannot upgrade right now"))
 			})
 		})
+
+		Describe("maintenance window", func() {
+			instanceWithWindow := func(window string) storage.ServiceInstanceDetails {
+				return storage.ServiceInstanceDetails{
+					GUID:              instanceID,
+					ServiceGUID:       offeringID,
+					PlanGUID:          originalPlanID,
+					MaintenanceWindow: window,
+				}
+			}
+
+			It("upgrades during the window", func() {
+				now := time.Now().UTC()
+				start, end := now.Add(-time.Hour), now.Add(time.Hour)
+				window := fmt.Sprintf("%s %s-%s", strings.ToLower(start.Weekday().String()), start.Format("15:04"), end.Format("15:04"))
+				fakeStorage.GetServiceInstanceDetailsReturns(instanceWithWindow(window), nil)
+
+				_, err := serviceBroker.Update(context.TODO(), instanceID, updateDetails, true)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(fakeServiceProvider.UpgradeCallCount()).To(Equal(1))
+			})
+
+			It("rejects an upgrade outside the window", func() {
+				day := strings.ToLower(time.Now().UTC().Add(48 * time.Hour).Weekday().String())
+				fakeStorage.GetServiceInstanceDetailsReturns(instanceWithWindow(day+" 01:00-02:00"), nil)
+
+				_, err := serviceBroker.Update(context.TODO(), instanceID, updateDetails, true)
+				Expect(err).To(MatchError(MatchRegexp(`^upgrades of this service instance are only allowed during its maintenance window \(%s 01:00-02:00 UTC\), the next window starts at \d{4}-\d{2}-\d{2}T01:00:00Z$`, day)))
+				Expect(fakeServiceProvider.UpgradeCallCount()).To(Equal(0))
+			})
+		})
 	})
 
 	Describe("context variables", func() {
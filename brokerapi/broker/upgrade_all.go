@@ -0,0 +1,282 @@
+package broker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/cloudfoundry/cloud-service-broker/dbservice/models"
+	"github.com/cloudfoundry/cloud-service-broker/internal/storage"
+	pkgBroker "github.com/cloudfoundry/cloud-service-broker/pkg/broker"
+	"github.com/pivotal-cf/brokerapi/v8/domain"
+)
+
+const defaultUpgradePollInterval = 10 * time.Second
+
+// UpgradeAllOptions control which service instances are upgraded by UpgradeAll, and how.
+type UpgradeAllOptions struct {
+	// ServiceGUID and PlanGUID restrict the upgrade to instances of a service or plan
+	ServiceGUID string `json:"service_id"`
+	PlanGUID    string `json:"plan_id"`
+
+	// Parallelism is the number of instances that are upgraded at the same time. Default: 1
+	Parallelism int `json:"parallelism"`
+
+	// BatchSize is the number of instances in each batch. A batch is finished before the next is started.
+	// Default: the parallelism
+	BatchSize int `json:"batch_size"`
+
+	// FailureThreshold is the number of failed upgrades after which no further batches are started.
+	// Zero means that all batches are attempted.
+	FailureThreshold int `json:"failure_threshold"`
+
+	// DryRun lists the instances that would be upgraded without upgrading them
+	DryRun bool `json:"dry_run"`
+
+	// PollInterval is how often the state of an upgrade is checked. Default: 10s
+	PollInterval time.Duration `json:"-"`
+}
+
+const (
+	UpgradeOutcomeSucceeded = "succeeded"
+	UpgradeOutcomeFailed    = "failed"
+	UpgradeOutcomeSkipped   = "skipped"
+	UpgradeOutcomePending   = "pending"
+)
+
+// UpgradeResult is the outcome of upgrading a service instance
+type UpgradeResult struct {
+	InstanceGUID string `json:"instance_id"`
+	ServiceGUID  string `json:"service_id"`
+	PlanGUID     string `json:"plan_id"`
+	Outcome      string `json:"outcome"`
+	Message      string `json:"message,omitempty"`
+}
+
+// UpgradeAllReport lists the service instances that needed an upgrade, and what happened to each of them
+type UpgradeAllReport struct {
+	Results []UpgradeResult `json:"results"`
+}
+
+// Failed returns the number of upgrades that failed
+func (r UpgradeAllReport) Failed() int {
+	count := 0
+	for _, result := range r.Results {
+		if result.Outcome == UpgradeOutcomeFailed {
+			count++
+		}
+	}
+	return count
+}
+
+// UpgradeAll upgrades the service instances that CheckUpgradeAvailable reports as being behind, waiting for
+// each upgrade to finish. Instances are upgraded in batches, and once the failure threshold is reached the
+// remaining instances are skipped. Instances outside their maintenance window are also skipped.
+func (broker *ServiceBroker) UpgradeAll(ctx context.Context, opts UpgradeAllOptions) (UpgradeAllReport, error) {
+	if opts.Parallelism < 1 {
+		opts.Parallelism = 1
+	}
+	if opts.BatchSize < 1 {
+		opts.BatchSize = opts.Parallelism
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaultUpgradePollInterval
+	}
+
+	candidates, failures, err := broker.findUpgradeCandidates(storage.ServiceInstanceFilter{ServiceGUID: opts.ServiceGUID, PlanGUID: opts.PlanGUID})
+	if err != nil {
+		return UpgradeAllReport{}, err
+	}
+
+	report := UpgradeAllReport{Results: make([]UpgradeResult, len(candidates))}
+	for i, c := range candidates {
+		report.Results[i] = UpgradeResult{
+			InstanceGUID: c.instance.GUID,
+			ServiceGUID:  c.instance.ServiceGUID,
+			PlanGUID:     c.instance.PlanGUID,
+			Outcome:      UpgradeOutcomePending,
+			Message:      c.reason,
+		}
+	}
+	report.Results = append(report.Results, failures...)
+
+	if opts.DryRun {
+		return report, nil
+	}
+
+	for start := 0; start < len(candidates); start += opts.BatchSize {
+		end := start + opts.BatchSize
+		if end > len(candidates) {
+			end = len(candidates)
+		}
+
+		// Only failed upgrades count towards the threshold, not instances that could not be checked
+		failed := UpgradeAllReport{Results: report.Results[:start]}.Failed()
+		if opts.FailureThreshold > 0 && failed >= opts.FailureThreshold {
+			for i := start; i < len(candidates); i++ {
+				report.Results[i].Outcome = UpgradeOutcomeSkipped
+				report.Results[i].Message = fmt.Sprintf("not attempted as %d upgrades failed", failed)
+			}
+			break
+		}
+
+		broker.upgradeBatch(ctx, candidates[start:end], report.Results[start:end], opts)
+	}
+
+	broker.Logger.Info("upgrade-all", lager.Data{"instances": len(candidates), "failed": report.Failed(), "dry_run": opts.DryRun})
+	return report, nil
+}
+
+type upgradeCandidate struct {
+	instance storage.ServiceInstanceDetails
+	reason   string
+}
+
+// findUpgradeCandidates returns the instances that have a newer Terraform version available, and a failed
+// result for each instance that could not be checked
+func (broker *ServiceBroker) findUpgradeCandidates(filter storage.ServiceInstanceFilter) ([]upgradeCandidate, []UpgradeResult, error) {
+	guids, err := broker.store.GetServiceInstanceGUIDs(filter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		candidates []upgradeCandidate
+		failures   []UpgradeResult
+	)
+	for _, guid := range guids {
+		instance, err := broker.store.GetServiceInstanceDetails(guid)
+		if err != nil {
+			return nil, nil, fmt.Errorf("database error getting existing instance: %w", err)
+		}
+
+		err = broker.checkUpgradeAvailable(instance)
+		switch {
+		case errors.Is(err, pkgBroker.ErrUpgradeRequired):
+			candidates = append(candidates, upgradeCandidate{instance: instance, reason: err.Error()})
+		case err != nil:
+			failures = append(failures, UpgradeResult{
+				InstanceGUID: instance.GUID,
+				ServiceGUID:  instance.ServiceGUID,
+				PlanGUID:     instance.PlanGUID,
+				Outcome:      UpgradeOutcomeFailed,
+				Message:      fmt.Sprintf("could not check whether an upgrade is available: %s", err),
+			})
+		}
+	}
+
+	return candidates, failures, nil
+}
+
+func (broker *ServiceBroker) checkUpgradeAvailable(instance storage.ServiceInstanceDetails) error {
+	_, serviceProvider, err := broker.getDefinitionAndProvider(instance.ServiceGUID)
+	if err != nil {
+		return err
+	}
+
+	return serviceProvider.CheckUpgradeAvailable(generateTFInstanceID(instance.GUID))
+}
+
+func (broker *ServiceBroker) upgradeBatch(ctx context.Context, batch []upgradeCandidate, results []UpgradeResult, opts UpgradeAllOptions) {
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < opts.Parallelism; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				results[i].Outcome, results[i].Message = broker.upgradeInstance(ctx, batch[i].instance, opts.PollInterval)
+			}
+		}()
+	}
+
+	for i := range batch {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+}
+
+func (broker *ServiceBroker) upgradeInstance(ctx context.Context, instance storage.ServiceInstanceDetails, pollInterval time.Duration) (outcome, message string) {
+	if err := checkMaintenanceWindow(instance, time.Now()); err != nil {
+		return UpgradeOutcomeSkipped, err.Error()
+	}
+
+	err := broker.auditor.Audit(ctx, models.UpgradeOperationType, instance.GUID, nil, func() (bool, error) {
+		return false, broker.upgrade(ctx, instance, pollInterval)
+	})
+	if err != nil {
+		return UpgradeOutcomeFailed, err.Error()
+	}
+	return UpgradeOutcomeSucceeded, ""
+}
+
+// upgrade updates the service instance to the maintenance info of its plan, and waits for the update to finish
+func (broker *ServiceBroker) upgrade(ctx context.Context, instance storage.ServiceInstanceDetails, pollInterval time.Duration) error {
+	serviceDefinition, _, err := broker.getDefinitionAndProvider(instance.ServiceGUID)
+	if err != nil {
+		return err
+	}
+
+	plan, err := serviceDefinition.GetPlanByID(instance.PlanGUID)
+	if err != nil {
+		return err
+	}
+	if plan.MaintenanceInfo == nil {
+		return errors.New("plan has no maintenance info, so cannot be upgraded")
+	}
+
+	rawContext, err := json.Marshal(map[string]string{"organization_guid": instance.OrganizationGUID, "space_guid": instance.SpaceGUID})
+	if err != nil {
+		return err
+	}
+
+	spec, err := broker.Update(ctx, instance.GUID, domain.UpdateDetails{
+		ServiceID:       instance.ServiceGUID,
+		PlanID:          instance.PlanGUID,
+		MaintenanceInfo: plan.MaintenanceInfo,
+		RawContext:      rawContext,
+		PreviousValues: domain.PreviousValues{
+			ServiceID: instance.ServiceGUID,
+			PlanID:    instance.PlanGUID,
+			OrgID:     instance.OrganizationGUID,
+			SpaceID:   instance.SpaceGUID,
+		},
+	}, true)
+	if err != nil {
+		return err
+	}
+
+	return broker.waitForOperation(ctx, instance, spec.OperationData, pollInterval)
+}
+
+func (broker *ServiceBroker) waitForOperation(ctx context.Context, instance storage.ServiceInstanceDetails, operationData string, pollInterval time.Duration) error {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		lastOperation, err := broker.LastOperation(ctx, instance.GUID, domain.PollDetails{
+			ServiceID:     instance.ServiceGUID,
+			PlanID:        instance.PlanGUID,
+			OperationData: operationData,
+		})
+		switch {
+		case err != nil:
+			return err
+		case lastOperation.State == domain.Failed:
+			return errors.New(lastOperation.Description)
+		case lastOperation.State == domain.Succeeded:
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
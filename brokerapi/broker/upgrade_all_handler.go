@@ -0,0 +1,118 @@
+package broker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/pivotal-cf/brokerapi/v8/domain"
+	"github.com/pivotal-cf/brokerapi/v8/domain/apiresponses"
+)
+
+const upgradeAllPath = "/admin/upgrade-all"
+
+// UpgradeAllJob is a run of UpgradeAll that was started with the UpgradeAllHandler. The report is set
+// once every upgrade has finished, and the state is failed when any upgrade failed.
+type UpgradeAllJob struct {
+	ID     string                    `json:"id"`
+	State  domain.LastOperationState `json:"state"`
+	Report *UpgradeAllReport         `json:"report,omitempty"`
+	Error  string                    `json:"error,omitempty"`
+}
+
+// upgradeAllJobs holds the jobs started since the broker started. Only one job runs at a time, so
+// that an instance is not upgraded by two jobs at once.
+type upgradeAllJobs struct {
+	lock    sync.Mutex
+	jobs    map[string]*UpgradeAllJob
+	running string
+}
+
+// UpgradeAllHandler serves the endpoints that start and poll UpgradeAll jobs. A POST to /admin/upgrade-all
+// starts a job with the UpgradeAllOptions in the JSON request body, and responds with the job. The job is not
+// tied to the request, so continues when the client disconnects. A GET to /admin/upgrade-all/:job_id responds
+// with the state of the job. The handler does not authenticate requests, so it must be wrapped by the caller.
+func (broker *ServiceBroker) UpgradeAllHandler() http.Handler {
+	router := mux.NewRouter()
+	router.HandleFunc(upgradeAllPath, broker.startUpgradeAll).Methods(http.MethodPost)
+	router.HandleFunc(upgradeAllPath+"/{job_id}", broker.getUpgradeAllJob).Methods(http.MethodGet)
+	return router
+}
+
+func (broker *ServiceBroker) startUpgradeAll(w http.ResponseWriter, req *http.Request) {
+	var opts UpgradeAllOptions
+	if req.ContentLength != 0 {
+		if err := json.NewDecoder(req.Body).Decode(&opts); err != nil {
+			writeJSON(w, http.StatusBadRequest, apiresponses.ErrorResponse{Description: "invalid upgrade options: " + err.Error()})
+			return
+		}
+	}
+
+	jobs := &broker.upgradeAllJobs
+	jobs.lock.Lock()
+	defer jobs.lock.Unlock()
+
+	if jobs.running != "" {
+		writeJSON(w, http.StatusConflict, apiresponses.ErrorResponse{Description: fmt.Sprintf("upgrade-all job %q is already running", jobs.running)})
+		return
+	}
+
+	job := &UpgradeAllJob{ID: uuid.NewString(), State: domain.InProgress}
+	if jobs.jobs == nil {
+		jobs.jobs = make(map[string]*UpgradeAllJob)
+	}
+	jobs.jobs[job.ID] = job
+	jobs.running = job.ID
+	broker.Logger.Info("upgrade-all-job-started", lager.Data{"job_id": job.ID, "options": opts})
+
+	go broker.runUpgradeAll(job.ID, opts)
+
+	w.Header().Set("Location", upgradeAllPath+"/"+job.ID)
+	writeJSON(w, http.StatusAccepted, *job)
+}
+
+func (broker *ServiceBroker) runUpgradeAll(jobID string, opts UpgradeAllOptions) {
+	report, err := broker.UpgradeAll(context.Background(), opts)
+
+	jobs := &broker.upgradeAllJobs
+	jobs.lock.Lock()
+	defer jobs.lock.Unlock()
+
+	job := jobs.jobs[jobID]
+	switch {
+	case err != nil:
+		broker.Logger.Error("upgrade-all", err, lager.Data{"job_id": jobID})
+		job.State = domain.Failed
+		job.Error = err.Error()
+	case report.Failed() > 0:
+		job.State = domain.Failed
+		job.Report = &report
+	default:
+		job.State = domain.Succeeded
+		job.Report = &report
+	}
+	jobs.running = ""
+}
+
+func (broker *ServiceBroker) getUpgradeAllJob(w http.ResponseWriter, req *http.Request) {
+	jobs := &broker.upgradeAllJobs
+	jobs.lock.Lock()
+	job, ok := jobs.jobs[mux.Vars(req)["job_id"]]
+	var current UpgradeAllJob
+	if ok {
+		current = *job
+	}
+	jobs.lock.Unlock()
+
+	if !ok {
+		writeJSON(w, http.StatusNotFound, apiresponses.ErrorResponse{Description: "upgrade-all job does not exist"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, current)
+}
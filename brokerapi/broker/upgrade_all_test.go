@@ -0,0 +1,316 @@
+package broker_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
+	"github.com/pivotal-cf/brokerapi/v8/domain"
+
+	"github.com/cloudfoundry/cloud-service-broker/brokerapi/broker"
+	"github.com/cloudfoundry/cloud-service-broker/brokerapi/broker/brokerfakes"
+	"github.com/cloudfoundry/cloud-service-broker/brokerapi/broker/decider"
+	"github.com/cloudfoundry/cloud-service-broker/internal/storage"
+	pkgBroker "github.com/cloudfoundry/cloud-service-broker/pkg/broker"
+	pkgBrokerFakes "github.com/cloudfoundry/cloud-service-broker/pkg/broker/brokerfakes"
+	"github.com/cloudfoundry/cloud-service-broker/utils"
+)
+
+var _ = Describe("UpgradeAll", func() {
+	const (
+		offeringID = "test-service-id"
+		planID     = "test-plan-id"
+	)
+
+	var (
+		serviceBroker       *broker.ServiceBroker
+		fakeStorage         *brokerfakes.FakeStorage
+		fakeServiceProvider *pkgBrokerFakes.FakeServiceProvider
+		fakeAuditor         *brokerfakes.FakeAuditor
+		instances           map[string]storage.ServiceInstanceDetails
+		behind              map[string]bool
+		checkErrors         map[string]error
+	)
+
+	addInstance := func(guid, window string, isBehind bool) {
+		instances[guid] = storage.ServiceInstanceDetails{
+			GUID:              guid,
+			ServiceGUID:       offeringID,
+			PlanGUID:          planID,
+			SpaceGUID:         "test-space-id",
+			OrganizationGUID:  "test-org-id",
+			MaintenanceWindow: window,
+		}
+		behind[guid] = isBehind
+	}
+
+	BeforeEach(func() {
+		instances = make(map[string]storage.ServiceInstanceDetails)
+		behind = make(map[string]bool)
+		checkErrors = make(map[string]error)
+
+		fakeServiceProvider = &pkgBrokerFakes.FakeServiceProvider{}
+		fakeServiceProvider.CheckUpgradeAvailableStub = func(tfID string) error {
+			guid := strings.Split(tfID, ":")[1]
+			switch {
+			case checkErrors[guid] != nil:
+				return checkErrors[guid]
+			case behind[guid]:
+				return pkgBroker.ErrUpgradeRequired
+			}
+			return nil
+		}
+		fakeServiceProvider.PollInstanceReturns(true, "", nil)
+
+		fakeAuditor = &brokerfakes.FakeAuditor{}
+		fakeAuditor.AuditStub = func(_ context.Context, _, _ string, _ json.RawMessage, run func() (bool, error)) error {
+			_, err := run()
+			return err
+		}
+
+		brokerConfig := &broker.BrokerConfig{
+			Auditor: fakeAuditor,
+			Registry: pkgBroker.BrokerRegistry{
+				"test-service": &pkgBroker.ServiceDefinition{
+					ID:   offeringID,
+					Name: "test-service",
+					Plans: []pkgBroker.ServicePlan{
+						{
+							ServicePlan: domain.ServicePlan{
+								ID:              planID,
+								Name:            "test-plan",
+								MaintenanceInfo: &domain.MaintenanceInfo{Version: "2.0.0"},
+							},
+						},
+					},
+					ProviderBuilder: func(logger lager.Logger, store pkgBroker.ServiceProviderStorage) pkgBroker.ServiceProvider {
+						return fakeServiceProvider
+					},
+				},
+			},
+		}
+
+		fakeStorage = &brokerfakes.FakeStorage{}
+		fakeStorage.ExistsServiceInstanceDetailsReturns(true, nil)
+		fakeStorage.GetServiceInstanceGUIDsStub = func(storage.ServiceInstanceFilter) ([]string, error) {
+			var guids []string
+			for guid := range instances {
+				guids = append(guids, guid)
+			}
+			sort.Strings(guids)
+			return guids, nil
+		}
+		fakeStorage.GetServiceInstanceDetailsStub = func(guid string) (storage.ServiceInstanceDetails, error) {
+			return instances[guid], nil
+		}
+
+		var err error
+		serviceBroker, err = broker.New(brokerConfig, fakeStorage, decider.Decider{}, utils.NewLogger("brokers-test"))
+		Expect(err).ToNot(HaveOccurred())
+
+		addInstance("up-to-date", "", false)
+		addInstance("behind", "", true)
+	})
+
+	It("lists the instances that are behind in a dry run", func() {
+		report, err := serviceBroker.UpgradeAll(context.TODO(), broker.UpgradeAllOptions{DryRun: true})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(report.Results).To(Equal([]broker.UpgradeResult{{
+			InstanceGUID: "behind",
+			ServiceGUID:  offeringID,
+			PlanGUID:     planID,
+			Outcome:      broker.UpgradeOutcomePending,
+			Message:      pkgBroker.ErrUpgradeRequired.Error(),
+		}}))
+		Expect(fakeServiceProvider.UpgradeCallCount()).To(BeZero())
+	})
+
+	It("upgrades the instances that are behind and waits for them", func() {
+		addInstance("also-behind", "", true)
+
+		report, err := serviceBroker.UpgradeAll(context.TODO(), broker.UpgradeAllOptions{Parallelism: 2, PollInterval: time.Millisecond})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(report.Results).To(HaveLen(2))
+		for _, r := range report.Results {
+			Expect(r.Outcome).To(Equal(broker.UpgradeOutcomeSucceeded), r.InstanceGUID)
+		}
+		Expect(report.Failed()).To(BeZero())
+		Expect(fakeServiceProvider.UpgradeCallCount()).To(Equal(2))
+		Expect(fakeServiceProvider.PollInstanceCallCount()).To(Equal(2))
+	})
+
+	It("records an audit event for each upgrade", func() {
+		_, err := serviceBroker.UpgradeAll(context.TODO(), broker.UpgradeAllOptions{PollInterval: time.Millisecond})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(fakeAuditor.AuditCallCount()).To(Equal(1))
+		_, operation, instanceID, _, _ := fakeAuditor.AuditArgsForCall(0)
+		Expect(operation).To(Equal("upgrade"))
+		Expect(instanceID).To(Equal("behind"))
+	})
+
+	It("reports failed upgrades and skips further batches once the failure threshold is reached", func() {
+		addInstance("behind-2", "", true)
+		addInstance("behind-3", "", true)
+		fakeServiceProvider.PollInstanceReturns(true, "", errors.New("terraform apply failed"))
+
+		report, err := serviceBroker.UpgradeAll(context.TODO(), broker.UpgradeAllOptions{BatchSize: 2, FailureThreshold: 2, PollInterval: time.Millisecond})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(report.Results).To(HaveLen(3))
+		Expect(report.Failed()).To(Equal(2))
+		Expect(report.Results[0].Message).To(Equal("terraform apply failed"))
+		Expect(report.Results[2].Outcome).To(Equal(broker.UpgradeOutcomeSkipped))
+		Expect(report.Results[2].Message).To(Equal("not attempted as 2 upgrades failed"))
+		Expect(fakeServiceProvider.UpgradeCallCount()).To(Equal(2))
+	})
+
+	It("reports instances that cannot be checked as failed, and does not upgrade them", func() {
+		addInstance("no-deployment", "", false)
+		checkErrors["no-deployment"] = errors.New("deployment not found")
+
+		report, err := serviceBroker.UpgradeAll(context.TODO(), broker.UpgradeAllOptions{FailureThreshold: 1, PollInterval: time.Millisecond})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(report.Results).To(ConsistOf(
+			MatchFields(IgnoreExtras, Fields{"InstanceGUID": Equal("behind"), "Outcome": Equal(broker.UpgradeOutcomeSucceeded)}),
+			MatchFields(IgnoreExtras, Fields{
+				"InstanceGUID": Equal("no-deployment"),
+				"Outcome":      Equal(broker.UpgradeOutcomeFailed),
+				"Message":      Equal("could not check whether an upgrade is available: deployment not found"),
+			}),
+		))
+		Expect(fakeServiceProvider.UpgradeCallCount()).To(Equal(1))
+		Expect(fakeAuditor.AuditCallCount()).To(Equal(1))
+	})
+
+	It("skips instances outside their maintenance window", func() {
+		day := strings.ToLower(time.Now().UTC().Add(48 * time.Hour).Weekday().String())
+		addInstance("behind", day+" 01:00-02:00", true)
+
+		report, err := serviceBroker.UpgradeAll(context.TODO(), broker.UpgradeAllOptions{PollInterval: time.Millisecond})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(report.Results).To(HaveLen(1))
+		Expect(report.Results[0].Outcome).To(Equal(broker.UpgradeOutcomeSkipped))
+		Expect(report.Results[0].Message).To(ContainSubstring("only allowed during its maintenance window"))
+		Expect(fakeServiceProvider.UpgradeCallCount()).To(BeZero())
+	})
+
+	It("returns an error when instances cannot be listed", func() {
+		fakeStorage.GetServiceInstanceGUIDsReturns(nil, errors.New("boom"))
+		fakeStorage.GetServiceInstanceGUIDsStub = nil
+
+		_, err := serviceBroker.UpgradeAll(context.TODO(), broker.UpgradeAllOptions{})
+		Expect(err).To(MatchError("boom"))
+	})
+
+	Describe("UpgradeAllHandler", func() {
+		serve := func(method, path, body string) *httptest.ResponseRecorder {
+			rec := httptest.NewRecorder()
+			serviceBroker.UpgradeAllHandler().ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+			return rec
+		}
+
+		getJob := func(path string) broker.UpgradeAllJob {
+			rec := serve(http.MethodGet, path, "")
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			var job broker.UpgradeAllJob
+			Expect(json.Unmarshal(rec.Body.Bytes(), &job)).To(Succeed())
+			return job
+		}
+
+		It("starts a job with the options in the request body, which can be polled", func() {
+			rec := serve(http.MethodPost, "/admin/upgrade-all", `{"dry_run":true,"service_id":"test-service-id"}`)
+
+			Expect(rec.Code).To(Equal(http.StatusAccepted))
+			var job broker.UpgradeAllJob
+			Expect(json.Unmarshal(rec.Body.Bytes(), &job)).To(Succeed())
+			Expect(job.ID).NotTo(BeEmpty())
+			Expect(job.State).To(Equal(domain.InProgress))
+			Expect(rec.Header().Get("Location")).To(Equal("/admin/upgrade-all/" + job.ID))
+
+			Eventually(func() domain.LastOperationState {
+				return getJob(rec.Header().Get("Location")).State
+			}).Should(Equal(domain.Succeeded))
+
+			report := getJob(rec.Header().Get("Location")).Report
+			Expect(report.Results).To(HaveLen(1))
+			Expect(report.Results[0].Outcome).To(Equal(broker.UpgradeOutcomePending))
+			Expect(fakeStorage.GetServiceInstanceGUIDsArgsForCall(0)).To(Equal(storage.ServiceInstanceFilter{ServiceGUID: offeringID}))
+			Expect(fakeServiceProvider.UpgradeCallCount()).To(BeZero())
+		})
+
+		It("reports a job with failed upgrades as failed", func() {
+			fakeServiceProvider.PollInstanceReturns(true, "", errors.New("terraform apply failed"))
+
+			rec := serve(http.MethodPost, "/admin/upgrade-all", "")
+			Expect(rec.Code).To(Equal(http.StatusAccepted))
+
+			Eventually(func() domain.LastOperationState {
+				return getJob(rec.Header().Get("Location")).State
+			}).Should(Equal(domain.Failed))
+			Expect(getJob(rec.Header().Get("Location")).Report.Failed()).To(Equal(1))
+		})
+
+		It("does not cancel the job when the request context is cancelled", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			rec := httptest.NewRecorder()
+			serviceBroker.UpgradeAllHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/upgrade-all", nil).WithContext(ctx))
+			cancel()
+
+			Expect(rec.Code).To(Equal(http.StatusAccepted))
+			Eventually(func() domain.LastOperationState {
+				return getJob(rec.Header().Get("Location")).State
+			}).Should(Equal(domain.Succeeded))
+			Expect(fakeServiceProvider.UpgradeCallCount()).To(Equal(1))
+		})
+
+		It("does not start a job while another is running", func() {
+			release := make(chan struct{})
+			fakeServiceProvider.PollInstanceStub = func(context.Context, string) (bool, string, error) {
+				<-release
+				return true, "", nil
+			}
+
+			first := serve(http.MethodPost, "/admin/upgrade-all", "")
+			Expect(first.Code).To(Equal(http.StatusAccepted))
+
+			second := serve(http.MethodPost, "/admin/upgrade-all", "")
+			Expect(second.Code).To(Equal(http.StatusConflict))
+			Expect(second.Body.String()).To(ContainSubstring("is already running"))
+
+			close(release)
+			Eventually(func() domain.LastOperationState {
+				return getJob(first.Header().Get("Location")).State
+			}).Should(Equal(domain.Succeeded))
+		})
+
+		It("returns not found for an unknown job", func() {
+			Expect(serve(http.MethodGet, "/admin/upgrade-all/not-a-job", "").Code).To(Equal(http.StatusNotFound))
+		})
+
+		It("rejects invalid options", func() {
+			rec := serve(http.MethodPost, "/admin/upgrade-all", `{"parallelism":"lots"}`)
+
+			Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		})
+
+		It("only allows POST to start a job", func() {
+			rec := serve(http.MethodGet, "/admin/upgrade-all", "")
+
+			Expect(rec.Code).To(Equal(http.StatusMethodNotAllowed))
+		})
+	})
+})
@@ -97,24 +97,28 @@ func (p TerraformMock) setTFStateFile(state workspace.Tfstate) error {
 }
 
 type TFStateValue struct {
-	Name  string
-	Type  string
-	Value interface{}
+	Name      string
+	Type      string
+	Value     interface{}
+	Sensitive bool
 }
 
 // SetTFState set the Terraform State in a JSON file.
 func (p TerraformMock) SetTFState(values []TFStateValue) error {
 	var outputs = make(map[string]struct {
-		Type  string      `json:"type"`
-		Value interface{} `json:"value"`
+		Type      string      `json:"type"`
+		Value     interface{} `json:"value"`
+		Sensitive bool        `json:"sensitive"`
 	})
 	for _, value := range values {
 		outputs[value.Name] = struct {
-			Type  string      `json:"type"`
-			Value interface{} `json:"value"`
+			Type      string      `json:"type"`
+			Value     interface{} `json:"value"`
+			Sensitive bool        `json:"sensitive"`
 		}{
-			Type:  value.Type,
-			Value: value.Value,
+			Type:      value.Type,
+			Value:     value.Value,
+			Sensitive: value.Sensitive,
 		}
 	}
 
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cloudfoundry/cloud-service-broker/dbservice"
+	"github.com/cloudfoundry/cloud-service-broker/internal/storage"
+	"github.com/cloudfoundry/cloud-service-broker/utils"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	var store *storage.Storage
+
+	auditCmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Inspect the audit log",
+		Long:  `Inspect the audit log of requests that created, changed or deleted service instances and bindings`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			logger := utils.NewLogger("audit")
+			db := dbservice.New(logger)
+			store = storage.New(db, setupDBEncryption(db, logger))
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+	rootCmd.AddCommand(auditCmd)
+
+	var instance, since string
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "show audit events",
+		Run: func(cmd *cobra.Command, args []string) {
+			sinceTime, err := parseSince(since, time.Now())
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			events, err := store.GetAuditEvents(storage.AuditEventFilter{
+				ServiceInstanceGUID: instance,
+				Since:               sinceTime,
+			})
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.StripEscape)
+			fmt.Fprintln(w, "Time\tOperation\tInstance\tBinding\tOrganization\tSpace\tResult\tDuration\tParameters Hash\tOriginating Identity\tMessage")
+			for _, e := range events {
+				fmt.Fprintf(w, "%s\t%s\t%q\t%q\t%s\t%s\t%s\t%s\t%s\t%s\t%q\n",
+					e.Time.Format(time.RFC3339),
+					e.Operation,
+					e.ServiceInstanceGUID,
+					e.ServiceBindingGUID,
+					e.OrganizationGUID,
+					e.SpaceGUID,
+					e.Result,
+					e.Duration.Truncate(time.Millisecond),
+					e.ParametersHash,
+					e.OriginatingIdentity,
+					e.Message,
+				)
+			}
+			w.Flush()
+		},
+	}
+	listCmd.Flags().StringVar(&instance, "instance", "", "only show events for this service instance ID")
+	listCmd.Flags().StringVar(&since, "since", "", "only show events since this RFC3339 time, or this long ago (e.g. 24h)")
+	auditCmd.AddCommand(listCmd)
+}
+
+// parseSince accepts either an RFC3339 timestamp or a duration before now.
+func parseSince(since string, now time.Time) (time.Time, error) {
+	if since == "" {
+		return time.Time{}, nil
+	}
+
+	if d, err := time.ParseDuration(since); err == nil {
+		return now.Add(-d), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, since)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid value for --since %q: must be an RFC3339 time or a duration", since)
+	}
+	return t, nil
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cloudfoundry/cloud-service-broker/dbservice"
+	"github.com/cloudfoundry/cloud-service-broker/internal/storage"
+	"github.com/cloudfoundry/cloud-service-broker/utils"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	encryptionCmd := &cobra.Command{
+		Use:   "encryption",
+		Short: "Inspect database encryption",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+	rootCmd.AddCommand(encryptionCmd)
+
+	encryptionCmd.AddCommand(&cobra.Command{
+		Use:   "rotation-status",
+		Short: "show the progress of re-encrypting the database with a new primary password",
+		Run: func(cmd *cobra.Command, args []string) {
+			logger := utils.NewLogger("encryption")
+			// Progress is not encrypted, so there is no need for an encryptor
+			store := storage.New(dbservice.New(logger), nil)
+
+			rotations, err := store.GetEncryptionRotations()
+			if err != nil {
+				log.Fatal(err)
+			}
+			total, err := store.CountEncryptedRecords()
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.StripEscape)
+			fmt.Fprintln(w, "Previous Primary\tNew Primary\tStarted\tFinished\tRecords\tCurrent Table\tCheckpoint")
+			for _, r := range rotations {
+				finished := ""
+				records := fmt.Sprintf("%d", r.Records)
+				if r.FinishedAt.IsZero() {
+					records = fmt.Sprintf("%d of %d", r.Records, total)
+				} else {
+					finished = r.FinishedAt.Format(time.RFC3339)
+				}
+
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%q\n",
+					labelName(r.FromLabel),
+					labelName(r.ToLabel),
+					r.StartedAt.Format(time.RFC3339),
+					finished,
+					records,
+					r.CurrentTable,
+					r.Checkpoint,
+				)
+			}
+			w.Flush()
+		},
+	})
+}
@@ -19,6 +19,7 @@ import (
 	"database/sql"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/cloudfoundry/cloud-service-broker/brokerapi/broker/decider"
 
@@ -28,6 +29,8 @@ import (
 	"github.com/cloudfoundry/cloud-service-broker/internal/encryption"
 	"github.com/cloudfoundry/cloud-service-broker/internal/infohandler"
 	"github.com/cloudfoundry/cloud-service-broker/internal/storage"
+	"github.com/cloudfoundry/cloud-service-broker/internal/tfstatebackend"
+	"github.com/cloudfoundry/cloud-service-broker/internal/webhook"
 	pakBroker "github.com/cloudfoundry/cloud-service-broker/pkg/broker"
 	"github.com/cloudfoundry/cloud-service-broker/pkg/brokerpak"
 	"github.com/cloudfoundry/cloud-service-broker/pkg/server"
@@ -35,7 +38,7 @@ import (
 	"github.com/cloudfoundry/cloud-service-broker/utils"
 	"github.com/gorilla/mux"
 	"github.com/pivotal-cf/brokerapi/v8"
-	"github.com/pivotal-cf/brokerapi/v8/domain"
+	"github.com/pivotal-cf/brokerapi/v8/auth"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"gorm.io/gorm"
@@ -48,6 +51,9 @@ const (
 	apiHostProp         = "api.host"
 	encryptionPasswords = "db.encryption.passwords"
 	encryptionEnabled   = "db.encryption.enabled"
+	auditRetentionProp  = "audit.retention_days"
+
+	webhookDeliveryInterval = 10 * time.Second
 )
 
 var cfCompatibilityToggle = toggles.Features.Toggle("enable-cf-sharing", false, `Set all services to have the Sharable flag so they can be shared
@@ -78,24 +84,45 @@ func init() {
 	viper.BindEnv(apiHostProp, "CSB_LISTENER_HOST")
 	viper.BindEnv(encryptionPasswords, "ENCRYPTION_PASSWORDS")
 	viper.BindEnv(encryptionEnabled, "ENCRYPTION_ENABLED")
+	viper.BindEnv(auditRetentionProp, "CSB_AUDIT_RETENTION_DAYS")
 }
 
 func serve() {
 	logger := utils.NewLogger("cloud-service-broker")
 	logger.Info("starting", lager.Data{"version": utils.Version})
 	db := dbservice.New(logger)
-	encryptor := setupDBEncryption(db, logger)
+
+	var encryptor storage.Encryptor
+	config := parseDBEncryption(db, logger)
+	if config.Changed {
+		encryptor = config.RotationEncryptor
+		go rotateDBEncryption(db, config, logger)
+	} else {
+		encryptor = checkDBEncryption(db, config, logger)
+	}
 
 	// init broker
 	cfg, err := osbapiBroker.NewBrokerConfigFromEnv(logger)
 	if err != nil {
 		logger.Fatal("Error initializing service broker config", err)
 	}
-	var serviceBroker domain.ServiceBroker
-	serviceBroker, err = osbapiBroker.New(cfg, storage.New(db, encryptor), decider.Decider{}, logger)
+	store := storage.New(db, encryptor)
+	auditor := server.NewAuditor(store, logger)
+	cfg.Auditor = auditor
+	csb, err := osbapiBroker.New(cfg, store, decider.Decider{}, logger)
 	if err != nil {
 		logger.Fatal("Error initializing service broker", err)
 	}
+	serviceBroker := server.NewAuditWrapper(csb, auditor)
+	go pruneAuditEvents(store, logger)
+
+	webhooks, err := webhook.ParseTargets()
+	if err != nil {
+		logger.Fatal("Error parsing webhook configuration", err)
+	}
+	if len(webhooks) > 0 {
+		go webhook.NewDispatcher(store, webhooks, logger).Run(context.Background(), webhookDeliveryInterval)
+	}
 
 	credentials := brokerapi.BrokerCredentials{
 		Username: viper.GetString(apiUserProp),
@@ -119,7 +146,11 @@ func serve() {
 	if err != nil {
 		logger.Error("failed to get database connection", err)
 	}
-	startServer(cfg.Registry, sqldb, brokerAPI)
+	authWrapper := auth.NewWrapper(credentials.Username, credentials.Password)
+	upgradeAll := authWrapper.Wrap(csb.UpgradeAllHandler())
+	extensions := authWrapper.Wrap(csb.ExtensionsHandler())
+	tfState := tfstatebackend.NewHandler(store, logger)
+	startServer(cfg.Registry, sqldb, brokerAPI, upgradeAll, extensions, tfState)
 }
 
 func serveDocs() {
@@ -130,30 +161,32 @@ func serveDocs() {
 		logger.Error("loading brokerpaks", err)
 	}
 
-	startServer(registry, nil, nil)
+	startServer(registry, nil, nil, nil, nil, nil)
 }
 
+// setupDBEncryption returns the encryptor for the database. While a rotation to a new primary
+// password is pending, this is the rotation encryptor, which can decrypt with either password.
 func setupDBEncryption(db *gorm.DB, logger lager.Logger) storage.Encryptor {
+	config := parseDBEncryption(db, logger)
+	if config.Changed {
+		logger.Info("database-encryption-rotation-pending", lager.Data{"previous-primary": labelName(config.StoredPrimaryLabel), "new-primary": labelName(config.ConfiguredPrimaryLabel)})
+		return config.RotationEncryptor
+	}
+
+	return checkDBEncryption(db, config, logger)
+}
+
+func parseDBEncryption(db *gorm.DB, logger lager.Logger) encryption.Configuration {
 	config, err := encryption.ParseConfiguration(db, viper.GetBool(encryptionEnabled), viper.GetString(encryptionPasswords))
 	if err != nil {
 		logger.Fatal("Error parsing encryption configuration", err)
 	}
 
-	if config.Changed {
-		if err := storage.New(db, config.RotationEncryptor).CheckAllRecords(); err != nil {
-			logger.Fatal("refusing to encrypt the database as some fields cannot be successfully read", err)
-		}
-
-		logger.Info("rotating-database-encryption", lager.Data{"previous-primary": labelName(config.StoredPrimaryLabel), "new-primary": labelName(config.ConfiguredPrimaryLabel)})
-		if err := storage.New(db, config.RotationEncryptor).UpdateAllRecords(); err != nil {
-			logger.Fatal("Error rotating database encryption", err)
-		}
-		if err := encryption.UpdatePasswordMetadata(db, config.ConfiguredPrimaryLabel); err != nil {
-			logger.Fatal("Error updating password metadata", err)
-		}
-	}
+	return config
+}
 
-	err = storage.New(db, config.Encryptor).CheckAllRecords()
+func checkDBEncryption(db *gorm.DB, config encryption.Configuration, logger lager.Logger) storage.Encryptor {
+	counts, err := storage.New(db, config.Encryptor).CheckAllRecords()
 	switch {
 	case err != nil:
 		// This error denotes that there was a problem reading at least one database field.
@@ -170,19 +203,57 @@ func setupDBEncryption(db *gorm.DB, logger lager.Logger) storage.Encryptor {
 		}
 	}
 
-	logger.Info("database-encryption", lager.Data{"primary": labelName(config.ConfiguredPrimaryLabel)})
+	logger.Info("database-encryption", lager.Data{"primary": labelName(config.ConfiguredPrimaryLabel), "records": recordsByLabel(counts)})
 	return config.Encryptor
 }
 
-func startServer(registry pakBroker.BrokerRegistry, db *sql.DB, brokerapi http.Handler) {
+// recordsByLabel names the records that were not encrypted with a labelled password, which were
+// either not encrypted or were encrypted before key labels were added
+func recordsByLabel(counts storage.KeyCounts) map[string]int {
+	result := make(map[string]int, len(counts))
+	for label, count := range counts {
+		if label == "" {
+			label = "unlabelled"
+		}
+		result[label] = count
+	}
+	return result
+}
+
+// rotateDBEncryption re-encrypts the database with the new primary password while the broker serves
+// requests. If it fails, or the broker stops, the rotation resumes when the broker next starts.
+func rotateDBEncryption(db *gorm.DB, config encryption.Configuration, logger lager.Logger) {
+	data := lager.Data{"previous-primary": labelName(config.StoredPrimaryLabel), "new-primary": labelName(config.ConfiguredPrimaryLabel)}
+	logger.Info("rotating-database-encryption", data)
+
+	if err := encryption.Rotate(db, config); err != nil {
+		logger.Error("database-encryption-rotation-failed", err, data)
+		return
+	}
+
+	logger.Info("database-encryption-rotated", data)
+}
+
+func startServer(registry pakBroker.BrokerRegistry, db *sql.DB, brokerapi, upgradeAll, extensions, tfState http.Handler) {
 	logger := utils.NewLogger("cloud-service-broker")
 
 	router := mux.NewRouter()
 
+	// the extensions endpoints are below the brokerapi paths, so must be matched before them
+	if extensions != nil {
+		router.PathPrefix("/v2/service_instances/{instance_id}/extensions").Handler(extensions)
+	}
+
 	// match paths going to the brokerapi first
 	if brokerapi != nil {
 		router.PathPrefix("/v2").Handler(brokerapi)
 	}
+	if upgradeAll != nil {
+		router.PathPrefix("/admin/upgrade-all").Handler(upgradeAll)
+	}
+	if tfState != nil {
+		router.PathPrefix(tfstatebackend.PathPrefix).Handler(tfState)
+	}
 
 	server.AddDocsHandler(router, registry)
 	router.HandleFunc("/examples", server.NewExampleHandler(registry))
@@ -193,6 +264,28 @@ func startServer(registry pakBroker.BrokerRegistry, db *sql.DB, brokerapi http.H
 	host := viper.GetString(apiHostProp)
 	logger.Info("Serving", lager.Data{"port": port})
 	http.ListenAndServe(fmt.Sprintf("%s:%s", host, port), router)
+	tfstatebackend.Stop()
+}
+
+// pruneAuditEvents deletes audit events older than the configured retention period once a day.
+// When no retention period is configured, audit events are kept forever.
+func pruneAuditEvents(store *storage.Storage, logger lager.Logger) {
+	retentionDays := viper.GetInt(auditRetentionProp)
+	if retentionDays <= 0 {
+		return
+	}
+	retention := time.Duration(retentionDays) * 24 * time.Hour
+
+	for {
+		deleted, err := store.DeleteAuditEventsBefore(time.Now().Add(-retention))
+		switch err {
+		case nil:
+			logger.Info("pruned-audit-events", lager.Data{"deleted": deleted, "retention-days": retentionDays})
+		default:
+			logger.Error("prune-audit-events", err)
+		}
+		time.Sleep(24 * time.Hour)
+	}
 }
 
 func labelName(label string) string {
@@ -15,21 +15,29 @@
 package cmd
 
 import (
+	"bufio"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"strings"
 	"text/tabwriter"
 	"time"
 
+	osbapiBroker "github.com/cloudfoundry/cloud-service-broker/brokerapi/broker"
+	"github.com/cloudfoundry/cloud-service-broker/brokerapi/broker/decider"
 	"github.com/cloudfoundry/cloud-service-broker/dbservice"
 	"github.com/cloudfoundry/cloud-service-broker/pkg/providers/tf/executor"
 
 	"github.com/cloudfoundry/cloud-service-broker/internal/storage"
 	"github.com/cloudfoundry/cloud-service-broker/pkg/providers/tf/invoker"
+	"github.com/cloudfoundry/cloud-service-broker/pkg/providers/tf/workspace"
 
 	"github.com/cloudfoundry/cloud-service-broker/dbservice/models"
 	"github.com/cloudfoundry/cloud-service-broker/pkg/providers/tf"
+	"github.com/cloudfoundry/cloud-service-broker/pkg/server"
 	"github.com/cloudfoundry/cloud-service-broker/utils"
 	"github.com/spf13/cobra"
 	"gorm.io/gorm"
@@ -50,10 +58,10 @@ func init() {
 			store := storage.New(db, encryptor)
 			terraformProvider = tf.NewTerraformProvider(
 				executor.TFBinariesContext{},
-				invoker.NewTerraformInvokerFactory(executor.NewExecutorFactory("", nil, nil), "", map[string]string{}),
+				invoker.NewTerraformInvokerFactory(executor.NewExecutorFactory(executor.TFBinariesContext{}, nil, executor.RetryPolicy{}), executor.TFBinariesContext{}),
 				logger,
 				tf.TfServiceDefinitionV1{},
-				tf.NewDeploymentManager(store),
+				tf.NewDeploymentManager(store, ""),
 			)
 			return nil
 		},
@@ -83,16 +91,95 @@ func init() {
 				log.Fatal(err)
 			}
 
-			if onlyState {
+			showSensitive, err := cmd.Flags().GetBool("show-sensitive")
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			switch {
+			case onlyState && showSensitive:
 				fmt.Printf("%s", string(ws.State))
-			} else {
-				fmt.Println(ws)
+			case onlyState:
+				state, err := workspace.RedactState(ws.State)
+				if err != nil {
+					log.Fatal(err)
+				}
+				fmt.Printf("%s", string(state))
+			default:
+				fmt.Println(ws.Describe(showSensitive))
 			}
 		},
 	}
 	dumpCmd.Flags().BoolP("only-state", "s", false, "dump the tf state file")
+	dumpCmd.Flags().Bool("show-sensitive", false, "show the values of sensitive outputs")
 	tfCmd.AddCommand(dumpCmd)
 
+	var adoptOpts osbapiBroker.AdoptOptions
+	var adoptImports []string
+	var adoptParams string
+	var adoptYes bool
+	adoptCmd := &cobra.Command{
+		Use:   "adopt",
+		Short: "bring existing resources under broker management",
+		Long: `Imports existing resources into a new Terraform workspace built from the provision
+template of a service, and shows the changes that Terraform would make to them. Once
+the changes are confirmed, they are applied and the service instance is registered
+in the database.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			logger := utils.NewLogger("adopt")
+
+			resources, err := parseAdoptImports(adoptImports)
+			if err != nil {
+				log.Fatal(err)
+			}
+			adoptOpts.Resources = resources
+
+			if adoptParams != "" {
+				if err := json.Unmarshal([]byte(adoptParams), &adoptOpts.Params); err != nil {
+					log.Fatalf("invalid parameters: %s", err)
+				}
+			}
+
+			cfg, err := osbapiBroker.NewBrokerConfigFromEnv(logger)
+			if err != nil {
+				log.Fatal(err)
+			}
+			store := storage.New(db, setupDBEncryption(db, logger))
+			cfg.Auditor = server.NewAuditor(store, logger)
+
+			csb, err := osbapiBroker.New(cfg, store, decider.Decider{}, logger)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			adopted, err := csb.Adopt(context.Background(), adoptOpts, func(plan string) bool {
+				fmt.Println(plan)
+				return adoptYes || confirmAdoption(os.Stdin)
+			})
+			switch {
+			case err != nil:
+				log.Fatal(err)
+			case !adopted:
+				fmt.Println("Adoption cancelled.")
+			default:
+				fmt.Printf("Service instance %q has been registered.\n", adoptOpts.InstanceGUID)
+			}
+		},
+	}
+	adoptCmd.Flags().StringVar(&adoptOpts.Service, "service", "", "name or ID of the service offering")
+	adoptCmd.Flags().StringVar(&adoptOpts.Plan, "plan", "", "name or ID of the plan")
+	adoptCmd.Flags().StringVar(&adoptOpts.InstanceGUID, "instance-id", "", "ID of the new service instance")
+	adoptCmd.Flags().StringVar(&adoptOpts.OrganizationGUID, "organization-id", "", "ID of the organization of the service instance")
+	adoptCmd.Flags().StringVar(&adoptOpts.SpaceGUID, "space-id", "", "ID of the space of the service instance")
+	adoptCmd.Flags().StringVarP(&adoptParams, "params", "c", "", "provision parameters as a JSON object")
+	adoptCmd.Flags().StringArrayVar(&adoptImports, "import", nil, "resource to import, as <terraform address>=<resource ID> (repeatable)")
+	adoptCmd.Flags().BoolVarP(&adoptYes, "yes", "y", false, "apply the changes without asking for confirmation")
+	adoptCmd.MarkFlagRequired("service")
+	adoptCmd.MarkFlagRequired("plan")
+	adoptCmd.MarkFlagRequired("instance-id")
+	adoptCmd.MarkFlagRequired("import")
+	tfCmd.AddCommand(adoptCmd)
+
 	tfCmd.AddCommand(&cobra.Command{
 		Use:   "wait",
 		Short: "wait for a Terraform job",
@@ -104,6 +191,40 @@ func init() {
 		},
 	})
 
+	tfCmd.AddCommand(&cobra.Command{
+		Use:   "history <deployment-id>",
+		Short: "show the history of operations on a Terraform workspace",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			logger := utils.NewLogger("cloud-service-broker")
+			store := storage.New(db, setupDBEncryption(db, logger))
+			operations, err := store.GetOperations(storage.OperationFilter{DeploymentID: args[0]})
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.StripEscape)
+			fmt.Fprintln(w, "Operation\tStarted\tFinished\tState\tTerraform Version\tBrokerpak Version\tMessage")
+			for _, o := range operations {
+				finished := ""
+				if !o.FinishedAt.IsZero() {
+					finished = o.FinishedAt.Format(time.RFC3339)
+				}
+
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%q\n",
+					o.Type,
+					o.StartedAt.Format(time.RFC3339),
+					finished,
+					o.State,
+					o.TerraformVersion,
+					o.BrokerpakVersion,
+					o.Message,
+				)
+			}
+			w.Flush()
+		},
+	})
+
 	tfCmd.AddCommand(&cobra.Command{
 		Use:   "list",
 		Short: "show the list of Terraform workspaces",
@@ -130,3 +251,23 @@ func init() {
 		},
 	})
 }
+
+// parseAdoptImports parses the resources given as <terraform address>=<resource ID>
+func parseAdoptImports(imports []string) (map[string]string, error) {
+	resources := make(map[string]string)
+	for _, i := range imports {
+		address, id, ok := strings.Cut(i, "=")
+		if !ok || address == "" || id == "" {
+			return nil, fmt.Errorf("invalid import %q, expected <terraform address>=<resource ID>", i)
+		}
+		resources[address] = id
+	}
+	return resources, nil
+}
+
+// confirmAdoption asks whether the planned changes should be made. Like Terraform, only "yes" is accepted.
+func confirmAdoption(in io.Reader) bool {
+	fmt.Print("Do you want to apply these changes and register the service instance? Only 'yes' will be accepted: ")
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	return strings.TrimSpace(answer) == "yes"
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	osbapiBroker "github.com/cloudfoundry/cloud-service-broker/brokerapi/broker"
+	"github.com/cloudfoundry/cloud-service-broker/brokerapi/broker/decider"
+	"github.com/cloudfoundry/cloud-service-broker/dbservice"
+	"github.com/cloudfoundry/cloud-service-broker/internal/storage"
+	"github.com/cloudfoundry/cloud-service-broker/pkg/server"
+	"github.com/cloudfoundry/cloud-service-broker/utils"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	var opts osbapiBroker.UpgradeAllOptions
+
+	upgradeAllCmd := &cobra.Command{
+		Use:   "upgrade-all",
+		Short: "Upgrade all service instances that are behind",
+		Long: `Upgrades every service instance whose Terraform state is older than the current
+Terraform version, optionally restricted to a service or plan. Each upgrade is
+waited for, and a report of the outcome for each instance is printed.
+
+The command uses the same configuration as the broker, and the same upgrade can be
+started on a running broker with a POST request to /admin/upgrade-all.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			logger := utils.NewLogger("upgrade-all")
+			db := dbservice.New(logger)
+			store := storage.New(db, setupDBEncryption(db, logger))
+
+			cfg, err := osbapiBroker.NewBrokerConfigFromEnv(logger)
+			if err != nil {
+				log.Fatal(err)
+			}
+			cfg.Auditor = server.NewAuditor(store, logger)
+
+			csb, err := osbapiBroker.New(cfg, store, decider.Decider{}, logger)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			report, err := csb.UpgradeAll(context.Background(), opts)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.StripEscape)
+			fmt.Fprintln(w, "Instance\tService\tPlan\tOutcome\tMessage")
+			for _, r := range report.Results {
+				fmt.Fprintf(w, "%q\t%s\t%s\t%s\t%q\n", r.InstanceGUID, r.ServiceGUID, r.PlanGUID, r.Outcome, r.Message)
+			}
+			w.Flush()
+
+			if report.Failed() > 0 {
+				os.Exit(1)
+			}
+		},
+	}
+
+	upgradeAllCmd.Flags().StringVar(&opts.ServiceGUID, "service-id", "", "only upgrade instances of this service")
+	upgradeAllCmd.Flags().StringVar(&opts.PlanGUID, "plan-id", "", "only upgrade instances of this plan")
+	upgradeAllCmd.Flags().IntVar(&opts.Parallelism, "parallelism", 1, "number of instances to upgrade at the same time")
+	upgradeAllCmd.Flags().IntVar(&opts.BatchSize, "batch-size", 0, "number of instances in each batch (default: the parallelism)")
+	upgradeAllCmd.Flags().IntVar(&opts.FailureThreshold, "failure-threshold", 0, "stop starting batches once this many upgrades have failed (default: never stop)")
+	upgradeAllCmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "list the instances that would be upgraded without upgrading them")
+	rootCmd.AddCommand(upgradeAllCmd)
+}
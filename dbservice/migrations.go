@@ -22,7 +22,7 @@ import (
 	"gorm.io/gorm"
 )
 
-const numMigrations = 16
+const numMigrations = 26
 
 // RunMigrations runs schema migrations on the provided service broker database to get it up to date
 func RunMigrations(db *gorm.DB) error {
@@ -127,6 +127,46 @@ func RunMigrations(db *gorm.DB) error {
 		return autoMigrateTables(db, &models.BindRequestDetailsV1{})
 	}
 
+	migrations[16] = func() error {
+		return autoMigrateTables(db, &models.AuditEventV1{})
+	}
+
+	migrations[17] = func() error {
+		return autoMigrateTables(db, &models.WebhookDeliveryV1{})
+	}
+
+	migrations[18] = func() error {
+		return autoMigrateTables(db, &models.ServiceInstanceDetailsV4{})
+	}
+
+	migrations[19] = func() error {
+		return autoMigrateTables(db, &models.TerraformStateV1{})
+	}
+
+	migrations[20] = func() error {
+		return autoMigrateTables(db, &models.ServiceInstanceDetailsV5{}, &models.TerraformDeploymentV4{})
+	}
+
+	migrations[21] = func() error {
+		return autoMigrateTables(db, &models.OperationV1{})
+	}
+
+	migrations[22] = func() error {
+		return autoMigrateTables(db, &models.EncryptionRotationV1{})
+	}
+
+	migrations[23] = func() error {
+		return autoMigrateTables(db, &models.PasswordMetadataV2{})
+	}
+
+	migrations[24] = func() error {
+		return autoMigrateTables(db, &models.BindRequestDetailsV2{})
+	}
+
+	migrations[25] = func() error {
+		return autoMigrateTables(db, &models.BindRequestDetailsV3{})
+	}
+
 	var lastMigrationNumber = -1
 
 	// if we've run any migrations before, we should have a migrations table, so find the last one we ran
@@ -25,6 +25,8 @@ const (
 	UpgradeOperationType     = "upgrade"
 	BindOperationType        = "bind"
 	UnbindOperationType      = "unbind"
+	ActionOperationType      = "action"
+	AdoptOperationType       = "adopt"
 	ClearOperationType       = ""
 )
 
@@ -33,7 +35,7 @@ const (
 type ServiceBindingCredentials ServiceBindingCredentialsV2
 
 // ServiceInstanceDetails holds information about provisioned services.
-type ServiceInstanceDetails ServiceInstanceDetailsV3
+type ServiceInstanceDetails ServiceInstanceDetailsV5
 
 // ProvisionRequestDetails holds user-defined properties passed to a call
 // to provision a service.
@@ -41,7 +43,7 @@ type ProvisionRequestDetails ProvisionRequestDetailsV3
 
 // BindRequestDetails holds user-defined properties passed to a call
 // to provision a service.
-type BindRequestDetails BindRequestDetailsV1
+type BindRequestDetails BindRequestDetailsV3
 
 // Migration represents the mgirations table. It holds a monotonically
 // increasing number that gets incremented with every database schema revision.
@@ -53,8 +55,25 @@ type CloudOperation CloudOperationV1
 
 // TerraformDeployment holds Terraform state and plan information for resources
 // that use that execution system.
-type TerraformDeployment TerraformDeploymentV3
+type TerraformDeployment TerraformDeploymentV4
 
 // PasswordMetadata contains information about the passwords, but never the
 // passwords themselves
-type PasswordMetadata PasswordMetadataV1
+type PasswordMetadata PasswordMetadataV2
+
+// AuditEvent records a request that created, changed or deleted a service
+// instance or service binding.
+type AuditEvent AuditEventV1
+
+// WebhookDelivery is an outbound webhook request waiting to be delivered.
+type WebhookDelivery WebhookDeliveryV1
+
+// TerraformState holds the Terraform state written through the broker-hosted
+// HTTP backend, and the lock that Terraform holds on it.
+type TerraformState TerraformStateV1
+
+// Operation records an operation on a Terraform deployment
+type Operation OperationV1
+
+// EncryptionRotation records the progress of re-encrypting the database with a new primary password
+type EncryptionRotation EncryptionRotationV1
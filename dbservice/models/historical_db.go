@@ -162,6 +162,89 @@ func (ServiceInstanceDetailsV3) TableName() string {
 	return "service_instance_details"
 }
 
+// ServiceInstanceDetailsV4 adds the maintenance window during which the instance may be upgraded
+type ServiceInstanceDetailsV4 struct {
+	ID        string `gorm:"primary_key;type:varchar(255);not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt *time.Time
+
+	Name         string
+	Location     string
+	URL          string
+	OtherDetails []byte `gorm:"type:blob"`
+
+	ServiceID        string
+	PlanID           string
+	SpaceGUID        string
+	OrganizationGUID string
+
+	// OperationType holds a string corresponding to what kind of operation
+	// OperationID is referencing. The object is "locked" for editing if
+	// an operation is pending.
+	OperationType string
+
+	// OperationID holds a string referencing an operation specific to a broker.
+	// Operations in GCP all have a unique ID.
+	// The OperationID will be cleared after a successful operation.
+	// This string MAY be sent to users and MUST NOT leak confidential information.
+	OperationID string `gorm:"type:varchar(1024)"`
+
+	// MaintenanceWindow is the weekly period in UTC when the instance may be upgraded,
+	// for example "sunday 02:00-04:00". It is empty when upgrades are always allowed.
+	MaintenanceWindow string
+}
+
+// TableName returns a consistent table name for
+// gorm so multiple structs from different versions of the database all operate
+// on the same table.
+func (ServiceInstanceDetailsV4) TableName() string {
+	return "service_instance_details"
+}
+
+// ServiceInstanceDetailsV5 adds a version, which is incremented every time the details are stored,
+// so that a write can be made conditional on the details not having changed since they were read
+type ServiceInstanceDetailsV5 struct {
+	ID        string `gorm:"primary_key;type:varchar(255);not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt *time.Time
+
+	Name         string
+	Location     string
+	URL          string
+	OtherDetails []byte `gorm:"type:blob"`
+
+	ServiceID        string
+	PlanID           string
+	SpaceGUID        string
+	OrganizationGUID string
+
+	// OperationType holds a string corresponding to what kind of operation
+	// OperationID is referencing. The object is "locked" for editing if
+	// an operation is pending.
+	OperationType string
+
+	// OperationID holds a string referencing an operation specific to a broker.
+	// Operations in GCP all have a unique ID.
+	// The OperationID will be cleared after a successful operation.
+	// This string MAY be sent to users and MUST NOT leak confidential information.
+	OperationID string `gorm:"type:varchar(1024)"`
+
+	// MaintenanceWindow is the weekly period in UTC when the instance may be upgraded,
+	// for example "sunday 02:00-04:00". It is empty when upgrades are always allowed.
+	MaintenanceWindow string
+
+	Version int `gorm:"not null;default:0"`
+}
+
+// TableName returns a consistent table name for
+// gorm so multiple structs from different versions of the database all operate
+// on the same table.
+func (ServiceInstanceDetailsV5) TableName() string {
+	return "service_instance_details"
+}
+
 // ProvisionRequestDetailsV1 holds user-defined properties passed to a call
 // to provision a service.
 type ProvisionRequestDetailsV1 struct {
@@ -234,6 +317,56 @@ func (BindRequestDetailsV1) TableName() string {
 	return "bind_request_details"
 }
 
+// BindRequestDetailsV2 adds the platform context of the bind request, which tells a credential
+// store such as Kubernetes where the binding credentials were stored.
+type BindRequestDetailsV2 struct {
+	gorm.Model
+
+	ServiceBindingID  string `gorm:"unique"`
+	ServiceInstanceID string
+
+	// is a json.Marshal of models.BindDetails
+	RequestDetails []byte `gorm:"type:blob"`
+
+	// is a json.Marshal of the context of the bind request
+	RequestContext []byte `gorm:"type:blob"`
+}
+
+// TableName returns a consistent table name for
+// gorm so multiple structs from different versions of the database all operate
+// on the same table.
+func (BindRequestDetailsV2) TableName() string {
+	return "bind_request_details"
+}
+
+// BindRequestDetailsV3 adds the plan, app and bind resource of the bind request, so that a repeated
+// bind request can be compared with the original.
+type BindRequestDetailsV3 struct {
+	gorm.Model
+
+	ServiceBindingID  string `gorm:"unique"`
+	ServiceInstanceID string
+
+	// is a json.Marshal of models.BindDetails
+	RequestDetails []byte `gorm:"type:blob"`
+
+	// is a json.Marshal of the context of the bind request
+	RequestContext []byte `gorm:"type:blob"`
+
+	PlanID  string
+	AppGUID string
+
+	// is a json.Marshal of the bind_resource of the bind request
+	BindResource []byte `gorm:"type:blob"`
+}
+
+// TableName returns a consistent table name for
+// gorm so multiple structs from different versions of the database all operate
+// on the same table.
+func (BindRequestDetailsV3) TableName() string {
+	return "bind_request_details"
+}
+
 // MigrationV1 represents the mgirations table. It holds a monotonically
 // increasing number that gets incremented with every database schema revision.
 type MigrationV1 struct {
@@ -382,6 +515,37 @@ func (TerraformDeploymentV3) TableName() string {
 	return "terraform_deployments"
 }
 
+// TerraformDeploymentV4 adds a version, which is incremented every time the deployment is stored,
+// so that a write can be made conditional on the deployment not having changed since it was read
+type TerraformDeploymentV4 struct {
+	ID        string `gorm:"primary_key;type:varchar(1024)"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt *time.Time
+
+	// Workspace contains a JSON serialized version of the Terraform workspace.
+	Workspace []byte `gorm:"type:mediumblob"`
+
+	// LastOperationType describes the last operation being performed on the resource.
+	LastOperationType string
+
+	// LastOperationState holds one of the following strings "in progress", "succeeded", "failed".
+	// These mirror the OSB API.
+	LastOperationState string
+
+	// LastOperationMessage is a description that can be passed back to the user.
+	LastOperationMessage string `gorm:"type:text"`
+
+	Version int `gorm:"not null;default:0"`
+}
+
+// TableName returns a consistent table name for
+// gorm so multiple structs from different versions of the database all operate
+// on the same table.
+func (TerraformDeploymentV4) TableName() string {
+	return "terraform_deployments"
+}
+
 // PasswordMetadataV1 contains information about the passwords, but never the
 // passwords themselves
 type PasswordMetadataV1 struct {
@@ -398,3 +562,132 @@ type PasswordMetadataV1 struct {
 func (PasswordMetadataV1) TableName() string {
 	return "password_metadata"
 }
+
+// PasswordMetadataV2 adds the key derivation function that is used to derive
+// the key from the password. It is empty for passwords that use PBKDF2.
+type PasswordMetadataV2 struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	Label   string `gorm:"index;unique;not null"`
+	Salt    []byte `gorm:"type:blob;not null"`
+	Canary  []byte `gorm:"type:blob;not null"`
+	Primary bool
+	KDF     string `gorm:"type:varchar(1024);not null;default:''"`
+}
+
+func (PasswordMetadataV2) TableName() string {
+	return "password_metadata"
+}
+
+// AuditEventV1 records a request that created, changed or deleted a service
+// instance or service binding.
+type AuditEventV1 struct {
+	ID        uint      `gorm:"primarykey"`
+	CreatedAt time.Time `gorm:"index"`
+
+	Operation           string
+	ServiceInstanceID   string `gorm:"index"`
+	ServiceBindingID    string
+	OriginatingIdentity string `gorm:"type:text"`
+	OrganizationGUID    string
+	SpaceGUID           string
+	ParametersHash      string
+	Result              string
+	Message             string `gorm:"type:text"`
+	Duration            time.Duration
+}
+
+// TableName returns a consistent table name for
+// gorm so multiple structs from different versions of the database all operate
+// on the same table.
+func (AuditEventV1) TableName() string {
+	return "audit_events"
+}
+
+// WebhookDeliveryV1 is an outbound webhook request waiting to be delivered.
+type WebhookDeliveryV1 struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	URL           string `gorm:"type:text"`
+	Payload       []byte `gorm:"type:blob"`
+	Attempts      int
+	NextAttemptAt time.Time `gorm:"index"`
+	LastError     string    `gorm:"type:text"`
+}
+
+// TableName returns a consistent table name for
+// gorm so multiple structs from different versions of the database all operate
+// on the same table.
+func (WebhookDeliveryV1) TableName() string {
+	return "webhook_deliveries"
+}
+
+// TerraformStateV1 holds the Terraform state written through the broker-hosted
+// HTTP backend while an operation is running, and the lock that Terraform holds on it.
+type TerraformStateV1 struct {
+	ID        string `gorm:"primary_key;type:varchar(1024)"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	State    []byte `gorm:"type:mediumblob"`
+	LockID   string `gorm:"type:varchar(255)"`
+	LockInfo []byte `gorm:"type:blob"`
+}
+
+// TableName returns a consistent table name for
+// gorm so multiple structs from different versions of the database all operate
+// on the same table.
+func (TerraformStateV1) TableName() string {
+	return "terraform_states"
+}
+
+// OperationV1 records an operation on a Terraform deployment. Rows are only ever added,
+// and completed when the operation finishes, so that the history of a service instance is kept.
+type OperationV1 struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	DeploymentID      string `gorm:"index;type:varchar(1024)"`
+	ServiceInstanceID string `gorm:"index"`
+	OperationType     string
+	StartedAt         time.Time
+	FinishedAt        *time.Time
+	TerraformVersion  string
+	BrokerpakVersion  string
+	State             string
+	Message           string `gorm:"type:text"`
+}
+
+// TableName returns a consistent table name for
+// gorm so multiple structs from different versions of the database all operate
+// on the same table.
+func (OperationV1) TableName() string {
+	return "operations"
+}
+
+// EncryptionRotationV1 records the progress of re-encrypting the database with a new primary
+// password, so that an interrupted rotation can resume from the last record it re-encrypted.
+type EncryptionRotationV1 struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	FromLabel    string `gorm:"uniqueIndex:idx_encryption_rotations_labels;type:varchar(255);not null"`
+	ToLabel      string `gorm:"uniqueIndex:idx_encryption_rotations_labels;type:varchar(255);not null"`
+	CurrentTable string
+	Checkpoint   string `gorm:"type:varchar(1024)"`
+	Records      int
+	FinishedAt   *time.Time
+}
+
+// TableName returns a consistent table name for
+// gorm so multiple structs from different versions of the database all operate
+// on the same table.
+func (EncryptionRotationV1) TableName() string {
+	return "encryption_rotations"
+}
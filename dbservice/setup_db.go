@@ -46,6 +46,11 @@ const (
 
 	DBTypeMySQL   = "mysql"
 	DBTypeSQLite3 = "sqlite3"
+
+	defaultDBTLS               = "true"
+	defaultCustomTLSSkipVerify = true
+	defaultDBPort              = "3306"
+	defaultDBName              = "servicebroker"
 )
 
 func init() {
@@ -53,18 +58,18 @@ func init() {
 	viper.BindEnv(clientCertProp, "CLIENT_CERT")
 	viper.BindEnv(clientKeyProp, "CLIENT_KEY")
 	viper.BindEnv(dbTLS, "DB_TLS")
-	viper.SetDefault(dbTLS, "true")
+	viper.SetDefault(dbTLS, defaultDBTLS)
 	viper.BindEnv(customTLSSkipVerify, "CUSTOM_CERT_TLS_SKIP_VERIFY")
-	viper.SetDefault(customTLSSkipVerify, true)
+	viper.SetDefault(customTLSSkipVerify, defaultCustomTLSSkipVerify)
 
 	viper.BindEnv(dbHostProp, "DB_HOST")
 	viper.BindEnv(dbUserProp, "DB_USERNAME")
 	viper.BindEnv(dbPassProp, "DB_PASSWORD")
 
 	viper.BindEnv(dbPortProp, "DB_PORT")
-	viper.SetDefault(dbPortProp, "3306")
+	viper.SetDefault(dbPortProp, defaultDBPort)
 	viper.BindEnv(dbNameProp, "DB_NAME")
-	viper.SetDefault(dbNameProp, "servicebroker")
+	viper.SetDefault(dbNameProp, defaultDBName)
 
 	viper.BindEnv(dbTypeProp, "DB_TYPE")
 	viper.SetDefault(dbTypeProp, DBTypeMySQL)
@@ -74,13 +79,18 @@ func init() {
 
 // SetupDB pulls db credentials from the environment, connects to the db, and returns the db connection
 func SetupDB(logger lager.Logger) *gorm.DB {
-	dbType := viper.GetString(dbTypeProp)
 	var db *gorm.DB
 	var err error
 	// if provided, use database injected by CF via VCAP_SERVICES environment variable
 	if err := UseVcapServices(); err != nil {
 		logger.Info("Invalid VCAP_SERVICES environment variable - falling back to explicit environment variables")
 	}
+	// if provided, the database URL takes precedence over VCAP_SERVICES and the explicit environment variables
+	if err := UseDatabaseURL(); err != nil {
+		logger.Error("Database Setup", err)
+		os.Exit(1)
+	}
+	dbType := viper.GetString(dbTypeProp)
 	switch dbType {
 	default:
 		logger.Error("Database Setup", fmt.Errorf("invalid database type %q, valid types are: sqlite3 and mysql", dbType))
@@ -46,6 +46,18 @@ func TestTerraformResource_URL(t *testing.T) {
 			},
 			ExpectedURL: fmt.Sprintf("https://myproject/%s_%s_%s_%s", "foo", "1.0", "my_os", "my_arch"),
 		},
+		"opentofu": {
+			Resource: manifest.TerraformResource{
+				Name:    "tofu",
+				Version: "1.6.0",
+				Source:  "https://github.com/opentofu/opentofu/archive/v1.6.0.zip",
+			},
+			Plat: platform.Platform{
+				Os:   "my_os",
+				Arch: "my_arch",
+			},
+			ExpectedURL: "https://github.com/opentofu/opentofu/releases/download/v1.6.0/tofu_1.6.0_my_os_my_arch.zip",
+		},
 		"handles_relative_path": {
 			Resource: manifest.TerraformResource{
 				Name:        "foo",
@@ -25,11 +25,30 @@ type Manifest struct {
 	TerraformStateProviderReplacements map[string]string
 }
 
+// Engine is the tool that runs Terraform configurations
+type Engine string
+
+const (
+	TerraformEngine Engine = "terraform"
+	OpenTofuEngine  Engine = "tofu"
+)
+
 type TerraformVersion struct {
 	Version     *version.Version
 	Default     bool
 	Source      string
 	URLTemplate string
+
+	// Engine is the tool of this version. The zero value is Terraform.
+	Engine Engine
+}
+
+// Binary is the name of the binary of the engine, and of its release
+func (v TerraformVersion) Binary() string {
+	if v.Engine == "" {
+		return string(TerraformEngine)
+	}
+	return string(v.Engine)
 }
 
 type TerraformProvider struct {
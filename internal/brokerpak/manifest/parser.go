@@ -78,7 +78,7 @@ func Parse(input []byte) (*Manifest, error) {
 func parseTerraformUpgradePath(p parser) (result []*version.Version, errs *validation.FieldError) {
 	available := make(map[string]bool)
 	for _, v := range p.TerraformResources {
-		if v.Name == "terraform" {
+		if v.resourceType() == terraformVersion {
 			available[v.Version] = true
 		}
 	}
@@ -113,6 +113,7 @@ func parseTerraformResources(p parser) (versions []TerraformVersion, providers [
 		return nil, nil, nil, validation.ErrMissingField("terraform_binaries")
 	}
 
+	registry := providerRegistry(p.TerraformResources)
 	terraformVersionCache := make(map[string]struct{})
 	for i, r := range p.TerraformResources {
 		var (
@@ -127,7 +128,7 @@ func parseTerraformResources(p parser) (versions []TerraformVersion, providers [
 		)
 
 		if r.resourceType() == terraformProvider {
-			providerFQN, err = tfproviderfqn.New(r.Name, r.Provider)
+			providerFQN, err = tfproviderfqn.NewInRegistry(r.Name, r.Provider, registry)
 			if err != nil {
 				errs = errs.Also((&validation.FieldError{
 					Message: err.Error(),
@@ -157,12 +158,16 @@ func parseTerraformResources(p parser) (versions []TerraformVersion, providers [
 
 		switch r.resourceType() {
 		case terraformVersion:
-			versions = append(versions, TerraformVersion{
+			tv := TerraformVersion{
 				Version:     ver,
 				Default:     r.Default,
 				Source:      r.Source,
 				URLTemplate: r.URLTemplate,
-			})
+			}
+			if r.Name == string(OpenTofuEngine) {
+				tv.Engine = OpenTofuEngine
+			}
+			versions = append(versions, tv)
 		case terraformProvider:
 			providers = append(providers, TerraformProvider{
 				Name:        r.Name,
@@ -213,6 +218,24 @@ func parseTerraformResources(p parser) (versions []TerraformVersion, providers [
 	return versions, providers, binaries, errs
 }
 
+// providerRegistry is where providers without a hostname are installed from, which is the
+// OpenTofu registry when the default version is an OpenTofu version
+func providerRegistry(resources []TerraformResource) string {
+	var versions []TerraformResource
+	for _, r := range resources {
+		if r.resourceType() == terraformVersion {
+			versions = append(versions, r)
+		}
+	}
+
+	for _, r := range versions {
+		if (r.Default || len(versions) == 1) && r.Name == string(OpenTofuEngine) {
+			return tfproviderfqn.OpenTofuRegistry
+		}
+	}
+	return tfproviderfqn.TerraformRegistry
+}
+
 var _ validation.Validatable = (*parser)(nil)
 
 func (m *parser) Validate() (errs *validation.FieldError) {
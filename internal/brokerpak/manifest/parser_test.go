@@ -114,6 +114,93 @@ var _ = Describe("Parser", func() {
 			))
 		})
 
+		It("can parse OpenTofu versions", func() {
+			m, err := manifest.Parse(fakeManifest(
+				withAdditionalEntry("terraform_binaries", map[string]interface{}{
+					"name":    "tofu",
+					"version": "1.6.0",
+					"default": true,
+					"source":  "https://github.com/opentofu/opentofu/archive/v1.6.0.zip",
+				}),
+				withAdditionalEntry("terraform_binaries", map[string]interface{}{
+					"name":    "terraform-provider-mysql",
+					"version": "1.9.0",
+				}),
+				with("terraform_upgrade_path", []map[string]interface{}{
+					{"version": "1.1.4"},
+					{"version": "1.6.0"},
+				}),
+			))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(m.TerraformVersions).To(ContainElement(manifest.TerraformVersion{
+				Version: version.Must(version.NewVersion("1.6.0")),
+				Default: true,
+				Source:  "https://github.com/opentofu/opentofu/archive/v1.6.0.zip",
+				Engine:  manifest.OpenTofuEngine,
+			}))
+			Expect(m.TerraformUpgradePath).To(Equal([]*version.Version{
+				version.Must(version.NewVersion("1.1.4")),
+				version.Must(version.NewVersion("1.6.0")),
+			}))
+
+			defaultVersion, err := m.DefaultTerraformVersion()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(defaultVersion).To(Equal(version.Must(version.NewVersion("1.6.0"))))
+
+			By("resolving providers without a hostname in the OpenTofu registry")
+			Expect(m.TerraformProviders[0].Provider.String()).To(Equal("registry.terraform.io/other/random"))
+			Expect(m.TerraformProviders[1].Provider.String()).To(Equal("registry.opentofu.org/hashicorp/mysql"))
+			Expect(m.OpenTofuProviderReplacements()).To(Equal(map[string]string{
+				"registry.terraform.io/hashicorp/mysql": "registry.opentofu.org/hashicorp/mysql",
+			}))
+
+			By("installing providers for the Terraform versions in the upgrade path")
+			Expect(m.ProviderAddresses(m.TerraformProviders[0])).To(HaveLen(1))
+			Expect(m.ProviderAddresses(m.TerraformProviders[1])).To(Equal([]tfproviderfqn.TfProviderFQN{
+				{Hostname: "registry.opentofu.org", Namespace: "hashicorp", Type: "mysql"},
+				{Hostname: "registry.terraform.io", Namespace: "hashicorp", Type: "mysql"},
+			}))
+		})
+
+		It("resolves providers in the OpenTofu registry when OpenTofu is the only version", func() {
+			m, err := manifest.Parse(fakeManifest(
+				with("terraform_binaries", []map[string]interface{}{
+					{"name": "tofu", "version": "1.6.0"},
+					{"name": "terraform-provider-random", "version": "3.1.0"},
+					{"name": "terraform-provider-mysql", "version": "1.9.0", "provider": "example.com/other/mysql"},
+				}),
+				without("terraform_upgrade_path"),
+			))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(m.TerraformProviders[0].Provider.String()).To(Equal("registry.opentofu.org/hashicorp/random"))
+			Expect(m.TerraformProviders[1].Provider.String()).To(Equal("example.com/other/mysql"))
+			Expect(m.ProviderAddresses(m.TerraformProviders[0])).To(HaveLen(1))
+			Expect(m.OpenTofuProviderReplacements()).To(Equal(map[string]string{
+				"registry.terraform.io/hashicorp/random": "registry.opentofu.org/hashicorp/random",
+			}))
+		})
+
+		It("resolves providers in the Terraform registry when Terraform is the default", func() {
+			m, err := manifest.Parse(fakeManifest(withAdditionalEntry("terraform_binaries", map[string]interface{}{
+				"name":    "terraform-provider-mysql",
+				"version": "1.9.0",
+			})))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(m.TerraformProviders[1].Provider.String()).To(Equal("registry.terraform.io/hashicorp/mysql"))
+			Expect(m.OpenTofuProviderReplacements()).To(BeEmpty())
+		})
+
+		It("does not allow Terraform and OpenTofu to have the same version", func() {
+			m, err := manifest.Parse(fakeManifest(
+				withAdditionalEntry("terraform_binaries", map[string]interface{}{
+					"name":    "tofu",
+					"version": "1.1.4",
+				}),
+			))
+			Expect(err).To(MatchError(ContainSubstring("duplicated value, must be unique: 1.1.4: version")))
+			Expect(m).To(BeNil())
+		})
+
 		When("none are marked as default", func() {
 			It("fails", func() {
 				m, err := manifest.Parse(fakeManifest(withAdditionalEntry("terraform_binaries", map[string]interface{}{
@@ -16,4 +16,17 @@ var _ = Describe("TerraformResource", func() {
 
 		Expect(s).To(MatchYAML(testManifest))
 	})
+
+	It("serializes OpenTofu versions with their name", func() {
+		p, err := manifest.Parse(testManifest)
+		Expect(err).NotTo(HaveOccurred())
+		p.TerraformVersions[0].Engine = manifest.OpenTofuEngine
+
+		s, err := p.Serialize()
+		Expect(err).NotTo(HaveOccurred())
+
+		r, err := manifest.Parse(s)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.TerraformVersions[0].Binary()).To(Equal("tofu"))
+	})
 })
@@ -19,10 +19,10 @@ type TerraformResource struct {
 
 	// URLTemplate holds a custom URL template to get the release of the given tool.
 	// Parameters available are ${name}, ${version}, ${os}, and ${arch}.
-	// If non is specified HashicorpUrlTemplate is used.
+	// If non is specified HashicorpUrlTemplate is used, or OpenTofuURLTemplate for `tofu`.
 	URLTemplate string `yaml:"url_template,omitempty"`
 
-	// Default is used to mark the default Terraform version when there is more than one
+	// Default is used to mark the default Terraform or OpenTofu version when there is more than one
 	Default bool `yaml:"default,omitempty"`
 }
 
@@ -39,7 +39,7 @@ func (tr *TerraformResource) resourceType() terraformResourceType {
 	switch {
 	case tr.Name == "":
 		return invalidType
-	case tr.Name == "terraform":
+	case tr.Name == string(TerraformEngine), tr.Name == string(OpenTofuEngine):
 		return terraformVersion
 	case strings.HasPrefix(tr.Name, "terraform-provider-"):
 		return terraformProvider
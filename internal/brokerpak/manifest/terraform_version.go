@@ -3,6 +3,7 @@ package manifest
 import (
 	"fmt"
 
+	"github.com/cloudfoundry/cloud-service-broker/internal/tfproviderfqn"
 	"github.com/hashicorp/go-version"
 )
 
@@ -24,3 +25,33 @@ func (m *Manifest) DefaultTerraformVersion() (*version.Version, error) {
 		return &version.Version{}, fmt.Errorf("no default terraform found")
 	}
 }
+
+// OpenTofuProviderReplacements returns the replacements of providers that OpenTofu makes in the state
+// of deployments that were made by Terraform. A provider without a hostname is in the Terraform registry
+// in that state, and is installed from the OpenTofu registry by OpenTofu.
+func (m *Manifest) OpenTofuProviderReplacements() map[string]string {
+	replacements := make(map[string]string)
+	for _, p := range m.TerraformProviders {
+		if p.Provider.Hostname == tfproviderfqn.OpenTofuRegistry {
+			replacements[p.Provider.InRegistry(tfproviderfqn.TerraformRegistry).String()] = p.Provider.String()
+		}
+	}
+	return replacements
+}
+
+// ProviderAddresses returns the addresses that a provider is installed at. When the Terraform upgrade
+// path steps from Terraform to OpenTofu, a provider of the OpenTofu registry is also installed at its
+// address in the Terraform registry, so that the Terraform versions can find it.
+func (m *Manifest) ProviderAddresses(p TerraformProvider) []tfproviderfqn.TfProviderFQN {
+	addresses := []tfproviderfqn.TfProviderFQN{p.Provider}
+	if p.Provider.Hostname != tfproviderfqn.OpenTofuRegistry {
+		return addresses
+	}
+
+	for _, v := range m.TerraformVersions {
+		if v.Binary() == string(TerraformEngine) {
+			return append(addresses, p.Provider.InRegistry(tfproviderfqn.TerraformRegistry))
+		}
+	}
+	return addresses
+}
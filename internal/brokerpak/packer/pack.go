@@ -72,7 +72,7 @@ func packSources(m *manifest.Manifest, tmp string, cachePath string) error {
 	}
 
 	for _, resource := range m.TerraformVersions {
-		if err := packSource(resource.Source, "terraform"); err != nil {
+		if err := packSource(resource.Source, resource.Binary()); err != nil {
 			return err
 		}
 	}
@@ -99,8 +99,8 @@ func packBinaries(m *manifest.Manifest, tmp string, cachePath string) error {
 		p := filepath.Join(tmp, "bin", platform.Os, platform.Arch)
 
 		for _, resource := range m.TerraformVersions {
-			log.Println("\t", brokerpakurl.URL("terraform", resource.Version.String(), resource.URLTemplate, platform), "->", filepath.Join(p, resource.Version.String()))
-			if err := cachedFetchFile(getAny, brokerpakurl.URL("terraform", resource.Version.String(), resource.URLTemplate, platform), filepath.Join(p, resource.Version.String()), cachePath); err != nil {
+			log.Println("\t", brokerpakurl.URL(resource.Binary(), resource.Version.String(), resource.URLTemplate, platform), "->", filepath.Join(p, resource.Version.String()))
+			if err := cachedFetchFile(getAny, brokerpakurl.URL(resource.Binary(), resource.Version.String(), resource.URLTemplate, platform), filepath.Join(p, resource.Version.String()), cachePath); err != nil {
 				return err
 			}
 		}
@@ -149,6 +149,13 @@ func packDefinitions(m *manifest.Manifest, tmp, base string) error {
 			return fmt.Errorf("couldn't load bind template %s: %v", defn.BindSettings.TemplateRef, err)
 		}
 
+		for i := range defn.Actions {
+			if err := defn.Actions[i].LoadTemplate(base); err != nil {
+				return fmt.Errorf("couldn't load template %s of action %s: %v", defn.Actions[i].TemplateRef, defn.Actions[i].Name, err)
+			}
+			defn.Actions[i].TemplateRef = ""
+		}
+
 		clearRefs(&defn.ProvisionSettings)
 		clearRefs(&defn.BindSettings)
 
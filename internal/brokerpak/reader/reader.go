@@ -28,6 +28,7 @@ import (
 	"github.com/cloudfoundry/cloud-service-broker/internal/brokerpak/fetcher"
 	"github.com/cloudfoundry/cloud-service-broker/internal/brokerpak/manifest"
 	"github.com/cloudfoundry/cloud-service-broker/internal/brokerpak/platform"
+	"github.com/cloudfoundry/cloud-service-broker/internal/tfproviderfqn"
 	"github.com/cloudfoundry/cloud-service-broker/internal/zippy"
 	"github.com/cloudfoundry/cloud-service-broker/utils/stream"
 	"github.com/hashicorp/go-version"
@@ -91,6 +92,7 @@ func (pak *BrokerPakReader) Services() ([]tf.TfServiceDefinitionV1, error) {
 		}
 
 		receiver.RequiredEnvVars = manifest.RequiredEnvVars
+		receiver.BrokerpakVersion = manifest.Version
 		services = append(services, receiver)
 	}
 
@@ -142,8 +144,10 @@ func (pak *BrokerPakReader) ExtractPlatformBins(destination string) error {
 	}
 
 	for _, r := range mf.TerraformProviders {
-		if err := pak.extractProvider(r, destination, terraformVersion); err != nil {
-			return err
+		for _, address := range mf.ProviderAddresses(r) {
+			if err := pak.extractProvider(r, address, destination, terraformVersion); err != nil {
+				return err
+			}
 		}
 	}
 	for _, r := range mf.TerraformVersions {
@@ -160,13 +164,53 @@ func (pak *BrokerPakReader) ExtractPlatformBins(destination string) error {
 	return nil
 }
 
-func (pak *BrokerPakReader) extractProvider(r manifest.TerraformProvider, destination string, terraformVersion *version.Version) error {
+// ExtractPluginCache extracts the providers for the current platform to a
+// Terraform plugin cache directory, which may be shared with other brokerpaks.
+// Providers already in the cache are not extracted again. Terraform 0.12 and
+// lower use a different cache layout, so nothing is extracted for them.
+func (pak *BrokerPakReader) ExtractPluginCache(cacheDir string) error {
+	mf, err := pak.Manifest()
+	if err != nil {
+		return err
+	}
+
+	terraformVersion, err := mf.DefaultTerraformVersion()
+	if err != nil {
+		return err
+	}
+
+	if terraformVersion.LessThan(version.Must(version.NewVersion("0.13.0"))) {
+		return nil
+	}
+
+	for _, r := range mf.TerraformProviders {
+		filePath, err := pak.findFileInZip(fmt.Sprintf("%s_v%s", r.Name, r.Version))
+		if err != nil {
+			return err
+		}
+
+		for _, address := range mf.ProviderAddresses(r) {
+			destination := providerInstallPath(terraformVersion, cacheDir, address, r.Version)
+			if _, err := os.Stat(filepath.Join(destination, path.Base(filePath))); err == nil {
+				continue
+			}
+
+			if err := pak.contents.ExtractFile(filePath, destination); err != nil {
+				return fmt.Errorf("error extracting terraform-provider file to plugin cache: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (pak *BrokerPakReader) extractProvider(r manifest.TerraformProvider, address tfproviderfqn.TfProviderFQN, destination string, terraformVersion *version.Version) error {
 	filePath, err := pak.findFileInZip(fmt.Sprintf("%s_v%s", r.Name, r.Version))
 	if err != nil {
 		return err
 	}
 
-	if err := pak.contents.ExtractFile(filePath, providerInstallPath(terraformVersion, destination, r)); err != nil {
+	if err := pak.contents.ExtractFile(filePath, providerInstallPath(terraformVersion, destination, address, r.Version)); err != nil {
 		return fmt.Errorf("error extracting terraform-provider file: %w", err)
 	}
 
@@ -188,18 +232,18 @@ func (pak *BrokerPakReader) extractBinary(r manifest.Binary, destination string)
 
 func (pak *BrokerPakReader) extractTerraform(r manifest.TerraformVersion, destination string) error {
 	plat := platform.CurrentPlatform()
-	versionedPath := path.Join("bin", plat.Os, plat.Arch, r.Version.String(), "terraform")
+	versionedPath := path.Join("bin", plat.Os, plat.Arch, r.Version.String(), r.Binary())
 	if pak.fileExistsInZip(versionedPath) {
 		if err := pak.contents.ExtractFile(versionedPath, filepath.Join(destination, "versions", r.Version.String())); err != nil {
-			return fmt.Errorf("error extracting versioned terraform binary: %w", err)
+			return fmt.Errorf("error extracting versioned %s binary: %w", r.Binary(), err)
 		}
 
 		return nil
 	}
 
-	// For compatibility with brokerpaks built with older versions
+	// For compatibility with brokerpaks built with older versions, which only had Terraform
 	unversionedPath := path.Join("bin", plat.Os, plat.Arch, "terraform")
-	if pak.fileExistsInZip(unversionedPath) {
+	if r.Binary() == string(manifest.TerraformEngine) && pak.fileExistsInZip(unversionedPath) {
 		if err := pak.contents.ExtractFile(unversionedPath, filepath.Join(destination, "versions", r.Version.String())); err != nil {
 			return fmt.Errorf("error extracting terraform binary: %w", err)
 		}
@@ -207,7 +251,7 @@ func (pak *BrokerPakReader) extractTerraform(r manifest.TerraformVersion, destin
 		return nil
 	}
 
-	return fmt.Errorf("could not find Terraform version %s in brokerpak", r.Version)
+	return fmt.Errorf("could not find %s version %s in brokerpak", r.Binary(), r.Version)
 }
 
 func (pak *BrokerPakReader) findFileInZip(name string) (string, error) {
@@ -267,7 +311,7 @@ func (pak *BrokerPakReader) readBytes(name string) ([]byte, error) {
 	return data, nil
 }
 
-func providerInstallPath(terraformVersion *version.Version, destination string, tfProvider manifest.TerraformProvider) string {
+func providerInstallPath(terraformVersion *version.Version, destination string, address tfproviderfqn.TfProviderFQN, providerVersion *version.Version) string {
 	if terraformVersion.LessThan(version.Must(version.NewVersion("0.13.0"))) {
 		return destination
 	}
@@ -275,8 +319,8 @@ func providerInstallPath(terraformVersion *version.Version, destination string,
 	plat := platform.CurrentPlatform()
 	return filepath.Join(
 		destination,
-		tfProvider.Provider.String(),
-		tfProvider.Version.String(),
+		address.String(),
+		providerVersion.String(),
 		fmt.Sprintf("%s_%s", plat.Os, plat.Arch),
 	)
 }
@@ -121,6 +121,67 @@ var _ = Describe("reader", func() {
 			})
 		})
 
+		Context("terraform and opentofu versions", func() {
+			It("extracts each binary into the directory of its version", func() {
+				pk := fakeBrokerpak(
+					withTerraform("1.5.7"),
+					withDefaultOpenTofu("1.6.0"),
+					withProvider("", "terraform-provider-google-beta", "1.19.0", "x4"),
+				)
+
+				pakReader, err := reader.OpenBrokerPak(pk)
+				Expect(err).NotTo(HaveOccurred())
+
+				binOutput := GinkgoT().TempDir()
+				Expect(pakReader.ExtractPlatformBins(binOutput)).NotTo(HaveOccurred())
+
+				data, err := os.ReadFile(filepath.Join(binOutput, "versions", "1.5.7", "terraform"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(data).To(Equal([]byte("1.5.7")))
+
+				data, err = os.ReadFile(filepath.Join(binOutput, "versions", "1.6.0", "tofu"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(data).To(Equal([]byte("1.6.0")))
+			})
+
+			It("extracts providers of the OpenTofu registry for both engines", func() {
+				pk := fakeBrokerpak(
+					withTerraform("1.5.7"),
+					withDefaultOpenTofu("1.6.0"),
+					withOpenTofuProvider("terraform-provider-google", "1.19.0", "x5"),
+				)
+
+				pakReader, err := reader.OpenBrokerPak(pk)
+				Expect(err).NotTo(HaveOccurred())
+
+				binOutput := GinkgoT().TempDir()
+				Expect(pakReader.ExtractPlatformBins(binOutput)).NotTo(HaveOccurred())
+
+				plat := fmt.Sprintf("%s_%s", runtime.GOOS, runtime.GOARCH)
+				Expect(filepath.Join(binOutput, "registry.opentofu.org", "hashicorp", "google", "1.19.0", plat, "terraform-provider-google_v1.19.0_x5")).To(BeAnExistingFile())
+				Expect(filepath.Join(binOutput, "registry.terraform.io", "hashicorp", "google", "1.19.0", plat, "terraform-provider-google_v1.19.0_x5")).To(BeAnExistingFile())
+			})
+		})
+
+		Context("only opentofu", func() {
+			It("extracts providers of the OpenTofu registry", func() {
+				pk := fakeBrokerpak(
+					withDefaultOpenTofu("1.6.0"),
+					withOpenTofuProvider("terraform-provider-google", "1.19.0", "x5"),
+				)
+
+				pakReader, err := reader.OpenBrokerPak(pk)
+				Expect(err).NotTo(HaveOccurred())
+
+				binOutput := GinkgoT().TempDir()
+				Expect(pakReader.ExtractPlatformBins(binOutput)).NotTo(HaveOccurred())
+
+				plat := fmt.Sprintf("%s_%s", runtime.GOOS, runtime.GOARCH)
+				Expect(filepath.Join(binOutput, "registry.opentofu.org", "hashicorp", "google", "1.19.0", plat, "terraform-provider-google_v1.19.0_x5")).To(BeAnExistingFile())
+				Expect(filepath.Join(binOutput, "registry.terraform.io")).NotTo(BeADirectory())
+			})
+		})
+
 		Context("multiple providers share same name and version", func() {
 			It("should return an error", func() {
 				pk := fakeBrokerpak(
@@ -157,6 +218,59 @@ var _ = Describe("reader", func() {
 		})
 	})
 
+	Describe("ExtractPluginCache", func() {
+		It("extracts providers to the plugin cache hierarchy", func() {
+			pk := fakeBrokerpak(
+				withTerraform("0.13.0"),
+				withProvider("", "terraform-provider-google", "1.19.0", "x5"),
+			)
+
+			pakReader, err := reader.OpenBrokerPak(pk)
+			Expect(err).NotTo(HaveOccurred())
+
+			cacheDir := GinkgoT().TempDir()
+			Expect(pakReader.ExtractPluginCache(cacheDir)).To(Succeed())
+
+			plat := fmt.Sprintf("%s_%s", runtime.GOOS, runtime.GOARCH)
+			Expect(filepath.Join(cacheDir, "registry.terraform.io", "hashicorp", "google", "1.19.0", plat, "terraform-provider-google_v1.19.0_x5")).To(BeAnExistingFile())
+		})
+
+		It("keeps providers that are already in the cache", func() {
+			pk := fakeBrokerpak(
+				withTerraform("0.13.0"),
+				withProvider("", "terraform-provider-google", "1.19.0", "x5"),
+			)
+
+			pakReader, err := reader.OpenBrokerPak(pk)
+			Expect(err).NotTo(HaveOccurred())
+
+			cacheDir := GinkgoT().TempDir()
+			plat := fmt.Sprintf("%s_%s", runtime.GOOS, runtime.GOARCH)
+			cached := filepath.Join(cacheDir, "registry.terraform.io", "hashicorp", "google", "1.19.0", plat, "terraform-provider-google_v1.19.0_x5")
+			Expect(os.MkdirAll(filepath.Dir(cached), 0755)).To(Succeed())
+			Expect(os.WriteFile(cached, []byte("cached"), 0755)).To(Succeed())
+
+			Expect(pakReader.ExtractPluginCache(cacheDir)).To(Succeed())
+
+			Expect(os.ReadFile(cached)).To(Equal([]byte("cached")))
+		})
+
+		It("does not extract anything for terraform 0.12", func() {
+			pk := fakeBrokerpak(
+				withTerraform("0.12.0"),
+				withProvider("", "terraform-provider-google", "1.19.0", "x5"),
+			)
+
+			pakReader, err := reader.OpenBrokerPak(pk)
+			Expect(err).NotTo(HaveOccurred())
+
+			cacheDir := GinkgoT().TempDir()
+			Expect(pakReader.ExtractPluginCache(cacheDir)).To(Succeed())
+
+			Expect(os.ReadDir(cacheDir)).To(BeEmpty())
+		})
+	})
+
 	Describe("including source", func() {
 		It("does not include source by default", func() {
 			pk := fakeBrokerpak(withProvider("", "terraform-provider-fake", "1.2.3", "x1"))
@@ -257,6 +371,21 @@ func withDefaultTerraform(tfVersion string) option {
 	}
 }
 
+func withDefaultOpenTofu(tofuVersion string) option {
+	return func(c *config) {
+		fakeFile := filepath.Join(c.dir, tofuVersion, "tofu")
+		Expect(stream.Copy(stream.FromString(tofuVersion), stream.ToFile(fakeFile))).NotTo(HaveOccurred())
+
+		c.manifest.TerraformVersions = append(c.manifest.TerraformVersions, manifest.TerraformVersion{
+			Version:     version.Must(version.NewVersion(tofuVersion)),
+			Default:     true,
+			Source:      fakeFile,
+			URLTemplate: fakeFile,
+			Engine:      manifest.OpenTofuEngine,
+		})
+	}
+}
+
 func withProvider(provider, name, providerVersion, suffix string) option {
 	return func(c *config) {
 		fakeFile := filepath.Join(c.dir, fmt.Sprintf("%s_v%s_%s", name, providerVersion, suffix))
@@ -272,6 +401,23 @@ func withProvider(provider, name, providerVersion, suffix string) option {
 	}
 }
 
+func withOpenTofuProvider(name, providerVersion, suffix string) option {
+	return func(c *config) {
+		fakeFile := filepath.Join(c.dir, fmt.Sprintf("%s_v%s_%s", name, providerVersion, suffix))
+		Expect(stream.Copy(stream.FromString("dummy-file"), stream.ToFile(fakeFile))).NotTo(HaveOccurred())
+
+		provider, err := tfproviderfqn.NewInRegistry(name, "", tfproviderfqn.OpenTofuRegistry)
+		Expect(err).NotTo(HaveOccurred())
+		c.manifest.TerraformProviders = append(c.manifest.TerraformProviders, manifest.TerraformProvider{
+			Name:        name,
+			Version:     version.Must(version.NewVersion(providerVersion)),
+			Provider:    provider,
+			Source:      fakeFile,
+			URLTemplate: fakeFile,
+		})
+	}
+}
+
 func withMissingProvider(name, providerVersion string) option {
 	return func(c *config) {
 		fakeFile := filepath.Join(c.dir, "file-name-does-not-match")
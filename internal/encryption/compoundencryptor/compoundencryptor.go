@@ -1,6 +1,9 @@
 package compoundencryptor
 
-import "github.com/cloudfoundry/cloud-service-broker/internal/storage"
+import (
+	"github.com/cloudfoundry/cloud-service-broker/internal/encryption/labelledencryptor"
+	"github.com/cloudfoundry/cloud-service-broker/internal/storage"
+)
 
 func New(encryptor storage.Encryptor, decryptors ...storage.Encryptor) storage.Encryptor {
 	return CompoundEncryptor{
@@ -18,7 +21,22 @@ func (c CompoundEncryptor) Encrypt(plaintext []byte) ([]byte, error) {
 	return c.encryptor.Encrypt(plaintext)
 }
 
+// Decrypt decrypts data that has a key label with the decryptor that has the same label. Data without
+// a key label, which was encrypted before labels were added, is decrypted by trying each decryptor in turn.
 func (c CompoundEncryptor) Decrypt(ciphertext []byte) (data []byte, err error) {
+	label, _, ok, err := labelledencryptor.Split(ciphertext)
+	switch {
+	case err != nil:
+		return nil, err
+	case ok:
+		for _, d := range c.decryptors {
+			if l, isLabelled := d.(labelled); isLabelled && l.Label() == label {
+				return d.Decrypt(ciphertext)
+			}
+		}
+		return nil, &labelledencryptor.MissingKeyError{Label: label}
+	}
+
 	for _, d := range c.decryptors {
 		data, err = d.Decrypt(ciphertext)
 		if err == nil {
@@ -28,3 +46,12 @@ func (c CompoundEncryptor) Decrypt(ciphertext []byte) (data []byte, err error) {
 
 	return nil, err
 }
+
+// KeyLabel returns the label of the password that encrypted the data
+func (c CompoundEncryptor) KeyLabel(ciphertext []byte) (string, bool) {
+	return labelledencryptor.KeyLabel(ciphertext)
+}
+
+type labelled interface {
+	Label() string
+}
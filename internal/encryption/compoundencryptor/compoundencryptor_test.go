@@ -4,6 +4,7 @@ import (
 	"errors"
 
 	"github.com/cloudfoundry/cloud-service-broker/internal/encryption/compoundencryptor"
+	"github.com/cloudfoundry/cloud-service-broker/internal/encryption/labelledencryptor"
 	"github.com/cloudfoundry/cloud-service-broker/internal/storage"
 	"github.com/cloudfoundry/cloud-service-broker/internal/storage/storagefakes"
 	. "github.com/onsi/ginkgo/v2"
@@ -79,4 +80,49 @@ var _ = Describe("CompoundEncryptor", func() {
 			Expect(decrypted).To(BeEmpty())
 		})
 	})
+
+	When("the data has a key label", func() {
+		var alphaEncryptor, betaEncryptor labelledencryptor.LabelledEncryptor
+
+		BeforeEach(func() {
+			alphaEncryptor = labelledencryptor.New("alpha", secondaryEncryptorAlpha)
+			betaEncryptor = labelledencryptor.New("beta", secondaryEncryptorBeta)
+			compoundEncryptor = compoundencryptor.New(primaryEncryptor, alphaEncryptor, betaEncryptor)
+		})
+
+		It("only uses the decryptor with the same label", func() {
+			secondaryEncryptorBeta.EncryptReturns([]byte("mopsy"), nil)
+			secondaryEncryptorBeta.DecryptReturns([]byte("flopsy"), nil)
+			encrypted, err := betaEncryptor.Encrypt([]byte("flopsy"))
+			Expect(err).NotTo(HaveOccurred())
+
+			decrypted, err := compoundEncryptor.Decrypt(encrypted)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(decrypted).To(Equal([]byte("flopsy")))
+
+			Expect(secondaryEncryptorAlpha.DecryptCallCount()).To(BeZero())
+			Expect(secondaryEncryptorBeta.DecryptCallCount()).To(Equal(1))
+			Expect(secondaryEncryptorBeta.DecryptArgsForCall(0)).To(Equal([]byte("mopsy")))
+		})
+
+		It("names the label when no decryptor has it", func() {
+			secondaryEncryptorAlpha.EncryptReturns([]byte("mopsy"), nil)
+			encrypted, err := labelledencryptor.New("gamma", secondaryEncryptorAlpha).Encrypt([]byte("flopsy"))
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = compoundEncryptor.Decrypt(encrypted)
+			Expect(err).To(MatchError(`data was encrypted with the password labelled "gamma", which is not configured`))
+			Expect(secondaryEncryptorAlpha.DecryptCallCount()).To(BeZero())
+			Expect(secondaryEncryptorBeta.DecryptCallCount()).To(BeZero())
+		})
+
+		It("reports the label", func() {
+			encrypted, err := betaEncryptor.Encrypt([]byte("flopsy"))
+			Expect(err).NotTo(HaveOccurred())
+
+			label, ok := compoundEncryptor.(compoundencryptor.CompoundEncryptor).KeyLabel(encrypted)
+			Expect(ok).To(BeTrue())
+			Expect(label).To(Equal("beta"))
+		})
+	})
 })
@@ -0,0 +1,142 @@
+package envelopeencryptor
+
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 -generate
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/cloudfoundry/cloud-service-broker/internal/encryption/gcmencryptor"
+)
+
+// header identifies data encrypted by an EnvelopeEncryptor, so that data encrypted
+// in other ways can be rejected without a call to the key management service
+var header = []byte("csbenv1:")
+
+//counterfeiter:generate . KeyWrapper
+
+// KeyWrapper wraps and unwraps data keys using a key that is held by an external
+// key management service, so that the key never needs to be known to the broker
+type KeyWrapper interface {
+	WrapKey(key []byte) ([]byte, error)
+	UnwrapKey(wrapped []byte) ([]byte, error)
+}
+
+// New creates an encryptor that encrypts data with AES-GCM using a random data key,
+// and stores the data key wrapped by the KeyWrapper alongside the data.
+// A single data key is used for all data encrypted by the encryptor, so that the
+// key management service is only called once to wrap it, and unwrapped data keys
+// are cached so that each one is only unwrapped once.
+func New(wrapper KeyWrapper) *EnvelopeEncryptor {
+	return &EnvelopeEncryptor{
+		wrapper:   wrapper,
+		unwrapped: make(map[string]gcmencryptor.GCMEncryptor),
+	}
+}
+
+type EnvelopeEncryptor struct {
+	wrapper KeyWrapper
+
+	lock       sync.Mutex
+	wrappedKey []byte
+	dataKey    gcmencryptor.GCMEncryptor
+	unwrapped  map[string]gcmencryptor.GCMEncryptor
+}
+
+// Encrypt returns the header, the length of the wrapped data key, the wrapped data key
+// and the encrypted data
+func (e *EnvelopeEncryptor) Encrypt(plaintext []byte) ([]byte, error) {
+	wrappedKey, dataKey, err := e.currentDataKey()
+	if err != nil {
+		return nil, err
+	}
+
+	sealed, err := dataKey.Encrypt(plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	length := make([]byte, 2)
+	binary.BigEndian.PutUint16(length, uint16(len(wrappedKey)))
+
+	result := make([]byte, 0, len(header)+len(length)+len(wrappedKey)+len(sealed))
+	result = append(result, header...)
+	result = append(result, length...)
+	result = append(result, wrappedKey...)
+	return append(result, sealed...), nil
+}
+
+func (e *EnvelopeEncryptor) Decrypt(ciphertext []byte) ([]byte, error) {
+	if !bytes.HasPrefix(ciphertext, header) || len(ciphertext) < len(header)+2 {
+		return nil, errors.New("malformed ciphertext")
+	}
+	rest := ciphertext[len(header):]
+
+	wrappedKeyLength := int(binary.BigEndian.Uint16(rest))
+	rest = rest[2:]
+	if wrappedKeyLength == 0 || len(rest) < wrappedKeyLength {
+		return nil, errors.New("malformed ciphertext")
+	}
+
+	dataKey, err := e.unwrapDataKey(rest[:wrappedKeyLength])
+	if err != nil {
+		return nil, err
+	}
+
+	return dataKey.Decrypt(rest[wrappedKeyLength:])
+}
+
+func (e *EnvelopeEncryptor) currentDataKey() ([]byte, gcmencryptor.GCMEncryptor, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if e.wrappedKey != nil {
+		return e.wrappedKey, e.dataKey, nil
+	}
+
+	var key [32]byte
+	if _, err := io.ReadFull(rand.Reader, key[:]); err != nil {
+		return nil, gcmencryptor.GCMEncryptor{}, fmt.Errorf("error generating data key: %w", err)
+	}
+
+	wrappedKey, err := e.wrapper.WrapKey(key[:])
+	switch {
+	case err != nil:
+		return nil, gcmencryptor.GCMEncryptor{}, fmt.Errorf("error wrapping data key: %w", err)
+	case len(wrappedKey) == 0 || len(wrappedKey) > 0xffff:
+		return nil, gcmencryptor.GCMEncryptor{}, fmt.Errorf("wrapped data key has invalid length %d", len(wrappedKey))
+	}
+
+	e.wrappedKey = wrappedKey
+	e.dataKey = gcmencryptor.New(key)
+	e.unwrapped[string(wrappedKey)] = e.dataKey
+	return e.wrappedKey, e.dataKey, nil
+}
+
+func (e *EnvelopeEncryptor) unwrapDataKey(wrappedKey []byte) (gcmencryptor.GCMEncryptor, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if dataKey, ok := e.unwrapped[string(wrappedKey)]; ok {
+		return dataKey, nil
+	}
+
+	key, err := e.wrapper.UnwrapKey(wrappedKey)
+	switch {
+	case err != nil:
+		return gcmencryptor.GCMEncryptor{}, fmt.Errorf("error unwrapping data key: %w", err)
+	case len(key) != 32:
+		return gcmencryptor.GCMEncryptor{}, fmt.Errorf("unwrapped data key has invalid length %d", len(key))
+	}
+
+	var k [32]byte
+	copy(k[:], key)
+	dataKey := gcmencryptor.New(k)
+	e.unwrapped[string(wrappedKey)] = dataKey
+	return dataKey, nil
+}
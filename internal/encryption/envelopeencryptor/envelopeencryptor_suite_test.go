@@ -0,0 +1,13 @@
+package envelopeencryptor_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestEnvelopeEncryptor(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Envelope Encryptor Suite")
+}
@@ -0,0 +1,114 @@
+package envelopeencryptor_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry/cloud-service-broker/internal/encryption/envelopeencryptor"
+	"github.com/cloudfoundry/cloud-service-broker/internal/encryption/envelopeencryptor/envelopeencryptorfakes"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("EnvelopeEncryptor", func() {
+	var (
+		encryptor  *envelopeencryptor.EnvelopeEncryptor
+		keyWrapper *envelopeencryptorfakes.FakeKeyWrapper
+		dataKey    []byte
+	)
+
+	BeforeEach(func() {
+		keyWrapper = &envelopeencryptorfakes.FakeKeyWrapper{}
+		keyWrapper.WrapKeyStub = func(key []byte) ([]byte, error) {
+			dataKey = key
+			return []byte("wrapped-key"), nil
+		}
+		keyWrapper.UnwrapKeyStub = func(wrapped []byte) ([]byte, error) {
+			Expect(wrapped).To(Equal([]byte("wrapped-key")))
+			return dataKey, nil
+		}
+
+		encryptor = envelopeencryptor.New(keyWrapper)
+	})
+
+	It("can decrypt what it encrypted", func() {
+		encrypted, err := encryptor.Encrypt([]byte("Text to Encrypt"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(encrypted).NotTo(ContainSubstring("Encrypt"))
+
+		decrypted, err := encryptor.Decrypt(encrypted)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(decrypted)).To(Equal("Text to Encrypt"))
+	})
+
+	Describe("Encrypt", func() {
+		It("stores the wrapped data key with the data", func() {
+			encrypted, err := encryptor.Encrypt([]byte("Text to Encrypt"))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(encrypted).To(HavePrefix("csbenv1:\x00\x0bwrapped-key"))
+			Expect(dataKey).To(HaveLen(32))
+		})
+
+		It("wraps the data key once", func() {
+			result1, err := encryptor.Encrypt([]byte("Text to Encrypt"))
+			Expect(err).NotTo(HaveOccurred())
+			result2, err := encryptor.Encrypt([]byte("Text to Encrypt"))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result2).NotTo(Equal(result1))
+			Expect(keyWrapper.WrapKeyCallCount()).To(Equal(1))
+		})
+
+		It("fails when the data key cannot be wrapped", func() {
+			keyWrapper.WrapKeyStub = nil
+			keyWrapper.WrapKeyReturns(nil, errors.New("vault sealed"))
+
+			_, err := encryptor.Encrypt([]byte("Text to Encrypt"))
+			Expect(err).To(MatchError("error wrapping data key: vault sealed"))
+		})
+	})
+
+	Describe("Decrypt", func() {
+		It("unwraps each data key once", func() {
+			encrypted, err := encryptor.Encrypt([]byte("Text to Encrypt"))
+			Expect(err).NotTo(HaveOccurred())
+
+			decryptor := envelopeencryptor.New(keyWrapper)
+			for i := 0; i < 3; i++ {
+				decrypted, err := decryptor.Decrypt(encrypted)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(decrypted)).To(Equal("Text to Encrypt"))
+			}
+			Expect(keyWrapper.UnwrapKeyCallCount()).To(Equal(1))
+		})
+
+		It("rejects data that was not envelope encrypted without calling the key management service", func() {
+			_, err := encryptor.Decrypt([]byte("a value encrypted with a password"))
+			Expect(err).To(MatchError("malformed ciphertext"))
+
+			_, err = encryptor.Decrypt([]byte("csbenv1:\x00\xffshort"))
+			Expect(err).To(MatchError("malformed ciphertext"))
+
+			Expect(keyWrapper.UnwrapKeyCallCount()).To(BeZero())
+		})
+
+		It("fails when the data key cannot be unwrapped", func() {
+			encrypted, err := encryptor.Encrypt([]byte("Text to Encrypt"))
+			Expect(err).NotTo(HaveOccurred())
+			keyWrapper.UnwrapKeyStub = nil
+			keyWrapper.UnwrapKeyReturns(nil, errors.New("permission denied"))
+
+			_, err = envelopeencryptor.New(keyWrapper).Decrypt(encrypted)
+			Expect(err).To(MatchError("error unwrapping data key: permission denied"))
+		})
+
+		It("fails when the data has been tampered with", func() {
+			encrypted, err := encryptor.Encrypt([]byte("Text to Encrypt"))
+			Expect(err).NotTo(HaveOccurred())
+			encrypted[len(encrypted)-1] ^= 0xff
+
+			_, err = encryptor.Decrypt(encrypted)
+			Expect(err).To(MatchError("cipher: message authentication failed"))
+		})
+	})
+})
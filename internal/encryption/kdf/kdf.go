@@ -0,0 +1,131 @@
+// Package kdf derives encryption keys from passwords. The key derivation function and its
+// cost parameters are stored with the password metadata, so that the key for a password is
+// always derived in the same way, even after the defaults for new passwords change.
+package kdf
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	PBKDF2Type   = "pbkdf2"
+	Argon2idType = "argon2id"
+	ScryptType   = "scrypt"
+)
+
+const (
+	keyLength        = 32
+	pbkdf2Iterations = 100000
+
+	// Defaults for Argon2id are the second recommended option from RFC 9106
+	defaultArgon2idTime    = 3
+	defaultArgon2idMemory  = 64 * 1024 // KiB
+	defaultArgon2idThreads = 4
+
+	defaultScryptN = 32768
+	defaultScryptR = 8
+	defaultScryptP = 1
+)
+
+// Config selects a key derivation function. Time, Memory (in KiB) and Threads are the cost
+// parameters for Argon2id, and N, R and P are the cost parameters for scrypt.
+type Config struct {
+	Type    string `json:"type"`
+	Time    uint32 `json:"time,omitempty"`
+	Memory  uint32 `json:"memory,omitempty"`
+	Threads uint8  `json:"threads,omitempty"`
+	N       int    `json:"n,omitempty"`
+	R       int    `json:"r,omitempty"`
+	P       int    `json:"p,omitempty"`
+}
+
+// WithDefaults fills in the cost parameters that have not been set. New passwords use
+// Argon2id unless another key derivation function has been chosen.
+func (c Config) WithDefaults() Config {
+	if c.Type == "" {
+		c.Type = Argon2idType
+	}
+
+	switch c.Type {
+	case Argon2idType:
+		if c.Time == 0 {
+			c.Time = defaultArgon2idTime
+		}
+		if c.Memory == 0 {
+			c.Memory = defaultArgon2idMemory
+		}
+		if c.Threads == 0 {
+			c.Threads = defaultArgon2idThreads
+		}
+	case ScryptType:
+		if c.N == 0 {
+			c.N = defaultScryptN
+		}
+		if c.R == 0 {
+			c.R = defaultScryptR
+		}
+		if c.P == 0 {
+			c.P = defaultScryptP
+		}
+	}
+
+	return c
+}
+
+// DeriveKey derives a key from the password and salt
+func (c Config) DeriveKey(password string, salt []byte) ([keyLength]byte, error) {
+	var (
+		key     [keyLength]byte
+		derived []byte
+		err     error
+	)
+
+	switch c.Type {
+	case PBKDF2Type:
+		derived = pbkdf2.Key([]byte(password), salt, pbkdf2Iterations, keyLength, sha256.New)
+	case Argon2idType:
+		if c.Time == 0 || c.Memory == 0 || c.Threads == 0 {
+			return key, fmt.Errorf("invalid argon2id parameters: time, memory and threads must be set")
+		}
+		derived = argon2.IDKey([]byte(password), salt, c.Time, c.Memory, c.Threads, keyLength)
+	case ScryptType:
+		derived, err = scrypt.Key([]byte(password), salt, c.N, c.R, c.P, keyLength)
+		if err != nil {
+			return key, fmt.Errorf("invalid scrypt parameters: %w", err)
+		}
+	default:
+		return key, fmt.Errorf("unknown key derivation function %q", c.Type)
+	}
+
+	copy(key[:], derived)
+	return key, nil
+}
+
+// Encode returns the config as it is stored in the password metadata
+func (c Config) Encode() (string, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("error encoding key derivation function: %w", err)
+	}
+	return string(data), nil
+}
+
+// Decode reads the config stored in the password metadata. Passwords that were stored
+// before the key derivation function was recorded have no config, and use PBKDF2.
+func Decode(stored string) (Config, error) {
+	if stored == "" {
+		return Config{Type: PBKDF2Type}, nil
+	}
+
+	var c Config
+	if err := json.Unmarshal([]byte(stored), &c); err != nil {
+		return Config{}, fmt.Errorf("error decoding key derivation function: %w", err)
+	}
+	return c, nil
+}
@@ -0,0 +1,13 @@
+package kdf
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestKDF(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "KDF Suite")
+}
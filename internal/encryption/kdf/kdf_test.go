@@ -0,0 +1,125 @@
+package kdf_test
+
+import (
+	"crypto/sha256"
+
+	"github.com/cloudfoundry/cloud-service-broker/internal/encryption/kdf"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+var _ = Describe("KDF", func() {
+	const password = "averyverygoodpassword"
+	salt := []byte("random-salt-containing-32-bytes!")
+
+	// Small costs keep the tests fast
+	argon2id := kdf.Config{Type: kdf.Argon2idType, Time: 1, Memory: 1024, Threads: 1}
+	scrypt := kdf.Config{Type: kdf.ScryptType, N: 1024, R: 8, P: 1}
+
+	Describe("WithDefaults", func() {
+		It("defaults to Argon2id", func() {
+			Expect(kdf.Config{}.WithDefaults()).To(Equal(kdf.Config{
+				Type:    kdf.Argon2idType,
+				Time:    3,
+				Memory:  65536,
+				Threads: 4,
+			}))
+		})
+
+		It("keeps cost parameters that have been set", func() {
+			Expect(kdf.Config{Type: kdf.Argon2idType, Memory: 1024}.WithDefaults()).To(Equal(kdf.Config{
+				Type:    kdf.Argon2idType,
+				Time:    3,
+				Memory:  1024,
+				Threads: 4,
+			}))
+		})
+
+		It("defaults the scrypt cost parameters", func() {
+			Expect(kdf.Config{Type: kdf.ScryptType}.WithDefaults()).To(Equal(kdf.Config{
+				Type: kdf.ScryptType,
+				N:    32768,
+				R:    8,
+				P:    1,
+			}))
+		})
+
+		It("leaves PBKDF2 alone", func() {
+			Expect(kdf.Config{Type: kdf.PBKDF2Type}.WithDefaults()).To(Equal(kdf.Config{Type: kdf.PBKDF2Type}))
+		})
+	})
+
+	Describe("DeriveKey", func() {
+		It("derives the same key as before for PBKDF2", func() {
+			key, err := kdf.Config{Type: kdf.PBKDF2Type}.DeriveKey(password, salt)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(key[:]).To(Equal(pbkdf2.Key([]byte(password), salt, 100000, 32, sha256.New)))
+		})
+
+		DescribeTable(
+			"derives a repeatable key that depends on the inputs",
+			func(c kdf.Config) {
+				key, err := c.DeriveKey(password, salt)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(key).NotTo(BeZero())
+
+				again, err := c.DeriveKey(password, salt)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(again).To(Equal(key))
+
+				otherPassword, err := c.DeriveKey("adifferentverygoodpassword", salt)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(otherPassword).NotTo(Equal(key))
+
+				otherSalt, err := c.DeriveKey(password, []byte("different-salt-containing-32byte"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(otherSalt).NotTo(Equal(key))
+			},
+			Entry("argon2id", argon2id),
+			Entry("scrypt", scrypt),
+		)
+
+		It("derives different keys with different functions", func() {
+			argon2idKey, err := argon2id.DeriveKey(password, salt)
+			Expect(err).NotTo(HaveOccurred())
+			scryptKey, err := scrypt.DeriveKey(password, salt)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(argon2idKey).NotTo(Equal(scryptKey))
+		})
+
+		It("fails for an unknown function", func() {
+			_, err := kdf.Config{Type: "md5"}.DeriveKey(password, salt)
+			Expect(err).To(MatchError(`unknown key derivation function "md5"`))
+		})
+
+		It("fails when the Argon2id parameters are missing", func() {
+			_, err := kdf.Config{Type: kdf.Argon2idType}.DeriveKey(password, salt)
+			Expect(err).To(MatchError("invalid argon2id parameters: time, memory and threads must be set"))
+		})
+
+		It("fails for invalid scrypt parameters", func() {
+			_, err := kdf.Config{Type: kdf.ScryptType, N: 1000, R: 8, P: 1}.DeriveKey(password, salt)
+			Expect(err).To(MatchError(ContainSubstring("invalid scrypt parameters")))
+		})
+	})
+
+	Describe("Encode and Decode", func() {
+		It("round trips", func() {
+			encoded, err := argon2id.Encode()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(encoded).To(Equal(`{"type":"argon2id","time":1,"memory":1024,"threads":1}`))
+
+			Expect(kdf.Decode(encoded)).To(Equal(argon2id))
+		})
+
+		It("decodes passwords stored before the function was recorded as PBKDF2", func() {
+			Expect(kdf.Decode("")).To(Equal(kdf.Config{Type: kdf.PBKDF2Type}))
+		})
+
+		It("fails for invalid JSON", func() {
+			_, err := kdf.Decode("{")
+			Expect(err).To(MatchError(ContainSubstring("error decoding key derivation function")))
+		})
+	})
+})
@@ -0,0 +1,43 @@
+package kms
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/cloudfoundry/cloud-service-broker/internal/encryption/gcmencryptor"
+)
+
+// NewFile creates a key wrapper that wraps keys with AES-GCM using a 32 byte key that is
+// read, base64 encoded, from the file at the path
+func NewFile(path string) (File, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return File{}, fmt.Errorf("error reading key file: %w", err)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(contents)))
+	switch {
+	case err != nil:
+		return File{}, fmt.Errorf("error decoding key file %q: %w", path, err)
+	case len(key) != 32:
+		return File{}, fmt.Errorf("key file %q must contain a base64 encoded 32 byte key, got %d bytes", path, len(key))
+	}
+
+	var k [32]byte
+	copy(k[:], key)
+	return File{encryptor: gcmencryptor.New(k)}, nil
+}
+
+type File struct {
+	encryptor gcmencryptor.GCMEncryptor
+}
+
+func (f File) WrapKey(key []byte) ([]byte, error) {
+	return f.encryptor.Encrypt(key)
+}
+
+func (f File) UnwrapKey(wrapped []byte) ([]byte, error) {
+	return f.encryptor.Decrypt(wrapped)
+}
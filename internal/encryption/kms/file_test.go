@@ -0,0 +1,70 @@
+package kms_test
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+
+	"github.com/cloudfoundry/cloud-service-broker/internal/encryption/kms"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("File", func() {
+	var keyFile string
+
+	writeKey := func(key string) {
+		Expect(os.WriteFile(keyFile, []byte(key), 0600)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		keyFile = filepath.Join(GinkgoT().TempDir(), "kms.key")
+		writeKey(base64.StdEncoding.EncodeToString([]byte("a-key-of-thirty-two-bytes-length")) + "\n")
+	})
+
+	It("can unwrap what it wrapped", func() {
+		wrapper, err := kms.NewFile(keyFile)
+		Expect(err).NotTo(HaveOccurred())
+
+		wrapped, err := wrapper.WrapKey([]byte("data key"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(wrapped).NotTo(ContainSubstring("data key"))
+
+		unwrapped, err := wrapper.UnwrapKey(wrapped)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(unwrapped).To(Equal([]byte("data key")))
+	})
+
+	It("cannot unwrap keys wrapped with a different key", func() {
+		wrapper, err := kms.NewFile(keyFile)
+		Expect(err).NotTo(HaveOccurred())
+		wrapped, err := wrapper.WrapKey([]byte("data key"))
+		Expect(err).NotTo(HaveOccurred())
+
+		writeKey(base64.StdEncoding.EncodeToString([]byte("a-different-key-with-thirty-two!")))
+		other, err := kms.NewFile(keyFile)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = other.UnwrapKey(wrapped)
+		Expect(err).To(MatchError("cipher: message authentication failed"))
+	})
+
+	It("fails when the key file does not exist", func() {
+		_, err := kms.NewFile(filepath.Join(filepath.Dir(keyFile), "missing"))
+		Expect(err).To(MatchError(ContainSubstring("error reading key file")))
+	})
+
+	It("fails when the key is not base64 encoded", func() {
+		writeKey("not base64!")
+
+		_, err := kms.NewFile(keyFile)
+		Expect(err).To(MatchError(ContainSubstring("error decoding key file")))
+	})
+
+	It("fails when the key is the wrong length", func() {
+		writeKey(base64.StdEncoding.EncodeToString([]byte("too short")))
+
+		_, err := kms.NewFile(keyFile)
+		Expect(err).To(MatchError(ContainSubstring("must contain a base64 encoded 32 byte key, got 9 bytes")))
+	})
+})
@@ -0,0 +1,40 @@
+// Package kms provides key wrappers that are backed by external key management services,
+// for use with the envelope encryptor
+package kms
+
+import (
+	"fmt"
+
+	"github.com/cloudfoundry/cloud-service-broker/internal/encryption/envelopeencryptor"
+)
+
+const (
+	// VaultTransitType wraps keys with the HashiCorp Vault Transit secrets engine
+	VaultTransitType = "vault-transit"
+	// FileType wraps keys with a key read from a local file. It is intended for testing,
+	// as the key is as exposed as an encryption password would be.
+	FileType = "file"
+)
+
+// Config describes the key management service that wraps data keys.
+// The fields that are required depend on the Type.
+type Config struct {
+	Type      string
+	Address   string
+	Token     string
+	Namespace string
+	Mount     string
+	KeyName   string
+	Path      string
+}
+
+func New(c Config) (envelopeencryptor.KeyWrapper, error) {
+	switch c.Type {
+	case VaultTransitType:
+		return NewVaultTransit(c.Address, c.Token, c.Namespace, c.Mount, c.KeyName), nil
+	case FileType:
+		return NewFile(c.Path)
+	default:
+		return nil, fmt.Errorf("unknown key management service type %q", c.Type)
+	}
+}
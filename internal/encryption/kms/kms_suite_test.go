@@ -0,0 +1,13 @@
+package kms_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestKMS(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "KMS Suite")
+}
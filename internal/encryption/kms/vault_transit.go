@@ -0,0 +1,120 @@
+package kms
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const defaultVaultTransitMount = "transit"
+
+// NewVaultTransit creates a key wrapper that wraps keys with the named key of the
+// HashiCorp Vault Transit secrets engine. The key never leaves Vault.
+func NewVaultTransit(address, token, namespace, mount, keyName string) VaultTransit {
+	if mount == "" {
+		mount = defaultVaultTransitMount
+	}
+
+	return VaultTransit{
+		address:   strings.TrimSuffix(address, "/"),
+		token:     token,
+		namespace: namespace,
+		mount:     strings.Trim(mount, "/"),
+		keyName:   keyName,
+		client:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+type VaultTransit struct {
+	address   string
+	token     string
+	namespace string
+	mount     string
+	keyName   string
+	client    *http.Client
+}
+
+// WrapKey returns the Vault ciphertext of the key, for example "vault:v1:..."
+func (v VaultTransit) WrapKey(key []byte) ([]byte, error) {
+	var receiver struct {
+		Ciphertext string `json:"ciphertext"`
+	}
+	request := map[string]string{"plaintext": base64.StdEncoding.EncodeToString(key)}
+	if err := v.call("encrypt", request, &receiver); err != nil {
+		return nil, err
+	}
+
+	return []byte(receiver.Ciphertext), nil
+}
+
+func (v VaultTransit) UnwrapKey(wrapped []byte) ([]byte, error) {
+	var receiver struct {
+		Plaintext string `json:"plaintext"`
+	}
+	request := map[string]string{"ciphertext": string(wrapped)}
+	if err := v.call("decrypt", request, &receiver); err != nil {
+		return nil, err
+	}
+
+	key, err := base64.StdEncoding.DecodeString(receiver.Plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding Vault Transit plaintext: %w", err)
+	}
+
+	return key, nil
+}
+
+func (v VaultTransit) call(operation string, request interface{}, data interface{}) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/v1/%s/%s/%s", v.address, v.mount, operation, v.keyName)
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Vault-Token", v.token)
+	if v.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.namespace)
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error calling Vault Transit %s: %w", operation, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading Vault Transit %s response: %w", operation, err)
+	}
+
+	var receiver struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []string        `json:"errors"`
+	}
+	if err := json.Unmarshal(respBody, &receiver); err != nil && resp.StatusCode == http.StatusOK {
+		return fmt.Errorf("error parsing Vault Transit %s response: %w", operation, err)
+	}
+
+	switch {
+	case resp.StatusCode != http.StatusOK && len(receiver.Errors) > 0:
+		return fmt.Errorf("Vault Transit %s failed with status %d: %s", operation, resp.StatusCode, strings.Join(receiver.Errors, "; "))
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("Vault Transit %s failed with status %d", operation, resp.StatusCode)
+	}
+
+	if err := json.Unmarshal(receiver.Data, data); err != nil {
+		return fmt.Errorf("error parsing Vault Transit %s response data: %w", operation, err)
+	}
+
+	return nil
+}
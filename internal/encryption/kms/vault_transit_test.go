@@ -0,0 +1,98 @@
+package kms_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/cloudfoundry/cloud-service-broker/internal/encryption/kms"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("VaultTransit", func() {
+	var (
+		server   *httptest.Server
+		requests []*http.Request
+	)
+
+	BeforeEach(func() {
+		requests = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r)
+
+			if r.Header.Get("X-Vault-Token") != "s.token" {
+				w.WriteHeader(http.StatusForbidden)
+				fmt.Fprint(w, `{"errors":["permission denied"]}`)
+				return
+			}
+
+			var body map[string]string
+			Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+
+			// A stand-in for Vault that "encrypts" by reversing the base64 plaintext
+			switch r.URL.Path {
+			case "/v1/transit/encrypt/csb":
+				fmt.Fprintf(w, `{"data":{"ciphertext":"vault:v1:%s"}}`, reverse(body["plaintext"]))
+			case "/v1/transit/decrypt/csb":
+				fmt.Fprintf(w, `{"data":{"plaintext":"%s"}}`, reverse(strings.TrimPrefix(body["ciphertext"], "vault:v1:")))
+			default:
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"errors":["encryption key not found"]}`)
+			}
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("wraps and unwraps keys with Vault", func() {
+		wrapper := kms.NewVaultTransit(server.URL+"/", "s.token", "team", "", "csb")
+
+		wrapped, err := wrapper.WrapKey([]byte("data key"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(wrapped)).To(Equal("vault:v1:" + reverse(base64.StdEncoding.EncodeToString([]byte("data key")))))
+
+		unwrapped, err := wrapper.UnwrapKey(wrapped)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(unwrapped).To(Equal([]byte("data key")))
+
+		Expect(requests).To(HaveLen(2))
+		Expect(requests[0].Method).To(Equal(http.MethodPost))
+		Expect(requests[0].Header.Get("X-Vault-Namespace")).To(Equal("team"))
+	})
+
+	It("uses the configured mount", func() {
+		wrapper := kms.NewVaultTransit(server.URL, "s.token", "", "csb-transit", "csb")
+
+		_, err := wrapper.WrapKey([]byte("data key"))
+		Expect(err).To(MatchError("Vault Transit encrypt failed with status 400: encryption key not found"))
+		Expect(requests[0].URL.Path).To(Equal("/v1/csb-transit/encrypt/csb"))
+	})
+
+	It("reports errors from Vault", func() {
+		wrapper := kms.NewVaultTransit(server.URL, "s.bad-token", "", "", "csb")
+
+		_, err := wrapper.UnwrapKey([]byte("vault:v1:abc"))
+		Expect(err).To(MatchError("Vault Transit decrypt failed with status 403: permission denied"))
+	})
+
+	It("reports errors connecting to Vault", func() {
+		wrapper := kms.NewVaultTransit("http://127.0.0.1:1", "s.token", "", "", "csb")
+
+		_, err := wrapper.WrapKey([]byte("data key"))
+		Expect(err).To(MatchError(ContainSubstring("error calling Vault Transit encrypt")))
+	})
+})
+
+func reverse(s string) string {
+	r := []rune(s)
+	for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+		r[i], r[j] = r[j], r[i]
+	}
+	return string(r)
+}
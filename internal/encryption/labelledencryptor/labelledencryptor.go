@@ -0,0 +1,112 @@
+// Package labelledencryptor wraps encrypted data in an envelope with a header that carries the
+// label of the password that encrypted it, so that the right password can be chosen to decrypt it
+package labelledencryptor
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/cloudfoundry/cloud-service-broker/internal/storage"
+)
+
+const version = 1
+
+// magic starts the header. It starts with a zero byte so that it cannot be mistaken for
+// unencrypted JSON, and is long enough to make a clash with un-headered ciphertext unlikely.
+var magic = []byte("\x00csb")
+
+// MissingKeyError is returned when data was encrypted with a password that is not configured
+type MissingKeyError struct {
+	Label string
+}
+
+func (e *MissingKeyError) Error() string {
+	return fmt.Sprintf("data was encrypted with the password labelled %q, which is not configured", e.Label)
+}
+
+func New(label string, encryptor storage.Encryptor) LabelledEncryptor {
+	if len(label) == 0 || len(label) > 0xff {
+		panic("invalid label for labelled encryptor")
+	}
+
+	return LabelledEncryptor{
+		label:     label,
+		encryptor: encryptor,
+	}
+}
+
+type LabelledEncryptor struct {
+	label     string
+	encryptor storage.Encryptor
+}
+
+func (e LabelledEncryptor) Label() string {
+	return e.label
+}
+
+// Encrypt returns the header, which is the magic bytes, the version, the length of the label and
+// the label, followed by the data encrypted by the wrapped encryptor
+func (e LabelledEncryptor) Encrypt(plaintext []byte) ([]byte, error) {
+	sealed, err := e.encryptor.Encrypt(plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]byte, 0, len(magic)+2+len(e.label)+len(sealed))
+	result = append(result, magic...)
+	result = append(result, version, byte(len(e.label)))
+	result = append(result, e.label...)
+	return append(result, sealed...), nil
+}
+
+// Decrypt decrypts data with a header when it has the label of the encryptor, and
+// decrypts data without a header, which was encrypted before headers were added
+func (e LabelledEncryptor) Decrypt(ciphertext []byte) ([]byte, error) {
+	label, sealed, ok, err := Split(ciphertext)
+	switch {
+	case err != nil:
+		return nil, err
+	case !ok:
+		return e.encryptor.Decrypt(ciphertext)
+	case label != e.label:
+		return nil, &MissingKeyError{Label: label}
+	default:
+		return e.encryptor.Decrypt(sealed)
+	}
+}
+
+// KeyLabel returns the label of the password that encrypted the data
+func (e LabelledEncryptor) KeyLabel(ciphertext []byte) (string, bool) {
+	return KeyLabel(ciphertext)
+}
+
+// KeyLabel returns the label from the header of the data, and false if the data has no header
+func KeyLabel(ciphertext []byte) (string, bool) {
+	label, _, ok, err := Split(ciphertext)
+	return label, ok && err == nil
+}
+
+// Split returns the label from the header of the data and the data without the header. It returns
+// false if the data has no header, and an error if the data has a header that cannot be parsed.
+func Split(ciphertext []byte) (string, []byte, bool, error) {
+	if !bytes.HasPrefix(ciphertext, magic) {
+		return "", nil, false, nil
+	}
+
+	rest := ciphertext[len(magic):]
+	switch {
+	case len(rest) < 2:
+		return "", nil, false, errors.New("malformed ciphertext header")
+	case rest[0] != version:
+		return "", nil, false, fmt.Errorf("unsupported ciphertext version %d", rest[0])
+	}
+
+	length := int(rest[1])
+	rest = rest[2:]
+	if length == 0 || len(rest) < length {
+		return "", nil, false, errors.New("malformed ciphertext header")
+	}
+
+	return string(rest[:length]), rest[length:], true, nil
+}
@@ -0,0 +1,13 @@
+package labelledencryptor
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestLabelledEncryptor(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Labelled Encryptor Suite")
+}
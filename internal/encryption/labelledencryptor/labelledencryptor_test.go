@@ -0,0 +1,107 @@
+package labelledencryptor_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry/cloud-service-broker/internal/encryption/labelledencryptor"
+	"github.com/cloudfoundry/cloud-service-broker/internal/storage/storagefakes"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LabelledEncryptor", func() {
+	var (
+		inner     *storagefakes.FakeEncryptor
+		encryptor labelledencryptor.LabelledEncryptor
+	)
+
+	BeforeEach(func() {
+		inner = &storagefakes.FakeEncryptor{}
+		inner.EncryptReturns([]byte("sealed"), nil)
+		inner.DecryptReturns([]byte("opened"), nil)
+		encryptor = labelledencryptor.New("barney", inner)
+	})
+
+	It("has a label", func() {
+		Expect(encryptor.Label()).To(Equal("barney"))
+	})
+
+	Describe("Encrypt", func() {
+		It("adds a header with the label to the encrypted data", func() {
+			encrypted, err := encryptor.Encrypt([]byte("plain"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(encrypted).To(Equal([]byte("\x00csb\x01\x06barneysealed")))
+
+			Expect(inner.EncryptCallCount()).To(Equal(1))
+			Expect(inner.EncryptArgsForCall(0)).To(Equal([]byte("plain")))
+		})
+
+		It("returns encryption errors", func() {
+			inner.EncryptReturns(nil, errors.New("boom"))
+
+			_, err := encryptor.Encrypt([]byte("plain"))
+			Expect(err).To(MatchError("boom"))
+		})
+	})
+
+	Describe("Decrypt", func() {
+		It("decrypts data with the same label", func() {
+			decrypted, err := encryptor.Decrypt([]byte("\x00csb\x01\x06barneysealed"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(decrypted).To(Equal([]byte("opened")))
+
+			Expect(inner.DecryptCallCount()).To(Equal(1))
+			Expect(inner.DecryptArgsForCall(0)).To(Equal([]byte("sealed")))
+		})
+
+		It("decrypts data that was encrypted before labels were added", func() {
+			decrypted, err := encryptor.Decrypt([]byte("legacy"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(decrypted).To(Equal([]byte("opened")))
+
+			Expect(inner.DecryptArgsForCall(0)).To(Equal([]byte("legacy")))
+		})
+
+		It("names the label when the data was encrypted with a different password", func() {
+			_, err := encryptor.Decrypt([]byte("\x00csb\x01\x04fredsealed"))
+			Expect(err).To(MatchError(`data was encrypted with the password labelled "fred", which is not configured`))
+			Expect(err).To(BeAssignableToTypeOf(&labelledencryptor.MissingKeyError{}))
+			Expect(inner.DecryptCallCount()).To(BeZero())
+		})
+
+		It("fails for an unsupported version", func() {
+			_, err := encryptor.Decrypt([]byte("\x00csb\x02\x06barneysealed"))
+			Expect(err).To(MatchError("unsupported ciphertext version 2"))
+		})
+
+		DescribeTable(
+			"malformed headers",
+			func(data string) {
+				_, err := encryptor.Decrypt([]byte(data))
+				Expect(err).To(MatchError("malformed ciphertext header"))
+			},
+			Entry("no version", "\x00csb"),
+			Entry("no label length", "\x00csb\x01"),
+			Entry("empty label", "\x00csb\x01\x00sealed"),
+			Entry("short label", "\x00csb\x01\x10bar"),
+		)
+	})
+
+	Describe("KeyLabel", func() {
+		It("returns the label from the header", func() {
+			label, ok := labelledencryptor.KeyLabel([]byte("\x00csb\x01\x04fredsealed"))
+			Expect(ok).To(BeTrue())
+			Expect(label).To(Equal("fred"))
+		})
+
+		It("returns false when there is no header", func() {
+			label, ok := labelledencryptor.KeyLabel([]byte(`{"foo":"bar"}`))
+			Expect(ok).To(BeFalse())
+			Expect(label).To(BeEmpty())
+		})
+	})
+
+	It("panics for an invalid label", func() {
+		Expect(func() { labelledencryptor.New("", inner) }).To(Panic())
+	})
+})
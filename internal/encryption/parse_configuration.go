@@ -5,6 +5,7 @@ import (
 
 	"github.com/cloudfoundry/cloud-service-broker/dbservice/models"
 	"github.com/cloudfoundry/cloud-service-broker/internal/encryption/compoundencryptor"
+	"github.com/cloudfoundry/cloud-service-broker/internal/encryption/labelledencryptor"
 	"github.com/cloudfoundry/cloud-service-broker/internal/encryption/noopencryptor"
 	"github.com/cloudfoundry/cloud-service-broker/internal/encryption/passwordcombiner"
 	"github.com/cloudfoundry/cloud-service-broker/internal/encryption/passwordparser"
@@ -36,6 +37,9 @@ func ParseConfiguration(db *gorm.DB, enabled bool, passwords string) (Configurat
 	if err != nil {
 		return Configuration{}, err
 	}
+	for i := range combined {
+		combined[i].Encryptor = labelledencryptor.New(combined[i].Label, combined[i].Encryptor)
+	}
 
 	parsedPrimary, parsedPrimaryOK := combined.ConfiguredPrimary()
 	storedPrimary, storedPrimaryOK := combined.StoredPrimary()
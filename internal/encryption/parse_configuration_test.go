@@ -1,10 +1,15 @@
 package encryption_test
 
 import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+
 	"github.com/cloudfoundry/cloud-service-broker/dbservice/models"
 	"github.com/cloudfoundry/cloud-service-broker/internal/encryption"
 	"github.com/cloudfoundry/cloud-service-broker/internal/encryption/compoundencryptor"
-	"github.com/cloudfoundry/cloud-service-broker/internal/encryption/gcmencryptor"
+	"github.com/cloudfoundry/cloud-service-broker/internal/encryption/labelledencryptor"
 	"github.com/cloudfoundry/cloud-service-broker/internal/encryption/noopencryptor"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -107,7 +112,7 @@ var _ = Describe("ParseConfiguration()", func() {
 				quzAsEncryptedBlob := []byte{59, 21, 133, 191, 122, 237, 117, 45, 137, 121, 21, 128, 28, 100, 131, 163, 91, 252, 73, 20, 74, 104, 237, 20, 103, 53, 207, 52, 154, 189, 66}
 				config, err := encryption.ParseConfiguration(db, true, password)
 				Expect(err).NotTo(HaveOccurred())
-				Expect(config.Encryptor).To(BeAssignableToTypeOf(gcmencryptor.GCMEncryptor{}))
+				Expect(config.Encryptor).To(BeAssignableToTypeOf(labelledencryptor.LabelledEncryptor{}))
 				Expect(config.Changed).To(BeFalse())
 				Expect(config.RotationEncryptor).To(BeNil())
 				Expect(config.ConfiguredPrimaryLabel).To(Equal("barfoo"))
@@ -123,7 +128,7 @@ var _ = Describe("ParseConfiguration()", func() {
 			It("returns an encryptor", func() {
 				config, err := encryption.ParseConfiguration(db, true, password)
 				Expect(err).NotTo(HaveOccurred())
-				Expect(config.Encryptor).To(BeAssignableToTypeOf(gcmencryptor.GCMEncryptor{}))
+				Expect(config.Encryptor).To(BeAssignableToTypeOf(labelledencryptor.LabelledEncryptor{}))
 				Expect(config.Changed).To(BeTrue())
 				Expect(config.RotationEncryptor).To(BeAssignableToTypeOf(compoundencryptor.CompoundEncryptor{}))
 				Expect(config.ConfiguredPrimaryLabel).To(Equal("barfoo"))
@@ -167,7 +172,7 @@ var _ = Describe("ParseConfiguration()", func() {
 
 					config, err := encryption.ParseConfiguration(db, true, password)
 					Expect(err).NotTo(HaveOccurred())
-					Expect(config.Encryptor).To(BeAssignableToTypeOf(gcmencryptor.GCMEncryptor{}))
+					Expect(config.Encryptor).To(BeAssignableToTypeOf(labelledencryptor.LabelledEncryptor{}))
 					Expect(config.Changed).To(BeTrue())
 					Expect(config.RotationEncryptor).To(BeAssignableToTypeOf(compoundencryptor.CompoundEncryptor{}))
 					Expect(config.ConfiguredPrimaryLabel).To(Equal("supernew"))
@@ -195,6 +200,46 @@ var _ = Describe("ParseConfiguration()", func() {
 				})
 			})
 
+			Context("new primary held by a key management service", func() {
+				It("returns an envelope encryptor and a rotation encryptor", func() {
+					keyFile := filepath.Join(GinkgoT().TempDir(), "kms.key")
+					Expect(os.WriteFile(keyFile, []byte(base64.StdEncoding.EncodeToString([]byte("a-key-of-thirty-two-bytes-length"))), 0600)).To(Succeed())
+					password := fmt.Sprintf(`[{"primary":false,"label":"barfoo","password":{"secret":"averyverygoodpassword"}},{"label":"kmskey","kms":{"type":"file","path":%q},"primary":true}]`, keyFile)
+
+					config, err := encryption.ParseConfiguration(db, true, password)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(config.Encryptor).To(BeAssignableToTypeOf(labelledencryptor.LabelledEncryptor{}))
+					Expect(config.Encryptor.(labelledencryptor.LabelledEncryptor).Label()).To(Equal("kmskey"))
+					Expect(config.Changed).To(BeTrue())
+					Expect(config.ConfiguredPrimaryLabel).To(Equal("kmskey"))
+					Expect(config.StoredPrimaryLabel).To(Equal("barfoo"))
+
+					By("being able to decrypt values encrypted with the envelope encryptor using the rotation encryptor")
+					encrypted, err := config.RotationEncryptor.Encrypt([]byte("foo"))
+					Expect(err).NotTo(HaveOccurred())
+					decrypted, err := config.Encryptor.Decrypt(encrypted)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(decrypted).To(Equal([]byte("foo")))
+
+					By("labelling the envelope with the key management service key")
+					label, sealed, ok, err := labelledencryptor.Split(encrypted)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(ok).To(BeTrue())
+					Expect(label).To(Equal("kmskey"))
+					Expect(string(sealed)).To(HavePrefix("csbenv1:"))
+
+					By("being able use rotation encryptor to decrypt a value encrypted with the stored primary")
+					decrypted, err = config.RotationEncryptor.Decrypt(encryptedCanary)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(decrypted).To(Equal([]byte("canary value")))
+
+					By("checking the key management service key on the next start")
+					Expect(os.WriteFile(keyFile, []byte(base64.StdEncoding.EncodeToString([]byte("a-different-key-with-thirty-two!"))), 0600)).To(Succeed())
+					_, err = encryption.ParseConfiguration(db, true, password)
+					Expect(err).To(MatchError(ContainSubstring("error unwrapping data key")))
+				})
+			})
+
 			Context("previous primary value not supplied", func() {
 				It("returns an error", func() {
 					const password = `[{"label":"supernew","password":{"secret":"supercoolnewpassword"},"primary":true}]`
@@ -225,7 +270,7 @@ var _ = Describe("ParseConfiguration()", func() {
 
 					config, err := encryption.ParseConfiguration(db, true, password)
 					Expect(err).NotTo(HaveOccurred())
-					Expect(config.Encryptor).To(BeAssignableToTypeOf(gcmencryptor.GCMEncryptor{}))
+					Expect(config.Encryptor).To(BeAssignableToTypeOf(labelledencryptor.LabelledEncryptor{}))
 					Expect(config.Changed).To(BeTrue())
 					Expect(config.RotationEncryptor).To(BeAssignableToTypeOf(compoundencryptor.CompoundEncryptor{}))
 					Expect(config.ConfiguredPrimaryLabel).To(Equal("barfoo"))
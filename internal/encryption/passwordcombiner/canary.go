@@ -3,7 +3,7 @@ package passwordcombiner
 import (
 	"fmt"
 
-	"github.com/cloudfoundry/cloud-service-broker/internal/encryption/gcmencryptor"
+	"github.com/cloudfoundry/cloud-service-broker/internal/storage"
 )
 
 // CanaryInput is the value that is encrypted with the key and stored in the database
@@ -11,11 +11,11 @@ import (
 // possible to create a rainbow table for this.
 const CanaryInput = "canary value"
 
-func encryptCanary(encryptor gcmencryptor.GCMEncryptor) ([]byte, error) {
+func encryptCanary(encryptor storage.Encryptor) ([]byte, error) {
 	return encryptor.Encrypt([]byte(CanaryInput))
 }
 
-func decryptCanary(encryptor gcmencryptor.GCMEncryptor, canary []byte, label string) error {
+func decryptCanary(encryptor storage.Encryptor, canary []byte, label string) error {
 	_, err := encryptor.Decrypt(canary)
 	switch {
 	case err == nil:
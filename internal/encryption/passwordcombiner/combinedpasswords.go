@@ -1,12 +1,12 @@
 package passwordcombiner
 
-import "github.com/cloudfoundry/cloud-service-broker/internal/encryption/gcmencryptor"
+import "github.com/cloudfoundry/cloud-service-broker/internal/storage"
 
 type CombinedPassword struct {
 	Label             string
 	Secret            string
 	Salt              []byte
-	Encryptor         gcmencryptor.GCMEncryptor
+	Encryptor         storage.Encryptor
 	configuredPrimary bool
 	storedPrimary     bool
 }
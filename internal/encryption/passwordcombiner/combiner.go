@@ -1,14 +1,16 @@
 package passwordcombiner
 
 import (
-	"crypto/sha256"
 	"errors"
 	"fmt"
 
 	"github.com/cloudfoundry/cloud-service-broker/dbservice/models"
+	"github.com/cloudfoundry/cloud-service-broker/internal/encryption/envelopeencryptor"
 	"github.com/cloudfoundry/cloud-service-broker/internal/encryption/gcmencryptor"
+	"github.com/cloudfoundry/cloud-service-broker/internal/encryption/kdf"
+	"github.com/cloudfoundry/cloud-service-broker/internal/encryption/kms"
 	"github.com/cloudfoundry/cloud-service-broker/internal/encryption/passwordparser"
-	"golang.org/x/crypto/pbkdf2"
+	"github.com/cloudfoundry/cloud-service-broker/internal/storage"
 	"gorm.io/gorm"
 )
 
@@ -52,7 +54,23 @@ func saveNewPasswordMetadata(db *gorm.DB, p passwordparser.PasswordEntry) (Combi
 		return CombinedPassword{}, err
 	}
 
-	e := encryptor(p.Secret, salt)
+	// Keys for new passwords are derived with the configured key derivation function, which
+	// is stored so that the key is derived in the same way when the defaults change
+	var (
+		k       kdf.Config
+		encoded string
+	)
+	if p.KMS.Type == "" {
+		k = p.KDF.WithDefaults()
+		if encoded, err = k.Encode(); err != nil {
+			return CombinedPassword{}, err
+		}
+	}
+
+	e, err := encryptor(p, salt, k)
+	if err != nil {
+		return CombinedPassword{}, err
+	}
 
 	canary, err := encryptCanary(e)
 	if err != nil {
@@ -64,6 +82,7 @@ func saveNewPasswordMetadata(db *gorm.DB, p passwordparser.PasswordEntry) (Combi
 		Salt:    salt,
 		Canary:  canary,
 		Primary: false, // Primary updated after successful rotation
+		KDF:     encoded,
 	}).Error
 	if err != nil {
 		return CombinedPassword{}, err
@@ -79,7 +98,15 @@ func saveNewPasswordMetadata(db *gorm.DB, p passwordparser.PasswordEntry) (Combi
 }
 
 func mergeWithStoredMetadata(s models.PasswordMetadata, p passwordparser.PasswordEntry) (CombinedPassword, error) {
-	e := encryptor(p.Secret, s.Salt)
+	k, err := kdf.Decode(s.KDF)
+	if err != nil {
+		return CombinedPassword{}, fmt.Errorf("error reading key derivation function for password labeled %q: %w", p.Label, err)
+	}
+
+	e, err := encryptor(p, s.Salt, k)
+	if err != nil {
+		return CombinedPassword{}, err
+	}
 
 	if err := decryptCanary(e, s.Canary, p.Label); err != nil {
 		return CombinedPassword{}, err
@@ -113,7 +140,23 @@ func storedWithPrimary(stored []models.PasswordMetadata) (map[string]models.Pass
 	return result, primary, nil
 }
 
-func encryptor(secret string, salt []byte) gcmencryptor.GCMEncryptor {
+// encryptor creates the encryptor for a password entry. Keys for passwords are derived from the
+// password and the salt with the key derivation function, and entries that refer to a key management service use envelope
+// encryption, with data keys wrapped by the key management service.
+func encryptor(p passwordparser.PasswordEntry, salt []byte, k kdf.Config) (storage.Encryptor, error) {
+	if p.KMS.Type == "" {
+		return passwordEncryptor(p.Secret, salt, k)
+	}
+
+	wrapper, err := kms.New(p.KMS)
+	if err != nil {
+		return nil, fmt.Errorf("error configuring key management service for password labeled %q: %w", p.Label, err)
+	}
+
+	return envelopeencryptor.New(wrapper), nil
+}
+
+func passwordEncryptor(secret string, salt []byte, k kdf.Config) (gcmencryptor.GCMEncryptor, error) {
 	switch {
 	case len(secret) < 20:
 		panic("invalid secret complexity for key generation")
@@ -121,7 +164,9 @@ func encryptor(secret string, salt []byte) gcmencryptor.GCMEncryptor {
 		panic("invalid salt complexity for key generation")
 	}
 
-	var key [32]byte
-	copy(key[:], pbkdf2.Key([]byte(secret), salt, 100000, 32, sha256.New))
-	return gcmencryptor.New(key)
+	key, err := k.DeriveKey(secret, salt)
+	if err != nil {
+		return gcmencryptor.GCMEncryptor{}, fmt.Errorf("error deriving key: %w", err)
+	}
+	return gcmencryptor.New(key), nil
 }
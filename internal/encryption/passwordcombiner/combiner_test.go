@@ -3,6 +3,7 @@ package passwordcombiner_test
 import (
 	"github.com/cloudfoundry/cloud-service-broker/dbservice/models"
 	"github.com/cloudfoundry/cloud-service-broker/internal/encryption/gcmencryptor"
+	"github.com/cloudfoundry/cloud-service-broker/internal/encryption/kdf"
 	"github.com/cloudfoundry/cloud-service-broker/internal/encryption/passwordcombiner"
 	"github.com/cloudfoundry/cloud-service-broker/internal/encryption/passwordparser"
 	. "github.com/onsi/ginkgo/v2"
@@ -122,6 +123,37 @@ var _ = Describe("Combine()", func() {
 			Expect(stored[2].Salt).To(HaveLen(32))
 			Expect(stored[2].Primary).To(BeFalse())
 		})
+
+		It("derives keys for new passwords with Argon2id by default", func() {
+			password := []passwordparser.PasswordEntry{
+				{
+					Label:  "firstone",
+					Secret: "averyverygoodpassword",
+				},
+			}
+			_, err := passwordcombiner.Combine(db, password, []models.PasswordMetadata{})
+			Expect(err).NotTo(HaveOccurred())
+
+			var stored models.PasswordMetadata
+			Expect(db.First(&stored).Error).NotTo(HaveOccurred())
+			Expect(stored.KDF).To(Equal(`{"type":"argon2id","time":3,"memory":65536,"threads":4}`))
+		})
+
+		It("derives keys for new passwords with the configured key derivation function", func() {
+			password := []passwordparser.PasswordEntry{
+				{
+					Label:  "firstone",
+					Secret: "averyverygoodpassword",
+					KDF:    kdf.Config{Type: kdf.ScryptType, N: 1024},
+				},
+			}
+			_, err := passwordcombiner.Combine(db, password, []models.PasswordMetadata{})
+			Expect(err).NotTo(HaveOccurred())
+
+			var stored models.PasswordMetadata
+			Expect(db.First(&stored).Error).NotTo(HaveOccurred())
+			Expect(stored.KDF).To(Equal(`{"type":"scrypt","n":1024,"r":8,"p":1}`))
+		})
 	})
 
 	Context("stored metadata", func() {
@@ -237,6 +269,54 @@ var _ = Describe("Combine()", func() {
 			Expect(stored.Primary).To(BeFalse())
 		})
 
+		It("uses PBKDF2 for passwords stored before the key derivation function was recorded", func() {
+			storedMetadata[0].KDF = ""
+			password := []passwordparser.PasswordEntry{
+				{
+					Label:  "barfoo",
+					Secret: "averyverygoodpassword",
+					KDF:    kdf.Config{Type: kdf.ScryptType},
+				},
+			}
+
+			_, err := passwordcombiner.Combine(db, password, storedMetadata)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("uses the stored key derivation function rather than the configured one", func() {
+			password := []passwordparser.PasswordEntry{
+				{
+					Label:  "firstone",
+					Secret: "averyverygoodpassword",
+					KDF:    kdf.Config{Type: kdf.ScryptType, N: 1024},
+				},
+			}
+			_, err := passwordcombiner.Combine(db, password, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			var stored []models.PasswordMetadata
+			Expect(db.Find(&stored).Error).NotTo(HaveOccurred())
+
+			password[0].KDF = kdf.Config{Type: kdf.Argon2idType, Time: 1, Memory: 1024, Threads: 1}
+			combined, err := passwordcombiner.Combine(db, password, stored)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(combined).To(HaveLen(1))
+		})
+
+		When("the stored key derivation function cannot be read", func() {
+			It("returns an error", func() {
+				storedMetadata[0].KDF = "{"
+				password := []passwordparser.PasswordEntry{
+					{
+						Label:  "barfoo",
+						Secret: "averyverygoodpassword",
+					},
+				}
+				_, err := passwordcombiner.Combine(db, password, storedMetadata)
+				Expect(err).To(MatchError(ContainSubstring(`error reading key derivation function for password labeled "barfoo"`)))
+			})
+		})
+
 		When("password changed value", func() {
 			It("returns an error", func() {
 				password := []passwordparser.PasswordEntry{
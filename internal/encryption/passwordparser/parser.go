@@ -4,12 +4,19 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/cloudfoundry/cloud-service-broker/internal/encryption/kdf"
+	"github.com/cloudfoundry/cloud-service-broker/internal/encryption/kms"
 	"github.com/cloudfoundry/cloud-service-broker/pkg/validation"
 )
 
+// PasswordEntry is either a password from which a key is derived, or a reference to a
+// key held by an external key management service when KMS.Type is set. KDF selects how
+// the key is derived from a new password.
 type PasswordEntry struct {
 	Label   string
 	Secret  string
+	KDF     kdf.Config
+	KMS     kms.Config
 	Primary bool
 }
 
@@ -40,8 +47,18 @@ type receiver []struct {
 	Label    string `json:"label"`
 	Primary  bool   `json:"primary"`
 	Password struct {
-		Secret string `json:"secret"`
+		Secret string     `json:"secret"`
+		KDF    kdf.Config `json:"kdf"`
 	} `json:"password"`
+	KMS struct {
+		Type      string `json:"type"`
+		Address   string `json:"address"`
+		Token     string `json:"token"`
+		Namespace string `json:"namespace"`
+		Mount     string `json:"mount"`
+		KeyName   string `json:"key_name"`
+		Path      string `json:"path"`
+	} `json:"kms"`
 }
 
 func convert(r receiver) []PasswordEntry {
@@ -50,6 +67,8 @@ func convert(r receiver) []PasswordEntry {
 		result = append(result, PasswordEntry{
 			Label:   p.Label,
 			Secret:  p.Password.Secret,
+			KDF:     p.Password.KDF,
+			KMS:     kms.Config(p.KMS),
 			Primary: p.Primary,
 		})
 	}
@@ -65,7 +84,7 @@ func validate(passwordEntries []PasswordEntry) (errs *validation.FieldError) {
 			primaries++
 		}
 		errs = errs.Also(
-			validation.ErrIfOutsideLength(p.Secret, "secret.password", 20, 1024).ViaIndex(i),
+			validateKey(p).ViaIndex(i),
 			validation.ErrIfOutsideLength(p.Label, "label", 5, 20).ViaIndex(i),
 			validation.ErrIfDuplicate(p.Label, "label", labels).ViaIndex(i),
 		)
@@ -81,3 +100,38 @@ func validate(passwordEntries []PasswordEntry) (errs *validation.FieldError) {
 		})
 	}
 }
+
+func validateKey(p PasswordEntry) *validation.FieldError {
+	switch {
+	case p.KMS.Type == "":
+		return validation.ErrIfOutsideLength(p.Secret, "secret.password", 20, 1024).Also(validateKDF(p.KDF))
+	case p.Secret != "", p.KDF != kdf.Config{}:
+		return validation.ErrMultipleOneOf("password", "kms")
+	}
+
+	switch p.KMS.Type {
+	case kms.VaultTransitType:
+		return validation.ErrIfNotURL(p.KMS.Address, "kms.address").Also(
+			validation.ErrIfBlank(p.KMS.Token, "kms.token"),
+			validation.ErrIfBlank(p.KMS.KeyName, "kms.key_name"),
+		)
+	case kms.FileType:
+		return validation.ErrIfBlank(p.KMS.Path, "kms.path")
+	default:
+		return validation.ErrInvalidValue(p.KMS.Type, "kms.type")
+	}
+}
+
+func validateKDF(k kdf.Config) *validation.FieldError {
+	switch k.Type {
+	case "", kdf.Argon2idType, kdf.ScryptType, kdf.PBKDF2Type:
+	default:
+		return validation.ErrInvalidValue(k.Type, "password.kdf.type")
+	}
+
+	// scrypt requires N to be a power of two greater than one
+	if n := k.WithDefaults().N; k.Type == kdf.ScryptType && (n <= 1 || n&(n-1) != 0) {
+		return validation.ErrInvalidValue(n, "password.kdf.n")
+	}
+	return nil
+}
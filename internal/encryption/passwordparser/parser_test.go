@@ -3,6 +3,8 @@ package passwordparser_test
 import (
 	"strings"
 
+	"github.com/cloudfoundry/cloud-service-broker/internal/encryption/kdf"
+	"github.com/cloudfoundry/cloud-service-broker/internal/encryption/kms"
 	"github.com/cloudfoundry/cloud-service-broker/internal/encryption/passwordparser"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -101,6 +103,60 @@ var _ = Describe("Parser", func() {
 				},
 			},
 		),
+		Entry(
+			"key management services",
+			`[{"label":"barfoo","password":{"secret":"veryverysecretpassword"}},{"label":"vault1","kms":{"type":"vault-transit","address":"https://vault.example.com:8200","token":"s.token","namespace":"team","mount":"csb-transit","key_name":"csb"},"primary":true},{"label":"file1","kms":{"type":"file","path":"/etc/csb/kms.key"}}]`,
+			[]passwordparser.PasswordEntry{
+				{
+					Label:  "barfoo",
+					Secret: "veryverysecretpassword",
+				},
+				{
+					Label: "vault1",
+					KMS: kms.Config{
+						Type:      kms.VaultTransitType,
+						Address:   "https://vault.example.com:8200",
+						Token:     "s.token",
+						Namespace: "team",
+						Mount:     "csb-transit",
+						KeyName:   "csb",
+					},
+					Primary: true,
+				},
+				{
+					Label: "file1",
+					KMS: kms.Config{
+						Type: kms.FileType,
+						Path: "/etc/csb/kms.key",
+					},
+				},
+			},
+		),
+		Entry(
+			"key derivation functions",
+			`[{"label":"barfoo","password":{"secret":"veryverysecretpassword","kdf":{"type":"argon2id","time":4,"memory":131072,"threads":2}}},{"label":"barbaz","password":{"secret":"anotherveryverysecretpassword","kdf":{"type":"scrypt","n":65536}},"primary":true}]`,
+			[]passwordparser.PasswordEntry{
+				{
+					Label:  "barfoo",
+					Secret: "veryverysecretpassword",
+					KDF: kdf.Config{
+						Type:    kdf.Argon2idType,
+						Time:    4,
+						Memory:  131072,
+						Threads: 2,
+					},
+				},
+				{
+					Label:  "barbaz",
+					Secret: "anotherveryverysecretpassword",
+					KDF: kdf.Config{
+						Type: kdf.ScryptType,
+						N:    65536,
+					},
+					Primary: true,
+				},
+			},
+		),
 	)
 
 	DescribeTable(
@@ -145,5 +201,35 @@ var _ = Describe("Parser", func() {
 			`[{"label":"barfoo","password":{"secret":"veryverysecretpassword"},"primary":true},{"label":"barbaz","password":{"secret":"anotherveryverysecretpassword"}},{"label":"bazquz","password":{"secret":"yetanotherveryverysecretpassword"},"primary":true}]`,
 			`password configuration error: expected exactly one primary, got multiple; mark one password as primary and others as non-primary but do not remove them: [].primary`,
 		),
+		Entry(
+			"both password and key management service",
+			`[{"label":"barfoo","password":{"secret":"veryverysecretpassword"},"kms":{"type":"file","path":"/etc/csb/kms.key"},"primary":true}]`,
+			`password configuration error: expected exactly one, got both: [0].kms, [0].password`,
+		),
+		Entry(
+			"unknown key management service type",
+			`[{"label":"barfoo","kms":{"type":"magic"},"primary":true}]`,
+			`password configuration error: invalid value: magic: [0].kms.type`,
+		),
+		Entry(
+			"incomplete Vault Transit configuration",
+			`[{"label":"barfoo","kms":{"type":"vault-transit","address":"not a url"},"primary":true}]`,
+			"password configuration error: field must be a URL: [0].kms.address\nmissing field(s): [0].kms.key_name, [0].kms.token",
+		),
+		Entry(
+			"file key management service without path",
+			`[{"label":"barfoo","kms":{"type":"file"},"primary":true}]`,
+			`password configuration error: missing field(s): [0].kms.path`,
+		),
+		Entry(
+			"unknown key derivation function",
+			`[{"label":"barfoo","password":{"secret":"veryverysecretpassword","kdf":{"type":"md5"}},"primary":true}]`,
+			`password configuration error: invalid value: md5: [0].password.kdf.type`,
+		),
+		Entry(
+			"scrypt cost that is not a power of two",
+			`[{"label":"barfoo","password":{"secret":"veryverysecretpassword","kdf":{"type":"scrypt","n":1000}},"primary":true}]`,
+			`password configuration error: invalid value: 1000: [0].password.kdf.n`,
+		),
 	)
 })
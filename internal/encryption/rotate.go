@@ -0,0 +1,86 @@
+package encryption
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cloudfoundry/cloud-service-broker/internal/storage"
+	"gorm.io/gorm"
+)
+
+// Rotate re-encrypts every record with the configured primary password, resuming an interrupted
+// rotation from its last checkpoint. It is intended to run while the broker serves requests using
+// the rotation encryptor, which can decrypt records encrypted with either password. The configured
+// primary is only recorded as the stored primary once every record has been re-encrypted.
+func Rotate(db *gorm.DB, config Configuration) error {
+	store := storage.New(db, config.RotationEncryptor)
+
+	progress, err := rotationProgress(store, config)
+	if err != nil {
+		return err
+	}
+
+	if progress.CurrentTable == "" {
+		if _, err := store.CheckAllRecords(); err != nil {
+			return fmt.Errorf("refusing to encrypt the database as some fields cannot be successfully read: %w", err)
+		}
+		if err := store.StoreEncryptionRotation(progress); err != nil {
+			return err
+		}
+	}
+
+	progress, err = store.UpdateRecordsFrom(progress, store.StoreEncryptionRotation)
+	if err != nil {
+		return fmt.Errorf("error rotating database encryption: %w", err)
+	}
+
+	if _, err := storage.New(db, config.Encryptor).CheckAllRecords(); err != nil {
+		return fmt.Errorf("some fields cannot be read with the new primary password after rotation: %w", err)
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := UpdatePasswordMetadata(tx, config.ConfiguredPrimaryLabel); err != nil {
+			return fmt.Errorf("error updating password metadata: %w", err)
+		}
+
+		progress.FinishedAt = time.Now()
+		return storage.New(tx, config.RotationEncryptor).StoreEncryptionRotation(progress)
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(config.ToDeleteLabels) > 0 {
+		if err := DeletePasswordMetadata(db, config.ToDeleteLabels); err != nil {
+			return fmt.Errorf("error deleting stale password metadata: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// rotationProgress returns the progress of an interrupted rotation, or starts a new one. A finished
+// rotation between the same passwords must be from an earlier rotation, as the password metadata is
+// updated when a rotation finishes, so it is started again.
+func rotationProgress(store *storage.Storage, config Configuration) (storage.EncryptionRotation, error) {
+	fresh := storage.EncryptionRotation{
+		FromLabel: config.StoredPrimaryLabel,
+		ToLabel:   config.ConfiguredPrimaryLabel,
+		StartedAt: time.Now(),
+	}
+
+	exists, err := store.ExistsEncryptionRotation(fresh.FromLabel, fresh.ToLabel)
+	if err != nil || !exists {
+		return fresh, err
+	}
+
+	progress, err := store.GetEncryptionRotation(fresh.FromLabel, fresh.ToLabel)
+	switch {
+	case err != nil:
+		return storage.EncryptionRotation{}, err
+	case !progress.FinishedAt.IsZero():
+		return fresh, nil
+	default:
+		return progress, nil
+	}
+}
@@ -0,0 +1,194 @@
+package encryption_test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cloudfoundry/cloud-service-broker/dbservice/models"
+	"github.com/cloudfoundry/cloud-service-broker/internal/encryption"
+	"github.com/cloudfoundry/cloud-service-broker/internal/storage"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+var _ = Describe("Rotate()", func() {
+	const (
+		oldPasswords = `[{"primary":true,"label":"barfoo","password":{"secret":"averyverygoodpassword"}}]`
+		newPasswords = `[{"primary":false,"label":"barfoo","password":{"secret":"averyverygoodpassword"}},{"primary":true,"label":"bazquz","password":{"secret":"anotherveryverygoodpassword"}}]`
+	)
+
+	var (
+		db     *gorm.DB
+		config encryption.Configuration
+	)
+
+	requestDetails := func(encryptor storage.Encryptor) []storage.JSONObject {
+		var result []storage.JSONObject
+		for i := 0; i < 3; i++ {
+			details, err := storage.New(db, encryptor).GetProvisionRequestDetails(fmt.Sprintf("fake-instance-%d", i))
+			Expect(err).NotTo(HaveOccurred())
+			result = append(result, details)
+		}
+		return result
+	}
+
+	BeforeEach(func() {
+		var err error
+		db, err = gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(db.Migrator().CreateTable(
+			&models.PasswordMetadata{},
+			&models.EncryptionRotation{},
+			&models.ServiceBindingCredentials{},
+			&models.BindRequestDetails{},
+			&models.ProvisionRequestDetails{},
+			&models.ServiceInstanceDetails{},
+			&models.TerraformDeployment{},
+			&models.TerraformState{},
+		)).To(Succeed())
+
+		By("encrypting records with the old primary password")
+		oldConfig, err := encryption.ParseConfiguration(db, true, oldPasswords)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(encryption.UpdatePasswordMetadata(db, "barfoo")).To(Succeed())
+		for i := 0; i < 3; i++ {
+			Expect(storage.New(db, oldConfig.Encryptor).StoreProvisionRequestDetails(fmt.Sprintf("fake-instance-%d", i), storage.JSONObject{"index": i})).To(Succeed())
+		}
+
+		config, err = encryption.ParseConfiguration(db, true, newPasswords)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(config.Changed).To(BeTrue())
+	})
+
+	It("re-encrypts the records and then updates the primary password", func() {
+		Expect(encryption.Rotate(db, config)).To(Succeed())
+
+		By("being able to read the records with only the new primary password")
+		Expect(requestDetails(config.Encryptor)).To(Equal([]storage.JSONObject{{"index": float64(0)}, {"index": float64(1)}, {"index": float64(2)}}))
+
+		By("recording the new primary password")
+		var primary models.PasswordMetadata
+		Expect(db.Where("`primary` = true").First(&primary).Error).NotTo(HaveOccurred())
+		Expect(primary.Label).To(Equal("bazquz"))
+
+		By("recording that the rotation finished")
+		rotation, err := storage.New(db, nil).GetEncryptionRotation("barfoo", "bazquz")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rotation.Records).To(Equal(3))
+		Expect(rotation.FinishedAt).NotTo(BeZero())
+
+		By("not needing another rotation")
+		next, err := encryption.ParseConfiguration(db, true, newPasswords)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(next.Changed).To(BeFalse())
+	})
+
+	It("resumes an interrupted rotation from the checkpoint", func() {
+		Expect(storage.New(db, nil).StoreEncryptionRotation(storage.EncryptionRotation{
+			FromLabel:    "barfoo",
+			ToLabel:      "bazquz",
+			CurrentTable: "provision_request_details",
+			Checkpoint:   "2",
+			Records:      2,
+		})).To(Succeed())
+
+		Expect(encryption.Rotate(db, config)).To(MatchError(ContainSubstring("some fields cannot be read with the new primary password after rotation")))
+
+		By("only re-encrypting records after the checkpoint")
+		var details []models.ProvisionRequestDetails
+		Expect(db.Order("id").Find(&details).Error).NotTo(HaveOccurred())
+		_, err := config.Encryptor.Decrypt(details[1].RequestDetails)
+		Expect(err).To(HaveOccurred())
+		_, err = config.Encryptor.Decrypt(details[2].RequestDetails)
+		Expect(err).NotTo(HaveOccurred())
+
+		By("not updating the primary password")
+		var primary models.PasswordMetadata
+		Expect(db.Where("`primary` = true").First(&primary).Error).NotTo(HaveOccurred())
+		Expect(primary.Label).To(Equal("barfoo"))
+
+		By("recording the progress")
+		rotation, err := storage.New(db, nil).GetEncryptionRotation("barfoo", "bazquz")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rotation.Records).To(Equal(3))
+		Expect(rotation.FinishedAt).To(BeZero())
+	})
+
+	It("starts again when a rotation between the same passwords has finished before", func() {
+		Expect(storage.New(db, nil).StoreEncryptionRotation(storage.EncryptionRotation{
+			FromLabel:    "barfoo",
+			ToLabel:      "bazquz",
+			CurrentTable: "terraform_states",
+			Checkpoint:   "fake-id",
+			Records:      1,
+			FinishedAt:   time.Now(),
+		})).To(Succeed())
+
+		Expect(encryption.Rotate(db, config)).To(Succeed())
+		Expect(requestDetails(config.Encryptor)).To(HaveLen(3))
+	})
+
+	It("refuses to rotate when records cannot be read", func() {
+		Expect(db.Create(&models.TerraformDeployment{ID: "fake-bad-id", Workspace: []byte("not encrypted")}).Error).To(Succeed())
+
+		Expect(encryption.Rotate(db, config)).To(MatchError(ContainSubstring("refusing to encrypt the database as some fields cannot be successfully read")))
+		Expect(requestDetails(config.RotationEncryptor)).To(HaveLen(3))
+
+		var primary models.PasswordMetadata
+		Expect(db.Where("`primary` = true").First(&primary).Error).NotTo(HaveOccurred())
+		Expect(primary.Label).To(Equal("barfoo"))
+	})
+})
+
+var _ = Describe("Rotate() between key derivation functions", func() {
+	const (
+		oldPasswords = `[{"primary":true,"label":"barfoo","password":{"secret":"averyverygoodpassword"}}]`
+		newPasswords = `[{"primary":false,"label":"barfoo","password":{"secret":"averyverygoodpassword"}},{"primary":true,"label":"bazquz","password":{"secret":"anotherveryverygoodpassword","kdf":{"type":"scrypt","n":1024}}}]`
+	)
+
+	It("re-encrypts records from a PBKDF2 password to a scrypt password", func() {
+		db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(db.Migrator().CreateTable(
+			&models.PasswordMetadata{},
+			&models.EncryptionRotation{},
+			&models.ServiceBindingCredentials{},
+			&models.BindRequestDetails{},
+			&models.ProvisionRequestDetails{},
+			&models.ServiceInstanceDetails{},
+			&models.TerraformDeployment{},
+			&models.TerraformState{},
+		)).To(Succeed())
+
+		By("storing a password from before the key derivation function was recorded")
+		Expect(db.Create(&models.PasswordMetadata{
+			Label:   "barfoo",
+			Salt:    []byte("random-salt-containing-32-bytes!"),
+			Canary:  []byte{250, 65, 162, 134, 203, 81, 170, 159, 176, 113, 29, 249, 223, 77, 187, 139, 97, 254, 110, 99, 177, 102, 234, 51, 47, 85, 126, 205, 110, 173, 159, 209, 234, 138, 66, 113, 117, 191, 211, 184},
+			Primary: true,
+		}).Error).To(Succeed())
+		oldConfig, err := encryption.ParseConfiguration(db, true, oldPasswords)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(storage.New(db, oldConfig.Encryptor).StoreProvisionRequestDetails("fake-instance", storage.JSONObject{"foo": "bar"})).To(Succeed())
+
+		By("rotating to a password that uses scrypt")
+		config, err := encryption.ParseConfiguration(db, true, newPasswords)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(config.Changed).To(BeTrue())
+		Expect(encryption.Rotate(db, config)).To(Succeed())
+
+		var stored []models.PasswordMetadata
+		Expect(db.Order("id").Find(&stored).Error).NotTo(HaveOccurred())
+		Expect(stored).To(HaveLen(2))
+		Expect(stored[0].KDF).To(BeEmpty())
+		Expect(stored[1].KDF).To(Equal(`{"type":"scrypt","n":1024,"r":8,"p":1}`))
+
+		By("checking both canaries and reading the records after a restart")
+		config, err = encryption.ParseConfiguration(db, true, newPasswords)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(config.Changed).To(BeFalse())
+		Expect(storage.New(db, config.Encryptor).GetProvisionRequestDetails("fake-instance")).To(Equal(storage.JSONObject{"foo": "bar"}))
+	})
+})
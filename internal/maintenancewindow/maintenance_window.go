@@ -0,0 +1,117 @@
+// Package maintenancewindow describes the weekly period during which a service
+// instance may be upgraded.
+package maintenancewindow
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var format = regexp.MustCompile(`^(\w+)\s+(\d{2}):(\d{2})\s*-\s*(\d{2}):(\d{2})$`)
+
+// Window is a weekly period in UTC. It starts on a day of the week, and may
+// finish on the following day when the end time is not after the start time.
+type Window struct {
+	Day   time.Weekday
+	Start time.Duration
+	End   time.Duration
+}
+
+// Parse reads a window in the format "<day> HH:MM-HH:MM", for example "sunday 02:00-04:00".
+// Days may be abbreviated to three letters.
+func Parse(s string) (Window, error) {
+	m := format.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return Window{}, fmt.Errorf("invalid maintenance window %q: must be in the format \"<day> HH:MM-HH:MM\", for example \"sunday 02:00-04:00\"", s)
+	}
+
+	day, err := parseDay(m[1])
+	if err != nil {
+		return Window{}, fmt.Errorf("invalid maintenance window %q: %w", s, err)
+	}
+
+	start, err := parseTime(m[2], m[3], false)
+	if err != nil {
+		return Window{}, fmt.Errorf("invalid maintenance window %q: %w", s, err)
+	}
+
+	end, err := parseTime(m[4], m[5], true)
+	if err != nil {
+		return Window{}, fmt.Errorf("invalid maintenance window %q: %w", s, err)
+	}
+
+	if start == end {
+		return Window{}, fmt.Errorf("invalid maintenance window %q: start and end times must differ", s)
+	}
+
+	return Window{Day: day, Start: start, End: end}, nil
+}
+
+// String formats the window so that it can be read by Parse
+func (w Window) String() string {
+	return fmt.Sprintf("%s %s-%s", strings.ToLower(w.Day.String()), clock(w.Start), clock(w.End))
+}
+
+// Contains reports whether the time falls inside the window
+func (w Window) Contains(t time.Time) bool {
+	return t.Before(w.lastStart(t).Add(w.duration()))
+}
+
+// Next returns the start of the window that contains the time, or otherwise the start of the following window
+func (w Window) Next(t time.Time) time.Time {
+	start := w.lastStart(t)
+	if t.Before(start.Add(w.duration())) {
+		return start
+	}
+	return start.AddDate(0, 0, 7)
+}
+
+func (w Window) duration() time.Duration {
+	d := w.End - w.Start
+	if d <= 0 {
+		d += 24 * time.Hour
+	}
+	return d
+}
+
+// lastStart returns the most recent start of the window that is not after the time
+func (w Window) lastStart(t time.Time) time.Time {
+	t = t.UTC()
+	start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Add(w.Start)
+	for start.Weekday() != w.Day || start.After(t) {
+		start = start.AddDate(0, 0, -1)
+	}
+	return start
+}
+
+func parseDay(s string) (time.Weekday, error) {
+	s = strings.ToLower(s)
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		if s == name || s == name[:3] {
+			return d, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown day of the week %q", s)
+}
+
+// parseTime reads a time of day. Midnight may be written as 24:00 only as an end time, as a start
+// time of 24:00 would move the window to the following day.
+func parseTime(hh, mm string, end bool) (time.Duration, error) {
+	h, _ := strconv.Atoi(hh)
+	m, _ := strconv.Atoi(mm)
+	switch {
+	case h == 24 && m == 0 && !end:
+		return 0, fmt.Errorf("invalid start time %s:%s: use 00:00 for midnight", hh, mm)
+	case h > 24 || m > 59 || (h == 24 && m != 0):
+		return 0, fmt.Errorf("invalid time %s:%s", hh, mm)
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute, nil
+}
+
+func clock(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+}
@@ -0,0 +1,13 @@
+package maintenancewindow_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestMaintenanceWindow(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Maintenance Window Suite")
+}
@@ -0,0 +1,84 @@
+package maintenancewindow_test
+
+import (
+	"time"
+
+	"github.com/cloudfoundry/cloud-service-broker/internal/maintenancewindow"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MaintenanceWindow", func() {
+	// 2022-05-01 is a Sunday
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2022, 5, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	Describe("Parse", func() {
+		DescribeTable(
+			"valid windows",
+			func(input, expected string) {
+				w, err := maintenancewindow.Parse(input)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(w.String()).To(Equal(expected))
+			},
+			Entry("full day name", "sunday 02:00-04:00", "sunday 02:00-04:00"),
+			Entry("abbreviated day name", "Tue 22:30-01:15", "tuesday 22:30-01:15"),
+			Entry("spaces", " saturday  23:00 - 24:00 ", "saturday 23:00-24:00"),
+		)
+
+		DescribeTable(
+			"invalid windows",
+			func(input, expectedError string) {
+				_, err := maintenancewindow.Parse(input)
+				Expect(err).To(MatchError(ContainSubstring(expectedError)))
+			},
+			Entry("wrong format", "sunday at 2am", `must be in the format "<day> HH:MM-HH:MM"`),
+			Entry("unknown day", "someday 02:00-04:00", `unknown day of the week "someday"`),
+			Entry("invalid hour", "monday 25:00-04:00", "invalid time 25:00"),
+			Entry("invalid minute", "monday 02:00-04:60", "invalid time 04:60"),
+			Entry("start at 24:00", "monday 24:00-02:00", "invalid start time 24:00: use 00:00 for midnight"),
+			Entry("empty window", "monday 02:00-02:00", "start and end times must differ"),
+		)
+	})
+
+	Describe("Contains and Next", func() {
+		It("handles a window within a day", func() {
+			w, err := maintenancewindow.Parse("sunday 02:00-04:00")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(w.Contains(at(1, 1, 59))).To(BeFalse())
+			Expect(w.Next(at(1, 1, 59))).To(Equal(at(1, 2, 0)))
+
+			Expect(w.Contains(at(1, 2, 0))).To(BeTrue())
+			Expect(w.Contains(at(1, 3, 59))).To(BeTrue())
+			Expect(w.Next(at(1, 3, 59))).To(Equal(at(1, 2, 0)))
+
+			Expect(w.Contains(at(1, 4, 0))).To(BeFalse())
+			Expect(w.Next(at(1, 4, 0))).To(Equal(at(8, 2, 0)))
+
+			Expect(w.Contains(at(4, 3, 0))).To(BeFalse())
+			Expect(w.Next(at(4, 3, 0))).To(Equal(at(8, 2, 0)))
+		})
+
+		It("handles a window that crosses midnight", func() {
+			w, err := maintenancewindow.Parse("saturday 23:00-01:00")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(w.Contains(at(7, 22, 59))).To(BeFalse())
+			Expect(w.Contains(at(7, 23, 0))).To(BeTrue())
+			Expect(w.Contains(at(8, 0, 30))).To(BeTrue())
+			Expect(w.Next(at(8, 0, 30))).To(Equal(at(7, 23, 0)))
+			Expect(w.Contains(at(8, 1, 0))).To(BeFalse())
+			Expect(w.Next(at(8, 1, 0))).To(Equal(at(14, 23, 0)))
+		})
+
+		It("uses UTC", func() {
+			w, err := maintenancewindow.Parse("sunday 02:00-04:00")
+			Expect(err).NotTo(HaveOccurred())
+
+			inNewYork := at(1, 3, 0).In(time.FixedZone("EDT", -4*60*60))
+			Expect(w.Contains(inNewYork)).To(BeTrue())
+		})
+	})
+})
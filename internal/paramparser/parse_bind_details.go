@@ -12,6 +12,7 @@ type BindDetails struct {
 	PlanID         string
 	ServiceID      string
 	BindAppGUID    string
+	BindResource   map[string]interface{}
 	RequestParams  map[string]interface{}
 	RequestContext map[string]interface{}
 }
@@ -25,6 +26,18 @@ func ParseBindDetails(input domain.BindDetails) (BindDetails, error) {
 
 	if input.BindResource != nil {
 		result.BindAppGUID = input.BindResource.AppGuid
+
+		data, err := json.Marshal(input.BindResource)
+		if err != nil {
+			return BindDetails{}, fmt.Errorf("error parsing bind resource: %w", err)
+		}
+		var bindResource map[string]interface{}
+		if err := json.Unmarshal(data, &bindResource); err != nil {
+			return BindDetails{}, fmt.Errorf("error parsing bind resource: %w", err)
+		}
+		if len(bindResource) > 0 {
+			result.BindResource = bindResource
+		}
 	}
 
 	if len(input.RawParameters) > 0 {
@@ -28,6 +28,7 @@ var _ = Describe("ParseBindDetails", func() {
 			PlanID:         "fake-plan-id",
 			ServiceID:      "fake-service-id",
 			BindAppGUID:    "fake-bind-app-guid",
+			BindResource:   map[string]interface{}{"app_guid": "fake-bind-app-guid"},
 			RequestParams:  map[string]interface{}{"baz": "quz"},
 			RequestContext: map[string]interface{}{"foo": "bar"},
 		}))
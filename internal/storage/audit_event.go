@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cloudfoundry/cloud-service-broker/dbservice/models"
+)
+
+type AuditEvent struct {
+	Time                time.Time
+	Operation           string
+	ServiceInstanceGUID string
+	ServiceBindingGUID  string
+	OriginatingIdentity string
+	OrganizationGUID    string
+	SpaceGUID           string
+	ParametersHash      string
+	Result              string
+	Message             string
+	Duration            time.Duration
+}
+
+// AuditEventFilter selects audit events. Empty fields match any value.
+type AuditEventFilter struct {
+	ServiceInstanceGUID string
+	Since               time.Time
+}
+
+func (s *Storage) StoreAuditEvent(event AuditEvent) error {
+	m := models.AuditEvent{
+		CreatedAt:           event.Time,
+		Operation:           event.Operation,
+		ServiceInstanceID:   event.ServiceInstanceGUID,
+		ServiceBindingID:    event.ServiceBindingGUID,
+		OriginatingIdentity: event.OriginatingIdentity,
+		OrganizationGUID:    event.OrganizationGUID,
+		SpaceGUID:           event.SpaceGUID,
+		ParametersHash:      event.ParametersHash,
+		Result:              event.Result,
+		Message:             event.Message,
+		Duration:            event.Duration,
+	}
+	if err := s.db.Create(&m).Error; err != nil {
+		return fmt.Errorf("error creating audit event: %w", err)
+	}
+
+	return nil
+}
+
+// GetAuditEvents returns the audit events matching the filter, oldest first.
+func (s *Storage) GetAuditEvents(f AuditEventFilter) ([]AuditEvent, error) {
+	query := s.db.Order("created_at, id")
+	if f.ServiceInstanceGUID != "" {
+		query = query.Where("service_instance_id = ?", f.ServiceInstanceGUID)
+	}
+	if !f.Since.IsZero() {
+		query = query.Where("created_at >= ?", f.Since)
+	}
+
+	var receiver []models.AuditEvent
+	if err := query.Find(&receiver).Error; err != nil {
+		return nil, fmt.Errorf("error finding audit events: %w", err)
+	}
+
+	result := make([]AuditEvent, 0, len(receiver))
+	for _, m := range receiver {
+		result = append(result, AuditEvent{
+			Time:                m.CreatedAt,
+			Operation:           m.Operation,
+			ServiceInstanceGUID: m.ServiceInstanceID,
+			ServiceBindingGUID:  m.ServiceBindingID,
+			OriginatingIdentity: m.OriginatingIdentity,
+			OrganizationGUID:    m.OrganizationGUID,
+			SpaceGUID:           m.SpaceGUID,
+			ParametersHash:      m.ParametersHash,
+			Result:              m.Result,
+			Message:             m.Message,
+			Duration:            m.Duration,
+		})
+	}
+
+	return result, nil
+}
+
+// DeleteAuditEventsBefore removes audit events older than the cutoff and returns how many were removed.
+func (s *Storage) DeleteAuditEventsBefore(cutoff time.Time) (int64, error) {
+	result := s.db.Where("created_at < ?", cutoff).Delete(&models.AuditEvent{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("error deleting audit events: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,118 @@
+package storage_test
+
+import (
+	"time"
+
+	"github.com/cloudfoundry/cloud-service-broker/dbservice/models"
+	"github.com/cloudfoundry/cloud-service-broker/internal/storage"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AuditEvent", func() {
+	var now time.Time
+
+	BeforeEach(func() {
+		now = time.Now().UTC().Truncate(time.Second)
+	})
+
+	Describe("StoreAuditEvent", func() {
+		It("creates the right object in the database", func() {
+			err := store.StoreAuditEvent(storage.AuditEvent{
+				Time:                now,
+				Operation:           "provision",
+				ServiceInstanceGUID: "fake-instance-guid",
+				ServiceBindingGUID:  "fake-binding-guid",
+				OriginatingIdentity: `{"platform":"cloudfoundry","value":{"user_id":"fake-user"}}`,
+				OrganizationGUID:    "fake-org-guid",
+				SpaceGUID:           "fake-space-guid",
+				ParametersHash:      "fake-hash",
+				Result:              "failed",
+				Message:             "fake-message",
+				Duration:            time.Minute,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			var receiver models.AuditEvent
+			Expect(db.Find(&receiver).Error).NotTo(HaveOccurred())
+			Expect(receiver.CreatedAt).To(BeTemporally("==", now))
+			Expect(receiver.Operation).To(Equal("provision"))
+			Expect(receiver.ServiceInstanceID).To(Equal("fake-instance-guid"))
+			Expect(receiver.ServiceBindingID).To(Equal("fake-binding-guid"))
+			Expect(receiver.OriginatingIdentity).To(Equal(`{"platform":"cloudfoundry","value":{"user_id":"fake-user"}}`))
+			Expect(receiver.OrganizationGUID).To(Equal("fake-org-guid"))
+			Expect(receiver.SpaceGUID).To(Equal("fake-space-guid"))
+			Expect(receiver.ParametersHash).To(Equal("fake-hash"))
+			Expect(receiver.Result).To(Equal("failed"))
+			Expect(receiver.Message).To(Equal("fake-message"))
+			Expect(receiver.Duration).To(Equal(time.Minute))
+		})
+	})
+
+	Describe("GetAuditEvents", func() {
+		BeforeEach(func() {
+			addFakeAuditEvents(now)
+		})
+
+		It("returns all events, oldest first, when the filter is empty", func() {
+			events, err := store.GetAuditEvents(storage.AuditEventFilter{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(events).To(HaveLen(3))
+			Expect(events[0].Operation).To(Equal("provision"))
+			Expect(events[0].ServiceInstanceGUID).To(Equal("fake-instance-1"))
+			Expect(events[0].Time).To(BeTemporally("==", now.Add(-48*time.Hour)))
+			Expect(events[1].Operation).To(Equal("bind"))
+			Expect(events[1].ServiceBindingGUID).To(Equal("fake-binding-1"))
+			Expect(events[2].Operation).To(Equal("provision"))
+			Expect(events[2].ServiceInstanceGUID).To(Equal("fake-instance-2"))
+		})
+
+		It("filters by instance and time", func() {
+			events, err := store.GetAuditEvents(storage.AuditEventFilter{ServiceInstanceGUID: "fake-instance-1"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(events).To(HaveLen(2))
+
+			events, err = store.GetAuditEvents(storage.AuditEventFilter{Since: now.Add(-time.Hour)})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(events).To(HaveLen(2))
+
+			events, err = store.GetAuditEvents(storage.AuditEventFilter{ServiceInstanceGUID: "fake-instance-1", Since: now.Add(-time.Hour)})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(events).To(HaveLen(1))
+			Expect(events[0].Operation).To(Equal("bind"))
+		})
+	})
+
+	Describe("DeleteAuditEventsBefore", func() {
+		BeforeEach(func() {
+			addFakeAuditEvents(now)
+		})
+
+		It("deletes the events older than the cutoff", func() {
+			Expect(store.DeleteAuditEventsBefore(now.Add(-24 * time.Hour))).To(BeNumerically("==", 1))
+
+			events, err := store.GetAuditEvents(storage.AuditEventFilter{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(events).To(HaveLen(2))
+		})
+	})
+})
+
+func addFakeAuditEvents(now time.Time) {
+	Expect(db.Create(&models.AuditEvent{
+		CreatedAt:         now.Add(-48 * time.Hour),
+		Operation:         "provision",
+		ServiceInstanceID: "fake-instance-1",
+	}).Error).NotTo(HaveOccurred())
+	Expect(db.Create(&models.AuditEvent{
+		CreatedAt:         now.Add(-time.Minute),
+		Operation:         "bind",
+		ServiceInstanceID: "fake-instance-1",
+		ServiceBindingID:  "fake-binding-1",
+	}).Error).NotTo(HaveOccurred())
+	Expect(db.Create(&models.AuditEvent{
+		CreatedAt:         now,
+		Operation:         "provision",
+		ServiceInstanceID: "fake-instance-2",
+	}).Error).NotTo(HaveOccurred())
+}
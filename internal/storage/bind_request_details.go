@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github.com/cloudfoundry/cloud-service-broker/dbservice/models"
@@ -10,10 +11,14 @@ type BindRequestDetails struct {
 	ServiceInstanceGUID string
 	ServiceBindingGUID  string
 	RequestDetails      JSONObject
+	RequestContext      JSONObject
+	PlanGUID            string
+	AppGUID             string
+	BindResource        JSONObject
 }
 
 func (s *Storage) StoreBindRequestDetails(bindRequestDetails BindRequestDetails) error {
-	if bindRequestDetails.RequestDetails == nil {
+	if bindRequestDetails.RequestDetails == nil && bindRequestDetails.RequestContext == nil && bindRequestDetails.PlanGUID == "" {
 		return nil
 	}
 
@@ -22,6 +27,22 @@ func (s *Storage) StoreBindRequestDetails(bindRequestDetails BindRequestDetails)
 		return fmt.Errorf("error encoding details: %w", err)
 	}
 
+	var encodedContext []byte
+	if bindRequestDetails.RequestContext != nil {
+		encodedContext, err = s.encodeJSON(bindRequestDetails.RequestContext)
+		if err != nil {
+			return fmt.Errorf("error encoding context: %w", err)
+		}
+	}
+
+	var bindResource []byte
+	if bindRequestDetails.BindResource != nil {
+		bindResource, err = json.Marshal(bindRequestDetails.BindResource)
+		if err != nil {
+			return fmt.Errorf("error encoding bind resource: %w", err)
+		}
+	}
+
 	var receiver []models.BindRequestDetails
 	if err := s.db.Where("service_binding_id = ?", bindRequestDetails.ServiceBindingGUID).Find(&receiver).Error; err != nil {
 		return fmt.Errorf("error searching for existing bind request details records: %w", err)
@@ -32,6 +53,10 @@ func (s *Storage) StoreBindRequestDetails(bindRequestDetails BindRequestDetails)
 			ServiceInstanceID: bindRequestDetails.ServiceInstanceGUID,
 			ServiceBindingID:  bindRequestDetails.ServiceBindingGUID,
 			RequestDetails:    encoded,
+			RequestContext:    encodedContext,
+			PlanID:            bindRequestDetails.PlanGUID,
+			AppGUID:           bindRequestDetails.AppGUID,
+			BindResource:      bindResource,
 		}
 		if err := s.db.Create(&m).Error; err != nil {
 			return fmt.Errorf("error creating bind request details: %w", err)
@@ -65,6 +90,63 @@ func (s *Storage) GetBindRequestDetails(bindingID string, instanceID string) (JS
 	return decoded, nil
 }
 
+// GetBindRequest returns the details of the bind request. The plan, app and bind resource are empty
+// for bindings that were created before they were recorded, and everything is empty when the
+// binding has no record.
+func (s *Storage) GetBindRequest(bindingID string, instanceID string) (BindRequestDetails, error) {
+	var receiver []models.BindRequestDetails
+	if err := s.db.Where("service_binding_id = ? AND service_instance_id = ?", bindingID, instanceID).Find(&receiver).Error; err != nil {
+		return BindRequestDetails{}, fmt.Errorf("error finding bind request details record: %w", err)
+	}
+	if len(receiver) == 0 {
+		return BindRequestDetails{ServiceInstanceGUID: instanceID, ServiceBindingGUID: bindingID}, nil
+	}
+
+	result := BindRequestDetails{
+		ServiceInstanceGUID: instanceID,
+		ServiceBindingGUID:  bindingID,
+		PlanGUID:            receiver[0].PlanID,
+		AppGUID:             receiver[0].AppGUID,
+	}
+
+	var err error
+	if result.RequestDetails, err = s.decodeJSONObject(receiver[0].RequestDetails); err != nil {
+		return BindRequestDetails{}, fmt.Errorf("error decoding bind request details %q: %w", bindingID, err)
+	}
+
+	if len(receiver[0].RequestContext) > 0 {
+		if result.RequestContext, err = s.decodeJSONObject(receiver[0].RequestContext); err != nil {
+			return BindRequestDetails{}, fmt.Errorf("error decoding bind request context %q: %w", bindingID, err)
+		}
+	}
+
+	if len(receiver[0].BindResource) > 0 {
+		if err := json.Unmarshal(receiver[0].BindResource, &result.BindResource); err != nil {
+			return BindRequestDetails{}, fmt.Errorf("error decoding bind resource %q: %w", bindingID, err)
+		}
+	}
+
+	return result, nil
+}
+
+// GetBindRequestContext returns the platform context of the bind request, or nil when it was not stored
+func (s *Storage) GetBindRequestContext(bindingID string, instanceID string) (JSONObject, error) {
+	var receiver []models.BindRequestDetails
+	if err := s.db.Where("service_binding_id = ? AND service_instance_id = ?", bindingID, instanceID).Find(&receiver).Error; err != nil {
+		return nil, fmt.Errorf("error finding bind request details record: %w", err)
+	}
+	if len(receiver) == 0 || len(receiver[0].RequestContext) == 0 {
+		return nil, nil
+	}
+
+	decoded, err := s.decodeJSONObject(receiver[0].RequestContext)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding bind request context %q: %w", bindingID, err)
+	}
+
+	return decoded, nil
+}
+
 func (s *Storage) DeleteBindRequestDetails(bindingID string, instanceID string) error {
 	err := s.db.Where("service_binding_id = ? AND service_instance_id = ?", bindingID, instanceID).Delete(&models.BindRequestDetails{}).Error
 	if err != nil {
@@ -30,6 +30,38 @@ var _ = Describe("BindRequestDetails", func() {
 			Expect(receiver.RequestDetails).To(Equal([]byte(`{"encrypted":{"foo":"bar"}}`)))
 		})
 
+		It("stores the request context", func() {
+			err := store.StoreBindRequestDetails(storage.BindRequestDetails{
+				ServiceInstanceGUID: serviceInstanceID,
+				ServiceBindingGUID:  serviceBindingID,
+				RequestDetails:      nil,
+				RequestContext:      storage.JSONObject{"namespace": "app-namespace"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			var receiver models.BindRequestDetails
+			Expect(db.Find(&receiver).Error).NotTo(HaveOccurred())
+			Expect(receiver.RequestDetails).To(Equal([]byte(`{"encrypted":null}`)))
+			Expect(receiver.RequestContext).To(Equal([]byte(`{"encrypted":{"namespace":"app-namespace"}}`)))
+		})
+
+		It("stores the plan, app and bind resource", func() {
+			err := store.StoreBindRequestDetails(storage.BindRequestDetails{
+				ServiceInstanceGUID: serviceInstanceID,
+				ServiceBindingGUID:  serviceBindingID,
+				PlanGUID:            "fake-plan-id",
+				AppGUID:             "fake-app-guid",
+				BindResource:        storage.JSONObject{"app_guid": "fake-app-guid"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			var receiver models.BindRequestDetails
+			Expect(db.Find(&receiver).Error).NotTo(HaveOccurred())
+			Expect(receiver.PlanID).To(Equal("fake-plan-id"))
+			Expect(receiver.AppGUID).To(Equal("fake-app-guid"))
+			Expect(receiver.BindResource).To(MatchJSON(`{"app_guid":"fake-app-guid"}`))
+		})
+
 		It("does not store when params are nil", func() {
 			err := store.StoreBindRequestDetails(storage.BindRequestDetails{
 				ServiceInstanceGUID: serviceInstanceID,
@@ -123,6 +155,80 @@ var _ = Describe("BindRequestDetails", func() {
 		)
 	})
 
+	Describe("GetBindRequest", func() {
+		BeforeEach(func() {
+			addFakeBindRequestDetails()
+		})
+
+		It("reads the bind request from the database", func() {
+			Expect(db.Model(&models.BindRequestDetails{}).Where("service_binding_id = ?", "fake-binding-id").Updates(map[string]interface{}{
+				"plan_id":       "fake-plan-id",
+				"app_guid":      "fake-app-guid",
+				"bind_resource": []byte(`{"app_guid":"fake-app-guid"}`),
+			}).Error).NotTo(HaveOccurred())
+
+			r, err := store.GetBindRequest("fake-binding-id", "fake-instance-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(r).To(Equal(storage.BindRequestDetails{
+				ServiceInstanceGUID: "fake-instance-id",
+				ServiceBindingGUID:  "fake-binding-id",
+				RequestDetails:      storage.JSONObject{"decrypted": map[string]interface{}{"foo": "bar"}},
+				RequestContext:      storage.JSONObject{"decrypted": map[string]interface{}{"namespace": "app-namespace"}},
+				PlanGUID:            "fake-plan-id",
+				AppGUID:             "fake-app-guid",
+				BindResource:        storage.JSONObject{"app_guid": "fake-app-guid"},
+			}))
+		})
+
+		It("returns empty details when the binding is not found", func() {
+			r, err := store.GetBindRequest("not-there", "fake-instance-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(r).To(Equal(storage.BindRequestDetails{ServiceInstanceGUID: "fake-instance-id", ServiceBindingGUID: "not-there"}))
+		})
+
+		When("decoding fails", func() {
+			It("returns an error", func() {
+				encryptor.DecryptReturns(nil, errors.New("bang"))
+
+				_, err := store.GetBindRequest("fake-binding-id", "fake-instance-id")
+				Expect(err).To(MatchError(`error decoding bind request details "fake-binding-id": decryption error: bang`))
+			})
+		})
+	})
+
+	Describe("GetBindRequestContext", func() {
+		BeforeEach(func() {
+			addFakeBindRequestDetails()
+		})
+
+		It("reads the request context from the database", func() {
+			r, err := store.GetBindRequestContext("fake-binding-id", "fake-instance-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(r).To(Equal(storage.JSONObject{"decrypted": map[string]interface{}{"namespace": "app-namespace"}}))
+		})
+
+		It("returns nil when the request context was not stored", func() {
+			r, err := store.GetBindRequestContext("fake-other-binding-id", "fake-other-instance-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(r).To(BeNil())
+		})
+
+		It("returns nil when the binding is not found", func() {
+			r, err := store.GetBindRequestContext("not-there", "fake-instance-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(r).To(BeNil())
+		})
+
+		When("decoding fails", func() {
+			It("returns an error", func() {
+				encryptor.DecryptReturns(nil, errors.New("bang"))
+
+				_, err := store.GetBindRequestContext("fake-binding-id", "fake-instance-id")
+				Expect(err).To(MatchError(`error decoding bind request context "fake-binding-id": decryption error: bang`))
+			})
+		})
+	})
+
 	Describe("DeleteBindRequestDetails", func() {
 		BeforeEach(func() {
 			addFakeBindRequestDetails()
@@ -156,6 +262,7 @@ var _ = Describe("BindRequestDetails", func() {
 func addFakeBindRequestDetails() {
 	Expect(db.Create(&models.BindRequestDetails{
 		RequestDetails:    []byte(`{"foo":"bar"}`),
+		RequestContext:    []byte(`{"namespace":"app-namespace"}`),
 		ServiceBindingID:  "fake-binding-id",
 		ServiceInstanceID: "fake-instance-id",
 	}).Error).NotTo(HaveOccurred())
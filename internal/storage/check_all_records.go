@@ -9,29 +9,53 @@ import (
 	"gorm.io/gorm"
 )
 
-func (s *Storage) CheckAllRecords() error {
+// KeyCounts is the number of encrypted records for each password label. Records that were
+// encrypted before key labels were added, or that are not encrypted, are counted with an
+// empty label.
+type KeyCounts map[string]int
+
+func (k KeyCounts) add(label string) {
+	k[label]++
+}
+
+// CheckAllRecords checks that every encrypted record can be read, and counts the records
+// that were encrypted with each password
+func (s *Storage) CheckAllRecords() (KeyCounts, error) {
 	var errs *multierror.Error
+	counts := make(KeyCounts)
 
-	checkers := []func() *multierror.Error{
+	checkers := []func(KeyCounts) *multierror.Error{
 		s.checkAllServiceBindingCredentials,
 		s.checkAllBindRequestDetails,
 		s.checkAllProvisionRequestDetails,
 		s.checkAllServiceInstanceDetails,
 		s.checkAllTerraformDeployments,
+		s.checkAllTerraformStates,
 	}
 	for _, e := range checkers {
-		if err := e(); err != nil {
+		if err := e(counts); err != nil {
 			errs = multierror.Append(err, errs)
 		}
 	}
 
-	return errs.ErrorOrNil()
+	return counts, errs.ErrorOrNil()
 }
 
-func (s *Storage) checkAllServiceBindingCredentials() (errs *multierror.Error) {
+// keyLabel returns the label of the password that encrypted the data, if it is known
+func (s *Storage) keyLabel(data []byte) string {
+	if l, ok := s.encryptor.(KeyLabeller); ok {
+		if label, ok := l.KeyLabel(data); ok {
+			return label
+		}
+	}
+	return ""
+}
+
+func (s *Storage) checkAllServiceBindingCredentials(counts KeyCounts) (errs *multierror.Error) {
 	var serviceBindingCredentialsBatch []models.ServiceBindingCredentials
 	result := s.db.FindInBatches(&serviceBindingCredentialsBatch, 100, func(tx *gorm.DB, batchNumber int) error {
 		for i := range serviceBindingCredentialsBatch {
+			counts.add(s.keyLabel(serviceBindingCredentialsBatch[i].OtherDetails))
 			if _, err := s.decodeJSONObject(serviceBindingCredentialsBatch[i].OtherDetails); err != nil {
 				errs = multierror.Append(fmt.Errorf("decode error for service binding credential %q: %w", serviceBindingCredentialsBatch[i].BindingID, err), errs)
 			}
@@ -46,13 +70,21 @@ func (s *Storage) checkAllServiceBindingCredentials() (errs *multierror.Error) {
 	return errs
 }
 
-func (s *Storage) checkAllBindRequestDetails() (errs *multierror.Error) {
+func (s *Storage) checkAllBindRequestDetails(counts KeyCounts) (errs *multierror.Error) {
 	var bindRequestDetailsBatch []models.BindRequestDetails
 	result := s.db.FindInBatches(&bindRequestDetailsBatch, 100, func(tx *gorm.DB, batchNumber int) error {
 		for i := range bindRequestDetailsBatch {
+			counts.add(s.keyLabel(bindRequestDetailsBatch[i].RequestDetails))
 			if _, err := s.decodeJSONObject(bindRequestDetailsBatch[i].RequestDetails); err != nil {
 				errs = multierror.Append(fmt.Errorf("decode error for binding request details %q: %w", bindRequestDetailsBatch[i].ServiceBindingID, err), errs)
 			}
+			if len(bindRequestDetailsBatch[i].RequestContext) == 0 {
+				continue
+			}
+			counts.add(s.keyLabel(bindRequestDetailsBatch[i].RequestContext))
+			if _, err := s.decodeJSONObject(bindRequestDetailsBatch[i].RequestContext); err != nil {
+				errs = multierror.Append(fmt.Errorf("decode error for binding request context %q: %w", bindRequestDetailsBatch[i].ServiceBindingID, err), errs)
+			}
 		}
 
 		return nil
@@ -64,10 +96,11 @@ func (s *Storage) checkAllBindRequestDetails() (errs *multierror.Error) {
 	return errs
 }
 
-func (s *Storage) checkAllProvisionRequestDetails() (errs *multierror.Error) {
+func (s *Storage) checkAllProvisionRequestDetails(counts KeyCounts) (errs *multierror.Error) {
 	var provisionRequestDetailsBatch []models.ProvisionRequestDetails
 	result := s.db.FindInBatches(&provisionRequestDetailsBatch, 100, func(tx *gorm.DB, batchNumber int) error {
 		for i := range provisionRequestDetailsBatch {
+			counts.add(s.keyLabel(provisionRequestDetailsBatch[i].RequestDetails))
 			if _, err := s.decodeJSONObject(provisionRequestDetailsBatch[i].RequestDetails); err != nil {
 				errs = multierror.Append(fmt.Errorf("decode error for provision request details %q: %w", provisionRequestDetailsBatch[i].ServiceInstanceID, err), errs)
 			}
@@ -82,10 +115,11 @@ func (s *Storage) checkAllProvisionRequestDetails() (errs *multierror.Error) {
 	return errs
 }
 
-func (s *Storage) checkAllServiceInstanceDetails() (errs *multierror.Error) {
+func (s *Storage) checkAllServiceInstanceDetails(counts KeyCounts) (errs *multierror.Error) {
 	var serviceInstanceDetailsBatch []models.ServiceInstanceDetails
 	result := s.db.FindInBatches(&serviceInstanceDetailsBatch, 100, func(tx *gorm.DB, batchNumber int) error {
 		for i := range serviceInstanceDetailsBatch {
+			counts.add(s.keyLabel(serviceInstanceDetailsBatch[i].OtherDetails))
 			if _, err := s.decodeJSONObject(serviceInstanceDetailsBatch[i].OtherDetails); err != nil {
 				errs = multierror.Append(fmt.Errorf("decode error for service instance details %q: %w", serviceInstanceDetailsBatch[i].ID, err), errs)
 			}
@@ -100,11 +134,12 @@ func (s *Storage) checkAllServiceInstanceDetails() (errs *multierror.Error) {
 	return errs
 }
 
-func (s *Storage) checkAllTerraformDeployments() (errs *multierror.Error) {
+func (s *Storage) checkAllTerraformDeployments(counts KeyCounts) (errs *multierror.Error) {
 	var terraformDeploymentBatch []models.TerraformDeployment
 	result := s.db.FindInBatches(&terraformDeploymentBatch, 100, func(tx *gorm.DB, batchNumber int) error {
 		for i := range terraformDeploymentBatch {
 			var tfWorkspace workspace.TerraformWorkspace
+			counts.add(s.keyLabel(terraformDeploymentBatch[i].Workspace))
 			if err := s.decodeJSON(terraformDeploymentBatch[i].Workspace, &tfWorkspace); err != nil {
 				errs = multierror.Append(fmt.Errorf("decode error for terraform deployment %q: %w", terraformDeploymentBatch[i].ID, err), errs)
 			}
@@ -118,3 +153,25 @@ func (s *Storage) checkAllTerraformDeployments() (errs *multierror.Error) {
 
 	return errs
 }
+
+func (s *Storage) checkAllTerraformStates(counts KeyCounts) (errs *multierror.Error) {
+	var terraformStateBatch []models.TerraformState
+	result := s.db.FindInBatches(&terraformStateBatch, 100, func(tx *gorm.DB, batchNumber int) error {
+		for i := range terraformStateBatch {
+			if len(terraformStateBatch[i].State) == 0 {
+				continue
+			}
+			counts.add(s.keyLabel(terraformStateBatch[i].State))
+			if _, err := s.decodeBytes(terraformStateBatch[i].State); err != nil {
+				errs = multierror.Append(fmt.Errorf("decode error for terraform state %q: %w", terraformStateBatch[i].ID, err), errs)
+			}
+		}
+
+		return nil
+	})
+	if result.Error != nil {
+		errs = multierror.Append(fmt.Errorf("error reading terraform state: %w", result.Error), errs)
+	}
+
+	return errs
+}
@@ -2,8 +2,11 @@ package storage_test
 
 import (
 	"errors"
+	"strings"
 
 	"github.com/cloudfoundry/cloud-service-broker/dbservice/models"
+	"github.com/cloudfoundry/cloud-service-broker/internal/storage"
+	"github.com/cloudfoundry/cloud-service-broker/internal/storage/storagefakes"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
@@ -27,7 +30,29 @@ var _ = Describe("CheckAllRecords", func() {
 	})
 
 	It("does not fail", func() {
-		Expect(store.CheckAllRecords()).NotTo(HaveOccurred())
+		_, err := store.CheckAllRecords()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("counts records without a key label", func() {
+		Expect(store.CheckAllRecords()).To(Equal(storage.KeyCounts{"": 16}))
+	})
+
+	When("the encryptor can tell which password encrypted the data", func() {
+		It("counts records for each key label", func() {
+			Expect(db.Model(&models.ServiceInstanceDetails{}).Where("id = ?", "fake-id-1").Update("other_details", []byte(`label-one:{"foo":"bar"}`)).Error).NotTo(HaveOccurred())
+			Expect(db.Model(&models.TerraformDeployment{}).Where("id = ?", "fake-id-2").Update("workspace", []byte(`label-two:{}`)).Error).NotTo(HaveOccurred())
+
+			encryptor.DecryptStub = func(bytes []byte) ([]byte, error) {
+				if _, after, ok := strings.Cut(string(bytes), ":"); ok && strings.HasPrefix(string(bytes), "label-") {
+					return []byte(after), nil
+				}
+				return bytes, nil
+			}
+			labellingStore := storage.New(db, labellingEncryptor{FakeEncryptor: encryptor})
+
+			Expect(labellingStore.CheckAllRecords()).To(Equal(storage.KeyCounts{"": 14, "label-one": 1, "label-two": 1}))
+		})
 	})
 
 	When("the database contains invalid data", func() {
@@ -68,6 +93,13 @@ var _ = Describe("CheckAllRecords", func() {
 				ServiceInstanceID: "fake-bad-instance-id",
 			}).Error).NotTo(HaveOccurred())
 
+			Expect(db.Create(&models.BindRequestDetails{
+				RequestDetails:    []byte(`{}`),
+				RequestContext:    []byte(`request-context-not-json`),
+				ServiceBindingID:  "fake-bad-binding-id-3",
+				ServiceInstanceID: "fake-bad-instance-id",
+			}).Error).NotTo(HaveOccurred())
+
 			Expect(db.Create(&models.ServiceInstanceDetails{
 				ID:           "fake-bad-instance-id-1",
 				OtherDetails: []byte(`service-instance-not-json`),
@@ -101,22 +133,42 @@ var _ = Describe("CheckAllRecords", func() {
 				LastOperationState:   "succeeded",
 				LastOperationMessage: "amazing",
 			}).Error).NotTo(HaveOccurred())
+
+			Expect(db.Create(&models.TerraformState{
+				ID:    "fake-bad-id-1",
+				State: []byte("cannot-be-decrypted"),
+			}).Error).NotTo(HaveOccurred())
 		})
 
 		It("returns all errors", func() {
-			Expect(store.CheckAllRecords()).To(MatchError(And(
+			_, err := store.CheckAllRecords()
+			Expect(err).To(MatchError(And(
 				ContainSubstring(`decode error for service binding credential "fake-bad-binding-id-1": JSON parse error: invalid character 'b' looking for beginning of value`),
 				ContainSubstring(`decode error for service binding credential "fake-bad-binding-id-2": decryption error: fake decryption error`),
 				ContainSubstring(`decode error for provision request details "fake-bad-instance-id-1": decryption error: fake decryption error`),
 				ContainSubstring(`decode error for provision request details "fake-bad-instance-id-2": JSON parse error: invalid character 'r' looking for beginning of value`),
 				ContainSubstring(`decode error for binding request details "fake-bad-binding-id-1": decryption error: fake decryption error`),
 				ContainSubstring(`decode error for binding request details "fake-bad-binding-id-2": JSON parse error: invalid character 'r' looking for beginning of value`),
+				ContainSubstring(`decode error for binding request context "fake-bad-binding-id-3": JSON parse error: invalid character 'r' looking for beginning of value`),
 				ContainSubstring(`decode error for service instance details "fake-bad-instance-id-1": JSON parse error: invalid character 's' looking for beginning of value`),
 				ContainSubstring(`decode error for service instance details "fake-bad-instance-id-2": decryption error: fake decryption error`),
 				ContainSubstring(`decode error for terraform deployment "fake-bad-id-1": decryption error: fake decryption error`),
 				ContainSubstring(`decode error for terraform deployment "fake-bad-id-2": JSON parse error: invalid character 'w' looking for beginning of value`),
 				ContainSubstring(`decode error for terraform deployment "fake-bad-id-3": JSON parse error: json: cannot unmarshal number into Go struct field TerraformWorkspace.tfstate of type []uint8`),
+				ContainSubstring(`decode error for terraform state "fake-bad-id-1": decryption error: fake decryption error`),
 			)))
 		})
 	})
 })
+
+// labellingEncryptor reads a key label that is separated from the data by a colon
+type labellingEncryptor struct {
+	*storagefakes.FakeEncryptor
+}
+
+func (labellingEncryptor) KeyLabel(ciphertext []byte) (string, bool) {
+	if label, _, ok := strings.Cut(string(ciphertext), ":"); ok && strings.HasPrefix(label, "label-") {
+		return label, true
+	}
+	return "", false
+}
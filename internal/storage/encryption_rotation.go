@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cloudfoundry/cloud-service-broker/dbservice/models"
+)
+
+// EncryptionRotation is the progress of re-encrypting the database from one primary password to
+// another. Records are re-encrypted table by table in primary key order, and Checkpoint is the
+// primary key of the last record in CurrentTable that was re-encrypted. FinishedAt is zero until
+// every record has been re-encrypted and the new primary password has been recorded.
+type EncryptionRotation struct {
+	FromLabel    string
+	ToLabel      string
+	CurrentTable string
+	Checkpoint   string
+	Records      int
+	StartedAt    time.Time
+	FinishedAt   time.Time
+}
+
+// StoreEncryptionRotation creates or updates the progress of the rotation between the labels
+func (s *Storage) StoreEncryptionRotation(r EncryptionRotation) error {
+	var m models.EncryptionRotation
+	if err := s.db.Where("from_label = ? AND to_label = ?", r.FromLabel, r.ToLabel).Limit(1).Find(&m).Error; err != nil {
+		return fmt.Errorf("error finding encryption rotation: %w", err)
+	}
+
+	m.FromLabel = r.FromLabel
+	m.ToLabel = r.ToLabel
+	m.CurrentTable = r.CurrentTable
+	m.Checkpoint = r.Checkpoint
+	m.Records = r.Records
+	m.FinishedAt = nil
+	if !r.StartedAt.IsZero() {
+		m.CreatedAt = r.StartedAt
+	}
+	if !r.FinishedAt.IsZero() {
+		m.FinishedAt = &r.FinishedAt
+	}
+
+	if err := s.db.Save(&m).Error; err != nil {
+		return fmt.Errorf("error saving encryption rotation: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Storage) ExistsEncryptionRotation(fromLabel, toLabel string) (bool, error) {
+	var count int64
+	if err := s.db.Model(&models.EncryptionRotation{}).Where("from_label = ? AND to_label = ?", fromLabel, toLabel).Count(&count).Error; err != nil {
+		return false, fmt.Errorf("error counting encryption rotations: %w", err)
+	}
+	return count != 0, nil
+}
+
+func (s *Storage) GetEncryptionRotation(fromLabel, toLabel string) (EncryptionRotation, error) {
+	var receiver models.EncryptionRotation
+	if err := s.db.Where("from_label = ? AND to_label = ?", fromLabel, toLabel).First(&receiver).Error; err != nil {
+		return EncryptionRotation{}, fmt.Errorf("error finding encryption rotation: %w", err)
+	}
+
+	return encryptionRotationFromModel(receiver), nil
+}
+
+// GetEncryptionRotations returns every rotation, the most recently started last
+func (s *Storage) GetEncryptionRotations() ([]EncryptionRotation, error) {
+	var receiver []models.EncryptionRotation
+	if err := s.db.Order("created_at, id").Find(&receiver).Error; err != nil {
+		return nil, fmt.Errorf("error finding encryption rotations: %w", err)
+	}
+
+	result := make([]EncryptionRotation, 0, len(receiver))
+	for _, m := range receiver {
+		result = append(result, encryptionRotationFromModel(m))
+	}
+
+	return result, nil
+}
+
+func encryptionRotationFromModel(m models.EncryptionRotation) EncryptionRotation {
+	r := EncryptionRotation{
+		FromLabel:    m.FromLabel,
+		ToLabel:      m.ToLabel,
+		CurrentTable: m.CurrentTable,
+		Checkpoint:   m.Checkpoint,
+		Records:      m.Records,
+		StartedAt:    m.CreatedAt,
+	}
+	if m.FinishedAt != nil {
+		r.FinishedAt = *m.FinishedAt
+	}
+	return r
+}
+
+// CountEncryptedRecords returns the number of records that are re-encrypted by a rotation
+func (s *Storage) CountEncryptedRecords() (int64, error) {
+	var total int64
+	for _, c := range encryptedColumns {
+		var count int64
+		if err := s.db.Table(c.table).Count(&count).Error; err != nil {
+			return 0, fmt.Errorf("error counting %s: %w", c.description, err)
+		}
+		total += count
+	}
+
+	return total, nil
+}
@@ -0,0 +1,134 @@
+package storage_test
+
+import (
+	"time"
+
+	"github.com/cloudfoundry/cloud-service-broker/dbservice/models"
+	"github.com/cloudfoundry/cloud-service-broker/internal/storage"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("EncryptionRotation", func() {
+	var now time.Time
+
+	BeforeEach(func() {
+		now = time.Now().UTC().Truncate(time.Second)
+	})
+
+	Describe("StoreEncryptionRotation", func() {
+		It("creates the right object in the database", func() {
+			err := store.StoreEncryptionRotation(storage.EncryptionRotation{
+				FromLabel:    "old-label",
+				ToLabel:      "new-label",
+				CurrentTable: "terraform_deployments",
+				Checkpoint:   "fake-id-2",
+				Records:      42,
+				StartedAt:    now,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			var receiver models.EncryptionRotation
+			Expect(db.Find(&receiver).Error).NotTo(HaveOccurred())
+			Expect(receiver.FromLabel).To(Equal("old-label"))
+			Expect(receiver.ToLabel).To(Equal("new-label"))
+			Expect(receiver.CurrentTable).To(Equal("terraform_deployments"))
+			Expect(receiver.Checkpoint).To(Equal("fake-id-2"))
+			Expect(receiver.Records).To(Equal(42))
+			Expect(receiver.CreatedAt).To(BeTemporally("==", now))
+			Expect(receiver.FinishedAt).To(BeNil())
+		})
+
+		It("updates the progress of an existing rotation", func() {
+			Expect(store.StoreEncryptionRotation(storage.EncryptionRotation{FromLabel: "old-label", ToLabel: "new-label", StartedAt: now})).To(Succeed())
+			Expect(store.StoreEncryptionRotation(storage.EncryptionRotation{FromLabel: "other-label", ToLabel: "new-label", StartedAt: now})).To(Succeed())
+
+			err := store.StoreEncryptionRotation(storage.EncryptionRotation{
+				FromLabel:    "old-label",
+				ToLabel:      "new-label",
+				CurrentTable: "terraform_states",
+				Checkpoint:   "fake-id-3",
+				Records:      100,
+				FinishedAt:   now.Add(time.Minute),
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			var receiver []models.EncryptionRotation
+			Expect(db.Order("id").Find(&receiver).Error).NotTo(HaveOccurred())
+			Expect(receiver).To(HaveLen(2))
+			Expect(receiver[0].CurrentTable).To(Equal("terraform_states"))
+			Expect(receiver[0].Records).To(Equal(100))
+			Expect(receiver[0].CreatedAt).To(BeTemporally("==", now))
+			Expect(*receiver[0].FinishedAt).To(BeTemporally("==", now.Add(time.Minute)))
+			Expect(receiver[1].Records).To(BeZero())
+		})
+	})
+
+	Describe("ExistsEncryptionRotation", func() {
+		BeforeEach(func() {
+			Expect(store.StoreEncryptionRotation(storage.EncryptionRotation{ToLabel: "new-label"})).To(Succeed())
+		})
+
+		It("reports whether the rotation exists", func() {
+			Expect(store.ExistsEncryptionRotation("", "new-label")).To(BeTrue())
+			Expect(store.ExistsEncryptionRotation("old-label", "new-label")).To(BeFalse())
+		})
+	})
+
+	Describe("GetEncryptionRotation", func() {
+		BeforeEach(func() {
+			Expect(store.StoreEncryptionRotation(storage.EncryptionRotation{
+				FromLabel:    "old-label",
+				ToLabel:      "new-label",
+				CurrentTable: "bind_request_details",
+				Checkpoint:   "7",
+				Records:      12,
+				StartedAt:    now,
+			})).To(Succeed())
+		})
+
+		It("reads the rotation", func() {
+			r, err := store.GetEncryptionRotation("old-label", "new-label")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(r).To(Equal(storage.EncryptionRotation{
+				FromLabel:    "old-label",
+				ToLabel:      "new-label",
+				CurrentTable: "bind_request_details",
+				Checkpoint:   "7",
+				Records:      12,
+				StartedAt:    r.StartedAt,
+			}))
+			Expect(r.StartedAt).To(BeTemporally("==", now))
+		})
+
+		It("fails when the rotation does not exist", func() {
+			_, err := store.GetEncryptionRotation("new-label", "old-label")
+			Expect(err).To(MatchError("error finding encryption rotation: record not found"))
+		})
+	})
+
+	Describe("GetEncryptionRotations", func() {
+		It("returns the rotations, the most recently started last", func() {
+			Expect(store.StoreEncryptionRotation(storage.EncryptionRotation{FromLabel: "b", ToLabel: "c", StartedAt: now})).To(Succeed())
+			Expect(store.StoreEncryptionRotation(storage.EncryptionRotation{FromLabel: "a", ToLabel: "b", StartedAt: now.Add(-time.Hour), FinishedAt: now.Add(-time.Minute)})).To(Succeed())
+
+			rotations, err := store.GetEncryptionRotations()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rotations).To(HaveLen(2))
+			Expect(rotations[0].ToLabel).To(Equal("b"))
+			Expect(rotations[0].FinishedAt).To(BeTemporally("==", now.Add(-time.Minute)))
+			Expect(rotations[1].ToLabel).To(Equal("c"))
+			Expect(rotations[1].FinishedAt).To(BeZero())
+		})
+	})
+
+	Describe("CountEncryptedRecords", func() {
+		It("counts the records in every table that is re-encrypted", func() {
+			addFakeServiceCredentialBindings()
+			addFakeTerraformDeployments()
+			Expect(db.Create(&models.TerraformState{ID: "fake-id-1"}).Error).NotTo(HaveOccurred())
+
+			Expect(store.CountEncryptedRecords()).To(BeEquivalentTo(7))
+		})
+	})
+})
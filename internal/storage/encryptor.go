@@ -6,3 +6,8 @@ type Encryptor interface {
 	Encrypt(plaintext []byte) ([]byte, error)
 	Decrypt(ciphertext []byte) ([]byte, error)
 }
+
+// KeyLabeller is implemented by encryptors that can tell which password encrypted data
+type KeyLabeller interface {
+	KeyLabel(ciphertext []byte) (string, bool)
+}
@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cloudfoundry/cloud-service-broker/dbservice/models"
+)
+
+// Operation is an entry in the history of operations on a Terraform deployment.
+// FinishedAt is zero while the operation is in progress.
+type Operation struct {
+	DeploymentID        string
+	ServiceInstanceGUID string
+	Type                string
+	StartedAt           time.Time
+	FinishedAt          time.Time
+	TerraformVersion    string
+	BrokerpakVersion    string
+	State               string
+	Message             string
+}
+
+// OperationFilter selects operations. Empty fields match any value.
+type OperationFilter struct {
+	DeploymentID        string
+	ServiceInstanceGUID string
+}
+
+// StoreOperation adds an operation to the history
+func (s *Storage) StoreOperation(o Operation) error {
+	m := models.Operation{
+		DeploymentID:      o.DeploymentID,
+		ServiceInstanceID: o.ServiceInstanceGUID,
+		OperationType:     o.Type,
+		StartedAt:         o.StartedAt,
+		TerraformVersion:  o.TerraformVersion,
+		BrokerpakVersion:  o.BrokerpakVersion,
+		State:             o.State,
+		Message:           o.Message,
+	}
+	if !o.FinishedAt.IsZero() {
+		m.FinishedAt = &o.FinishedAt
+	}
+
+	if err := s.db.Create(&m).Error; err != nil {
+		return fmt.Errorf("error creating operation: %w", err)
+	}
+
+	return nil
+}
+
+// FinishOperation completes the latest unfinished operation on the deployment with the finish time,
+// Terraform version, state and message. When there is no unfinished operation, for instance because
+// it was started by an earlier version of the broker, the operation is added to the history.
+func (s *Storage) FinishOperation(o Operation) error {
+	var m models.Operation
+	result := s.db.Where("deployment_id = ? AND finished_at IS NULL", o.DeploymentID).Order("id desc").Limit(1).Find(&m)
+	switch {
+	case result.Error != nil:
+		return fmt.Errorf("error finding operation: %w", result.Error)
+	case result.RowsAffected == 0:
+		return s.StoreOperation(o)
+	}
+
+	err := s.db.Model(&m).Updates(map[string]interface{}{
+		"finished_at":       o.FinishedAt,
+		"terraform_version": o.TerraformVersion,
+		"state":             o.State,
+		"message":           o.Message,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("error finishing operation: %w", err)
+	}
+
+	return nil
+}
+
+// GetOperations returns the operations matching the filter, oldest first.
+func (s *Storage) GetOperations(f OperationFilter) ([]Operation, error) {
+	query := s.db.Order("started_at, id")
+	if f.DeploymentID != "" {
+		query = query.Where("deployment_id = ?", f.DeploymentID)
+	}
+	if f.ServiceInstanceGUID != "" {
+		query = query.Where("service_instance_id = ?", f.ServiceInstanceGUID)
+	}
+
+	var receiver []models.Operation
+	if err := query.Find(&receiver).Error; err != nil {
+		return nil, fmt.Errorf("error finding operations: %w", err)
+	}
+
+	result := make([]Operation, 0, len(receiver))
+	for _, m := range receiver {
+		o := Operation{
+			DeploymentID:        m.DeploymentID,
+			ServiceInstanceGUID: m.ServiceInstanceID,
+			Type:                m.OperationType,
+			StartedAt:           m.StartedAt,
+			TerraformVersion:    m.TerraformVersion,
+			BrokerpakVersion:    m.BrokerpakVersion,
+			State:               m.State,
+			Message:             m.Message,
+		}
+		if m.FinishedAt != nil {
+			o.FinishedAt = *m.FinishedAt
+		}
+		result = append(result, o)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,119 @@
+package storage_test
+
+import (
+	"time"
+
+	"github.com/cloudfoundry/cloud-service-broker/dbservice/models"
+	"github.com/cloudfoundry/cloud-service-broker/internal/storage"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Operation", func() {
+	var now time.Time
+
+	BeforeEach(func() {
+		now = time.Now().UTC().Truncate(time.Second)
+	})
+
+	Describe("StoreOperation", func() {
+		It("creates the right object in the database", func() {
+			err := store.StoreOperation(storage.Operation{
+				DeploymentID:        "tf:fake-instance-guid:",
+				ServiceInstanceGUID: "fake-instance-guid",
+				Type:                "provision",
+				StartedAt:           now,
+				BrokerpakVersion:    "1.2.3",
+				State:               "in progress",
+				Message:             "provision in progress",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			var receiver models.Operation
+			Expect(db.Find(&receiver).Error).NotTo(HaveOccurred())
+			Expect(receiver.DeploymentID).To(Equal("tf:fake-instance-guid:"))
+			Expect(receiver.ServiceInstanceID).To(Equal("fake-instance-guid"))
+			Expect(receiver.OperationType).To(Equal("provision"))
+			Expect(receiver.StartedAt).To(BeTemporally("==", now))
+			Expect(receiver.FinishedAt).To(BeNil())
+			Expect(receiver.BrokerpakVersion).To(Equal("1.2.3"))
+			Expect(receiver.State).To(Equal("in progress"))
+			Expect(receiver.Message).To(Equal("provision in progress"))
+		})
+	})
+
+	Describe("FinishOperation", func() {
+		It("completes the latest unfinished operation on the deployment", func() {
+			Expect(store.StoreOperation(storage.Operation{DeploymentID: "tf:fake-instance-guid:", Type: "provision", StartedAt: now.Add(-time.Hour), FinishedAt: now.Add(-time.Minute), State: "failed"})).To(Succeed())
+			Expect(store.StoreOperation(storage.Operation{DeploymentID: "tf:fake-instance-guid:", Type: "update", StartedAt: now.Add(-time.Second), State: "in progress", BrokerpakVersion: "1.2.3"})).To(Succeed())
+			Expect(store.StoreOperation(storage.Operation{DeploymentID: "tf:other-instance-guid:", Type: "provision", StartedAt: now, State: "in progress"})).To(Succeed())
+
+			err := store.FinishOperation(storage.Operation{
+				DeploymentID:     "tf:fake-instance-guid:",
+				FinishedAt:       now,
+				TerraformVersion: "1.1.6",
+				State:            "succeeded",
+				Message:          "update succeeded",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			operations, err := store.GetOperations(storage.OperationFilter{DeploymentID: "tf:fake-instance-guid:"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(operations).To(HaveLen(2))
+			Expect(operations[0].State).To(Equal("failed"))
+			Expect(operations[1].Type).To(Equal("update"))
+			Expect(operations[1].FinishedAt).To(BeTemporally("==", now))
+			Expect(operations[1].TerraformVersion).To(Equal("1.1.6"))
+			Expect(operations[1].BrokerpakVersion).To(Equal("1.2.3"))
+			Expect(operations[1].State).To(Equal("succeeded"))
+			Expect(operations[1].Message).To(Equal("update succeeded"))
+
+			others, err := store.GetOperations(storage.OperationFilter{DeploymentID: "tf:other-instance-guid:"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(others[0].FinishedAt).To(BeZero())
+		})
+
+		It("adds the operation when there is no unfinished operation", func() {
+			err := store.FinishOperation(storage.Operation{
+				DeploymentID: "tf:fake-instance-guid:",
+				Type:         "upgrade",
+				FinishedAt:   now,
+				State:        "succeeded",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			operations, err := store.GetOperations(storage.OperationFilter{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(operations).To(HaveLen(1))
+			Expect(operations[0].Type).To(Equal("upgrade"))
+			Expect(operations[0].FinishedAt).To(BeTemporally("==", now))
+		})
+	})
+
+	Describe("GetOperations", func() {
+		BeforeEach(func() {
+			Expect(store.StoreOperation(storage.Operation{DeploymentID: "tf:instance-1:", ServiceInstanceGUID: "instance-1", Type: "update", StartedAt: now})).To(Succeed())
+			Expect(store.StoreOperation(storage.Operation{DeploymentID: "tf:instance-1:", ServiceInstanceGUID: "instance-1", Type: "provision", StartedAt: now.Add(-time.Hour)})).To(Succeed())
+			Expect(store.StoreOperation(storage.Operation{DeploymentID: "tf:instance-1:binding-1", ServiceInstanceGUID: "instance-1", Type: "bind", StartedAt: now.Add(-time.Minute)})).To(Succeed())
+			Expect(store.StoreOperation(storage.Operation{DeploymentID: "tf:instance-2:", ServiceInstanceGUID: "instance-2", Type: "provision", StartedAt: now})).To(Succeed())
+		})
+
+		It("returns the operations on the service instance oldest first", func() {
+			operations, err := store.GetOperations(storage.OperationFilter{ServiceInstanceGUID: "instance-1"})
+			Expect(err).NotTo(HaveOccurred())
+
+			var types []string
+			for _, o := range operations {
+				types = append(types, o.Type)
+			}
+			Expect(types).To(Equal([]string{"provision", "bind", "update"}))
+		})
+
+		It("returns the operations on the deployment", func() {
+			operations, err := store.GetOperations(storage.OperationFilter{DeploymentID: "tf:instance-1:binding-1"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(operations).To(HaveLen(1))
+			Expect(operations[0].Type).To(Equal("bind"))
+		})
+	})
+})
@@ -4,20 +4,26 @@ import (
 	"fmt"
 
 	"github.com/cloudfoundry/cloud-service-broker/dbservice/models"
+	"gorm.io/gorm"
 )
 
 type ServiceInstanceDetails struct {
-	GUID             string
-	Name             string
-	Location         string
-	URL              string
-	Outputs          JSONObject
-	ServiceGUID      string
-	PlanGUID         string
-	SpaceGUID        string
-	OrganizationGUID string
-	OperationType    string
-	OperationGUID    string
+	GUID              string
+	Name              string
+	Location          string
+	URL               string
+	Outputs           JSONObject
+	ServiceGUID       string
+	PlanGUID          string
+	SpaceGUID         string
+	OrganizationGUID  string
+	OperationType     string
+	OperationGUID     string
+	MaintenanceWindow string
+
+	// Version is the version of the details that were read. Storing the details fails with a
+	// ConflictError unless it is still the current version, and makes the next version current.
+	Version int
 }
 
 func (s *Storage) StoreServiceInstanceDetails(d ServiceInstanceDetails) error {
@@ -26,32 +32,55 @@ func (s *Storage) StoreServiceInstanceDetails(d ServiceInstanceDetails) error {
 		return fmt.Errorf("error encoding details: %w", err)
 	}
 
-	var m models.ServiceInstanceDetails
-	if err := s.loadServiceInstanceDetailsIfExists(d.GUID, &m); err != nil {
+	exists, err := s.ExistsServiceInstanceDetails(d.GUID)
+	switch {
+	case err != nil:
 		return err
-	}
-
-	m.Name = d.Name
-	m.Location = d.Location
-	m.URL = d.URL
-	m.OtherDetails = encoded
-	m.ServiceID = d.ServiceGUID
-	m.PlanID = d.PlanGUID
-	m.SpaceGUID = d.SpaceGUID
-	m.OrganizationGUID = d.OrganizationGUID
-	m.OperationType = d.OperationType
-	m.OperationID = d.OperationGUID
-
-	switch m.ID {
-	case "":
-		m.ID = d.GUID
+	case !exists && d.Version != 0:
+		return &ConflictError{Kind: "service instance details", ID: d.GUID, Version: d.Version}
+	case !exists:
+		m := models.ServiceInstanceDetails{
+			ID:                d.GUID,
+			Name:              d.Name,
+			Location:          d.Location,
+			URL:               d.URL,
+			OtherDetails:      encoded,
+			ServiceID:         d.ServiceGUID,
+			PlanID:            d.PlanGUID,
+			SpaceGUID:         d.SpaceGUID,
+			OrganizationGUID:  d.OrganizationGUID,
+			OperationType:     d.OperationType,
+			OperationID:       d.OperationGUID,
+			MaintenanceWindow: d.MaintenanceWindow,
+			Version:           1,
+		}
 		if err := s.db.Create(&m).Error; err != nil {
 			return fmt.Errorf("error creating service instance details: %w", err)
 		}
-	default:
-		if err := s.db.Save(&m).Error; err != nil {
-			return fmt.Errorf("error saving service instance details: %w", err)
-		}
+		return nil
+	}
+
+	result := s.db.Model(&models.ServiceInstanceDetails{}).
+		Where("id = ? AND version = ?", d.GUID, d.Version).
+		Updates(map[string]interface{}{
+			"name":               d.Name,
+			"location":           d.Location,
+			"url":                d.URL,
+			"other_details":      encoded,
+			"service_id":         d.ServiceGUID,
+			"plan_id":            d.PlanGUID,
+			"space_guid":         d.SpaceGUID,
+			"organization_guid":  d.OrganizationGUID,
+			"operation_type":     d.OperationType,
+			"operation_id":       d.OperationGUID,
+			"maintenance_window": d.MaintenanceWindow,
+			"version":            d.Version + 1,
+		})
+	switch {
+	case result.Error != nil:
+		return fmt.Errorf("error saving service instance details: %w", result.Error)
+	case result.RowsAffected == 0:
+		return &ConflictError{Kind: "service instance details", ID: d.GUID, Version: d.Version}
 	}
 
 	return nil
@@ -65,6 +94,49 @@ func (s *Storage) ExistsServiceInstanceDetails(guid string) (bool, error) {
 	return count != 0, nil
 }
 
+// ServiceInstanceFilter restricts the service instances that are counted or listed.
+// Fields that are empty match any value.
+type ServiceInstanceFilter struct {
+	OrganizationGUID string
+	SpaceGUID        string
+	ServiceGUID      string
+	PlanGUID         string
+}
+
+func (s *Storage) CountServiceInstanceDetails(f ServiceInstanceFilter) (int64, error) {
+	var count int64
+	if err := s.filterServiceInstanceDetails(f).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("error counting service instance details: %w", err)
+	}
+	return count, nil
+}
+
+// GetServiceInstanceGUIDs returns the GUIDs of the service instances that match the filter, in order
+func (s *Storage) GetServiceInstanceGUIDs(f ServiceInstanceFilter) ([]string, error) {
+	var guids []string
+	if err := s.filterServiceInstanceDetails(f).Order("id").Pluck("id", &guids).Error; err != nil {
+		return nil, fmt.Errorf("error listing service instance details: %w", err)
+	}
+	return guids, nil
+}
+
+func (s *Storage) filterServiceInstanceDetails(f ServiceInstanceFilter) *gorm.DB {
+	query := s.db.Model(&models.ServiceInstanceDetails{})
+	if f.OrganizationGUID != "" {
+		query = query.Where("organization_guid = ?", f.OrganizationGUID)
+	}
+	if f.SpaceGUID != "" {
+		query = query.Where("space_guid = ?", f.SpaceGUID)
+	}
+	if f.ServiceGUID != "" {
+		query = query.Where("service_id = ?", f.ServiceGUID)
+	}
+	if f.PlanGUID != "" {
+		query = query.Where("plan_id = ?", f.PlanGUID)
+	}
+	return query
+}
+
 func (s *Storage) GetServiceInstanceDetails(guid string) (ServiceInstanceDetails, error) {
 	exists, err := s.ExistsServiceInstanceDetails(guid)
 	switch {
@@ -85,17 +157,19 @@ func (s *Storage) GetServiceInstanceDetails(guid string) (ServiceInstanceDetails
 	}
 
 	return ServiceInstanceDetails{
-		GUID:             guid,
-		Name:             receiver.Name,
-		Location:         receiver.Location,
-		URL:              receiver.URL,
-		Outputs:          decoded,
-		ServiceGUID:      receiver.ServiceID,
-		PlanGUID:         receiver.PlanID,
-		SpaceGUID:        receiver.SpaceGUID,
-		OrganizationGUID: receiver.OrganizationGUID,
-		OperationType:    receiver.OperationType,
-		OperationGUID:    receiver.OperationID,
+		GUID:              guid,
+		Name:              receiver.Name,
+		Location:          receiver.Location,
+		URL:               receiver.URL,
+		Outputs:           decoded,
+		ServiceGUID:       receiver.ServiceID,
+		PlanGUID:          receiver.PlanID,
+		SpaceGUID:         receiver.SpaceGUID,
+		OrganizationGUID:  receiver.OrganizationGUID,
+		OperationType:     receiver.OperationType,
+		OperationGUID:     receiver.OperationID,
+		MaintenanceWindow: receiver.MaintenanceWindow,
+		Version:           receiver.Version,
 	}, nil
 }
 
@@ -106,19 +180,3 @@ func (s *Storage) DeleteServiceInstanceDetails(guid string) error {
 	}
 	return nil
 }
-
-func (s *Storage) loadServiceInstanceDetailsIfExists(guid string, receiver interface{}) error {
-	if guid == "" {
-		return nil
-	}
-
-	exists, err := s.ExistsServiceInstanceDetails(guid)
-	switch {
-	case err != nil:
-		return err
-	case !exists:
-		return nil
-	}
-
-	return s.db.Where("id = ?", guid).First(receiver).Error
-}
@@ -13,17 +13,18 @@ var _ = Describe("ServiceInstanceDetails", func() {
 	Describe("StoreServiceInstanceDetails", func() {
 		It("creates the right object in the database", func() {
 			err := store.StoreServiceInstanceDetails(storage.ServiceInstanceDetails{
-				GUID:             "fake-guid",
-				Name:             "fake-name",
-				Location:         "fake-location",
-				URL:              "fake-url",
-				Outputs:          map[string]interface{}{"foo": "bar"},
-				ServiceGUID:      "fake-service-guid",
-				PlanGUID:         "fake-plan-guid",
-				SpaceGUID:        "fake-space-guid",
-				OrganizationGUID: "fake-org-guid",
-				OperationType:    "fake-operation-type",
-				OperationGUID:    "fake-operation-guid",
+				GUID:              "fake-guid",
+				Name:              "fake-name",
+				Location:          "fake-location",
+				URL:               "fake-url",
+				Outputs:           map[string]interface{}{"foo": "bar"},
+				ServiceGUID:       "fake-service-guid",
+				PlanGUID:          "fake-plan-guid",
+				SpaceGUID:         "fake-space-guid",
+				OrganizationGUID:  "fake-org-guid",
+				OperationType:     "fake-operation-type",
+				OperationGUID:     "fake-operation-guid",
+				MaintenanceWindow: "sunday 02:00-04:00",
 			})
 			Expect(err).NotTo(HaveOccurred())
 
@@ -40,6 +41,7 @@ var _ = Describe("ServiceInstanceDetails", func() {
 			Expect(receiver.OrganizationGUID).To(Equal("fake-org-guid"))
 			Expect(receiver.OperationType).To(Equal("fake-operation-type"))
 			Expect(receiver.OperationID).To(Equal("fake-operation-guid"))
+			Expect(receiver.MaintenanceWindow).To(Equal("sunday 02:00-04:00"))
 		})
 
 		When("encoding fails", func() {
@@ -85,7 +87,76 @@ var _ = Describe("ServiceInstanceDetails", func() {
 				Expect(receiver.OrganizationGUID).To(Equal("fake-org-guid"))
 				Expect(receiver.OperationType).To(Equal("fake-operation-type"))
 				Expect(receiver.OperationID).To(Equal("fake-operation-guid"))
+				Expect(receiver.Version).To(Equal(1))
 			})
+
+			It("fails when the details were changed since they were read", func() {
+				err := store.StoreServiceInstanceDetails(storage.ServiceInstanceDetails{
+					GUID:    "fake-id-2",
+					Name:    "stale-name",
+					Version: 3,
+				})
+				Expect(err).To(MatchError(`service instance details "fake-id-2" was modified concurrently: version 3 is no longer current`))
+				Expect(storage.IsConflict(err)).To(BeTrue())
+
+				var receiver models.ServiceInstanceDetails
+				Expect(db.Where(`id = "fake-id-2"`).Find(&receiver).Error).NotTo(HaveOccurred())
+				Expect(receiver.Name).To(Equal("fake-name-2"))
+				Expect(receiver.Version).To(Equal(4))
+			})
+		})
+
+		When("details for the instance were deleted since they were read", func() {
+			It("returns a conflict", func() {
+				err := store.StoreServiceInstanceDetails(storage.ServiceInstanceDetails{GUID: "fake-id-4", Version: 2})
+				Expect(storage.IsConflict(err)).To(BeTrue())
+			})
+		})
+	})
+
+	Describe("CountServiceInstanceDetails", func() {
+		BeforeEach(func() {
+			addFakeServiceInstanceDetails()
+			Expect(db.Create(&models.ServiceInstanceDetails{
+				ID:               "fake-id-4",
+				ServiceID:        "fake-service-id-1",
+				PlanID:           "fake-plan-id-3",
+				SpaceGUID:        "fake-space-guid-3",
+				OrganizationGUID: "fake-org-guid-1",
+			}).Error).NotTo(HaveOccurred())
+		})
+
+		It("counts all instances when the filter is empty", func() {
+			Expect(store.CountServiceInstanceDetails(storage.ServiceInstanceFilter{})).To(BeNumerically("==", 4))
+		})
+
+		It("counts the instances matching every field of the filter", func() {
+			Expect(store.CountServiceInstanceDetails(storage.ServiceInstanceFilter{OrganizationGUID: "fake-org-guid-1"})).To(BeNumerically("==", 2))
+			Expect(store.CountServiceInstanceDetails(storage.ServiceInstanceFilter{SpaceGUID: "fake-space-guid-3"})).To(BeNumerically("==", 2))
+			Expect(store.CountServiceInstanceDetails(storage.ServiceInstanceFilter{
+				OrganizationGUID: "fake-org-guid-1",
+				ServiceGUID:      "fake-service-id-1",
+			})).To(BeNumerically("==", 2))
+			Expect(store.CountServiceInstanceDetails(storage.ServiceInstanceFilter{
+				OrganizationGUID: "fake-org-guid-1",
+				PlanGUID:         "fake-plan-id-3",
+			})).To(BeNumerically("==", 1))
+			Expect(store.CountServiceInstanceDetails(storage.ServiceInstanceFilter{SpaceGUID: "not-there"})).To(BeZero())
+		})
+	})
+
+	Describe("GetServiceInstanceGUIDs", func() {
+		BeforeEach(func() {
+			addFakeServiceInstanceDetails()
+		})
+
+		It("lists all instances when the filter is empty", func() {
+			Expect(store.GetServiceInstanceGUIDs(storage.ServiceInstanceFilter{})).To(Equal([]string{"fake-id-1", "fake-id-2", "fake-id-3"}))
+		})
+
+		It("lists the instances matching the filter", func() {
+			Expect(store.GetServiceInstanceGUIDs(storage.ServiceInstanceFilter{ServiceGUID: "fake-service-id-2"})).To(Equal([]string{"fake-id-2"}))
+			Expect(store.GetServiceInstanceGUIDs(storage.ServiceInstanceFilter{PlanGUID: "not-there"})).To(BeEmpty())
 		})
 	})
 
@@ -108,6 +179,8 @@ var _ = Describe("ServiceInstanceDetails", func() {
 			Expect(r.OrganizationGUID).To(Equal("fake-org-guid-2"))
 			Expect(r.OperationType).To(Equal("fake-operation-type-2"))
 			Expect(r.OperationGUID).To(Equal("fake-operation-id-2"))
+			Expect(r.MaintenanceWindow).To(Equal("monday 22:00-02:00"))
+			Expect(r.Version).To(Equal(4))
 		})
 
 		When("decoding fails", func() {
@@ -189,17 +262,19 @@ func addFakeServiceInstanceDetails() {
 		OperationID:      "fake-operation-id-1",
 	}).Error).NotTo(HaveOccurred())
 	Expect(db.Create(&models.ServiceInstanceDetails{
-		ID:               "fake-id-2",
-		Name:             "fake-name-2",
-		Location:         "fake-location-2",
-		URL:              "fake-url-2",
-		OtherDetails:     []byte(`{"foo":"bar-2"}`),
-		ServiceID:        "fake-service-id-2",
-		PlanID:           "fake-plan-id-2",
-		SpaceGUID:        "fake-space-guid-2",
-		OrganizationGUID: "fake-org-guid-2",
-		OperationType:    "fake-operation-type-2",
-		OperationID:      "fake-operation-id-2",
+		ID:                "fake-id-2",
+		Name:              "fake-name-2",
+		Location:          "fake-location-2",
+		URL:               "fake-url-2",
+		OtherDetails:      []byte(`{"foo":"bar-2"}`),
+		ServiceID:         "fake-service-id-2",
+		PlanID:            "fake-plan-id-2",
+		SpaceGUID:         "fake-space-guid-2",
+		OrganizationGUID:  "fake-org-guid-2",
+		OperationType:     "fake-operation-type-2",
+		OperationID:       "fake-operation-id-2",
+		MaintenanceWindow: "monday 22:00-02:00",
+		Version:           4,
 	}).Error).NotTo(HaveOccurred())
 	Expect(db.Create(&models.ServiceInstanceDetails{
 		ID:               "fake-id-3",
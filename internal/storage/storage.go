@@ -1,6 +1,11 @@
 package storage
 
-import "gorm.io/gorm"
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
 
 type Storage struct {
 	db        *gorm.DB
@@ -15,3 +20,21 @@ func New(db *gorm.DB, encryptor Encryptor) *Storage {
 }
 
 type JSONObject map[string]interface{}
+
+// ConflictError is returned when a record cannot be stored because it was changed or deleted
+// since it was read. The record should be read again, and the change made again.
+type ConflictError struct {
+	Kind    string
+	ID      string
+	Version int
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s %q was modified concurrently: version %d is no longer current", e.Kind, e.ID, e.Version)
+}
+
+// IsConflict returns whether the error is, or wraps, a ConflictError
+func IsConflict(err error) bool {
+	var conflict *ConflictError
+	return errors.As(err, &conflict)
+}
@@ -38,6 +38,11 @@ var _ = BeforeEach(func() {
 	Expect(db.Migrator().CreateTable(&models.BindRequestDetails{})).NotTo(HaveOccurred())
 	Expect(db.Migrator().CreateTable(&models.ServiceInstanceDetails{})).NotTo(HaveOccurred())
 	Expect(db.Migrator().CreateTable(&models.TerraformDeployment{})).NotTo(HaveOccurred())
+	Expect(db.Migrator().CreateTable(&models.AuditEvent{})).NotTo(HaveOccurred())
+	Expect(db.Migrator().CreateTable(&models.WebhookDelivery{})).NotTo(HaveOccurred())
+	Expect(db.Migrator().CreateTable(&models.TerraformState{})).NotTo(HaveOccurred())
+	Expect(db.Migrator().CreateTable(&models.Operation{})).NotTo(HaveOccurred())
+	Expect(db.Migrator().CreateTable(&models.EncryptionRotation{})).NotTo(HaveOccurred())
 
 	encryptor = &storagefakes.FakeEncryptor{
 		DecryptStub: func(bytes []byte) ([]byte, error) {
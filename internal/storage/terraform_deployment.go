@@ -14,6 +14,10 @@ type TerraformDeployment struct {
 	LastOperationType    string
 	LastOperationState   string
 	LastOperationMessage string
+
+	// Version is the version of the deployment that was read. Storing the deployment fails with a
+	// ConflictError unless it is still the current version, and makes the next version current.
+	Version int
 }
 
 func (deployment *TerraformDeployment) TFWorkspace() *workspace.TerraformWorkspace {
@@ -26,26 +30,41 @@ func (s *Storage) StoreTerraformDeployment(t TerraformDeployment) error {
 		return fmt.Errorf("error encoding workspace: %w", err)
 	}
 
-	var m models.TerraformDeployment
-	if err := s.loadTerraformDeploymentIfExists(t.ID, &m); err != nil {
+	exists, err := s.ExistsTerraformDeployment(t.ID)
+	switch {
+	case err != nil:
 		return err
-	}
-
-	m.Workspace = encoded
-	m.LastOperationType = t.LastOperationType
-	m.LastOperationState = t.LastOperationState
-	m.LastOperationMessage = t.LastOperationMessage
-
-	switch m.ID {
-	case "":
-		m.ID = t.ID
+	case !exists && t.Version != 0:
+		return &ConflictError{Kind: "terraform deployment", ID: t.ID, Version: t.Version}
+	case !exists:
+		m := models.TerraformDeployment{
+			ID:                   t.ID,
+			Workspace:            encoded,
+			LastOperationType:    t.LastOperationType,
+			LastOperationState:   t.LastOperationState,
+			LastOperationMessage: t.LastOperationMessage,
+			Version:              1,
+		}
 		if err := s.db.Create(&m).Error; err != nil {
 			return fmt.Errorf("error creating terraform deployment: %w", err)
 		}
-	default:
-		if err := s.db.Save(&m).Error; err != nil {
-			return fmt.Errorf("error saving terraform deployment: %w", err)
-		}
+		return nil
+	}
+
+	result := s.db.Model(&models.TerraformDeployment{}).
+		Where("id = ? AND version = ?", t.ID, t.Version).
+		Updates(map[string]interface{}{
+			"workspace":              encoded,
+			"last_operation_type":    t.LastOperationType,
+			"last_operation_state":   t.LastOperationState,
+			"last_operation_message": t.LastOperationMessage,
+			"version":                t.Version + 1,
+		})
+	switch {
+	case result.Error != nil:
+		return fmt.Errorf("error saving terraform deployment: %w", result.Error)
+	case result.RowsAffected == 0:
+		return &ConflictError{Kind: "terraform deployment", ID: t.ID, Version: t.Version}
 	}
 
 	return nil
@@ -75,6 +94,7 @@ func (s *Storage) GetTerraformDeployment(id string) (TerraformDeployment, error)
 		LastOperationState:   receiver.LastOperationState,
 		LastOperationMessage: receiver.LastOperationMessage,
 		Workspace:            &tfWorkspace,
+		Version:              receiver.Version,
 	}, nil
 }
 
@@ -91,21 +111,5 @@ func (s *Storage) DeleteTerraformDeployment(id string) error {
 	if err != nil {
 		return fmt.Errorf("error deleting terraform deployment: %w", err)
 	}
-	return nil
-}
-
-func (s *Storage) loadTerraformDeploymentIfExists(id string, receiver interface{}) error {
-	if id == "" {
-		return nil
-	}
-
-	exists, err := s.ExistsTerraformDeployment(id)
-	switch {
-	case err != nil:
-		return err
-	case !exists:
-		return nil
-	}
-
-	return s.db.Where("id = ?", id).First(receiver).Error
+	return s.DeleteTerraformState(id)
 }
@@ -99,6 +99,49 @@ var _ = Describe("TerraformDeployments", func() {
 				Expect(receiver.LastOperationType).To(Equal("create"))
 				Expect(receiver.LastOperationState).To(Equal("succeeded"))
 				Expect(receiver.LastOperationMessage).To(Equal("yes!!"))
+				Expect(receiver.Version).To(Equal(1))
+			})
+
+			It("makes the next version current", func() {
+				d, err := store.GetTerraformDeployment("fake-id-2")
+				Expect(err).NotTo(HaveOccurred())
+
+				d.LastOperationMessage = "first"
+				Expect(store.StoreTerraformDeployment(d)).To(Succeed())
+				d.Version++
+				d.LastOperationMessage = "second"
+				Expect(store.StoreTerraformDeployment(d)).To(Succeed())
+
+				r, err := store.GetTerraformDeployment("fake-id-2")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(r.Version).To(Equal(2))
+				Expect(r.LastOperationMessage).To(Equal("second"))
+			})
+
+			It("fails when the deployment was changed since it was read", func() {
+				d, err := store.GetTerraformDeployment("fake-id-2")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(store.StoreTerraformDeployment(d)).To(Succeed())
+
+				d.LastOperationMessage = "stale"
+				err = store.StoreTerraformDeployment(d)
+				Expect(err).To(MatchError(`terraform deployment "fake-id-2" was modified concurrently: version 0 is no longer current`))
+				Expect(storage.IsConflict(err)).To(BeTrue())
+
+				r, err := store.GetTerraformDeployment("fake-id-2")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(r.LastOperationMessage).To(Equal("too bad"))
+			})
+
+			It("fails when the deployment was deleted since it was read", func() {
+				d, err := store.GetTerraformDeployment("fake-id-2")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(store.StoreTerraformDeployment(d)).To(Succeed())
+				d.Version++
+				Expect(store.DeleteTerraformDeployment("fake-id-2")).To(Succeed())
+
+				err = store.StoreTerraformDeployment(d)
+				Expect(storage.IsConflict(err)).To(BeTrue())
 			})
 		})
 	})
@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cloudfoundry/cloud-service-broker/dbservice/models"
+	"gorm.io/gorm/clause"
+)
+
+// ErrTerraformStateLocked is returned when the Terraform state is locked by somebody else
+var ErrTerraformStateLocked = errors.New("terraform state is locked")
+
+// GetTerraformState returns the Terraform state written through the HTTP backend,
+// or nil if there is none
+func (s *Storage) GetTerraformState(id string) ([]byte, error) {
+	var receiver []models.TerraformState
+	if err := s.db.Where("id = ?", id).Find(&receiver).Error; err != nil {
+		return nil, fmt.Errorf("error finding terraform state: %w", err)
+	}
+	if len(receiver) == 0 || len(receiver[0].State) == 0 {
+		return nil, nil
+	}
+
+	state, err := s.decodeBytes(receiver[0].State)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding terraform state %q: %w", id, err)
+	}
+	return state, nil
+}
+
+func (s *Storage) StoreTerraformState(id string, state []byte) error {
+	encoded, err := s.encodeBytes(state)
+	if err != nil {
+		return fmt.Errorf("error encoding terraform state: %w", err)
+	}
+
+	if err := s.createTerraformStateIfNotExists(id); err != nil {
+		return err
+	}
+
+	if err := s.db.Model(&models.TerraformState{ID: id}).Update("state", encoded).Error; err != nil {
+		return fmt.Errorf("error saving terraform state: %w", err)
+	}
+	return nil
+}
+
+// LockTerraformState takes the lock on the Terraform state. When the lock is held with a different
+// lock ID, the information about the lock that is held is returned with ErrTerraformStateLocked.
+func (s *Storage) LockTerraformState(id, lockID string, info []byte) ([]byte, error) {
+	if err := s.createTerraformStateIfNotExists(id); err != nil {
+		return nil, err
+	}
+
+	result := s.db.Model(&models.TerraformState{}).
+		Where("id = ? AND (lock_id = '' OR lock_id IS NULL)", id).
+		Updates(map[string]interface{}{"lock_id": lockID, "lock_info": info})
+	switch {
+	case result.Error != nil:
+		return nil, fmt.Errorf("error locking terraform state: %w", result.Error)
+	case result.RowsAffected == 1:
+		return nil, nil
+	}
+
+	var receiver models.TerraformState
+	if err := s.db.Where("id = ?", id).First(&receiver).Error; err != nil {
+		return nil, fmt.Errorf("error finding terraform state lock: %w", err)
+	}
+	if receiver.LockID == lockID {
+		return nil, nil
+	}
+	return receiver.LockInfo, ErrTerraformStateLocked
+}
+
+// UnlockTerraformState releases the lock on the Terraform state. An empty lock ID
+// releases the lock whoever holds it.
+func (s *Storage) UnlockTerraformState(id, lockID string) error {
+	query := s.db.Model(&models.TerraformState{}).Where("id = ?", id)
+	if lockID != "" {
+		query = query.Where("lock_id = ?", lockID)
+	}
+
+	result := query.Updates(map[string]interface{}{"lock_id": "", "lock_info": nil})
+	switch {
+	case result.Error != nil:
+		return fmt.Errorf("error unlocking terraform state: %w", result.Error)
+	case result.RowsAffected == 0 && lockID != "":
+		return ErrTerraformStateLocked
+	}
+	return nil
+}
+
+func (s *Storage) DeleteTerraformState(id string) error {
+	if err := s.db.Where("id = ?", id).Delete(&models.TerraformState{}).Error; err != nil {
+		return fmt.Errorf("error deleting terraform state: %w", err)
+	}
+	return nil
+}
+
+func (s *Storage) createTerraformStateIfNotExists(id string) error {
+	if err := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.TerraformState{ID: id}).Error; err != nil {
+		return fmt.Errorf("error creating terraform state: %w", err)
+	}
+	return nil
+}
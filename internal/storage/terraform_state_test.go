@@ -0,0 +1,141 @@
+package storage_test
+
+import (
+	"github.com/cloudfoundry/cloud-service-broker/dbservice/models"
+	"github.com/cloudfoundry/cloud-service-broker/internal/storage"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TerraformState", func() {
+	Describe("StoreTerraformState", func() {
+		It("creates the right object in the database", func() {
+			Expect(store.StoreTerraformState("tf:instance:", []byte(`"state"`))).To(Succeed())
+
+			var receiver models.TerraformState
+			Expect(db.Where("id = ?", "tf:instance:").First(&receiver).Error).NotTo(HaveOccurred())
+			Expect(receiver.State).To(Equal([]byte(`{"encrypted":"state"}`)))
+		})
+
+		It("updates the state and keeps the lock", func() {
+			Expect(store.StoreTerraformState("tf:instance:", []byte(`"first"`))).To(Succeed())
+			_, err := store.LockTerraformState("tf:instance:", "lock-id", []byte(`{"ID":"lock-id"}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(store.StoreTerraformState("tf:instance:", []byte(`"second"`))).To(Succeed())
+
+			var receiver models.TerraformState
+			Expect(db.Where("id = ?", "tf:instance:").First(&receiver).Error).NotTo(HaveOccurred())
+			Expect(receiver.State).To(Equal([]byte(`{"encrypted":"second"}`)))
+			Expect(receiver.LockID).To(Equal("lock-id"))
+		})
+
+		When("encoding fails", func() {
+			It("returns an error", func() {
+				err := store.StoreTerraformState("tf:instance:", []byte(`"cannot-be-encrypted"`))
+				Expect(err).To(MatchError("error encoding terraform state: encryption error: fake encryption error"))
+			})
+		})
+	})
+
+	Describe("GetTerraformState", func() {
+		It("reads the state from the database", func() {
+			Expect(db.Create(&models.TerraformState{ID: "tf:instance:", State: []byte(`"state"`)}).Error).NotTo(HaveOccurred())
+
+			state, err := store.GetTerraformState("tf:instance:")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state).To(Equal([]byte(`{"decrypted":"state"}`)))
+		})
+
+		It("returns nil when there is no state", func() {
+			state, err := store.GetTerraformState("tf:instance:")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state).To(BeNil())
+
+			_, err = store.LockTerraformState("tf:instance:", "lock-id", nil)
+			Expect(err).NotTo(HaveOccurred())
+			state, err = store.GetTerraformState("tf:instance:")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state).To(BeNil())
+		})
+
+		When("decoding fails", func() {
+			It("returns an error", func() {
+				Expect(db.Create(&models.TerraformState{ID: "tf:instance:", State: []byte(`cannot-be-decrypted`)}).Error).NotTo(HaveOccurred())
+
+				_, err := store.GetTerraformState("tf:instance:")
+				Expect(err).To(MatchError(`error decoding terraform state "tf:instance:": decryption error: fake decryption error`))
+			})
+		})
+	})
+
+	Describe("LockTerraformState", func() {
+		It("takes the lock", func() {
+			info, err := store.LockTerraformState("tf:instance:", "lock-id", []byte(`{"ID":"lock-id"}`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info).To(BeNil())
+
+			var receiver models.TerraformState
+			Expect(db.Where("id = ?", "tf:instance:").First(&receiver).Error).NotTo(HaveOccurred())
+			Expect(receiver.LockID).To(Equal("lock-id"))
+			Expect(receiver.LockInfo).To(Equal([]byte(`{"ID":"lock-id"}`)))
+		})
+
+		It("succeeds when the same lock is taken again", func() {
+			_, err := store.LockTerraformState("tf:instance:", "lock-id", []byte(`{"ID":"lock-id"}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = store.LockTerraformState("tf:instance:", "lock-id", []byte(`{"ID":"lock-id"}`))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("returns the lock information when the lock is held by somebody else", func() {
+			_, err := store.LockTerraformState("tf:instance:", "lock-id", []byte(`{"ID":"lock-id"}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			info, err := store.LockTerraformState("tf:instance:", "other-lock-id", []byte(`{"ID":"other-lock-id"}`))
+			Expect(err).To(MatchError(storage.ErrTerraformStateLocked))
+			Expect(info).To(Equal([]byte(`{"ID":"lock-id"}`)))
+		})
+	})
+
+	Describe("UnlockTerraformState", func() {
+		BeforeEach(func() {
+			_, err := store.LockTerraformState("tf:instance:", "lock-id", []byte(`{"ID":"lock-id"}`))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("releases the lock", func() {
+			Expect(store.UnlockTerraformState("tf:instance:", "lock-id")).To(Succeed())
+
+			_, err := store.LockTerraformState("tf:instance:", "other-lock-id", nil)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("fails when the lock is held by somebody else", func() {
+			Expect(store.UnlockTerraformState("tf:instance:", "other-lock-id")).To(MatchError(storage.ErrTerraformStateLocked))
+		})
+
+		It("releases any lock when the lock ID is empty", func() {
+			Expect(store.UnlockTerraformState("tf:instance:", "")).To(Succeed())
+
+			var receiver models.TerraformState
+			Expect(db.Where("id = ?", "tf:instance:").First(&receiver).Error).NotTo(HaveOccurred())
+			Expect(receiver.LockID).To(BeEmpty())
+			Expect(receiver.LockInfo).To(BeNil())
+		})
+	})
+
+	Describe("DeleteTerraformState", func() {
+		It("deletes from the database", func() {
+			Expect(store.StoreTerraformState("tf:instance:", []byte(`"state"`))).To(Succeed())
+			Expect(store.StoreTerraformState("tf:other:", []byte(`"state"`))).To(Succeed())
+
+			Expect(store.DeleteTerraformState("tf:instance:")).To(Succeed())
+
+			var count int64
+			Expect(db.Model(&models.TerraformState{}).Count(&count).Error).NotTo(HaveOccurred())
+			Expect(count).To(BeNumerically("==", 1))
+		})
+	})
+})
@@ -2,143 +2,144 @@ package storage
 
 import (
 	"fmt"
-
-	"github.com/cloudfoundry/cloud-service-broker/dbservice/models"
-	"gorm.io/gorm"
 )
 
-func (s *Storage) UpdateAllRecords() error {
-	updaters := []func() error{
-		s.updateAllServiceBindingCredentials,
-		s.updateAllBindRequestDetails,
-		s.updateAllProvisionRequestDetails,
-		s.updateAllServiceInstanceDetails,
-		s.updateAllTerraformDeployments,
-	}
-	for _, e := range updaters {
-		if err := e(); err != nil {
-			return err
-		}
+const updateBatchSize = 100
+
+// encryptedColumn describes a column that holds encrypted data
+type encryptedColumn struct {
+	table string
+	// column holds the encrypted data
+	column string
+	// description is used in error messages
+	description string
+	// identifier is the column that identifies a record in error messages
+	identifier string
+	// optional columns may be empty, in which case they are not encrypted
+	optional bool
+	// checkpoint names the column in encryption rotation checkpoints, and defaults to the table
+	// name. It must be set when a table has more than one encrypted column.
+	checkpoint string
+}
+
+func (c encryptedColumn) checkpointName() string {
+	if c.checkpoint != "" {
+		return c.checkpoint
 	}
+	return c.table
+}
 
-	return nil
+// encryptedColumns are updated in order when re-encrypting the database
+var encryptedColumns = []encryptedColumn{
+	{table: "service_binding_credentials", column: "other_details", description: "service binding credentials", identifier: "binding_id"},
+	{table: "bind_request_details", column: "request_details", description: "service binding request details", identifier: "service_binding_id"},
+	{table: "bind_request_details", column: "request_context", description: "service binding request context", identifier: "service_binding_id", optional: true, checkpoint: "bind_request_details.request_context"},
+	{table: "provision_request_details", column: "request_details", description: "provision request details", identifier: "service_instance_id"},
+	{table: "service_instance_details", column: "other_details", description: "service instance details", identifier: "id"},
+	{table: "terraform_deployments", column: "workspace", description: "terraform deployment", identifier: "id"},
+	{table: "terraform_states", column: "state", description: "terraform state", identifier: "id", optional: true},
 }
 
-func (s *Storage) updateAllServiceBindingCredentials() error {
-	var serviceBindingCredentialsBatch []models.ServiceBindingCredentials
-	result := s.db.FindInBatches(&serviceBindingCredentialsBatch, 100, func(tx *gorm.DB, batchNumber int) error {
-		for i := range serviceBindingCredentialsBatch {
-			data, err := s.decodeBytes(serviceBindingCredentialsBatch[i].OtherDetails)
-			if err != nil {
-				return fmt.Errorf("decode error for %q: %w", serviceBindingCredentialsBatch[i].BindingID, err)
-			}
+type encryptedRecord struct {
+	ID   string
+	Name string
+	Data []byte
+}
 
-			serviceBindingCredentialsBatch[i].OtherDetails, err = s.encodeBytes(data)
-			if err != nil {
-				return fmt.Errorf("encode error for %q: %w", serviceBindingCredentialsBatch[i].BindingID, err)
+// UpdateAllRecords re-encrypts every record with the encryptor
+func (s *Storage) UpdateAllRecords() error {
+	_, err := s.UpdateRecordsFrom(EncryptionRotation{}, func(EncryptionRotation) error { return nil })
+	return err
+}
+
+// UpdateRecordsFrom re-encrypts the records that come after the checkpoint in the rotation, and
+// calls the checkpoint function with the progress after each batch of records. It is safe to
+// call while the records are being used: a record that has been written since it was read has
+// already been encrypted with the encryptor, so it is left alone.
+func (s *Storage) UpdateRecordsFrom(progress EncryptionRotation, checkpoint func(EncryptionRotation) error) (EncryptionRotation, error) {
+	start := 0
+	if progress.CurrentTable != "" {
+		start = -1
+		for i, c := range encryptedColumns {
+			if c.checkpointName() == progress.CurrentTable {
+				start = i
 			}
 		}
-
-		return tx.Save(&serviceBindingCredentialsBatch).Error
-	})
-	if result.Error != nil {
-		return fmt.Errorf("error re-encoding service binding credentials: %w", result.Error)
+		if start == -1 {
+			return progress, fmt.Errorf("unknown table %q in encryption rotation checkpoint", progress.CurrentTable)
+		}
 	}
 
-	return nil
-}
+	for _, c := range encryptedColumns[start:] {
+		if c.checkpointName() != progress.CurrentTable {
+			progress.CurrentTable = c.checkpointName()
+			progress.Checkpoint = ""
+		}
 
-func (s *Storage) updateAllBindRequestDetails() error {
-	var bindRequestDetailsBatch []models.BindRequestDetails
-	result := s.db.FindInBatches(&bindRequestDetailsBatch, 100, func(tx *gorm.DB, batchNumber int) error {
-		for i := range bindRequestDetailsBatch {
-			data, err := s.decodeBytes(bindRequestDetailsBatch[i].RequestDetails)
+		for {
+			records, err := s.readEncryptedRecords(c, progress.Checkpoint)
 			if err != nil {
-				return fmt.Errorf("decode error for %q: %w", bindRequestDetailsBatch[i].ServiceBindingID, err)
+				return progress, fmt.Errorf("error re-encoding %s: %w", c.description, err)
 			}
 
-			bindRequestDetailsBatch[i].RequestDetails, err = s.encodeBytes(data)
-			if err != nil {
-				return fmt.Errorf("encode error for %q: %w", bindRequestDetailsBatch[i].ServiceBindingID, err)
+			for _, r := range records {
+				if err := s.updateEncryptedRecord(c, r); err != nil {
+					return progress, fmt.Errorf("error re-encoding %s: %w", c.description, err)
+				}
 			}
-		}
-
-		return tx.Save(&bindRequestDetailsBatch).Error
-	})
-	if result.Error != nil {
-		return fmt.Errorf("error re-encoding service binding request details: %w", result.Error)
-	}
 
-	return nil
-}
+			if len(records) == 0 {
+				break
+			}
 
-func (s *Storage) updateAllProvisionRequestDetails() error {
-	var provisionRequestDetailsBatch []models.ProvisionRequestDetails
-	result := s.db.FindInBatches(&provisionRequestDetailsBatch, 100, func(tx *gorm.DB, batchNumber int) error {
-		for i := range provisionRequestDetailsBatch {
-			data, err := s.decodeBytes(provisionRequestDetailsBatch[i].RequestDetails)
-			if err != nil {
-				return fmt.Errorf("decode error for %q: %w", provisionRequestDetailsBatch[i].ServiceInstanceID, err)
+			progress.Checkpoint = records[len(records)-1].ID
+			progress.Records += len(records)
+			if err := checkpoint(progress); err != nil {
+				return progress, fmt.Errorf("error saving encryption rotation checkpoint: %w", err)
 			}
 
-			provisionRequestDetailsBatch[i].RequestDetails, err = s.encodeBytes(data)
-			if err != nil {
-				return fmt.Errorf("encode error for %q: %w", provisionRequestDetailsBatch[i].ServiceInstanceID, err)
+			if len(records) < updateBatchSize {
+				break
 			}
 		}
-
-		return tx.Save(&provisionRequestDetailsBatch).Error
-	})
-	if result.Error != nil {
-		return fmt.Errorf("error re-encoding provision request details: %w", result.Error)
 	}
 
-	return nil
+	return progress, nil
 }
 
-func (s *Storage) updateAllServiceInstanceDetails() error {
-	var serviceInstanceDetailsBatch []models.ServiceInstanceDetails
-	result := s.db.FindInBatches(&serviceInstanceDetailsBatch, 100, func(tx *gorm.DB, batchNumber int) error {
-		for i := range serviceInstanceDetailsBatch {
-			data, err := s.decodeBytes(serviceInstanceDetailsBatch[i].OtherDetails)
-			if err != nil {
-				return fmt.Errorf("decode error for %q: %w", serviceInstanceDetailsBatch[i].ID, err)
-			}
-
-			serviceInstanceDetailsBatch[i].OtherDetails, err = s.encodeBytes(data)
-			if err != nil {
-				return fmt.Errorf("encode error for %q: %w", serviceInstanceDetailsBatch[i].ID, err)
-			}
-		}
+func (s *Storage) readEncryptedRecords(c encryptedColumn, after string) ([]encryptedRecord, error) {
+	query := s.db.Table(c.table).Select(fmt.Sprintf("id, %s AS name, %s AS data", c.identifier, c.column)).Order("id").Limit(updateBatchSize)
+	if after != "" {
+		query = query.Where("id > ?", after)
+	}
 
-		return tx.Save(&serviceInstanceDetailsBatch).Error
-	})
-	if result.Error != nil {
-		return fmt.Errorf("error re-encoding service instance details: %w", result.Error)
+	var records []encryptedRecord
+	if err := query.Scan(&records).Error; err != nil {
+		return nil, err
 	}
 
-	return nil
+	return records, nil
 }
 
-func (s *Storage) updateAllTerraformDeployments() error {
-	var terraformDeploymentBatch []models.TerraformDeployment
-	result := s.db.FindInBatches(&terraformDeploymentBatch, 100, func(tx *gorm.DB, batchNumber int) error {
-		for i := range terraformDeploymentBatch {
-			data, err := s.decodeBytes(terraformDeploymentBatch[i].Workspace)
-			if err != nil {
-				return fmt.Errorf("decode error for %q: %w", terraformDeploymentBatch[i].ID, err)
-			}
+func (s *Storage) updateEncryptedRecord(c encryptedColumn, r encryptedRecord) error {
+	if c.optional && len(r.Data) == 0 {
+		return nil
+	}
 
-			terraformDeploymentBatch[i].Workspace, err = s.encodeBytes(data)
-			if err != nil {
-				return fmt.Errorf("encode error for %q: %w", terraformDeploymentBatch[i].ID, err)
-			}
-		}
+	data, err := s.decodeBytes(r.Data)
+	if err != nil {
+		return fmt.Errorf("decode error for %q: %w", r.Name, err)
+	}
+
+	encoded, err := s.encodeBytes(data)
+	if err != nil {
+		return fmt.Errorf("encode error for %q: %w", r.Name, err)
+	}
 
-		return tx.Save(&terraformDeploymentBatch).Error
-	})
-	if result.Error != nil {
-		return fmt.Errorf("error re-encoding terraform deployment: %w", result.Error)
+	// Only update the record if it has not been written since it was read
+	err = s.db.Table(c.table).Where(fmt.Sprintf("id = ? AND %s = ?", c.column), r.ID, r.Data).Update(c.column, encoded).Error
+	if err != nil {
+		return fmt.Errorf("error updating %q: %w", r.Name, err)
 	}
 
 	return nil
@@ -1,7 +1,11 @@
 package storage_test
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/cloudfoundry/cloud-service-broker/dbservice/models"
+	"github.com/cloudfoundry/cloud-service-broker/internal/storage"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
@@ -14,6 +18,8 @@ var _ = Describe("UpdateAllRecords", func() {
 		addFakeBindRequestDetails()
 		addFakeServiceInstanceDetails()
 		addFakeTerraformDeployments()
+		Expect(db.Create(&models.TerraformState{ID: "fake-id-1", State: []byte(`"state"`)}).Error).NotTo(HaveOccurred())
+		Expect(db.Create(&models.TerraformState{ID: "fake-id-2", LockID: "fake-lock-id"}).Error).NotTo(HaveOccurred())
 	})
 
 	It("updates all the records with the latest encoding", func() {
@@ -35,6 +41,9 @@ var _ = Describe("UpdateAllRecords", func() {
 			Expect(receiver[0].RequestDetails).To(Equal([]byte(`{"encrypted":{"decrypted":{"foo":"bar"}}}`)))
 			Expect(receiver[1].RequestDetails).To(Equal([]byte(`{"encrypted":{"decrypted":{"foo":"baz","bar":"quz"}}}`)))
 			Expect(receiver[2].RequestDetails).To(Equal([]byte(`{"encrypted":{"decrypted":{"foo":"boz"}}}`)))
+			Expect(receiver[0].RequestContext).To(Equal([]byte(`{"encrypted":{"decrypted":{"namespace":"app-namespace"}}}`)))
+			Expect(receiver[1].RequestContext).To(BeEmpty())
+			Expect(receiver[2].RequestContext).To(BeEmpty())
 		})
 
 		By("checking provision request details", func() {
@@ -63,6 +72,109 @@ var _ = Describe("UpdateAllRecords", func() {
 			Expect(receiver[1].Workspace).To(Equal([]byte(`{"encrypted":{"decrypted":{"modules":[{"Name":"fake-2","Definition":"","Definitions":null}],"instances":null,"tfstate":null,"transform":{"parameter_mappings":null,"parameters_to_remove":null,"parameters_to_add":null}}}}`)))
 			Expect(receiver[2].Workspace).To(Equal([]byte(`{"encrypted":{"decrypted":{"modules":[{"Name":"fake-3","Definition":"","Definitions":null}],"instances":null,"tfstate":null,"transform":{"parameter_mappings":null,"parameters_to_remove":null,"parameters_to_add":null}}}}`)))
 		})
+
+		By("checking terraform states", func() {
+			var receiver []models.TerraformState
+			Expect(db.Find(&receiver).Error).NotTo(HaveOccurred())
+			Expect(receiver).To(HaveLen(2))
+			Expect(receiver[0].State).To(Equal([]byte(`{"encrypted":{"decrypted":"state"}}`)))
+			Expect(receiver[1].State).To(BeEmpty())
+		})
+	})
+
+	Describe("UpdateRecordsFrom", func() {
+		var checkpoints []storage.EncryptionRotation
+
+		saveCheckpoint := func(r storage.EncryptionRotation) error {
+			checkpoints = append(checkpoints, r)
+			return nil
+		}
+
+		BeforeEach(func() {
+			checkpoints = nil
+		})
+
+		It("records a checkpoint after each batch of records", func() {
+			for i := 0; i < 150; i++ {
+				Expect(db.Create(&models.ProvisionRequestDetails{
+					ServiceInstanceID: fmt.Sprintf("fake-many-instance-%d", i),
+					RequestDetails:    []byte(`{"foo":"bar"}`),
+				}).Error).NotTo(HaveOccurred())
+			}
+
+			progress, err := store.UpdateRecordsFrom(storage.EncryptionRotation{FromLabel: "old", ToLabel: "new"}, saveCheckpoint)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(progress).To(Equal(storage.EncryptionRotation{
+				FromLabel:    "old",
+				ToLabel:      "new",
+				CurrentTable: "terraform_states",
+				Checkpoint:   "fake-id-2",
+				Records:      170,
+			}))
+
+			var tables, ids []string
+			for _, c := range checkpoints {
+				tables = append(tables, c.CurrentTable)
+				ids = append(ids, c.Checkpoint)
+			}
+			Expect(tables).To(Equal([]string{"service_binding_credentials", "bind_request_details", "bind_request_details.request_context", "provision_request_details", "provision_request_details", "service_instance_details", "terraform_deployments", "terraform_states"}))
+			Expect(ids).To(Equal([]string{"3", "3", "3", "100", "153", "fake-id-3", "fake-id-3", "fake-id-2"}))
+
+			var receiver []models.ProvisionRequestDetails
+			Expect(db.Find(&receiver).Error).NotTo(HaveOccurred())
+			Expect(receiver).To(HaveLen(153))
+			for _, r := range receiver {
+				Expect(string(r.RequestDetails)).To(HavePrefix(`{"encrypted":{"decrypted":`))
+			}
+		})
+
+		It("resumes from the checkpoint", func() {
+			progress, err := store.UpdateRecordsFrom(storage.EncryptionRotation{CurrentTable: "terraform_deployments", Checkpoint: "fake-id-1", Records: 12}, saveCheckpoint)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(progress.Records).To(Equal(16))
+
+			var deployments []models.TerraformDeployment
+			Expect(db.Order("id").Find(&deployments).Error).NotTo(HaveOccurred())
+			Expect(string(deployments[0].Workspace)).To(HavePrefix(`{"modules"`))
+			Expect(string(deployments[1].Workspace)).To(HavePrefix(`{"encrypted":{"decrypted":`))
+			Expect(string(deployments[2].Workspace)).To(HavePrefix(`{"encrypted":{"decrypted":`))
+
+			var credentials []models.ServiceBindingCredentials
+			Expect(db.Find(&credentials).Error).NotTo(HaveOccurred())
+			for _, c := range credentials {
+				Expect(string(c.OtherDetails)).NotTo(HavePrefix(`{"encrypted"`))
+			}
+		})
+
+		It("does not overwrite records that are written while they are being re-encrypted", func() {
+			encryptor.DecryptStub = func(bytes []byte) ([]byte, error) {
+				if string(bytes) == `{"foo":"bar-2"}` {
+					Expect(db.Model(&models.ServiceInstanceDetails{}).Where("id = ?", "fake-id-2").Update("other_details", []byte(`written-concurrently`)).Error).NotTo(HaveOccurred())
+				}
+				return []byte(`{"decrypted":` + string(bytes) + `}`), nil
+			}
+
+			_, err := store.UpdateRecordsFrom(storage.EncryptionRotation{}, saveCheckpoint)
+			Expect(err).NotTo(HaveOccurred())
+
+			var receiver []models.ServiceInstanceDetails
+			Expect(db.Order("id").Find(&receiver).Error).NotTo(HaveOccurred())
+			Expect(receiver[0].OtherDetails).To(Equal([]byte(`{"encrypted":{"decrypted":{"foo":"bar-1"}}}`)))
+			Expect(receiver[1].OtherDetails).To(Equal([]byte(`written-concurrently`)))
+			Expect(receiver[2].OtherDetails).To(Equal([]byte(`{"encrypted":{"decrypted":{"foo":"bar-3"}}}`)))
+		})
+
+		It("fails when the checkpoint cannot be saved", func() {
+			_, err := store.UpdateRecordsFrom(storage.EncryptionRotation{}, func(storage.EncryptionRotation) error {
+				return errors.New("boom")
+			})
+			Expect(err).To(MatchError("error saving encryption rotation checkpoint: boom"))
+		})
+
+		It("fails when the checkpoint table is unknown", func() {
+			_, err := store.UpdateRecordsFrom(storage.EncryptionRotation{CurrentTable: "bogus"}, saveCheckpoint)
+			Expect(err).To(MatchError(`unknown table "bogus" in encryption rotation checkpoint`))
+		})
 	})
 
 	Describe("errors", func() {
@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cloudfoundry/cloud-service-broker/dbservice/models"
+)
+
+type WebhookDelivery struct {
+	ID            uint
+	URL           string
+	Payload       []byte
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     string
+}
+
+// StoreWebhookDelivery creates a delivery when the ID is zero, and otherwise updates it.
+func (s *Storage) StoreWebhookDelivery(d WebhookDelivery) error {
+	m := models.WebhookDelivery{
+		ID:            d.ID,
+		URL:           d.URL,
+		Payload:       d.Payload,
+		Attempts:      d.Attempts,
+		NextAttemptAt: d.NextAttemptAt,
+		LastError:     d.LastError,
+	}
+
+	switch m.ID {
+	case 0:
+		if err := s.db.Create(&m).Error; err != nil {
+			return fmt.Errorf("error creating webhook delivery: %w", err)
+		}
+	default:
+		if err := s.db.Model(&m).Select("attempts", "next_attempt_at", "last_error").Updates(&m).Error; err != nil {
+			return fmt.Errorf("error saving webhook delivery: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// GetDueWebhookDeliveries returns the deliveries whose next attempt is due, oldest first.
+func (s *Storage) GetDueWebhookDeliveries(now time.Time) ([]WebhookDelivery, error) {
+	var receiver []models.WebhookDelivery
+	if err := s.db.Where("next_attempt_at <= ?", now).Order("id").Find(&receiver).Error; err != nil {
+		return nil, fmt.Errorf("error finding webhook deliveries: %w", err)
+	}
+
+	result := make([]WebhookDelivery, 0, len(receiver))
+	for _, m := range receiver {
+		result = append(result, WebhookDelivery{
+			ID:            m.ID,
+			URL:           m.URL,
+			Payload:       m.Payload,
+			Attempts:      m.Attempts,
+			NextAttemptAt: m.NextAttemptAt,
+			LastError:     m.LastError,
+		})
+	}
+
+	return result, nil
+}
+
+func (s *Storage) DeleteWebhookDelivery(id uint) error {
+	if err := s.db.Delete(&models.WebhookDelivery{}, id).Error; err != nil {
+		return fmt.Errorf("error deleting webhook delivery: %w", err)
+	}
+	return nil
+}
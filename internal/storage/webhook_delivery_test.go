@@ -0,0 +1,105 @@
+package storage_test
+
+import (
+	"time"
+
+	"github.com/cloudfoundry/cloud-service-broker/dbservice/models"
+	"github.com/cloudfoundry/cloud-service-broker/internal/storage"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WebhookDelivery", func() {
+	var now time.Time
+
+	BeforeEach(func() {
+		now = time.Now().UTC().Truncate(time.Second)
+	})
+
+	Describe("StoreWebhookDelivery", func() {
+		It("creates the right object in the database", func() {
+			err := store.StoreWebhookDelivery(storage.WebhookDelivery{
+				URL:           "https://example.com/hook",
+				Payload:       []byte(`{"foo":"bar"}`),
+				NextAttemptAt: now,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			var receiver models.WebhookDelivery
+			Expect(db.Find(&receiver).Error).NotTo(HaveOccurred())
+			Expect(receiver.ID).NotTo(BeZero())
+			Expect(receiver.URL).To(Equal("https://example.com/hook"))
+			Expect(receiver.Payload).To(Equal([]byte(`{"foo":"bar"}`)))
+			Expect(receiver.Attempts).To(BeZero())
+			Expect(receiver.NextAttemptAt).To(BeTemporally("==", now))
+		})
+
+		It("updates the retry state of an existing delivery", func() {
+			addFakeWebhookDeliveries(now)
+
+			err := store.StoreWebhookDelivery(storage.WebhookDelivery{
+				ID:            1,
+				Attempts:      3,
+				NextAttemptAt: now.Add(time.Hour),
+				LastError:     "boom",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			var receiver models.WebhookDelivery
+			Expect(db.First(&receiver, 1).Error).NotTo(HaveOccurred())
+			Expect(receiver.URL).To(Equal("https://example.com/1"))
+			Expect(receiver.Payload).To(Equal([]byte(`{"n":1}`)))
+			Expect(receiver.Attempts).To(Equal(3))
+			Expect(receiver.NextAttemptAt).To(BeTemporally("==", now.Add(time.Hour)))
+			Expect(receiver.LastError).To(Equal("boom"))
+		})
+	})
+
+	Describe("GetDueWebhookDeliveries", func() {
+		BeforeEach(func() {
+			addFakeWebhookDeliveries(now)
+		})
+
+		It("returns the deliveries that are due", func() {
+			deliveries, err := store.GetDueWebhookDeliveries(now)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deliveries).To(HaveLen(1))
+			Expect(deliveries[0].ID).To(BeNumerically("==", 1))
+			Expect(deliveries[0].URL).To(Equal("https://example.com/1"))
+			Expect(deliveries[0].Payload).To(Equal([]byte(`{"n":1}`)))
+
+			deliveries, err = store.GetDueWebhookDeliveries(now.Add(time.Hour))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deliveries).To(HaveLen(2))
+		})
+	})
+
+	Describe("DeleteWebhookDelivery", func() {
+		BeforeEach(func() {
+			addFakeWebhookDeliveries(now)
+		})
+
+		It("deletes from the database", func() {
+			Expect(store.DeleteWebhookDelivery(1)).To(Succeed())
+
+			deliveries, err := store.GetDueWebhookDeliveries(now.Add(time.Hour))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deliveries).To(HaveLen(1))
+			Expect(deliveries[0].ID).To(BeNumerically("==", 2))
+		})
+	})
+})
+
+func addFakeWebhookDeliveries(now time.Time) {
+	Expect(db.Create(&models.WebhookDelivery{
+		URL:           "https://example.com/1",
+		Payload:       []byte(`{"n":1}`),
+		NextAttemptAt: now.Add(-time.Minute),
+	}).Error).NotTo(HaveOccurred())
+	Expect(db.Create(&models.WebhookDelivery{
+		URL:           "https://example.com/2",
+		Payload:       []byte(`{"n":2}`),
+		Attempts:      2,
+		NextAttemptAt: now.Add(time.Minute),
+	}).Error).NotTo(HaveOccurred())
+}
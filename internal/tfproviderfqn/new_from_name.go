@@ -5,13 +5,13 @@ import (
 	"strings"
 )
 
-func newFromName(name string) (TfProviderFQN, error) {
+func newFromName(name string, registry string) (TfProviderFQN, error) {
 	if !strings.HasPrefix(name, prefix) {
 		return TfProviderFQN{}, fmt.Errorf("name must have prefix: %s", prefix)
 	}
 
 	return TfProviderFQN{
-		Hostname:  defaultRegistry,
+		Hostname:  registry,
 		Namespace: defaultNamespace,
 		Type:      name[len(prefix):],
 	}, nil
@@ -4,16 +4,27 @@ import "fmt"
 
 const (
 	prefix           = "terraform-provider-"
-	defaultRegistry  = "registry.terraform.io"
 	defaultNamespace = "hashicorp"
+
+	// TerraformRegistry is where Terraform finds providers that have no hostname
+	TerraformRegistry = "registry.terraform.io"
+
+	// OpenTofuRegistry is where OpenTofu finds providers that have no hostname
+	OpenTofuRegistry = "registry.opentofu.org"
 )
 
+// New creates the name of a provider, using the Terraform registry when no hostname is given
 func New(name, provider string) (TfProviderFQN, error) {
+	return NewInRegistry(name, provider, TerraformRegistry)
+}
+
+// NewInRegistry creates the name of a provider, using the given registry when no hostname is given
+func NewInRegistry(name, provider, registry string) (TfProviderFQN, error) {
 	switch provider {
 	case "":
-		return newFromName(name)
+		return newFromName(name, registry)
 	default:
-		return newFromProvider(provider)
+		return newFromProvider(provider, registry)
 	}
 }
 
@@ -33,6 +44,12 @@ type TfProviderFQN struct {
 	Type      string
 }
 
+// InRegistry returns the name of the same provider in another registry
+func (t TfProviderFQN) InRegistry(registry string) TfProviderFQN {
+	t.Hostname = registry
+	return t
+}
+
 func (t TfProviderFQN) String() string {
 	if t.Hostname == "" && t.Namespace == "" && t.Type == "" {
 		return ""
@@ -51,6 +51,29 @@ var _ = Describe("TfProviderFQN", func() {
 		})
 	})
 
+	Context("in a registry", func() {
+		It("uses the registry when there is no hostname", func() {
+			n, err := tfproviderfqn.NewInRegistry("terraform-provider-mysql", "", tfproviderfqn.OpenTofuRegistry)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(n.String()).To(Equal("registry.opentofu.org/hashicorp/mysql"))
+
+			n, err = tfproviderfqn.NewInRegistry("", "cyrilgdn/postgresql", tfproviderfqn.OpenTofuRegistry)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(n.String()).To(Equal("registry.opentofu.org/cyrilgdn/postgresql"))
+		})
+
+		It("keeps the hostname that is given", func() {
+			n, err := tfproviderfqn.NewInRegistry("", "myreg.mydomain.com/cyrilgdn/postgresql", tfproviderfqn.OpenTofuRegistry)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(n.String()).To(Equal("myreg.mydomain.com/cyrilgdn/postgresql"))
+		})
+
+		It("can be moved to another registry", func() {
+			n := tfproviderfqn.Must("terraform-provider-mysql", "").InRegistry(tfproviderfqn.OpenTofuRegistry)
+			Expect(n.String()).To(Equal("registry.opentofu.org/hashicorp/mysql"))
+		})
+	})
+
 	Context("empty", func() {
 		It("is an empty string", func() {
 			Expect(tfproviderfqn.TfProviderFQN{}.String()).To(BeEmpty())
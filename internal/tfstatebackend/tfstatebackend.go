@@ -0,0 +1,297 @@
+// Package tfstatebackend serves the Terraform state of deployments over HTTP, so that
+// Terraform can use the broker as an "http" backend. Terraform then writes the state
+// as it changes during a command, rather than the broker reading it when the command ends.
+//
+// Terraform authenticates to the backend with a token that is issued for one deployment,
+// so the broker credentials are never written to the workspace. The tokens are kept in
+// memory, so the backend is only used by the process that serves it: commands that do not
+// run the broker, such as "tf" and "upgrade-all", keep the state in the workspace.
+package tfstatebackend
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/cloudfoundry/cloud-service-broker/internal/storage"
+	"github.com/cloudfoundry/cloud-service-broker/pkg/featureflags"
+	"github.com/cloudfoundry/cloud-service-broker/pkg/providers/tf/workspace"
+	"github.com/gorilla/mux"
+	"github.com/spf13/viper"
+)
+
+const (
+	// PathPrefix is where the backend is served. The deployment ID follows it.
+	PathPrefix = "/tfstate/"
+
+	// TokenLifetime is how long a token can be used after it is issued, unless it is revoked first
+	TokenLifetime = 12 * time.Hour
+
+	// tokenUsername is the username that Terraform sends with a token
+	tokenUsername = "deployment"
+
+	addressProp = "brokerpak.terraform.http_backend.address"
+
+	// the broker listener is configured by the serve command
+	apiPortProp = "api.port"
+)
+
+var tokens = tokenRegistry{tokens: make(map[string]token)}
+
+func init() {
+	viper.BindEnv(addressProp, "TERRAFORM_HTTP_BACKEND_ADDRESS")
+}
+
+// Store is where the state is kept
+type Store interface {
+	GetTerraformState(id string) ([]byte, error)
+	StoreTerraformState(id string, state []byte) error
+	LockTerraformState(id, lockID string, info []byte) ([]byte, error)
+	UnlockTerraformState(id, lockID string) error
+}
+
+// Backend returns the configuration that a workspace needs to use the HTTP backend for
+// a deployment, with a new token for the deployment. It is nil when the HTTP backend is not
+// enabled, or when it is not served by this process.
+func Backend(deploymentID string) *workspace.HTTPBackend {
+	if !viper.GetBool(featureflags.TerraformHTTPBackendEnabled) || !tokens.isServing() {
+		return nil
+	}
+
+	address := viper.GetString(addressProp)
+	if address == "" {
+		address = fmt.Sprintf("http://127.0.0.1:%s", viper.GetString(apiPortProp))
+	}
+
+	return &workspace.HTTPBackend{
+		Address:  strings.TrimSuffix(address, "/") + PathPrefix + url.PathEscape(deploymentID),
+		Username: tokenUsername,
+		Password: tokens.issue(deploymentID, time.Now().Add(TokenLifetime)),
+	}
+}
+
+// Revoke revokes the tokens that were issued for a deployment. It is called when
+// an operation on the deployment finishes.
+func Revoke(deploymentID string) {
+	tokens.revoke(deploymentID)
+}
+
+// Stop stops operations from using the backend once it is no longer served, and revokes the
+// tokens that were issued. Operations that start afterwards keep the state in the workspace.
+func Stop() {
+	tokens.stop()
+}
+
+// NewHandler returns a handler that implements the Terraform HTTP backend protocol for
+// the path PathPrefix + "{deployment_id}". Requests must authenticate with a token that
+// was issued by Backend for the deployment.
+func NewHandler(store Store, logger lager.Logger) http.Handler {
+	h := handler{store: store, logger: logger.Session("tfstate-backend")}
+	tokens.serve()
+
+	const path = PathPrefix + "{deployment_id}"
+	router := mux.NewRouter()
+	router.HandleFunc(path, h.get).Methods(http.MethodGet)
+	router.HandleFunc(path, h.post).Methods(http.MethodPost)
+	router.HandleFunc(path, h.lock).Methods("LOCK")
+	router.HandleFunc(path, h.unlock).Methods("UNLOCK")
+	router.Use(h.authenticate)
+	return router
+}
+
+type handler struct {
+	store  Store
+	logger lager.Logger
+}
+
+func (h handler) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != tokenUsername || !tokens.valid(password, mux.Vars(r)["deployment_id"], time.Now()) {
+			w.Header().Set("WWW-Authenticate", `Basic realm="tfstate"`)
+			http.Error(w, "Not Authorized", http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (h handler) get(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["deployment_id"]
+
+	state, err := h.store.GetTerraformState(id)
+	switch {
+	case err != nil:
+		h.fail(w, id, "get", err)
+	case state == nil:
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(state)
+	}
+}
+
+func (h handler) post(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["deployment_id"]
+
+	state, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.fail(w, id, "post", err)
+		return
+	}
+
+	if err := h.store.StoreTerraformState(id, state); err != nil {
+		h.fail(w, id, "post", err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h handler) lock(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["deployment_id"]
+
+	info, lockID, err := readLockInfo(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	held, err := h.store.LockTerraformState(id, lockID, info)
+	switch {
+	case errors.Is(err, storage.ErrTerraformStateLocked):
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusLocked)
+		w.Write(held)
+	case err != nil:
+		h.fail(w, id, "lock", err)
+	default:
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+func (h handler) unlock(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["deployment_id"]
+
+	_, lockID, err := readLockInfo(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = h.store.UnlockTerraformState(id, lockID)
+	switch {
+	case errors.Is(err, storage.ErrTerraformStateLocked):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case err != nil:
+		h.fail(w, id, "unlock", err)
+	default:
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+func (h handler) fail(w http.ResponseWriter, id, action string, err error) {
+	h.logger.Error(action, err, lager.Data{"deployment-id": id})
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
+
+// readLockInfo reads the lock information that Terraform sends, which is JSON with the lock ID in the "ID" field
+func readLockInfo(r *http.Request) ([]byte, string, error) {
+	info, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var receiver struct {
+		ID string `json:"ID"`
+	}
+	if err := json.Unmarshal(info, &receiver); err != nil {
+		return nil, "", fmt.Errorf("invalid lock information: %w", err)
+	}
+	if receiver.ID == "" {
+		return nil, "", errors.New("invalid lock information: missing ID")
+	}
+
+	return info, receiver.ID, nil
+}
+
+type token struct {
+	deploymentID string
+	expires      time.Time
+}
+
+// tokenRegistry holds the tokens that have been issued, and whether the backend is served
+type tokenRegistry struct {
+	lock    sync.Mutex
+	serving bool
+	tokens  map[string]token
+}
+
+func (t *tokenRegistry) serve() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.serving = true
+}
+
+func (t *tokenRegistry) stop() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.serving = false
+	t.tokens = make(map[string]token)
+}
+
+func (t *tokenRegistry) isServing() bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.serving
+}
+
+func (t *tokenRegistry) issue(deploymentID string, expires time.Time) string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("reading random bytes for a token: %s", err))
+	}
+	value := hex.EncodeToString(b)
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for v, tok := range t.tokens {
+		if !tok.expires.After(time.Now()) {
+			delete(t.tokens, v)
+		}
+	}
+	t.tokens[value] = token{deploymentID: deploymentID, expires: expires}
+	return value
+}
+
+func (t *tokenRegistry) revoke(deploymentID string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for v, tok := range t.tokens {
+		if tok.deploymentID == deploymentID {
+			delete(t.tokens, v)
+		}
+	}
+}
+
+func (t *tokenRegistry) valid(value, deploymentID string, now time.Time) bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	tok, ok := t.tokens[value]
+	return ok && tok.deploymentID == deploymentID && now.Before(tok.expires)
+}
@@ -0,0 +1,13 @@
+package tfstatebackend_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestTFStateBackend(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "TF State Backend Suite")
+}
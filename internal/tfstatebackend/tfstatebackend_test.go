@@ -0,0 +1,209 @@
+package tfstatebackend_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/cloudfoundry/cloud-service-broker/dbservice/models"
+	"github.com/cloudfoundry/cloud-service-broker/internal/storage"
+	"github.com/cloudfoundry/cloud-service-broker/internal/storage/storagefakes"
+	"github.com/cloudfoundry/cloud-service-broker/internal/tfstatebackend"
+	"github.com/cloudfoundry/cloud-service-broker/pkg/featureflags"
+	"github.com/cloudfoundry/cloud-service-broker/pkg/providers/tf/workspace"
+	"github.com/cloudfoundry/cloud-service-broker/utils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
+	"github.com/spf13/viper"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+var _ = Describe("Handler", func() {
+	const path = "/tfstate/tf:instance-id:"
+
+	var (
+		store   *storage.Storage
+		handler http.Handler
+		backend *workspace.HTTPBackend
+	)
+
+	BeforeEach(func() {
+		db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(db.Migrator().CreateTable(&models.TerraformState{})).To(Succeed())
+
+		encryptor := &storagefakes.FakeEncryptor{
+			EncryptStub: func(b []byte) ([]byte, error) { return b, nil },
+			DecryptStub: func(b []byte) ([]byte, error) { return b, nil },
+		}
+		store = storage.New(db, encryptor)
+		handler = tfstatebackend.NewHandler(store, utils.NewLogger("tfstatebackend-test"))
+
+		viper.Set(featureflags.TerraformHTTPBackendEnabled, true)
+		backend = tfstatebackend.Backend("tf:instance-id:")
+		Expect(backend).NotTo(BeNil())
+	})
+
+	AfterEach(func() {
+		viper.Set(featureflags.TerraformHTTPBackendEnabled, false)
+		tfstatebackend.Revoke("tf:instance-id:")
+	})
+
+	requestAs := func(username, password, method, path, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		r.SetBasicAuth(username, password)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, r)
+		return recorder
+	}
+
+	request := func(method, body string) *httptest.ResponseRecorder {
+		return requestAs(backend.Username, backend.Password, method, path, body)
+	}
+
+	responseBody := func(r *httptest.ResponseRecorder) string {
+		b, err := io.ReadAll(r.Result().Body)
+		Expect(err).NotTo(HaveOccurred())
+		return string(b)
+	}
+
+	Describe("GET", func() {
+		It("returns no content when there is no state", func() {
+			Expect(request(http.MethodGet, "").Code).To(Equal(http.StatusNoContent))
+		})
+
+		It("returns the state", func() {
+			Expect(store.StoreTerraformState("tf:instance-id:", []byte(`{"serial":1}`))).To(Succeed())
+
+			response := request(http.MethodGet, "")
+			Expect(response.Code).To(Equal(http.StatusOK))
+			Expect(responseBody(response)).To(MatchJSON(`{"serial":1}`))
+		})
+	})
+
+	Describe("POST", func() {
+		It("stores the state", func() {
+			Expect(request(http.MethodPost, `{"serial":2}`).Code).To(Equal(http.StatusOK))
+
+			Expect(store.GetTerraformState("tf:instance-id:")).To(MatchJSON(`{"serial":2}`))
+		})
+	})
+
+	Describe("LOCK and UNLOCK", func() {
+		It("locks and unlocks the state", func() {
+			Expect(request("LOCK", `{"ID":"lock-1"}`).Code).To(Equal(http.StatusOK))
+
+			response := request("LOCK", `{"ID":"lock-2"}`)
+			Expect(response.Code).To(Equal(http.StatusLocked))
+			Expect(responseBody(response)).To(MatchJSON(`{"ID":"lock-1"}`))
+
+			Expect(request("UNLOCK", `{"ID":"lock-2"}`).Code).To(Equal(http.StatusConflict))
+			Expect(request("UNLOCK", `{"ID":"lock-1"}`).Code).To(Equal(http.StatusOK))
+			Expect(request("LOCK", `{"ID":"lock-2"}`).Code).To(Equal(http.StatusOK))
+		})
+
+		It("rejects invalid lock information", func() {
+			Expect(request("LOCK", `not-json`).Code).To(Equal(http.StatusBadRequest))
+			Expect(request("UNLOCK", `{}`).Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	It("does not allow other methods", func() {
+		Expect(request(http.MethodDelete, "").Code).To(Equal(http.StatusMethodNotAllowed))
+	})
+
+	Describe("authentication", func() {
+		It("rejects requests without a token", func() {
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+			Expect(recorder.Code).To(Equal(http.StatusUnauthorized))
+		})
+
+		It("rejects the broker credentials", func() {
+			viper.Set("api.user", "user")
+			viper.Set("api.password", "password")
+			DeferCleanup(func() {
+				viper.Set("api.user", "")
+				viper.Set("api.password", "")
+			})
+
+			Expect(requestAs("user", "password", http.MethodGet, path, "").Code).To(Equal(http.StatusUnauthorized))
+		})
+
+		It("rejects a token that was issued for another deployment", func() {
+			other := tfstatebackend.Backend("tf:other-instance-id:")
+			DeferCleanup(tfstatebackend.Revoke, "tf:other-instance-id:")
+
+			Expect(requestAs(other.Username, other.Password, http.MethodGet, path, "").Code).To(Equal(http.StatusUnauthorized))
+			Expect(requestAs(backend.Username, backend.Password, http.MethodGet, "/tfstate/tf:other-instance-id:", "").Code).To(Equal(http.StatusUnauthorized))
+		})
+
+		It("rejects tokens once the backend is stopped", func() {
+			tfstatebackend.Stop()
+
+			Expect(request(http.MethodGet, "").Code).To(Equal(http.StatusUnauthorized))
+		})
+
+		It("rejects a token that has been revoked", func() {
+			tfstatebackend.Revoke("tf:instance-id:")
+
+			Expect(request(http.MethodGet, "").Code).To(Equal(http.StatusUnauthorized))
+		})
+	})
+})
+
+var _ = Describe("Backend", func() {
+	BeforeEach(func() {
+		tfstatebackend.NewHandler(nil, utils.NewLogger("tfstatebackend-test"))
+	})
+
+	AfterEach(func() {
+		tfstatebackend.Revoke("tf:instance-id:")
+		tfstatebackend.Revoke("tf:instance-id:binding-id")
+		viper.Set(featureflags.TerraformHTTPBackendEnabled, false)
+		viper.Set("brokerpak.terraform.http_backend.address", "")
+		viper.Set("api.port", "")
+		viper.Set("api.user", "")
+		viper.Set("api.password", "")
+	})
+
+	It("is nil when the HTTP backend is not enabled", func() {
+		Expect(tfstatebackend.Backend("tf:instance-id:")).To(BeNil())
+	})
+
+	It("defaults to the broker listener, with a token rather than the broker credentials", func() {
+		viper.Set(featureflags.TerraformHTTPBackendEnabled, true)
+		viper.Set("api.port", "8080")
+		viper.Set("api.user", "user")
+		viper.Set("api.password", "password")
+
+		Expect(tfstatebackend.Backend("tf:instance-id:binding-id")).To(PointTo(MatchAllFields(Fields{
+			"Address":  Equal("http://127.0.0.1:8080/tfstate/tf:instance-id:binding-id"),
+			"Username": Equal("deployment"),
+			"Password": MatchRegexp(`^[0-9a-f]{64}$`),
+		})))
+	})
+
+	It("issues a new token each time", func() {
+		viper.Set(featureflags.TerraformHTTPBackendEnabled, true)
+
+		Expect(tfstatebackend.Backend("tf:instance-id:").Password).NotTo(Equal(tfstatebackend.Backend("tf:instance-id:").Password))
+	})
+
+	It("is nil when the backend is not served", func() {
+		viper.Set(featureflags.TerraformHTTPBackendEnabled, true)
+		tfstatebackend.Stop()
+
+		Expect(tfstatebackend.Backend("tf:instance-id:")).To(BeNil())
+	})
+
+	It("can be configured with an address", func() {
+		viper.Set(featureflags.TerraformHTTPBackendEnabled, true)
+		viper.Set("brokerpak.terraform.http_backend.address", "https://broker.example.com/")
+
+		Expect(tfstatebackend.Backend("tf:instance-id:").Address).To(Equal("https://broker.example.com/tfstate/tf:instance-id:"))
+	})
+})
@@ -0,0 +1,141 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/cloudfoundry/cloud-service-broker/internal/storage"
+)
+
+const (
+	maxAttempts    = 10
+	initialBackoff = 10 * time.Second
+	maxBackoff     = time.Hour
+)
+
+// Store is the persisted outbox of webhook deliveries
+type Store interface {
+	OutboxStore
+	GetDueWebhookDeliveries(now time.Time) ([]storage.WebhookDelivery, error)
+	DeleteWebhookDelivery(id uint) error
+}
+
+// Dispatcher delivers webhooks from the outbox. A failed delivery is retried with
+// exponential backoff, and abandoned after a fixed number of attempts.
+type Dispatcher struct {
+	store   Store
+	secrets map[string]string
+	client  *http.Client
+	logger  lager.Logger
+}
+
+func NewDispatcher(store Store, targets []Target, logger lager.Logger) *Dispatcher {
+	secrets := make(map[string]string)
+	for _, t := range targets {
+		secrets[t.URL] = t.Secret
+	}
+
+	return &Dispatcher{
+		store:   store,
+		secrets: secrets,
+		client:  &http.Client{Timeout: 30 * time.Second},
+		logger:  logger.Session("webhook"),
+	}
+}
+
+// Run delivers due webhooks at the specified interval until the context is cancelled.
+func (d *Dispatcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := d.DeliverDue(); err != nil {
+			d.logger.Error("deliver-webhooks", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverDue attempts every delivery in the outbox that is due.
+func (d *Dispatcher) DeliverDue() error {
+	deliveries, err := d.store.GetDueWebhookDeliveries(time.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, delivery := range deliveries {
+		if err := d.deliver(delivery); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (d *Dispatcher) deliver(delivery storage.WebhookDelivery) error {
+	secret, ok := d.secrets[delivery.URL]
+	if !ok {
+		d.logger.Info("webhook-no-longer-configured", lager.Data{"url": delivery.URL, "id": delivery.ID})
+		return d.store.DeleteWebhookDelivery(delivery.ID)
+	}
+
+	sendErr := d.send(delivery.URL, secret, delivery.Payload)
+	if sendErr == nil {
+		return d.store.DeleteWebhookDelivery(delivery.ID)
+	}
+
+	delivery.Attempts++
+	if delivery.Attempts >= maxAttempts {
+		d.logger.Error("webhook-abandoned", sendErr, lager.Data{"url": delivery.URL, "id": delivery.ID, "attempts": delivery.Attempts})
+		return d.store.DeleteWebhookDelivery(delivery.ID)
+	}
+
+	d.logger.Info("webhook-failed", lager.Data{"url": delivery.URL, "id": delivery.ID, "attempts": delivery.Attempts, "error": sendErr.Error()})
+	delivery.LastError = sendErr.Error()
+	delivery.NextAttemptAt = time.Now().Add(backoff(delivery.Attempts))
+	return d.store.StoreWebhookDelivery(delivery)
+}
+
+func (d *Dispatcher) send(url, secret string, payload []byte) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(secret, payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// backoff doubles the wait after each failed attempt, up to a maximum.
+func backoff(attempts int) time.Duration {
+	wait := initialBackoff
+	for i := 1; i < attempts && wait < maxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > maxBackoff {
+		return maxBackoff
+	}
+	return wait
+}
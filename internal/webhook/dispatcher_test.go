@@ -0,0 +1,138 @@
+package webhook_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/cloudfoundry/cloud-service-broker/dbservice/models"
+	"github.com/cloudfoundry/cloud-service-broker/internal/storage"
+	"github.com/cloudfoundry/cloud-service-broker/internal/webhook"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+var _ = Describe("Dispatcher", func() {
+	const secret = "webhook-secret"
+
+	var (
+		db         *gorm.DB
+		store      *storage.Storage
+		server     *httptest.Server
+		dispatcher *webhook.Dispatcher
+
+		mutex      sync.Mutex
+		statusCode int
+		received   []*http.Request
+		bodies     []string
+	)
+
+	BeforeEach(func() {
+		var err error
+		db, err = gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(db.Migrator().CreateTable(&models.WebhookDelivery{})).To(Succeed())
+		store = storage.New(db, nil)
+
+		statusCode = http.StatusOK
+		received = nil
+		bodies = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			body, _ := io.ReadAll(r.Body)
+			received = append(received, r)
+			bodies = append(bodies, string(body))
+			w.WriteHeader(statusCode)
+		}))
+
+		dispatcher = webhook.NewDispatcher(store, []webhook.Target{{URL: server.URL, Secret: secret}}, lager.NewLogger("dispatcher"))
+
+		Expect(webhook.Enqueue(store, []webhook.Target{{URL: server.URL}}, webhook.Event{InstanceID: "instance-id", Time: time.Now()})).To(Succeed())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	outbox := func() []models.WebhookDelivery {
+		var deliveries []models.WebhookDelivery
+		Expect(db.Find(&deliveries).Error).NotTo(HaveOccurred())
+		return deliveries
+	}
+
+	It("posts the event with a signature and removes it from the outbox", func() {
+		stored := outbox()
+		Expect(dispatcher.DeliverDue()).To(Succeed())
+
+		Expect(received).To(HaveLen(1))
+		Expect(received[0].Method).To(Equal(http.MethodPost))
+		Expect(received[0].Header.Get("Content-Type")).To(Equal("application/json"))
+		Expect(bodies[0]).To(Equal(string(stored[0].Payload)))
+		Expect(bodies[0]).To(ContainSubstring(`"instance_id":"instance-id"`))
+		Expect(received[0].Header.Get(webhook.SignatureHeader)).To(Equal(webhook.Sign(secret, []byte(bodies[0]))))
+		Expect(outbox()).To(BeEmpty())
+	})
+
+	When("the webhook fails", func() {
+		BeforeEach(func() {
+			statusCode = http.StatusInternalServerError
+		})
+
+		It("keeps the delivery and retries it after a backoff", func() {
+			Expect(dispatcher.DeliverDue()).To(Succeed())
+
+			deliveries := outbox()
+			Expect(deliveries).To(HaveLen(1))
+			Expect(deliveries[0].Attempts).To(Equal(1))
+			Expect(deliveries[0].LastError).To(Equal("unexpected status code 500"))
+			Expect(deliveries[0].NextAttemptAt).To(BeTemporally("~", time.Now().Add(10*time.Second), time.Second))
+
+			By("not retrying before the backoff has elapsed")
+			Expect(dispatcher.DeliverDue()).To(Succeed())
+			Expect(received).To(HaveLen(1))
+
+			By("doubling the backoff after each attempt")
+			Expect(db.Model(&models.WebhookDelivery{}).Where("id = ?", deliveries[0].ID).Update("next_attempt_at", time.Now().Add(-time.Second)).Error).To(Succeed())
+			Expect(dispatcher.DeliverDue()).To(Succeed())
+			Expect(received).To(HaveLen(2))
+			deliveries = outbox()
+			Expect(deliveries[0].Attempts).To(Equal(2))
+			Expect(deliveries[0].NextAttemptAt).To(BeTemporally("~", time.Now().Add(20*time.Second), time.Second))
+
+			By("delivering once the webhook recovers")
+			statusCode = http.StatusNoContent
+			Expect(db.Model(&models.WebhookDelivery{}).Where("id = ?", deliveries[0].ID).Update("next_attempt_at", time.Now().Add(-time.Second)).Error).To(Succeed())
+			Expect(dispatcher.DeliverDue()).To(Succeed())
+			Expect(received).To(HaveLen(3))
+			Expect(outbox()).To(BeEmpty())
+		})
+
+		It("abandons the delivery after the maximum number of attempts", func() {
+			Expect(db.Model(&models.WebhookDelivery{}).Where("1 = 1").Update("attempts", 9).Error).To(Succeed())
+
+			Expect(dispatcher.DeliverDue()).To(Succeed())
+
+			Expect(received).To(HaveLen(1))
+			Expect(outbox()).To(BeEmpty())
+		})
+	})
+
+	When("the webhook is no longer configured", func() {
+		BeforeEach(func() {
+			dispatcher = webhook.NewDispatcher(store, nil, lager.NewLogger("dispatcher"))
+		})
+
+		It("drops the delivery", func() {
+			Expect(dispatcher.DeliverDue()).To(Succeed())
+
+			Expect(received).To(BeEmpty())
+			Expect(outbox()).To(BeEmpty())
+		})
+	})
+})
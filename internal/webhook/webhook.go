@@ -0,0 +1,115 @@
+// Package webhook notifies external systems when asynchronous operations finish.
+// Notifications are persisted to an outbox in the database before being delivered,
+// so that they survive broker restarts and can be retried.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/cloudfoundry/cloud-service-broker/internal/storage"
+	"github.com/spf13/viper"
+)
+
+const (
+	webhooksProp = "webhooks"
+
+	// SignatureHeader carries the HMAC-SHA256 signature of the request body,
+	// computed with the secret configured for the webhook.
+	SignatureHeader = "X-CSB-Signature"
+)
+
+func init() {
+	viper.BindEnv(webhooksProp, "CSB_WEBHOOKS")
+}
+
+// Target is a configured webhook endpoint
+type Target struct {
+	URL    string `json:"url"`
+	Secret string `json:"secret"`
+}
+
+func (t Target) validate() error {
+	u, err := url.Parse(t.URL)
+	switch {
+	case err != nil:
+		return fmt.Errorf("invalid webhook url %q: %w", t.URL, err)
+	case u.Scheme != "http" && u.Scheme != "https", u.Host == "":
+		return fmt.Errorf("invalid webhook url %q: must be an absolute http or https URL", t.URL)
+	case t.Secret == "":
+		return fmt.Errorf("webhook %q must specify a secret", t.URL)
+	default:
+		return nil
+	}
+}
+
+// ParseTargets reads the JSON list of webhooks from the broker configuration.
+func ParseTargets() ([]Target, error) {
+	config := viper.GetString(webhooksProp)
+	if config == "" {
+		return nil, nil
+	}
+
+	var targets []Target
+	if err := json.Unmarshal([]byte(config), &targets); err != nil {
+		return nil, fmt.Errorf("failed unmarshaling config value %s: %w", webhooksProp, err)
+	}
+
+	for _, t := range targets {
+		if err := t.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return targets, nil
+}
+
+// Event describes an operation that has finished
+type Event struct {
+	InstanceID    string    `json:"instance_id"`
+	BindingID     string    `json:"binding_id,omitempty"`
+	ServiceID     string    `json:"service_id"`
+	PlanID        string    `json:"plan_id"`
+	OperationType string    `json:"operation_type"`
+	State         string    `json:"state"`
+	Message       string    `json:"message"`
+	Time          time.Time `json:"time"`
+}
+
+// OutboxStore persists webhook deliveries
+type OutboxStore interface {
+	StoreWebhookDelivery(d storage.WebhookDelivery) error
+}
+
+// Enqueue adds a delivery of the event to the outbox for each webhook target.
+func Enqueue(store OutboxStore, targets []Target, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("error encoding webhook event: %w", err)
+	}
+
+	for _, t := range targets {
+		err := store.StoreWebhookDelivery(storage.WebhookDelivery{
+			URL:           t.URL,
+			Payload:       payload,
+			NextAttemptAt: event.Time,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Sign returns the value of the signature header for a payload.
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,101 @@
+package webhook_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry/cloud-service-broker/internal/storage"
+	"github.com/cloudfoundry/cloud-service-broker/internal/webhook"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/viper"
+)
+
+var _ = Describe("ParseTargets", func() {
+	AfterEach(func() {
+		viper.Set("webhooks", "")
+	})
+
+	It("returns no targets when none are configured", func() {
+		Expect(webhook.ParseTargets()).To(BeEmpty())
+	})
+
+	It("parses the configured targets", func() {
+		viper.Set("webhooks", `[{"url":"https://example.com/hook","secret":"shh"}]`)
+
+		Expect(webhook.ParseTargets()).To(Equal([]webhook.Target{{URL: "https://example.com/hook", Secret: "shh"}}))
+	})
+
+	DescribeTable(
+		"invalid targets",
+		func(config, expectedError string) {
+			viper.Set("webhooks", config)
+
+			_, err := webhook.ParseTargets()
+			Expect(err).To(MatchError(ContainSubstring(expectedError)))
+		},
+		Entry("invalid JSON", `{`, "failed unmarshaling config value webhooks"),
+		Entry("relative URL", `[{"url":"/hook","secret":"shh"}]`, `invalid webhook url "/hook"`),
+		Entry("unsupported scheme", `[{"url":"ftp://example.com","secret":"shh"}]`, `invalid webhook url "ftp://example.com"`),
+		Entry("no secret", `[{"url":"https://example.com"}]`, `webhook "https://example.com" must specify a secret`),
+	)
+})
+
+var _ = Describe("Enqueue", func() {
+	It("stores a delivery of the event for each target", func() {
+		var stored []storage.WebhookDelivery
+		store := outboxFunc(func(d storage.WebhookDelivery) error {
+			stored = append(stored, d)
+			return nil
+		})
+		now := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+
+		err := webhook.Enqueue(store, []webhook.Target{{URL: "https://one.example.com"}, {URL: "https://two.example.com"}}, webhook.Event{
+			InstanceID:    "instance-id",
+			BindingID:     "binding-id",
+			ServiceID:     "service-id",
+			PlanID:        "plan-id",
+			OperationType: "upgrade",
+			State:         "failed",
+			Message:       "upgrade failed: boom",
+			Time:          now,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(stored).To(HaveLen(2))
+		Expect(stored[0].URL).To(Equal("https://one.example.com"))
+		Expect(stored[1].URL).To(Equal("https://two.example.com"))
+		Expect(stored[0].NextAttemptAt).To(Equal(now))
+		Expect(stored[0].Payload).To(MatchJSON(`{
+			"instance_id": "instance-id",
+			"binding_id": "binding-id",
+			"service_id": "service-id",
+			"plan_id": "plan-id",
+			"operation_type": "upgrade",
+			"state": "failed",
+			"message": "upgrade failed: boom",
+			"time": "2022-05-01T12:00:00Z"
+		}`))
+		Expect(stored[1].Payload).To(Equal(stored[0].Payload))
+	})
+
+	It("returns storage errors", func() {
+		store := outboxFunc(func(storage.WebhookDelivery) error { return errors.New("boom") })
+
+		err := webhook.Enqueue(store, []webhook.Target{{URL: "https://example.com"}}, webhook.Event{})
+		Expect(err).To(MatchError("boom"))
+	})
+})
+
+var _ = Describe("Sign", func() {
+	It("computes an HMAC-SHA256 signature", func() {
+		Expect(webhook.Sign("key", []byte("The quick brown fox jumps over the lazy dog"))).
+			To(Equal("sha256=f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8"))
+	})
+})
+
+type outboxFunc func(storage.WebhookDelivery) error
+
+func (f outboxFunc) StoreWebhookDelivery(d storage.WebhookDelivery) error {
+	return f(d)
+}
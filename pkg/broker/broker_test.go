@@ -905,7 +905,7 @@ func TestServiceDefinition_BindVariables(t *testing.T) {
 			instance := storage.ServiceInstanceDetails{Outputs: tc.InstanceVars}
 
 			service.Plans[0].BindOverrides = tc.BindOverrides
-			vars, err := service.BindVariables(instance, "binding-id-here", parsedDetails, &service.Plans[0], tc.OriginatingIdentity)
+			vars, err := service.BindVariables(instance, nil, "binding-id-here", parsedDetails, &service.Plans[0], tc.OriginatingIdentity)
 
 			expectError(t, tc.ExpectedError, err)
 
@@ -916,6 +916,41 @@ func TestServiceDefinition_BindVariables(t *testing.T) {
 	}
 }
 
+func TestServiceDefinition_BindVariablesInstanceState(t *testing.T) {
+	service := ServiceDefinition{
+		ID:    "00000000-0000-0000-0000-000000000000",
+		Name:  "left-handed-smoke-sifter",
+		Plans: []ServicePlan{{ServicePlan: domain.ServicePlan{ID: "builtin-plan", Name: "Builtin!"}}},
+		BindComputedVariables: []varcontext.DefaultVariable{
+			{
+				Name:      "endpoint",
+				Default:   `${instance.state["aws_db_instance.db.endpoint"]}`,
+				Overwrite: true,
+			},
+			{
+				Name:      "status",
+				Default:   `${instance.state["output.status"]}`,
+				Overwrite: true,
+			},
+		},
+	}
+	instanceState := storage.JSONObject{
+		"output.status":               "created",
+		"aws_db_instance.db.endpoint": "db.example.com:3306",
+		"aws_db_instance.db.port":     3306,
+	}
+
+	vars, err := service.BindVariables(storage.ServiceInstanceDetails{}, instanceState, "binding-id-here", paramparser.BindDetails{}, &service.Plans[0], nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{"endpoint": "db.example.com:3306", "status": "created"}
+	if !reflect.DeepEqual(vars.ToMap(), expected) {
+		t.Errorf("Expected context: %v got %v", expected, vars.ToMap())
+	}
+}
+
 func TestServiceDefinition_createSchemas(t *testing.T) {
 	service := ServiceDefinition{
 		ID:   "00000000-0000-0000-0000-000000000000",
@@ -924,7 +959,8 @@ func TestServiceDefinition_createSchemas(t *testing.T) {
 			{ServicePlan: domain.ServicePlan{ID: "builtin-plan", Name: "Builtin!"}},
 		},
 		ProvisionInputVariables: []BrokerVariable{
-			{FieldName: "location", Type: JSONTypeString, Default: "us"},
+			{FieldName: "location", Type: JSONTypeString, Default: "us", ProhibitUpdate: true},
+			{FieldName: "size", Type: JSONTypeInteger, Default: 10, Required: true},
 		},
 		BindInputVariables: []BrokerVariable{
 			{FieldName: "name", Type: JSONTypeString, Default: "name"},
@@ -947,10 +983,17 @@ func TestServiceDefinition_createSchemas(t *testing.T) {
 		t.Errorf("expected create params to be: %v got %v", expectedCreateParams, instanceCreate.Parameters)
 	}
 
-	// It leaves the instance update schema blank.
+	// it populates the instance update schema with the fields in ProvisionInputVariables that may be updated
 	instanceUpdate := schemas.Instance.Update
-	if instanceUpdate.Parameters != nil {
-		t.Error("instance update params were not nil, expected nil")
+	expectedUpdateParams := map[string]interface{}{
+		"$schema": "http://json-schema.org/draft-04/schema#",
+		"type":    "object",
+		"properties": map[string]interface{}{
+			"size": service.ProvisionInputVariables[1].ToSchema(),
+		},
+	}
+	if !reflect.DeepEqual(instanceUpdate.Parameters, expectedUpdateParams) {
+		t.Errorf("expected update params to be: %v got %v", expectedUpdateParams, instanceUpdate.Parameters)
 	}
 
 	// it populates the binding create schema with the fields in BindInputVariables.
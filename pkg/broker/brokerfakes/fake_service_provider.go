@@ -13,6 +13,22 @@ import (
 )
 
 type FakeServiceProvider struct {
+	AdoptStub        func(context.Context, *varcontext.VarContext, map[string]string, func(plan string) bool) (bool, error)
+	adoptMutex       sync.RWMutex
+	adoptArgsForCall []struct {
+		arg1 context.Context
+		arg2 *varcontext.VarContext
+		arg3 map[string]string
+		arg4 func(plan string) bool
+	}
+	adoptReturns struct {
+		result1 bool
+		result2 error
+	}
+	adoptReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
 	BindStub        func(context.Context, *varcontext.VarContext) (map[string]interface{}, error)
 	bindMutex       sync.RWMutex
 	bindArgsForCall []struct {
@@ -70,6 +86,20 @@ type FakeServiceProvider struct {
 		result1 map[string]interface{}
 		result2 error
 	}
+	GetInstanceStateStub        func(context.Context, string) (storage.JSONObject, error)
+	getInstanceStateMutex       sync.RWMutex
+	getInstanceStateArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	getInstanceStateReturns struct {
+		result1 storage.JSONObject
+		result2 error
+	}
+	getInstanceStateReturnsOnCall map[int]struct {
+		result1 storage.JSONObject
+		result2 error
+	}
 	GetTerraformOutputsStub        func(context.Context, string) (storage.JSONObject, error)
 	getTerraformOutputsMutex       sync.RWMutex
 	getTerraformOutputsArgsForCall []struct {
@@ -84,6 +114,23 @@ type FakeServiceProvider struct {
 		result1 storage.JSONObject
 		result2 error
 	}
+	PollActionStub        func(context.Context, string, string) (bool, string, error)
+	pollActionMutex       sync.RWMutex
+	pollActionArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+	}
+	pollActionReturns struct {
+		result1 bool
+		result2 string
+		result3 error
+	}
+	pollActionReturnsOnCall map[int]struct {
+		result1 bool
+		result2 string
+		result3 error
+	}
 	PollInstanceStub        func(context.Context, string) (bool, string, error)
 	pollInstanceMutex       sync.RWMutex
 	pollInstanceArgsForCall []struct {
@@ -114,6 +161,22 @@ type FakeServiceProvider struct {
 		result1 storage.ServiceInstanceDetails
 		result2 error
 	}
+	RunActionStub        func(context.Context, string, string, *varcontext.VarContext) (string, error)
+	runActionMutex       sync.RWMutex
+	runActionArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 *varcontext.VarContext
+	}
+	runActionReturns struct {
+		result1 string
+		result2 error
+	}
+	runActionReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
 	UnbindStub        func(context.Context, string, string, *varcontext.VarContext) error
 	unbindMutex       sync.RWMutex
 	unbindArgsForCall []struct {
@@ -160,6 +223,73 @@ type FakeServiceProvider struct {
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeServiceProvider) Adopt(arg1 context.Context, arg2 *varcontext.VarContext, arg3 map[string]string, arg4 func(plan string) bool) (bool, error) {
+	fake.adoptMutex.Lock()
+	ret, specificReturn := fake.adoptReturnsOnCall[len(fake.adoptArgsForCall)]
+	fake.adoptArgsForCall = append(fake.adoptArgsForCall, struct {
+		arg1 context.Context
+		arg2 *varcontext.VarContext
+		arg3 map[string]string
+		arg4 func(plan string) bool
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.AdoptStub
+	fakeReturns := fake.adoptReturns
+	fake.recordInvocation("Adopt", []interface{}{arg1, arg2, arg3, arg4})
+	fake.adoptMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeServiceProvider) AdoptCallCount() int {
+	fake.adoptMutex.RLock()
+	defer fake.adoptMutex.RUnlock()
+	return len(fake.adoptArgsForCall)
+}
+
+func (fake *FakeServiceProvider) AdoptCalls(stub func(context.Context, *varcontext.VarContext, map[string]string, func(plan string) bool) (bool, error)) {
+	fake.adoptMutex.Lock()
+	defer fake.adoptMutex.Unlock()
+	fake.AdoptStub = stub
+}
+
+func (fake *FakeServiceProvider) AdoptArgsForCall(i int) (context.Context, *varcontext.VarContext, map[string]string, func(plan string) bool) {
+	fake.adoptMutex.RLock()
+	defer fake.adoptMutex.RUnlock()
+	argsForCall := fake.adoptArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeServiceProvider) AdoptReturns(result1 bool, result2 error) {
+	fake.adoptMutex.Lock()
+	defer fake.adoptMutex.Unlock()
+	fake.AdoptStub = nil
+	fake.adoptReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeServiceProvider) AdoptReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.adoptMutex.Lock()
+	defer fake.adoptMutex.Unlock()
+	fake.AdoptStub = nil
+	if fake.adoptReturnsOnCall == nil {
+		fake.adoptReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.adoptReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeServiceProvider) Bind(arg1 context.Context, arg2 *varcontext.VarContext) (map[string]interface{}, error) {
 	fake.bindMutex.Lock()
 	ret, specificReturn := fake.bindReturnsOnCall[len(fake.bindArgsForCall)]
@@ -425,6 +555,71 @@ func (fake *FakeServiceProvider) GetImportedPropertiesReturnsOnCall(i int, resul
 	}{result1, result2}
 }
 
+func (fake *FakeServiceProvider) GetInstanceState(arg1 context.Context, arg2 string) (storage.JSONObject, error) {
+	fake.getInstanceStateMutex.Lock()
+	ret, specificReturn := fake.getInstanceStateReturnsOnCall[len(fake.getInstanceStateArgsForCall)]
+	fake.getInstanceStateArgsForCall = append(fake.getInstanceStateArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.GetInstanceStateStub
+	fakeReturns := fake.getInstanceStateReturns
+	fake.recordInvocation("GetInstanceState", []interface{}{arg1, arg2})
+	fake.getInstanceStateMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeServiceProvider) GetInstanceStateCallCount() int {
+	fake.getInstanceStateMutex.RLock()
+	defer fake.getInstanceStateMutex.RUnlock()
+	return len(fake.getInstanceStateArgsForCall)
+}
+
+func (fake *FakeServiceProvider) GetInstanceStateCalls(stub func(context.Context, string) (storage.JSONObject, error)) {
+	fake.getInstanceStateMutex.Lock()
+	defer fake.getInstanceStateMutex.Unlock()
+	fake.GetInstanceStateStub = stub
+}
+
+func (fake *FakeServiceProvider) GetInstanceStateArgsForCall(i int) (context.Context, string) {
+	fake.getInstanceStateMutex.RLock()
+	defer fake.getInstanceStateMutex.RUnlock()
+	argsForCall := fake.getInstanceStateArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeServiceProvider) GetInstanceStateReturns(result1 storage.JSONObject, result2 error) {
+	fake.getInstanceStateMutex.Lock()
+	defer fake.getInstanceStateMutex.Unlock()
+	fake.GetInstanceStateStub = nil
+	fake.getInstanceStateReturns = struct {
+		result1 storage.JSONObject
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeServiceProvider) GetInstanceStateReturnsOnCall(i int, result1 storage.JSONObject, result2 error) {
+	fake.getInstanceStateMutex.Lock()
+	defer fake.getInstanceStateMutex.Unlock()
+	fake.GetInstanceStateStub = nil
+	if fake.getInstanceStateReturnsOnCall == nil {
+		fake.getInstanceStateReturnsOnCall = make(map[int]struct {
+			result1 storage.JSONObject
+			result2 error
+		})
+	}
+	fake.getInstanceStateReturnsOnCall[i] = struct {
+		result1 storage.JSONObject
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeServiceProvider) GetTerraformOutputs(arg1 context.Context, arg2 string) (storage.JSONObject, error) {
 	fake.getTerraformOutputsMutex.Lock()
 	ret, specificReturn := fake.getTerraformOutputsReturnsOnCall[len(fake.getTerraformOutputsArgsForCall)]
@@ -490,6 +685,75 @@ func (fake *FakeServiceProvider) GetTerraformOutputsReturnsOnCall(i int, result1
 	}{result1, result2}
 }
 
+func (fake *FakeServiceProvider) PollAction(arg1 context.Context, arg2 string, arg3 string) (bool, string, error) {
+	fake.pollActionMutex.Lock()
+	ret, specificReturn := fake.pollActionReturnsOnCall[len(fake.pollActionArgsForCall)]
+	fake.pollActionArgsForCall = append(fake.pollActionArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.PollActionStub
+	fakeReturns := fake.pollActionReturns
+	fake.recordInvocation("PollAction", []interface{}{arg1, arg2, arg3})
+	fake.pollActionMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fakeReturns.result1, fakeReturns.result2, fakeReturns.result3
+}
+
+func (fake *FakeServiceProvider) PollActionCallCount() int {
+	fake.pollActionMutex.RLock()
+	defer fake.pollActionMutex.RUnlock()
+	return len(fake.pollActionArgsForCall)
+}
+
+func (fake *FakeServiceProvider) PollActionCalls(stub func(context.Context, string, string) (bool, string, error)) {
+	fake.pollActionMutex.Lock()
+	defer fake.pollActionMutex.Unlock()
+	fake.PollActionStub = stub
+}
+
+func (fake *FakeServiceProvider) PollActionArgsForCall(i int) (context.Context, string, string) {
+	fake.pollActionMutex.RLock()
+	defer fake.pollActionMutex.RUnlock()
+	argsForCall := fake.pollActionArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeServiceProvider) PollActionReturns(result1 bool, result2 string, result3 error) {
+	fake.pollActionMutex.Lock()
+	defer fake.pollActionMutex.Unlock()
+	fake.PollActionStub = nil
+	fake.pollActionReturns = struct {
+		result1 bool
+		result2 string
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeServiceProvider) PollActionReturnsOnCall(i int, result1 bool, result2 string, result3 error) {
+	fake.pollActionMutex.Lock()
+	defer fake.pollActionMutex.Unlock()
+	fake.PollActionStub = nil
+	if fake.pollActionReturnsOnCall == nil {
+		fake.pollActionReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 string
+			result3 error
+		})
+	}
+	fake.pollActionReturnsOnCall[i] = struct {
+		result1 bool
+		result2 string
+		result3 error
+	}{result1, result2, result3}
+}
+
 func (fake *FakeServiceProvider) PollInstance(arg1 context.Context, arg2 string) (bool, string, error) {
 	fake.pollInstanceMutex.Lock()
 	ret, specificReturn := fake.pollInstanceReturnsOnCall[len(fake.pollInstanceArgsForCall)]
@@ -623,6 +887,73 @@ func (fake *FakeServiceProvider) ProvisionReturnsOnCall(i int, result1 storage.S
 	}{result1, result2}
 }
 
+func (fake *FakeServiceProvider) RunAction(arg1 context.Context, arg2 string, arg3 string, arg4 *varcontext.VarContext) (string, error) {
+	fake.runActionMutex.Lock()
+	ret, specificReturn := fake.runActionReturnsOnCall[len(fake.runActionArgsForCall)]
+	fake.runActionArgsForCall = append(fake.runActionArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 *varcontext.VarContext
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.RunActionStub
+	fakeReturns := fake.runActionReturns
+	fake.recordInvocation("RunAction", []interface{}{arg1, arg2, arg3, arg4})
+	fake.runActionMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeServiceProvider) RunActionCallCount() int {
+	fake.runActionMutex.RLock()
+	defer fake.runActionMutex.RUnlock()
+	return len(fake.runActionArgsForCall)
+}
+
+func (fake *FakeServiceProvider) RunActionCalls(stub func(context.Context, string, string, *varcontext.VarContext) (string, error)) {
+	fake.runActionMutex.Lock()
+	defer fake.runActionMutex.Unlock()
+	fake.RunActionStub = stub
+}
+
+func (fake *FakeServiceProvider) RunActionArgsForCall(i int) (context.Context, string, string, *varcontext.VarContext) {
+	fake.runActionMutex.RLock()
+	defer fake.runActionMutex.RUnlock()
+	argsForCall := fake.runActionArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeServiceProvider) RunActionReturns(result1 string, result2 error) {
+	fake.runActionMutex.Lock()
+	defer fake.runActionMutex.Unlock()
+	fake.RunActionStub = nil
+	fake.runActionReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeServiceProvider) RunActionReturnsOnCall(i int, result1 string, result2 error) {
+	fake.runActionMutex.Lock()
+	defer fake.runActionMutex.Unlock()
+	fake.RunActionStub = nil
+	if fake.runActionReturnsOnCall == nil {
+		fake.runActionReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.runActionReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeServiceProvider) Unbind(arg1 context.Context, arg2 string, arg3 string, arg4 *varcontext.VarContext) error {
 	fake.unbindMutex.Lock()
 	ret, specificReturn := fake.unbindReturnsOnCall[len(fake.unbindArgsForCall)]
@@ -820,6 +1151,8 @@ func (fake *FakeServiceProvider) UpgradeReturnsOnCall(i int, result1 models.Serv
 func (fake *FakeServiceProvider) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.adoptMutex.RLock()
+	defer fake.adoptMutex.RUnlock()
 	fake.bindMutex.RLock()
 	defer fake.bindMutex.RUnlock()
 	fake.checkUpgradeAvailableMutex.RLock()
@@ -828,12 +1161,18 @@ func (fake *FakeServiceProvider) Invocations() map[string][][]interface{} {
 	defer fake.deprovisionMutex.RUnlock()
 	fake.getImportedPropertiesMutex.RLock()
 	defer fake.getImportedPropertiesMutex.RUnlock()
+	fake.getInstanceStateMutex.RLock()
+	defer fake.getInstanceStateMutex.RUnlock()
 	fake.getTerraformOutputsMutex.RLock()
 	defer fake.getTerraformOutputsMutex.RUnlock()
+	fake.pollActionMutex.RLock()
+	defer fake.pollActionMutex.RUnlock()
 	fake.pollInstanceMutex.RLock()
 	defer fake.pollInstanceMutex.RUnlock()
 	fake.provisionMutex.RLock()
 	defer fake.provisionMutex.RUnlock()
+	fake.runActionMutex.RLock()
+	defer fake.runActionMutex.RUnlock()
 	fake.unbindMutex.RLock()
 	defer fake.unbindMutex.RUnlock()
 	fake.updateMutex.RLock()
@@ -9,6 +9,28 @@ import (
 )
 
 type FakeServiceProviderStorage struct {
+	DeleteTerraformDeploymentStub        func(string) error
+	deleteTerraformDeploymentMutex       sync.RWMutex
+	deleteTerraformDeploymentArgsForCall []struct {
+		arg1 string
+	}
+	deleteTerraformDeploymentReturns struct {
+		result1 error
+	}
+	deleteTerraformDeploymentReturnsOnCall map[int]struct {
+		result1 error
+	}
+	DeleteTerraformStateStub        func(string) error
+	deleteTerraformStateMutex       sync.RWMutex
+	deleteTerraformStateArgsForCall []struct {
+		arg1 string
+	}
+	deleteTerraformStateReturns struct {
+		result1 error
+	}
+	deleteTerraformStateReturnsOnCall map[int]struct {
+		result1 error
+	}
 	ExistsTerraformDeploymentStub        func(string) (bool, error)
 	existsTerraformDeploymentMutex       sync.RWMutex
 	existsTerraformDeploymentArgsForCall []struct {
@@ -22,6 +44,30 @@ type FakeServiceProviderStorage struct {
 		result1 bool
 		result2 error
 	}
+	FinishOperationStub        func(storage.Operation) error
+	finishOperationMutex       sync.RWMutex
+	finishOperationArgsForCall []struct {
+		arg1 storage.Operation
+	}
+	finishOperationReturns struct {
+		result1 error
+	}
+	finishOperationReturnsOnCall map[int]struct {
+		result1 error
+	}
+	GetServiceInstanceDetailsStub        func(string) (storage.ServiceInstanceDetails, error)
+	getServiceInstanceDetailsMutex       sync.RWMutex
+	getServiceInstanceDetailsArgsForCall []struct {
+		arg1 string
+	}
+	getServiceInstanceDetailsReturns struct {
+		result1 storage.ServiceInstanceDetails
+		result2 error
+	}
+	getServiceInstanceDetailsReturnsOnCall map[int]struct {
+		result1 storage.ServiceInstanceDetails
+		result2 error
+	}
 	GetTerraformDeploymentStub        func(string) (storage.TerraformDeployment, error)
 	getTerraformDeploymentMutex       sync.RWMutex
 	getTerraformDeploymentArgsForCall []struct {
@@ -35,6 +81,30 @@ type FakeServiceProviderStorage struct {
 		result1 storage.TerraformDeployment
 		result2 error
 	}
+	GetTerraformStateStub        func(string) ([]byte, error)
+	getTerraformStateMutex       sync.RWMutex
+	getTerraformStateArgsForCall []struct {
+		arg1 string
+	}
+	getTerraformStateReturns struct {
+		result1 []byte
+		result2 error
+	}
+	getTerraformStateReturnsOnCall map[int]struct {
+		result1 []byte
+		result2 error
+	}
+	StoreOperationStub        func(storage.Operation) error
+	storeOperationMutex       sync.RWMutex
+	storeOperationArgsForCall []struct {
+		arg1 storage.Operation
+	}
+	storeOperationReturns struct {
+		result1 error
+	}
+	storeOperationReturnsOnCall map[int]struct {
+		result1 error
+	}
 	StoreTerraformDeploymentStub        func(storage.TerraformDeployment) error
 	storeTerraformDeploymentMutex       sync.RWMutex
 	storeTerraformDeploymentArgsForCall []struct {
@@ -46,10 +116,167 @@ type FakeServiceProviderStorage struct {
 	storeTerraformDeploymentReturnsOnCall map[int]struct {
 		result1 error
 	}
+	StoreTerraformStateStub        func(string, []byte) error
+	storeTerraformStateMutex       sync.RWMutex
+	storeTerraformStateArgsForCall []struct {
+		arg1 string
+		arg2 []byte
+	}
+	storeTerraformStateReturns struct {
+		result1 error
+	}
+	storeTerraformStateReturnsOnCall map[int]struct {
+		result1 error
+	}
+	StoreWebhookDeliveryStub        func(storage.WebhookDelivery) error
+	storeWebhookDeliveryMutex       sync.RWMutex
+	storeWebhookDeliveryArgsForCall []struct {
+		arg1 storage.WebhookDelivery
+	}
+	storeWebhookDeliveryReturns struct {
+		result1 error
+	}
+	storeWebhookDeliveryReturnsOnCall map[int]struct {
+		result1 error
+	}
+	UnlockTerraformStateStub        func(string, string) error
+	unlockTerraformStateMutex       sync.RWMutex
+	unlockTerraformStateArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	unlockTerraformStateReturns struct {
+		result1 error
+	}
+	unlockTerraformStateReturnsOnCall map[int]struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeServiceProviderStorage) DeleteTerraformDeployment(arg1 string) error {
+	fake.deleteTerraformDeploymentMutex.Lock()
+	ret, specificReturn := fake.deleteTerraformDeploymentReturnsOnCall[len(fake.deleteTerraformDeploymentArgsForCall)]
+	fake.deleteTerraformDeploymentArgsForCall = append(fake.deleteTerraformDeploymentArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.DeleteTerraformDeploymentStub
+	fakeReturns := fake.deleteTerraformDeploymentReturns
+	fake.recordInvocation("DeleteTerraformDeployment", []interface{}{arg1})
+	fake.deleteTerraformDeploymentMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeServiceProviderStorage) DeleteTerraformDeploymentCallCount() int {
+	fake.deleteTerraformDeploymentMutex.RLock()
+	defer fake.deleteTerraformDeploymentMutex.RUnlock()
+	return len(fake.deleteTerraformDeploymentArgsForCall)
+}
+
+func (fake *FakeServiceProviderStorage) DeleteTerraformDeploymentCalls(stub func(string) error) {
+	fake.deleteTerraformDeploymentMutex.Lock()
+	defer fake.deleteTerraformDeploymentMutex.Unlock()
+	fake.DeleteTerraformDeploymentStub = stub
+}
+
+func (fake *FakeServiceProviderStorage) DeleteTerraformDeploymentArgsForCall(i int) string {
+	fake.deleteTerraformDeploymentMutex.RLock()
+	defer fake.deleteTerraformDeploymentMutex.RUnlock()
+	argsForCall := fake.deleteTerraformDeploymentArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeServiceProviderStorage) DeleteTerraformDeploymentReturns(result1 error) {
+	fake.deleteTerraformDeploymentMutex.Lock()
+	defer fake.deleteTerraformDeploymentMutex.Unlock()
+	fake.DeleteTerraformDeploymentStub = nil
+	fake.deleteTerraformDeploymentReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeServiceProviderStorage) DeleteTerraformDeploymentReturnsOnCall(i int, result1 error) {
+	fake.deleteTerraformDeploymentMutex.Lock()
+	defer fake.deleteTerraformDeploymentMutex.Unlock()
+	fake.DeleteTerraformDeploymentStub = nil
+	if fake.deleteTerraformDeploymentReturnsOnCall == nil {
+		fake.deleteTerraformDeploymentReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteTerraformDeploymentReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeServiceProviderStorage) DeleteTerraformState(arg1 string) error {
+	fake.deleteTerraformStateMutex.Lock()
+	ret, specificReturn := fake.deleteTerraformStateReturnsOnCall[len(fake.deleteTerraformStateArgsForCall)]
+	fake.deleteTerraformStateArgsForCall = append(fake.deleteTerraformStateArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.DeleteTerraformStateStub
+	fakeReturns := fake.deleteTerraformStateReturns
+	fake.recordInvocation("DeleteTerraformState", []interface{}{arg1})
+	fake.deleteTerraformStateMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeServiceProviderStorage) DeleteTerraformStateCallCount() int {
+	fake.deleteTerraformStateMutex.RLock()
+	defer fake.deleteTerraformStateMutex.RUnlock()
+	return len(fake.deleteTerraformStateArgsForCall)
+}
+
+func (fake *FakeServiceProviderStorage) DeleteTerraformStateCalls(stub func(string) error) {
+	fake.deleteTerraformStateMutex.Lock()
+	defer fake.deleteTerraformStateMutex.Unlock()
+	fake.DeleteTerraformStateStub = stub
+}
+
+func (fake *FakeServiceProviderStorage) DeleteTerraformStateArgsForCall(i int) string {
+	fake.deleteTerraformStateMutex.RLock()
+	defer fake.deleteTerraformStateMutex.RUnlock()
+	argsForCall := fake.deleteTerraformStateArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeServiceProviderStorage) DeleteTerraformStateReturns(result1 error) {
+	fake.deleteTerraformStateMutex.Lock()
+	defer fake.deleteTerraformStateMutex.Unlock()
+	fake.DeleteTerraformStateStub = nil
+	fake.deleteTerraformStateReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeServiceProviderStorage) DeleteTerraformStateReturnsOnCall(i int, result1 error) {
+	fake.deleteTerraformStateMutex.Lock()
+	defer fake.deleteTerraformStateMutex.Unlock()
+	fake.DeleteTerraformStateStub = nil
+	if fake.deleteTerraformStateReturnsOnCall == nil {
+		fake.deleteTerraformStateReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteTerraformStateReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeServiceProviderStorage) ExistsTerraformDeployment(arg1 string) (bool, error) {
 	fake.existsTerraformDeploymentMutex.Lock()
 	ret, specificReturn := fake.existsTerraformDeploymentReturnsOnCall[len(fake.existsTerraformDeploymentArgsForCall)]
@@ -114,6 +341,131 @@ func (fake *FakeServiceProviderStorage) ExistsTerraformDeploymentReturnsOnCall(i
 	}{result1, result2}
 }
 
+func (fake *FakeServiceProviderStorage) FinishOperation(arg1 storage.Operation) error {
+	fake.finishOperationMutex.Lock()
+	ret, specificReturn := fake.finishOperationReturnsOnCall[len(fake.finishOperationArgsForCall)]
+	fake.finishOperationArgsForCall = append(fake.finishOperationArgsForCall, struct {
+		arg1 storage.Operation
+	}{arg1})
+	stub := fake.FinishOperationStub
+	fakeReturns := fake.finishOperationReturns
+	fake.recordInvocation("FinishOperation", []interface{}{arg1})
+	fake.finishOperationMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeServiceProviderStorage) FinishOperationCallCount() int {
+	fake.finishOperationMutex.RLock()
+	defer fake.finishOperationMutex.RUnlock()
+	return len(fake.finishOperationArgsForCall)
+}
+
+func (fake *FakeServiceProviderStorage) FinishOperationCalls(stub func(storage.Operation) error) {
+	fake.finishOperationMutex.Lock()
+	defer fake.finishOperationMutex.Unlock()
+	fake.FinishOperationStub = stub
+}
+
+func (fake *FakeServiceProviderStorage) FinishOperationArgsForCall(i int) storage.Operation {
+	fake.finishOperationMutex.RLock()
+	defer fake.finishOperationMutex.RUnlock()
+	argsForCall := fake.finishOperationArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeServiceProviderStorage) FinishOperationReturns(result1 error) {
+	fake.finishOperationMutex.Lock()
+	defer fake.finishOperationMutex.Unlock()
+	fake.FinishOperationStub = nil
+	fake.finishOperationReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeServiceProviderStorage) FinishOperationReturnsOnCall(i int, result1 error) {
+	fake.finishOperationMutex.Lock()
+	defer fake.finishOperationMutex.Unlock()
+	fake.FinishOperationStub = nil
+	if fake.finishOperationReturnsOnCall == nil {
+		fake.finishOperationReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.finishOperationReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeServiceProviderStorage) GetServiceInstanceDetails(arg1 string) (storage.ServiceInstanceDetails, error) {
+	fake.getServiceInstanceDetailsMutex.Lock()
+	ret, specificReturn := fake.getServiceInstanceDetailsReturnsOnCall[len(fake.getServiceInstanceDetailsArgsForCall)]
+	fake.getServiceInstanceDetailsArgsForCall = append(fake.getServiceInstanceDetailsArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.GetServiceInstanceDetailsStub
+	fakeReturns := fake.getServiceInstanceDetailsReturns
+	fake.recordInvocation("GetServiceInstanceDetails", []interface{}{arg1})
+	fake.getServiceInstanceDetailsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeServiceProviderStorage) GetServiceInstanceDetailsCallCount() int {
+	fake.getServiceInstanceDetailsMutex.RLock()
+	defer fake.getServiceInstanceDetailsMutex.RUnlock()
+	return len(fake.getServiceInstanceDetailsArgsForCall)
+}
+
+func (fake *FakeServiceProviderStorage) GetServiceInstanceDetailsCalls(stub func(string) (storage.ServiceInstanceDetails, error)) {
+	fake.getServiceInstanceDetailsMutex.Lock()
+	defer fake.getServiceInstanceDetailsMutex.Unlock()
+	fake.GetServiceInstanceDetailsStub = stub
+}
+
+func (fake *FakeServiceProviderStorage) GetServiceInstanceDetailsArgsForCall(i int) string {
+	fake.getServiceInstanceDetailsMutex.RLock()
+	defer fake.getServiceInstanceDetailsMutex.RUnlock()
+	argsForCall := fake.getServiceInstanceDetailsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeServiceProviderStorage) GetServiceInstanceDetailsReturns(result1 storage.ServiceInstanceDetails, result2 error) {
+	fake.getServiceInstanceDetailsMutex.Lock()
+	defer fake.getServiceInstanceDetailsMutex.Unlock()
+	fake.GetServiceInstanceDetailsStub = nil
+	fake.getServiceInstanceDetailsReturns = struct {
+		result1 storage.ServiceInstanceDetails
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeServiceProviderStorage) GetServiceInstanceDetailsReturnsOnCall(i int, result1 storage.ServiceInstanceDetails, result2 error) {
+	fake.getServiceInstanceDetailsMutex.Lock()
+	defer fake.getServiceInstanceDetailsMutex.Unlock()
+	fake.GetServiceInstanceDetailsStub = nil
+	if fake.getServiceInstanceDetailsReturnsOnCall == nil {
+		fake.getServiceInstanceDetailsReturnsOnCall = make(map[int]struct {
+			result1 storage.ServiceInstanceDetails
+			result2 error
+		})
+	}
+	fake.getServiceInstanceDetailsReturnsOnCall[i] = struct {
+		result1 storage.ServiceInstanceDetails
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeServiceProviderStorage) GetTerraformDeployment(arg1 string) (storage.TerraformDeployment, error) {
 	fake.getTerraformDeploymentMutex.Lock()
 	ret, specificReturn := fake.getTerraformDeploymentReturnsOnCall[len(fake.getTerraformDeploymentArgsForCall)]
@@ -178,6 +530,131 @@ func (fake *FakeServiceProviderStorage) GetTerraformDeploymentReturnsOnCall(i in
 	}{result1, result2}
 }
 
+func (fake *FakeServiceProviderStorage) GetTerraformState(arg1 string) ([]byte, error) {
+	fake.getTerraformStateMutex.Lock()
+	ret, specificReturn := fake.getTerraformStateReturnsOnCall[len(fake.getTerraformStateArgsForCall)]
+	fake.getTerraformStateArgsForCall = append(fake.getTerraformStateArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.GetTerraformStateStub
+	fakeReturns := fake.getTerraformStateReturns
+	fake.recordInvocation("GetTerraformState", []interface{}{arg1})
+	fake.getTerraformStateMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeServiceProviderStorage) GetTerraformStateCallCount() int {
+	fake.getTerraformStateMutex.RLock()
+	defer fake.getTerraformStateMutex.RUnlock()
+	return len(fake.getTerraformStateArgsForCall)
+}
+
+func (fake *FakeServiceProviderStorage) GetTerraformStateCalls(stub func(string) ([]byte, error)) {
+	fake.getTerraformStateMutex.Lock()
+	defer fake.getTerraformStateMutex.Unlock()
+	fake.GetTerraformStateStub = stub
+}
+
+func (fake *FakeServiceProviderStorage) GetTerraformStateArgsForCall(i int) string {
+	fake.getTerraformStateMutex.RLock()
+	defer fake.getTerraformStateMutex.RUnlock()
+	argsForCall := fake.getTerraformStateArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeServiceProviderStorage) GetTerraformStateReturns(result1 []byte, result2 error) {
+	fake.getTerraformStateMutex.Lock()
+	defer fake.getTerraformStateMutex.Unlock()
+	fake.GetTerraformStateStub = nil
+	fake.getTerraformStateReturns = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeServiceProviderStorage) GetTerraformStateReturnsOnCall(i int, result1 []byte, result2 error) {
+	fake.getTerraformStateMutex.Lock()
+	defer fake.getTerraformStateMutex.Unlock()
+	fake.GetTerraformStateStub = nil
+	if fake.getTerraformStateReturnsOnCall == nil {
+		fake.getTerraformStateReturnsOnCall = make(map[int]struct {
+			result1 []byte
+			result2 error
+		})
+	}
+	fake.getTerraformStateReturnsOnCall[i] = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeServiceProviderStorage) StoreOperation(arg1 storage.Operation) error {
+	fake.storeOperationMutex.Lock()
+	ret, specificReturn := fake.storeOperationReturnsOnCall[len(fake.storeOperationArgsForCall)]
+	fake.storeOperationArgsForCall = append(fake.storeOperationArgsForCall, struct {
+		arg1 storage.Operation
+	}{arg1})
+	stub := fake.StoreOperationStub
+	fakeReturns := fake.storeOperationReturns
+	fake.recordInvocation("StoreOperation", []interface{}{arg1})
+	fake.storeOperationMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeServiceProviderStorage) StoreOperationCallCount() int {
+	fake.storeOperationMutex.RLock()
+	defer fake.storeOperationMutex.RUnlock()
+	return len(fake.storeOperationArgsForCall)
+}
+
+func (fake *FakeServiceProviderStorage) StoreOperationCalls(stub func(storage.Operation) error) {
+	fake.storeOperationMutex.Lock()
+	defer fake.storeOperationMutex.Unlock()
+	fake.StoreOperationStub = stub
+}
+
+func (fake *FakeServiceProviderStorage) StoreOperationArgsForCall(i int) storage.Operation {
+	fake.storeOperationMutex.RLock()
+	defer fake.storeOperationMutex.RUnlock()
+	argsForCall := fake.storeOperationArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeServiceProviderStorage) StoreOperationReturns(result1 error) {
+	fake.storeOperationMutex.Lock()
+	defer fake.storeOperationMutex.Unlock()
+	fake.StoreOperationStub = nil
+	fake.storeOperationReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeServiceProviderStorage) StoreOperationReturnsOnCall(i int, result1 error) {
+	fake.storeOperationMutex.Lock()
+	defer fake.storeOperationMutex.Unlock()
+	fake.StoreOperationStub = nil
+	if fake.storeOperationReturnsOnCall == nil {
+		fake.storeOperationReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.storeOperationReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeServiceProviderStorage) StoreTerraformDeployment(arg1 storage.TerraformDeployment) error {
 	fake.storeTerraformDeploymentMutex.Lock()
 	ret, specificReturn := fake.storeTerraformDeploymentReturnsOnCall[len(fake.storeTerraformDeploymentArgsForCall)]
@@ -239,15 +716,223 @@ func (fake *FakeServiceProviderStorage) StoreTerraformDeploymentReturnsOnCall(i
 	}{result1}
 }
 
+func (fake *FakeServiceProviderStorage) StoreTerraformState(arg1 string, arg2 []byte) error {
+	var arg2Copy []byte
+	if arg2 != nil {
+		arg2Copy = make([]byte, len(arg2))
+		copy(arg2Copy, arg2)
+	}
+	fake.storeTerraformStateMutex.Lock()
+	ret, specificReturn := fake.storeTerraformStateReturnsOnCall[len(fake.storeTerraformStateArgsForCall)]
+	fake.storeTerraformStateArgsForCall = append(fake.storeTerraformStateArgsForCall, struct {
+		arg1 string
+		arg2 []byte
+	}{arg1, arg2Copy})
+	stub := fake.StoreTerraformStateStub
+	fakeReturns := fake.storeTerraformStateReturns
+	fake.recordInvocation("StoreTerraformState", []interface{}{arg1, arg2Copy})
+	fake.storeTerraformStateMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeServiceProviderStorage) StoreTerraformStateCallCount() int {
+	fake.storeTerraformStateMutex.RLock()
+	defer fake.storeTerraformStateMutex.RUnlock()
+	return len(fake.storeTerraformStateArgsForCall)
+}
+
+func (fake *FakeServiceProviderStorage) StoreTerraformStateCalls(stub func(string, []byte) error) {
+	fake.storeTerraformStateMutex.Lock()
+	defer fake.storeTerraformStateMutex.Unlock()
+	fake.StoreTerraformStateStub = stub
+}
+
+func (fake *FakeServiceProviderStorage) StoreTerraformStateArgsForCall(i int) (string, []byte) {
+	fake.storeTerraformStateMutex.RLock()
+	defer fake.storeTerraformStateMutex.RUnlock()
+	argsForCall := fake.storeTerraformStateArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeServiceProviderStorage) StoreTerraformStateReturns(result1 error) {
+	fake.storeTerraformStateMutex.Lock()
+	defer fake.storeTerraformStateMutex.Unlock()
+	fake.StoreTerraformStateStub = nil
+	fake.storeTerraformStateReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeServiceProviderStorage) StoreTerraformStateReturnsOnCall(i int, result1 error) {
+	fake.storeTerraformStateMutex.Lock()
+	defer fake.storeTerraformStateMutex.Unlock()
+	fake.StoreTerraformStateStub = nil
+	if fake.storeTerraformStateReturnsOnCall == nil {
+		fake.storeTerraformStateReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.storeTerraformStateReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeServiceProviderStorage) StoreWebhookDelivery(arg1 storage.WebhookDelivery) error {
+	fake.storeWebhookDeliveryMutex.Lock()
+	ret, specificReturn := fake.storeWebhookDeliveryReturnsOnCall[len(fake.storeWebhookDeliveryArgsForCall)]
+	fake.storeWebhookDeliveryArgsForCall = append(fake.storeWebhookDeliveryArgsForCall, struct {
+		arg1 storage.WebhookDelivery
+	}{arg1})
+	stub := fake.StoreWebhookDeliveryStub
+	fakeReturns := fake.storeWebhookDeliveryReturns
+	fake.recordInvocation("StoreWebhookDelivery", []interface{}{arg1})
+	fake.storeWebhookDeliveryMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeServiceProviderStorage) StoreWebhookDeliveryCallCount() int {
+	fake.storeWebhookDeliveryMutex.RLock()
+	defer fake.storeWebhookDeliveryMutex.RUnlock()
+	return len(fake.storeWebhookDeliveryArgsForCall)
+}
+
+func (fake *FakeServiceProviderStorage) StoreWebhookDeliveryCalls(stub func(storage.WebhookDelivery) error) {
+	fake.storeWebhookDeliveryMutex.Lock()
+	defer fake.storeWebhookDeliveryMutex.Unlock()
+	fake.StoreWebhookDeliveryStub = stub
+}
+
+func (fake *FakeServiceProviderStorage) StoreWebhookDeliveryArgsForCall(i int) storage.WebhookDelivery {
+	fake.storeWebhookDeliveryMutex.RLock()
+	defer fake.storeWebhookDeliveryMutex.RUnlock()
+	argsForCall := fake.storeWebhookDeliveryArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeServiceProviderStorage) StoreWebhookDeliveryReturns(result1 error) {
+	fake.storeWebhookDeliveryMutex.Lock()
+	defer fake.storeWebhookDeliveryMutex.Unlock()
+	fake.StoreWebhookDeliveryStub = nil
+	fake.storeWebhookDeliveryReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeServiceProviderStorage) StoreWebhookDeliveryReturnsOnCall(i int, result1 error) {
+	fake.storeWebhookDeliveryMutex.Lock()
+	defer fake.storeWebhookDeliveryMutex.Unlock()
+	fake.StoreWebhookDeliveryStub = nil
+	if fake.storeWebhookDeliveryReturnsOnCall == nil {
+		fake.storeWebhookDeliveryReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.storeWebhookDeliveryReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeServiceProviderStorage) UnlockTerraformState(arg1 string, arg2 string) error {
+	fake.unlockTerraformStateMutex.Lock()
+	ret, specificReturn := fake.unlockTerraformStateReturnsOnCall[len(fake.unlockTerraformStateArgsForCall)]
+	fake.unlockTerraformStateArgsForCall = append(fake.unlockTerraformStateArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.UnlockTerraformStateStub
+	fakeReturns := fake.unlockTerraformStateReturns
+	fake.recordInvocation("UnlockTerraformState", []interface{}{arg1, arg2})
+	fake.unlockTerraformStateMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeServiceProviderStorage) UnlockTerraformStateCallCount() int {
+	fake.unlockTerraformStateMutex.RLock()
+	defer fake.unlockTerraformStateMutex.RUnlock()
+	return len(fake.unlockTerraformStateArgsForCall)
+}
+
+func (fake *FakeServiceProviderStorage) UnlockTerraformStateCalls(stub func(string, string) error) {
+	fake.unlockTerraformStateMutex.Lock()
+	defer fake.unlockTerraformStateMutex.Unlock()
+	fake.UnlockTerraformStateStub = stub
+}
+
+func (fake *FakeServiceProviderStorage) UnlockTerraformStateArgsForCall(i int) (string, string) {
+	fake.unlockTerraformStateMutex.RLock()
+	defer fake.unlockTerraformStateMutex.RUnlock()
+	argsForCall := fake.unlockTerraformStateArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeServiceProviderStorage) UnlockTerraformStateReturns(result1 error) {
+	fake.unlockTerraformStateMutex.Lock()
+	defer fake.unlockTerraformStateMutex.Unlock()
+	fake.UnlockTerraformStateStub = nil
+	fake.unlockTerraformStateReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeServiceProviderStorage) UnlockTerraformStateReturnsOnCall(i int, result1 error) {
+	fake.unlockTerraformStateMutex.Lock()
+	defer fake.unlockTerraformStateMutex.Unlock()
+	fake.UnlockTerraformStateStub = nil
+	if fake.unlockTerraformStateReturnsOnCall == nil {
+		fake.unlockTerraformStateReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.unlockTerraformStateReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeServiceProviderStorage) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.deleteTerraformDeploymentMutex.RLock()
+	defer fake.deleteTerraformDeploymentMutex.RUnlock()
+	fake.deleteTerraformStateMutex.RLock()
+	defer fake.deleteTerraformStateMutex.RUnlock()
 	fake.existsTerraformDeploymentMutex.RLock()
 	defer fake.existsTerraformDeploymentMutex.RUnlock()
+	fake.finishOperationMutex.RLock()
+	defer fake.finishOperationMutex.RUnlock()
+	fake.getServiceInstanceDetailsMutex.RLock()
+	defer fake.getServiceInstanceDetailsMutex.RUnlock()
 	fake.getTerraformDeploymentMutex.RLock()
 	defer fake.getTerraformDeploymentMutex.RUnlock()
+	fake.getTerraformStateMutex.RLock()
+	defer fake.getTerraformStateMutex.RUnlock()
+	fake.storeOperationMutex.RLock()
+	defer fake.storeOperationMutex.RUnlock()
 	fake.storeTerraformDeploymentMutex.RLock()
 	defer fake.storeTerraformDeploymentMutex.RUnlock()
+	fake.storeTerraformStateMutex.RLock()
+	defer fake.storeTerraformStateMutex.RUnlock()
+	fake.storeWebhookDeliveryMutex.RLock()
+	defer fake.storeWebhookDeliveryMutex.RUnlock()
+	fake.unlockTerraformStateMutex.RLock()
+	defer fake.unlockTerraformStateMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -0,0 +1,103 @@
+package broker
+
+import (
+	"fmt"
+
+	"github.com/cloudfoundry/cloud-service-broker/internal/storage"
+	"github.com/cloudfoundry/cloud-service-broker/pkg/validation"
+	"github.com/cloudfoundry/cloud-service-broker/pkg/varcontext"
+)
+
+// InstanceAction is a named operation that can be run on an existing service instance,
+// such as a restart or a snapshot, for tasks that do not fit provision, update or bind.
+type InstanceAction struct {
+	Name              string
+	Description       string
+	InputVariables    []BrokerVariable
+	ComputedVariables []varcontext.DefaultVariable
+}
+
+var _ validation.Validatable = (*InstanceAction)(nil)
+
+// Validate implements validation.Validatable.
+func (action *InstanceAction) Validate() (errs *validation.FieldError) {
+	errs = errs.Also(
+		validation.ErrIfNotOSBName(action.Name, "Name"),
+		validation.ErrIfBlank(action.Description, "Description"),
+	)
+
+	for i, v := range action.InputVariables {
+		errs = errs.Also(v.Validate().ViaFieldIndex("InputVariables", i))
+	}
+
+	for i, v := range action.ComputedVariables {
+		errs = errs.Also(v.Validate().ViaFieldIndex("ComputedVariables", i))
+	}
+
+	return errs
+}
+
+// ExtensionPath is the OSBAPI extensions endpoint that runs the action
+func (action *InstanceAction) ExtensionPath() string {
+	return fmt.Sprintf("/v2/service_instances/:instance_id/extensions/%s", action.Name)
+}
+
+// GetActionByName finds an action of this service by its name.
+func (svc *ServiceDefinition) GetActionByName(name string) (*InstanceAction, error) {
+	for _, action := range svc.Actions {
+		if action.Name == name {
+			return &action, nil
+		}
+	}
+
+	return nil, fmt.Errorf("action %q could not be found", name)
+}
+
+// ExtensionsMetadata describes the actions of this service, so that they can be listed in the
+// catalog metadata and by the extensions endpoint of a service instance.
+func (svc *ServiceDefinition) ExtensionsMetadata() []map[string]interface{} {
+	var extensions []map[string]interface{}
+	for _, action := range svc.Actions {
+		extensions = append(extensions, map[string]interface{}{
+			"name":        action.Name,
+			"description": action.Description,
+			"path":        action.ExtensionPath(),
+			"parameters":  CreateJSONSchema(action.InputVariables),
+		})
+	}
+	return extensions
+}
+
+// ActionVariables gets the variable resolution context for running an action on a service instance.
+// The variable resolution order is the following:
+//
+// 1. Variables defined in the action's computed variables.
+// 2. User defined variables from the request.
+// 3. Default variables (in the action's input variables).
+func (svc *ServiceDefinition) ActionVariables(instance storage.ServiceInstanceDetails, action InstanceAction, params map[string]interface{}, plan *ServicePlan, originatingIdentity map[string]interface{}) (*varcontext.VarContext, error) {
+	constants := map[string]interface{}{
+		"request.x_broker_api_originating_identity": originatingIdentity,
+
+		"request.instance_id":     instance.GUID,
+		"request.action":          action.Name,
+		"request.plan_id":         instance.PlanGUID,
+		"request.service_id":      instance.ServiceGUID,
+		"request.plan_properties": plan.GetServiceProperties(),
+
+		"instance.name":    instance.Name,
+		"instance.details": instance.Outputs,
+	}
+
+	var defaults []varcontext.DefaultVariable
+	for _, v := range action.InputVariables {
+		defaults = append(defaults, varcontext.DefaultVariable{Name: v.FieldName, Default: v.Default, Overwrite: false, Type: string(v.Type)})
+	}
+
+	builder := varcontext.Builder().
+		SetEvalConstants(constants).
+		MergeMap(params).
+		MergeDefaultWithEval(defaults).
+		MergeDefaultWithEval(action.ComputedVariables)
+
+	return buildAndValidate(builder, action.InputVariables)
+}
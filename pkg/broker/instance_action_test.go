@@ -0,0 +1,124 @@
+package broker_test
+
+import (
+	"github.com/cloudfoundry/cloud-service-broker/internal/storage"
+	"github.com/cloudfoundry/cloud-service-broker/pkg/broker"
+	"github.com/cloudfoundry/cloud-service-broker/pkg/varcontext"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cf/brokerapi/v8/domain"
+)
+
+var _ = Describe("InstanceAction", func() {
+	var service broker.ServiceDefinition
+
+	BeforeEach(func() {
+		service = broker.ServiceDefinition{
+			ID:   "55ad8194-0431-11ec-948a-63ff62e94b14",
+			Name: "fake-service",
+			Plans: []broker.ServicePlan{
+				{
+					ServicePlan:       domain.ServicePlan{ID: "b8e5ba2e-0431-11ec-948a-63ff62e94b14", Name: "fake-plan"},
+					ServiceProperties: map[string]interface{}{"tier": "small"},
+				},
+			},
+			Actions: []broker.InstanceAction{
+				{
+					Name:        "snapshot",
+					Description: "Takes a snapshot of the database",
+					InputVariables: []broker.BrokerVariable{
+						{FieldName: "snapshot_name", Type: broker.JSONTypeString, Details: "name of the snapshot", Required: true},
+						{FieldName: "retention_days", Type: broker.JSONTypeInteger, Details: "days to keep the snapshot", Default: 7},
+					},
+					ComputedVariables: []varcontext.DefaultVariable{
+						{Name: "tf_id", Default: "tf:${request.instance_id}:action:${request.action}", Overwrite: true},
+						{Name: "tier", Default: "${request.plan_properties[\"tier\"]}", Overwrite: true},
+					},
+				},
+			},
+		}
+	})
+
+	Describe("Validate", func() {
+		It("should fail when an action name is duplicated", func() {
+			service.Actions = append(service.Actions, broker.InstanceAction{Name: "snapshot", Description: "another"})
+
+			Expect(service.Validate()).To(MatchError("duplicated value, must be unique: snapshot: Actions[1].Name"))
+		})
+
+		It("should fail when an action has no description", func() {
+			service.Actions[0].Description = ""
+
+			Expect(service.Validate()).To(MatchError("missing field(s): Actions[0].Description"))
+		})
+	})
+
+	Describe("GetActionByName", func() {
+		It("returns the action", func() {
+			action, err := service.GetActionByName("snapshot")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(action.Description).To(Equal("Takes a snapshot of the database"))
+		})
+
+		It("returns an error when the action does not exist", func() {
+			_, err := service.GetActionByName("restart")
+			Expect(err).To(MatchError(`action "restart" could not be found`))
+		})
+	})
+
+	Describe("CatalogEntry", func() {
+		It("lists the actions as extensions in the service metadata", func() {
+			entry := service.CatalogEntry()
+
+			Expect(entry.Metadata.AdditionalMetadata).To(HaveKey("extensions"))
+			extensions := entry.Metadata.AdditionalMetadata["extensions"].([]map[string]interface{})
+			Expect(extensions).To(HaveLen(1))
+			Expect(extensions[0]).To(HaveKeyWithValue("name", "snapshot"))
+			Expect(extensions[0]).To(HaveKeyWithValue("description", "Takes a snapshot of the database"))
+			Expect(extensions[0]).To(HaveKeyWithValue("path", "/v2/service_instances/:instance_id/extensions/snapshot"))
+			Expect(extensions[0]).To(HaveKeyWithValue("parameters", HaveKeyWithValue("required", []string{"snapshot_name"})))
+		})
+
+		It("has no extensions metadata when there are no actions", func() {
+			service.Actions = nil
+
+			Expect(service.CatalogEntry().Metadata.AdditionalMetadata).To(BeNil())
+		})
+	})
+
+	Describe("ActionVariables", func() {
+		var instance storage.ServiceInstanceDetails
+
+		BeforeEach(func() {
+			instance = storage.ServiceInstanceDetails{
+				GUID:        "instance-id",
+				Name:        "my-db",
+				ServiceGUID: service.ID,
+				PlanGUID:    "b8e5ba2e-0431-11ec-948a-63ff62e94b14",
+				Outputs:     storage.JSONObject{"hostname": "db.example.com"},
+			}
+		})
+
+		It("merges the request parameters, defaults and computed variables", func() {
+			vars, err := service.ActionVariables(instance, service.Actions[0], map[string]interface{}{"snapshot_name": "nightly"}, &service.Plans[0], nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(vars.ToMap()).To(Equal(map[string]interface{}{
+				"snapshot_name":  "nightly",
+				"retention_days": 7,
+				"tf_id":          "tf:instance-id:action:snapshot",
+				"tier":           "small",
+			}))
+		})
+
+		It("fails when the parameters do not match the schema", func() {
+			_, err := service.ActionVariables(instance, service.Actions[0], map[string]interface{}{"snapshot_name": 42}, &service.Plans[0], nil)
+			Expect(err).To(MatchError(ContainSubstring("snapshot_name: Invalid type. Expected: string, given: integer")))
+		})
+
+		It("fails when a required parameter is missing", func() {
+			_, err := service.ActionVariables(instance, service.Actions[0], nil, &service.Plans[0], nil)
+			Expect(err).To(MatchError(ContainSubstring("snapshot_name is required")))
+		})
+	})
+})
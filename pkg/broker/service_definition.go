@@ -60,6 +60,7 @@ type ServiceDefinition struct {
 	PlanVariables              []BrokerVariable
 	Examples                   []ServiceExample
 	DefaultRoleWhitelist       []string
+	Actions                    []InstanceAction
 
 	// ProviderBuilder creates a new provider given the project, auth, and logger.
 	ProviderBuilder func(plogger lager.Logger, store ServiceProviderStorage) ServiceProvider
@@ -113,6 +114,14 @@ func (svc *ServiceDefinition) Validate() (errs *validation.FieldError) {
 		errs = errs.Also(v.Validate().ViaFieldIndex("PlanVariables", i))
 	}
 
+	actionNames := make(map[string]struct{})
+	for i, v := range svc.Actions {
+		errs = errs.Also(
+			v.Validate().ViaFieldIndex("Actions", i),
+			validation.ErrIfDuplicate(v.Name, "Name", actionNames).ViaFieldIndex("Actions", i),
+		)
+	}
+
 	names := make(map[string]struct{})
 	ids := make(map[string]struct{})
 	for i, v := range svc.Plans {
@@ -216,10 +225,16 @@ func (svc *ServiceDefinition) CatalogEntry() *Service {
 			Tags:          svc.Tags,
 			Bindable:      svc.Bindable,
 			PlanUpdatable: svc.PlanUpdateable,
+			// GET /v2/service_instances/:instance_id is implemented by the broker for all services
+			InstancesRetrievable: true,
 		},
 		Plans: svc.Plans,
 	}
 
+	if len(svc.Actions) > 0 {
+		sd.Metadata.AdditionalMetadata = map[string]interface{}{"extensions": svc.ExtensionsMetadata()}
+	}
+
 	if enableCatalogSchemas.IsActive() {
 		for i := range sd.Plans {
 			sd.Plans[i].Schemas = svc.createSchemas()
@@ -229,14 +244,16 @@ func (svc *ServiceDefinition) CatalogEntry() *Service {
 	return sd
 }
 
-// createSchemas creates JSONSchemas compatible with the OSB spec for provision and bind.
-// It leaves the instance update schema empty to indicate updates are not supported.
+// createSchemas creates JSONSchemas compatible with the OSB spec for provision, update and bind.
 func (svc *ServiceDefinition) createSchemas() *domain.ServiceSchemas {
 	return &domain.ServiceSchemas{
 		Instance: domain.ServiceInstanceSchema{
 			Create: domain.Schema{
 				Parameters: CreateJSONSchema(svc.ProvisionInputVariables),
 			},
+			Update: domain.Schema{
+				Parameters: CreateUpdateJSONSchema(svc.ProvisionInputVariables),
+			},
 		},
 		Binding: domain.ServiceBindingSchema{
 			Create: domain.Schema{
@@ -453,7 +470,8 @@ func (svc *ServiceDefinition) UpdateVariables(instanceID string, details parampa
 // 4. Operator default variables loaded from the environment.
 // 5. Default variables (in `bind_input_variables`).
 //
-func (svc *ServiceDefinition) BindVariables(instance storage.ServiceInstanceDetails, bindingID string, details paramparser.BindDetails, plan *ServicePlan, originatingIdentity map[string]interface{}) (*varcontext.VarContext, error) {
+// The instanceState is the view of the Terraform state of the instance that is given as `instance.state`.
+func (svc *ServiceDefinition) BindVariables(instance storage.ServiceInstanceDetails, instanceState storage.JSONObject, bindingID string, details paramparser.BindDetails, plan *ServicePlan, originatingIdentity map[string]interface{}) (*varcontext.VarContext, error) {
 	// The namespaces of these values roughly align with the OSB spec.
 	constants := map[string]interface{}{
 		"request.x_broker_api_originating_identity": originatingIdentity,
@@ -475,6 +493,7 @@ func (svc *ServiceDefinition) BindVariables(instance storage.ServiceInstanceDeta
 		// specified by the existing instance
 		"instance.name":    instance.Name,
 		"instance.details": instance.Outputs,
+		"instance.state":   instanceState,
 	}
 
 	builder := varcontext.Builder().
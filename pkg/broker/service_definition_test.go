@@ -1,6 +1,7 @@
 package broker_test
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"sort"
@@ -257,6 +258,18 @@ var _ = Describe("ServiceDefinition", func() {
 		)
 	})
 
+	Describe("CatalogEntry", func() {
+		It("lets platforms fetch service instances", func() {
+			serviceDefinition := broker.ServiceDefinition{ID: "fake-service-id", Name: "fake-service"}
+
+			entry := serviceDefinition.CatalogEntry()
+
+			Expect(entry.InstancesRetrievable).To(BeTrue())
+			Expect(entry.BindingsRetrievable).To(BeFalse())
+			Expect(json.Marshal(entry.ToPlain())).To(ContainSubstring(`"instances_retrievable":true`))
+		})
+	})
+
 	Describe("UserDefinedPlans", func() {
 
 		const (
@@ -16,6 +16,7 @@ package broker
 
 import (
 	"context"
+	"errors"
 
 	"github.com/cloudfoundry/cloud-service-broker/dbservice/models"
 	"github.com/cloudfoundry/cloud-service-broker/internal/storage"
@@ -23,6 +24,10 @@ import (
 	"github.com/pivotal-cf/brokerapi/v8/domain"
 )
 
+// ErrUpgradeRequired is returned by CheckUpgradeAvailable when the deployment was made with an older
+// Terraform version than the current one, so must be upgraded before other operations are allowed.
+var ErrUpgradeRequired = errors.New("operation attempted with newer version of Terraform than current state, upgrade the service before retrying operation")
+
 //go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 -generate
 //counterfeiter:generate . ServiceProvider
 
@@ -61,7 +66,23 @@ type ServiceProvider interface {
 
 	GetTerraformOutputs(ctx context.Context, instanceGUID string) (storage.JSONObject, error)
 
+	// GetInstanceState returns a read-only view of the Terraform state of the instance, with the
+	// attributes of the resources and the outputs
+	GetInstanceState(ctx context.Context, instanceGUID string) (storage.JSONObject, error)
+
+	// CheckUpgradeAvailable returns ErrUpgradeRequired when the deployment must be upgraded before other
+	// operations, and any other error when the deployment could not be checked.
 	CheckUpgradeAvailable(deploymentGUID string) error
+
+	// RunAction starts a custom action on the service instance. It returns the ID of the operation.
+	RunAction(ctx context.Context, instanceGUID, actionName string, vc *varcontext.VarContext) (operationID string, err error)
+
+	// PollAction returns the status of the last run of a custom action on the service instance.
+	PollAction(ctx context.Context, instanceGUID, actionName string) (bool, string, error)
+
+	// Adopt brings existing resources under the management of the broker as the resources of a new
+	// service instance. The planned changes are passed to confirm, and are only made if it returns true.
+	Adopt(ctx context.Context, provisionContext *varcontext.VarContext, resources map[string]string, confirm func(plan string) bool) (bool, error)
 }
 
 //counterfeiter:generate . ServiceProviderStorage
@@ -69,4 +90,13 @@ type ServiceProviderStorage interface {
 	StoreTerraformDeployment(t storage.TerraformDeployment) error
 	GetTerraformDeployment(id string) (storage.TerraformDeployment, error)
 	ExistsTerraformDeployment(id string) (bool, error)
+	DeleteTerraformDeployment(id string) error
+	GetServiceInstanceDetails(guid string) (storage.ServiceInstanceDetails, error)
+	StoreWebhookDelivery(d storage.WebhookDelivery) error
+	GetTerraformState(id string) ([]byte, error)
+	StoreTerraformState(id string, state []byte) error
+	UnlockTerraformState(id, lockID string) error
+	DeleteTerraformState(id string) error
+	StoreOperation(o storage.Operation) error
+	FinishOperation(o storage.Operation) error
 }
@@ -230,3 +230,23 @@ func CreateJSONSchema(schemaVariables []BrokerVariable) map[string]interface{} {
 
 	return schema
 }
+
+// CreateUpdateJSONSchema outputs a JSONSchema for an update request given a list of
+// BrokerVariables. Variables that are prohibited from being updated are left out, and no
+// variables are required because an update is merged with the previous parameters.
+func CreateUpdateJSONSchema(schemaVariables []BrokerVariable) map[string]interface{} {
+	properties := make(map[string]interface{})
+
+	for _, variable := range schemaVariables {
+		if variable.ProhibitUpdate {
+			continue
+		}
+		properties[variable.FieldName] = variable.ToSchema()
+	}
+
+	return map[string]interface{}{
+		"$schema":    "http://json-schema.org/draft-04/schema#",
+		"type":       "object",
+		"properties": properties,
+	}
+}
@@ -36,6 +36,10 @@ const (
 	brokerpakSourcesKey     = "brokerpak.sources"
 	brokerpakConfigKey      = "brokerpak.config"
 	brokerpakBuiltinPathKey = "brokerpak.builtin.path"
+
+	// terraformPluginCacheDirKey is a directory shared by all brokerpaks that Terraform
+	// installs providers from, rather than copying them into every workspace
+	terraformPluginCacheDirKey = "brokerpak.terraform.plugin_cache_dir"
 )
 
 var loadBuiltinToggle = toggles.Features.Toggle("enable-builtin-brokerpaks", true, `Load brokerpaks that are built-in to the software.`)
@@ -44,6 +48,7 @@ func init() {
 	viper.SetDefault(brokerpakSourcesKey, "{}")
 	viper.SetDefault(brokerpakConfigKey, "{}")
 	viper.SetDefault(brokerpakBuiltinPathKey, BuiltinPakLocation)
+	viper.BindEnv(terraformPluginCacheDirKey, "TF_PLUGIN_CACHE_DIR")
 }
 
 // BrokerpakSourceConfig represents a single configuration of a brokerpak.
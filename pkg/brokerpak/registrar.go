@@ -157,12 +157,27 @@ func (r *Registrar) extractTfBinaries(brokerPak *reader.BrokerPakReader, vc *var
 		return executor.TFBinariesContext{}, err
 	}
 
+	pluginCacheDir := viper.GetString(terraformPluginCacheDirKey)
+	if pluginCacheDir != "" {
+		if err := brokerPak.ExtractPluginCache(pluginCacheDir); err != nil {
+			return executor.TFBinariesContext{}, err
+		}
+	}
+
+	var binaries []executor.TerraformBinary
+	for _, v := range manifest.TerraformVersions {
+		binaries = append(binaries, executor.TerraformBinary{Name: v.Binary(), Version: v.Version})
+	}
+
 	return executor.TFBinariesContext{
-		Dir:                  dir,
-		DefaultTfVersion:     tfVersion,
-		Params:               resolveParameters(manifest.Parameters, vc),
-		TfUpgradePath:        manifest.TerraformUpgradePath,
-		ProviderReplacements: manifest.TerraformStateProviderReplacements,
+		Dir:                          dir,
+		PluginCacheDir:               pluginCacheDir,
+		Binaries:                     binaries,
+		DefaultTfVersion:             tfVersion,
+		Params:                       resolveParameters(manifest.Parameters, vc),
+		TfUpgradePath:                manifest.TerraformUpgradePath,
+		ProviderReplacements:         manifest.TerraformStateProviderReplacements,
+		OpenTofuProviderReplacements: manifest.OpenTofuProviderReplacements(),
 	}, nil
 }
 
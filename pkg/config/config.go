@@ -26,6 +26,22 @@ const (
 	credhubSkipSSLValidation    = "credhub.skip_ssl_validation"
 	credhubCaCertFile           = "credhub.ca_cert_file"
 	credhubStoreBindCredentials = "credhub.store_bind_credentials"
+
+	vaultEnabled           = "vault.enabled"
+	vaultAddress           = "vault.address"
+	vaultNamespace         = "vault.namespace"
+	vaultMount             = "vault.mount"
+	vaultToken             = "vault.token"
+	vaultRoleID            = "vault.role_id"
+	vaultSecretID          = "vault.secret_id"
+	vaultAppRoleMount      = "vault.approle_mount"
+	vaultSkipSSLValidation = "vault.skip_ssl_validation"
+	vaultCaCertFile        = "vault.ca_cert_file"
+
+	kubernetesEnabled    = "kubernetes.enabled"
+	kubernetesKubeconfig = "kubernetes.kubeconfig"
+	kubernetesContext    = "kubernetes.context"
+	kubernetesNamespace  = "kubernetes.namespace"
 )
 
 type CredStoreConfig struct {
@@ -38,8 +54,37 @@ type CredStoreConfig struct {
 	StoreBindCredentials bool   `mapstructure:"store_bind_credentials"`
 }
 
+// VaultConfig configures a HashiCorp Vault KV version 2 secrets engine as the credential store.
+// Vault is authenticated with either a token, or an AppRole role ID and secret ID. It must be
+// enabled explicitly, as the address is read from VAULT_ADDR, which is often set for the Vault CLI.
+type VaultConfig struct {
+	Enabled           bool   `mapstructure:"enabled"`
+	Address           string `mapstructure:"address"`
+	Namespace         string `mapstructure:"namespace"`
+	Mount             string `mapstructure:"mount"`
+	Token             string `mapstructure:"token"`
+	RoleID            string `mapstructure:"role_id"`
+	SecretID          string `mapstructure:"secret_id"`
+	AppRoleMount      string `mapstructure:"approle_mount"`
+	SkipSSLValidation bool   `mapstructure:"skip_ssl_validation"`
+	CaCertFile        string `mapstructure:"ca_cert_file"`
+}
+
+// KubernetesConfig configures Kubernetes Secrets as the credential store. The broker uses the
+// kubeconfig file when it is set, and otherwise the service account of the pod it runs in.
+// Credentials are written to the namespace of the bind request, or to Namespace when the request
+// does not have one.
+type KubernetesConfig struct {
+	Enabled    bool   `mapstructure:"enabled"`
+	Kubeconfig string `mapstructure:"kubeconfig"`
+	Context    string `mapstructure:"context"`
+	Namespace  string `mapstructure:"namespace"`
+}
+
 type Config struct {
-	CredStoreConfig CredStoreConfig `mapstructure:"credhub"`
+	CredStoreConfig  CredStoreConfig  `mapstructure:"credhub"`
+	VaultConfig      VaultConfig      `mapstructure:"vault"`
+	KubernetesConfig KubernetesConfig `mapstructure:"kubernetes"`
 }
 
 func Parse() (*Config, error) {
@@ -51,6 +96,20 @@ func Parse() (*Config, error) {
 	viper.BindEnv(credhubSkipSSLValidation, "CH_SKIP_SSL_VALIDATION")
 	viper.BindEnv(credhubCaCertFile, "CH_CA_CERT_FILE")
 	viper.BindEnv(credhubStoreBindCredentials, "CH_STORE_BIND_CREDENTIALS")
+	viper.BindEnv(vaultEnabled, "VAULT_CREDSTORE_ENABLED")
+	viper.BindEnv(vaultAddress, "VAULT_ADDR")
+	viper.BindEnv(vaultNamespace, "VAULT_NAMESPACE")
+	viper.BindEnv(vaultMount, "VAULT_KV_MOUNT")
+	viper.BindEnv(vaultToken, "VAULT_TOKEN")
+	viper.BindEnv(vaultRoleID, "VAULT_ROLE_ID")
+	viper.BindEnv(vaultSecretID, "VAULT_SECRET_ID")
+	viper.BindEnv(vaultAppRoleMount, "VAULT_APPROLE_MOUNT")
+	viper.BindEnv(vaultSkipSSLValidation, "VAULT_SKIP_SSL_VALIDATION")
+	viper.BindEnv(vaultCaCertFile, "VAULT_CA_CERT_FILE")
+	viper.BindEnv(kubernetesEnabled, "KUBERNETES_CREDSTORE_ENABLED")
+	viper.BindEnv(kubernetesKubeconfig, "KUBERNETES_CREDSTORE_KUBECONFIG")
+	viper.BindEnv(kubernetesContext, "KUBERNETES_CREDSTORE_CONTEXT")
+	viper.BindEnv(kubernetesNamespace, "KUBERNETES_CREDSTORE_NAMESPACE")
 
 	err := viper.Unmarshal(&c)
 	if err != nil {
@@ -63,3 +122,11 @@ func Parse() (*Config, error) {
 func (c *CredStoreConfig) HasCredHubConfig() bool {
 	return c.CredHubURL != ""
 }
+
+func (c *VaultConfig) HasVaultConfig() bool {
+	return c.Enabled
+}
+
+func (c *KubernetesConfig) HasKubernetesConfig() bool {
+	return c.Enabled
+}
@@ -35,6 +35,8 @@ var _ = Describe("Config", func() {
 			Expect(c).ToNot(BeNil())
 
 			Expect(c.CredStoreConfig.HasCredHubConfig()).To(BeFalse())
+			Expect(c.VaultConfig.HasVaultConfig()).To(BeFalse())
+			Expect(c.KubernetesConfig.HasKubernetesConfig()).To(BeFalse())
 		})
 
 		Context("credstore config", func() {
@@ -55,5 +57,81 @@ var _ = Describe("Config", func() {
 				Expect(c.CredStoreConfig.UaaURL).To(Equal("https://uaa.example.com"))
 			})
 		})
+
+		Context("vault config", func() {
+			It("parses vault config", func() {
+				os.Setenv("VAULT_CREDSTORE_ENABLED", "true")
+				os.Setenv("VAULT_ADDR", "https://vault.example.com:8200")
+				os.Setenv("VAULT_NAMESPACE", "team")
+				os.Setenv("VAULT_KV_MOUNT", "csb")
+				os.Setenv("VAULT_ROLE_ID", "my-role")
+				os.Setenv("VAULT_SECRET_ID", "my-secret")
+				os.Setenv("VAULT_APPROLE_MOUNT", "csb-approle")
+				os.Setenv("VAULT_SKIP_SSL_VALIDATION", "true")
+
+				c, err := Parse()
+				Expect(err).To(BeNil())
+				Expect(c).ToNot(BeNil())
+
+				Expect(c.VaultConfig.HasVaultConfig()).To(BeTrue())
+				Expect(c.CredStoreConfig.HasCredHubConfig()).To(BeFalse())
+				Expect(c.VaultConfig).To(Equal(VaultConfig{
+					Enabled:           true,
+					Address:           "https://vault.example.com:8200",
+					Namespace:         "team",
+					Mount:             "csb",
+					RoleID:            "my-role",
+					SecretID:          "my-secret",
+					AppRoleMount:      "csb-approle",
+					SkipSSLValidation: true,
+				}))
+			})
+
+			It("does not select Vault when only the Vault CLI variables are set", func() {
+				os.Setenv("CH_CRED_HUB_URL", "https://credhub.example.com")
+				os.Setenv("VAULT_ADDR", "https://vault.example.com:8200")
+				os.Setenv("VAULT_TOKEN", "operator-token")
+
+				c, err := Parse()
+				Expect(err).To(BeNil())
+				Expect(c).ToNot(BeNil())
+
+				Expect(c.VaultConfig.HasVaultConfig()).To(BeFalse())
+				Expect(c.CredStoreConfig.HasCredHubConfig()).To(BeTrue())
+			})
+		})
+
+		Context("kubernetes config", func() {
+			It("parses kubernetes config", func() {
+				os.Setenv("KUBERNETES_CREDSTORE_ENABLED", "true")
+				os.Setenv("KUBERNETES_CREDSTORE_KUBECONFIG", "/home/broker/.kube/config")
+				os.Setenv("KUBERNETES_CREDSTORE_CONTEXT", "broker")
+				os.Setenv("KUBERNETES_CREDSTORE_NAMESPACE", "csb")
+
+				c, err := Parse()
+				Expect(err).To(BeNil())
+				Expect(c).ToNot(BeNil())
+
+				Expect(c.KubernetesConfig.HasKubernetesConfig()).To(BeTrue())
+				Expect(c.VaultConfig.HasVaultConfig()).To(BeFalse())
+				Expect(c.KubernetesConfig).To(Equal(KubernetesConfig{
+					Enabled:    true,
+					Kubeconfig: "/home/broker/.kube/config",
+					Context:    "broker",
+					Namespace:  "csb",
+				}))
+			})
+
+			It("does not read the kubeconfig of the operator's shell", func() {
+				os.Setenv("KUBERNETES_CREDSTORE_ENABLED", "true")
+				os.Setenv("KUBECONFIG", "/home/operator/.kube/config")
+
+				c, err := Parse()
+				Expect(err).To(BeNil())
+				Expect(c).ToNot(BeNil())
+
+				Expect(c.KubernetesConfig).To(Equal(KubernetesConfig{Enabled: true}))
+			})
+		})
 	})
 })
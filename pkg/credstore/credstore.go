@@ -38,6 +38,66 @@ type CredStore interface {
 	DeletePermission(path string) error
 }
 
+// Referencer is implemented by credential stores that refer apps to stored credentials in their
+// own way, rather than with a "credhub-ref"
+type Referencer interface {
+	Reference(key string) map[string]interface{}
+}
+
+// ContextPutter is implemented by credential stores that store credentials in a location given by
+// the platform context of the bind request, such as the namespace on a Kubernetes platform
+type ContextPutter interface {
+	PutWithContext(key string, credentials interface{}, requestContext map[string]interface{}) (interface{}, error)
+}
+
+// ContextGetter is implemented by credential stores that read credentials from the location given by
+// the platform context of the bind request
+type ContextGetter interface {
+	GetWithContext(key string, requestContext map[string]interface{}) (interface{}, error)
+}
+
+// ContextDeleter is implemented by credential stores that delete credentials from the location given
+// by the platform context of the bind request
+type ContextDeleter interface {
+	DeleteWithContext(key string, requestContext map[string]interface{}) error
+}
+
+// Reference returns the binding credentials that refer apps to the credentials stored with the key.
+// It is a "credhub-ref" unless the credential store is a Referencer.
+func Reference(cs CredStore, key string) map[string]interface{} {
+	if r, ok := cs.(Referencer); ok {
+		return r.Reference(key)
+	}
+	return map[string]interface{}{"credhub-ref": key}
+}
+
+// PutWithContext stores credentials like Put, or with the platform context of the bind request
+// when the credential store is a ContextPutter.
+func PutWithContext(cs CredStore, key string, credentials interface{}, requestContext map[string]interface{}) (interface{}, error) {
+	if c, ok := cs.(ContextPutter); ok {
+		return c.PutWithContext(key, credentials, requestContext)
+	}
+	return cs.Put(key, credentials)
+}
+
+// GetWithContext reads credentials like Get, or with the platform context of the bind request
+// when the credential store is a ContextGetter.
+func GetWithContext(cs CredStore, key string, requestContext map[string]interface{}) (interface{}, error) {
+	if c, ok := cs.(ContextGetter); ok {
+		return c.GetWithContext(key, requestContext)
+	}
+	return cs.Get(key)
+}
+
+// DeleteWithContext deletes credentials like Delete, or with the platform context of the bind request
+// when the credential store is a ContextDeleter.
+func DeleteWithContext(cs CredStore, key string, requestContext map[string]interface{}) error {
+	if c, ok := cs.(ContextDeleter); ok {
+		return c.DeleteWithContext(key, requestContext)
+	}
+	return cs.Delete(key)
+}
+
 type credhubStore struct {
 	credHubClient *credhub.CredHub
 	logger        lager.Logger
@@ -0,0 +1,283 @@
+package credstore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"code.cloudfoundry.org/credhub-cli/credhub/permissions"
+	"code.cloudfoundry.org/lager"
+	"github.com/cloudfoundry/cloud-service-broker/pkg/config"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	kubernetesManagedByLabel  = "app.kubernetes.io/managed-by"
+	kubernetesManagedBy       = "cloud-service-broker"
+	kubernetesCredentialLabel = "cloud-service-broker.cloudfoundry.org/credential"
+	kubernetesCredentialName  = "cloud-service-broker.cloudfoundry.org/credential-name"
+	kubernetesMaxNameLength   = 253
+
+	serviceAccountNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+)
+
+var invalidSecretNameCharacters = regexp.MustCompile(`[^a-z0-9.-]+`)
+
+// kubernetesStore stores credentials in Kubernetes Secrets in the namespace of the bind request.
+// The broker records the context of the bind request, so that the Secret is read and deleted in the
+// same namespace; the store never looks for Secrets in other namespaces. Access to Secrets is
+// controlled with RBAC in the namespace, so the permissions of the credential store are not used.
+type kubernetesStore struct {
+	clientset kubernetes.Interface
+	namespace string
+	logger    lager.Logger
+}
+
+var (
+	_ Referencer     = (*kubernetesStore)(nil)
+	_ ContextPutter  = (*kubernetesStore)(nil)
+	_ ContextGetter  = (*kubernetesStore)(nil)
+	_ ContextDeleter = (*kubernetesStore)(nil)
+)
+
+// NewKubernetesStore connects with the kubeconfig file when it is set, and otherwise with the
+// service account of the pod that the broker runs in
+func NewKubernetesStore(kubernetesConfig *config.KubernetesConfig, logger lager.Logger) (CredStore, error) {
+	if !kubernetesConfig.HasKubernetesConfig() {
+		return nil, fmt.Errorf("KubernetesConfig not found")
+	}
+
+	var (
+		restConfig *rest.Config
+		namespace  string
+		err        error
+	)
+	switch kubernetesConfig.Kubeconfig {
+	case "":
+		restConfig, namespace, err = inClusterConfig()
+	default:
+		restConfig, namespace, err = kubeconfigConfig(kubernetesConfig.Kubeconfig, kubernetesConfig.Context)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("error creating Kubernetes client: %w", err)
+	}
+
+	return NewKubernetesStoreWithClientset(clientset, withDefault(kubernetesConfig.Namespace, namespace), logger), nil
+}
+
+// NewKubernetesStoreWithClientset stores Secrets with the clientset, in the namespace of the
+// request or else in the given namespace
+func NewKubernetesStoreWithClientset(clientset kubernetes.Interface, namespace string, logger lager.Logger) CredStore {
+	return &kubernetesStore{
+		clientset: clientset,
+		namespace: withDefault(namespace, metav1.NamespaceDefault),
+		logger:    logger,
+	}
+}
+
+func inClusterConfig() (*rest.Config, string, error) {
+	restConfig, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, "", fmt.Errorf("error reading in-cluster Kubernetes config, set a kubeconfig file when not running in a cluster: %w", err)
+	}
+
+	namespace, err := os.ReadFile(serviceAccountNamespaceFile)
+	if err != nil {
+		return nil, "", fmt.Errorf("error reading service account namespace: %w", err)
+	}
+
+	return restConfig, strings.TrimSpace(string(namespace)), nil
+}
+
+func kubeconfigConfig(path, contextName string) (*rest.Config, string, error) {
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: path},
+		&clientcmd.ConfigOverrides{CurrentContext: contextName},
+	)
+
+	restConfig, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, "", fmt.Errorf("error reading kubeconfig %q: %w", path, err)
+	}
+
+	namespace, _, err := clientConfig.Namespace()
+	if err != nil {
+		return nil, "", fmt.Errorf("error reading namespace from kubeconfig %q: %w", path, err)
+	}
+
+	return restConfig, namespace, nil
+}
+
+// Reference tells apps the name of the Secret in their namespace that holds their credentials
+func (k *kubernetesStore) Reference(key string) map[string]interface{} {
+	return map[string]interface{}{"kubernetes-secret-ref": secretName(key)}
+}
+
+func (k *kubernetesStore) Put(key string, credentials interface{}) (interface{}, error) {
+	return k.PutWithContext(key, credentials, nil)
+}
+
+// PutWithContext creates or updates the Secret in the namespace of the request context, which is
+// set by Kubernetes platforms. Each credential is a key in the Secret, and values that are not
+// strings are JSON encoded.
+func (k *kubernetesStore) PutWithContext(key string, credentials interface{}, requestContext map[string]interface{}) (interface{}, error) {
+	c, ok := credentials.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("credentials for Kubernetes Secret %q must be an object", secretName(key))
+	}
+
+	data := make(map[string]string, len(c))
+	for name, value := range c {
+		switch v := value.(type) {
+		case string:
+			data[name] = v
+		default:
+			encoded, err := json.Marshal(v)
+			if err != nil {
+				return nil, fmt.Errorf("error encoding credential %q: %w", name, err)
+			}
+			data[name] = string(encoded)
+		}
+	}
+
+	return k.write(key, k.namespaceFor(requestContext), data)
+}
+
+func (k *kubernetesStore) PutValue(key string, credentials interface{}) (interface{}, error) {
+	value, ok := credentials.(string)
+	if !ok {
+		return nil, fmt.Errorf("value for Kubernetes Secret %q must be a string", secretName(key))
+	}
+	return k.write(key, k.namespace, map[string]string{"value": value})
+}
+
+func (k *kubernetesStore) Get(key string) (interface{}, error) {
+	return k.GetWithContext(key, nil)
+}
+
+// GetWithContext reads the Secret from the namespace of the request context
+func (k *kubernetesStore) GetWithContext(key string, requestContext map[string]interface{}) (interface{}, error) {
+	secret, err := k.clientset.CoreV1().Secrets(k.namespaceFor(requestContext)).Get(context.TODO(), secretName(key), metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		return nil, fmt.Errorf("Kubernetes Secret %q not found", secretName(key))
+	case err != nil:
+		return nil, fmt.Errorf("error reading Kubernetes Secret %q: %w", secretName(key), err)
+	}
+
+	result := make(map[string]interface{}, len(secret.Data))
+	for name, value := range secret.Data {
+		result[name] = string(value)
+	}
+
+	return result, nil
+}
+
+func (k *kubernetesStore) GetValue(key string) (string, error) {
+	data, err := k.Get(key)
+	if err != nil {
+		return "", err
+	}
+
+	value, ok := data.(map[string]interface{})["value"].(string)
+	if !ok {
+		return "", fmt.Errorf("Kubernetes Secret %q does not have a value", secretName(key))
+	}
+	return value, nil
+}
+
+func (k *kubernetesStore) Delete(key string) error {
+	return k.DeleteWithContext(key, nil)
+}
+
+// DeleteWithContext deletes the Secret from the namespace of the request context
+func (k *kubernetesStore) DeleteWithContext(key string, requestContext map[string]interface{}) error {
+	err := k.clientset.CoreV1().Secrets(k.namespaceFor(requestContext)).Delete(context.TODO(), secretName(key), metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("error deleting Kubernetes Secret %q: %w", secretName(key), err)
+	}
+	return nil
+}
+
+func (k *kubernetesStore) AddPermission(path string, actor string, ops []string) (*permissions.Permission, error) {
+	k.logger.Info("kubernetes-permissions-not-managed", lager.Data{
+		"path":    path,
+		"actor":   actor,
+		"warning": "access to Kubernetes Secrets is controlled by RBAC in the namespace, so no permission was added",
+	})
+	return nil, nil
+}
+
+func (k *kubernetesStore) DeletePermission(path string) error {
+	return nil
+}
+
+// write creates the Secret, or replaces it when it already exists
+func (k *kubernetesStore) write(key, namespace string, data map[string]string) (interface{}, error) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secretName(key),
+			Namespace: namespace,
+			Labels: map[string]string{
+				kubernetesManagedByLabel:  kubernetesManagedBy,
+				kubernetesCredentialLabel: credentialHash(key),
+			},
+			Annotations: map[string]string{kubernetesCredentialName: key},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: make(map[string][]byte, len(data)),
+	}
+	for name, value := range data {
+		secret.Data[name] = []byte(value)
+	}
+
+	secrets := k.clientset.CoreV1().Secrets(namespace)
+	result, err := secrets.Create(context.TODO(), secret, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		result, err = secrets.Update(context.TODO(), secret, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error writing Kubernetes Secret %q: %w", secret.Name, err)
+	}
+
+	return result.ObjectMeta, nil
+}
+
+// namespaceFor returns the namespace of the request context, which is set by Kubernetes platforms,
+// or the namespace of the store
+func (k *kubernetesStore) namespaceFor(requestContext map[string]interface{}) string {
+	if namespace, ok := requestContext["namespace"].(string); ok && namespace != "" {
+		return namespace
+	}
+	return k.namespace
+}
+
+// secretName turns the key into a valid Secret name, for example "/c/csb/service/binding/secrets-and-services"
+// becomes "c-csb-service-binding-secrets-and-services"
+func secretName(key string) string {
+	name := strings.Trim(invalidSecretNameCharacters.ReplaceAllString(strings.ToLower(key), "-"), "-.")
+	if len(name) > kubernetesMaxNameLength {
+		hash := credentialHash(key)[:10]
+		name = strings.Trim(name[:kubernetesMaxNameLength-len(hash)-1], "-.") + "-" + hash
+	}
+	return name
+}
+
+// credentialHash identifies the Secret for a key in a label, as keys are not valid label values
+func credentialHash(key string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(key)))[:32]
+}
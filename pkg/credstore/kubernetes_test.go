@@ -0,0 +1,208 @@
+package credstore_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/cloudfoundry/cloud-service-broker/pkg/config"
+	"github.com/cloudfoundry/cloud-service-broker/pkg/credstore"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var _ = Describe("Kubernetes Store", func() {
+	const (
+		key        = "/c/csb/my-service/my-binding/secrets-and-services"
+		secretName = "c-csb-my-service-my-binding-secrets-and-services"
+	)
+
+	var (
+		logger    lager.Logger
+		clientset *fake.Clientset
+		store     credstore.CredStore
+	)
+
+	getSecret := func(namespace string) (*corev1.Secret, error) {
+		return clientset.CoreV1().Secrets(namespace).Get(context.TODO(), secretName, metav1.GetOptions{})
+	}
+
+	verbs := func() []string {
+		var result []string
+		for _, action := range clientset.Actions() {
+			result = append(result, action.GetVerb())
+		}
+		return result
+	}
+
+	BeforeEach(func() {
+		logger = lager.NewLogger("test")
+		clientset = fake.NewSimpleClientset()
+		store = credstore.NewKubernetesStoreWithClientset(clientset, "broker-namespace", logger)
+	})
+
+	It("writes credentials to a Secret in the namespace of the bind request", func() {
+		_, err := credstore.PutWithContext(store, key, map[string]interface{}{"username": "admin", "port": 5432}, map[string]interface{}{"platform": "kubernetes", "namespace": "app-namespace"})
+		Expect(err).NotTo(HaveOccurred())
+
+		secret, err := getSecret("app-namespace")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(secret.Data).To(Equal(map[string][]byte{
+			"username": []byte("admin"),
+			"port":     []byte("5432"),
+		}))
+		Expect(secret.Type).To(Equal(corev1.SecretTypeOpaque))
+		Expect(secret.Labels).To(HaveKeyWithValue("app.kubernetes.io/managed-by", "cloud-service-broker"))
+		Expect(secret.Annotations).To(HaveKeyWithValue("cloud-service-broker.cloudfoundry.org/credential-name", key))
+
+		Expect(credstore.GetWithContext(store, key, map[string]interface{}{"namespace": "app-namespace"})).To(Equal(map[string]interface{}{"username": "admin", "port": "5432"}))
+	})
+
+	It("uses the namespace of the store when the request does not have one", func() {
+		_, err := store.Put(key, map[string]interface{}{"username": "admin"})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = getSecret("broker-namespace")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("uses the default namespace when the store does not have one", func() {
+		store = credstore.NewKubernetesStoreWithClientset(clientset, "", logger)
+
+		_, err := store.Put(key, map[string]interface{}{"username": "admin"})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = getSecret("default")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("updates a Secret that already exists", func() {
+		_, err := store.Put(key, map[string]interface{}{"username": "admin"})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = store.Put(key, map[string]interface{}{"username": "other"})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(verbs()).To(Equal([]string{"create", "create", "update"}))
+		Expect(store.Get(key)).To(Equal(map[string]interface{}{"username": "other"}))
+	})
+
+	It("deletes the Secret from the namespace it was written to", func() {
+		_, err := credstore.PutWithContext(store, key, map[string]interface{}{"username": "admin"}, map[string]interface{}{"namespace": "app-namespace"})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(credstore.DeleteWithContext(store, key, map[string]interface{}{"namespace": "app-namespace"})).To(Succeed())
+		_, err = getSecret("app-namespace")
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+		By("not failing when the Secret has already been deleted")
+		Expect(credstore.DeleteWithContext(store, key, map[string]interface{}{"namespace": "app-namespace"})).To(Succeed())
+	})
+
+	It("does not read or delete Secrets in other namespaces", func() {
+		_, err := credstore.PutWithContext(store, key, map[string]interface{}{"username": "admin"}, map[string]interface{}{"namespace": "app-namespace"})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = store.Get(key)
+		Expect(err).To(MatchError(fmt.Sprintf("Kubernetes Secret %q not found", secretName)))
+		Expect(credstore.DeleteWithContext(store, key, map[string]interface{}{"namespace": "other-namespace"})).To(Succeed())
+		Expect(store.Delete(key)).To(Succeed())
+
+		_, err = getSecret("app-namespace")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("stores and reads values", func() {
+		_, err := store.PutValue("/c/csb/value", "some-value")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(store.GetValue("/c/csb/value")).To(Equal("some-value"))
+	})
+
+	It("fails to read a Secret that does not exist", func() {
+		_, err := store.Get(key)
+		Expect(err).To(MatchError(fmt.Sprintf("Kubernetes Secret %q not found", secretName)))
+	})
+
+	It("does not manage permissions", func() {
+		permission, err := store.AddPermission(key, "mtls-app:app-guid", []string{"read"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(permission).To(BeNil())
+		Expect(store.DeletePermission(key)).To(Succeed())
+		Expect(clientset.Actions()).To(BeEmpty())
+	})
+
+	It("refers to credentials by the name of the Secret", func() {
+		Expect(credstore.Reference(store, key)).To(Equal(map[string]interface{}{
+			"kubernetes-secret-ref": secretName,
+		}))
+	})
+
+	It("returns errors from the API server", func() {
+		clientset.PrependReactor("create", "secrets", func(k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, apierrors.NewUnauthorized("Unauthorized")
+		})
+
+		_, err := store.Put(key, map[string]interface{}{"username": "admin"})
+		Expect(err).To(MatchError(fmt.Sprintf("error writing Kubernetes Secret %q: Unauthorized", secretName)))
+	})
+
+	Describe("NewKubernetesStore", func() {
+		writeKubeconfig := func() string {
+			path := filepath.Join(GinkgoT().TempDir(), "kubeconfig")
+			Expect(os.WriteFile(path, []byte(`
+apiVersion: v1
+kind: Config
+current-context: broker
+clusters:
+- name: test
+  cluster:
+    server: https://127.0.0.1:6443
+contexts:
+- name: broker
+  context:
+    cluster: test
+    user: broker
+    namespace: broker-namespace
+users:
+- name: broker
+  user:
+    token: my-token
+`), 0600)).To(Succeed())
+			return path
+		}
+
+		It("connects with a kubeconfig file", func() {
+			_, err := credstore.NewKubernetesStore(&config.KubernetesConfig{Enabled: true, Kubeconfig: writeKubeconfig()}, logger)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("fails when the kubeconfig context does not exist", func() {
+			_, err := credstore.NewKubernetesStore(&config.KubernetesConfig{Enabled: true, Kubeconfig: writeKubeconfig(), Context: "other"}, logger)
+			Expect(err).To(MatchError(ContainSubstring(`context "other" does not exist`)))
+		})
+
+		It("fails when not configured", func() {
+			_, err := credstore.NewKubernetesStore(&config.KubernetesConfig{}, logger)
+			Expect(err).To(MatchError("KubernetesConfig not found"))
+		})
+
+		It("fails to connect from inside a cluster when not running in one", func() {
+			if host, ok := os.LookupEnv("KUBERNETES_SERVICE_HOST"); ok {
+				DeferCleanup(os.Setenv, "KUBERNETES_SERVICE_HOST", host)
+			}
+			os.Unsetenv("KUBERNETES_SERVICE_HOST")
+
+			_, err := credstore.NewKubernetesStore(&config.KubernetesConfig{Enabled: true}, logger)
+			Expect(err).To(MatchError(rest.ErrNotInCluster))
+		})
+	})
+})
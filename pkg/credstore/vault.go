@@ -0,0 +1,252 @@
+package credstore
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"code.cloudfoundry.org/credhub-cli/credhub/permissions"
+	"code.cloudfoundry.org/lager"
+	"github.com/cloudfoundry/cloud-service-broker/pkg/config"
+)
+
+const (
+	defaultVaultMount        = "secret"
+	defaultVaultAppRoleMount = "approle"
+)
+
+// vaultStore stores credentials in a HashiCorp Vault KV version 2 secrets engine. Vault
+// controls access with policies rather than per-credential permissions, so apps must be
+// given a policy that allows them to read their credentials.
+type vaultStore struct {
+	client       *http.Client
+	address      string
+	namespace    string
+	mount        string
+	roleID       string
+	secretID     string
+	appRoleMount string
+	logger       lager.Logger
+
+	tokenLock sync.Mutex
+	token     string
+}
+
+var _ Referencer = (*vaultStore)(nil)
+
+func NewVaultStore(vaultConfig *config.VaultConfig, logger lager.Logger) (CredStore, error) {
+	switch {
+	case !vaultConfig.HasVaultConfig():
+		return nil, fmt.Errorf("VaultConfig not found")
+	case vaultConfig.Address == "":
+		return nil, fmt.Errorf("Vault requires an address")
+	case vaultConfig.Token == "" && (vaultConfig.RoleID == "" || vaultConfig.SecretID == ""):
+		return nil, fmt.Errorf("Vault requires either a token, or an AppRole role ID and secret ID")
+	case vaultConfig.Token != "" && vaultConfig.RoleID != "":
+		return nil, fmt.Errorf("Vault requires either a token, or an AppRole role ID and secret ID, not both")
+	}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: vaultConfig.SkipSSLValidation}
+	if vaultConfig.CaCertFile != "" {
+		dat, err := os.ReadFile(vaultConfig.CaCertFile)
+		if err != nil {
+			return nil, err
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(dat) {
+			return nil, fmt.Errorf("Vault certificate is not valid: %s", vaultConfig.CaCertFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return &vaultStore{
+		client:       &http.Client{Transport: transport},
+		address:      strings.TrimSuffix(vaultConfig.Address, "/"),
+		namespace:    vaultConfig.Namespace,
+		mount:        withDefault(strings.Trim(vaultConfig.Mount, "/"), defaultVaultMount),
+		roleID:       vaultConfig.RoleID,
+		secretID:     vaultConfig.SecretID,
+		appRoleMount: withDefault(strings.Trim(vaultConfig.AppRoleMount, "/"), defaultVaultAppRoleMount),
+		token:        vaultConfig.Token,
+		logger:       logger,
+	}, nil
+}
+
+// Reference tells apps where to find their credentials in Vault
+func (v *vaultStore) Reference(key string) map[string]interface{} {
+	return map[string]interface{}{"vault-ref": key}
+}
+
+func (v *vaultStore) Put(key string, credentials interface{}) (interface{}, error) {
+	return v.write(key, credentials)
+}
+
+func (v *vaultStore) PutValue(key string, credentials interface{}) (interface{}, error) {
+	return v.write(key, map[string]interface{}{"value": credentials})
+}
+
+func (v *vaultStore) Get(key string) (interface{}, error) {
+	var receiver struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	if err := v.do(http.MethodGet, v.path("data", key), nil, &receiver); err != nil {
+		return nil, err
+	}
+
+	return receiver.Data.Data, nil
+}
+
+func (v *vaultStore) GetValue(key string) (string, error) {
+	data, err := v.Get(key)
+	if err != nil {
+		return "", err
+	}
+
+	value, ok := data.(map[string]interface{})["value"].(string)
+	if !ok {
+		return "", fmt.Errorf("Vault secret %q does not have a string value", key)
+	}
+	return value, nil
+}
+
+// Delete deletes every version of the secret
+func (v *vaultStore) Delete(key string) error {
+	return v.do(http.MethodDelete, v.path("metadata", key), nil, nil)
+}
+
+func (v *vaultStore) AddPermission(path string, actor string, ops []string) (*permissions.Permission, error) {
+	v.logger.Debug("vault-permissions-are-policies", lager.Data{"path": path, "actor": actor})
+	return nil, nil
+}
+
+func (v *vaultStore) DeletePermission(path string) error {
+	return nil
+}
+
+func (v *vaultStore) write(key string, data interface{}) (interface{}, error) {
+	var receiver struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := v.do(http.MethodPost, v.path("data", key), map[string]interface{}{"data": data}, &receiver); err != nil {
+		return nil, err
+	}
+
+	return receiver.Data, nil
+}
+
+func (v *vaultStore) path(kind, key string) string {
+	return fmt.Sprintf("/v1/%s/%s/%s", v.mount, kind, strings.TrimPrefix(key, "/"))
+}
+
+// do makes a request to Vault. When AppRole auth is used, it logs in before the first
+// request, and logs in again if the token has expired.
+func (v *vaultStore) do(method, path string, body, receiver interface{}) error {
+	token, err := v.currentToken(false)
+	if err != nil {
+		return err
+	}
+
+	status, err := v.request(method, path, token, body, receiver)
+	if status == http.StatusForbidden && v.roleID != "" {
+		if token, err = v.currentToken(true); err != nil {
+			return err
+		}
+		_, err = v.request(method, path, token, body, receiver)
+	}
+
+	return err
+}
+
+func (v *vaultStore) currentToken(renew bool) (string, error) {
+	v.tokenLock.Lock()
+	defer v.tokenLock.Unlock()
+
+	if v.token != "" && !renew {
+		return v.token, nil
+	}
+
+	var receiver struct {
+		Auth struct {
+			ClientToken string `json:"client_token"`
+		} `json:"auth"`
+	}
+	body := map[string]string{"role_id": v.roleID, "secret_id": v.secretID}
+	if _, err := v.request(http.MethodPost, fmt.Sprintf("/v1/auth/%s/login", v.appRoleMount), "", body, &receiver); err != nil {
+		return "", fmt.Errorf("error logging in to Vault with AppRole: %w", err)
+	}
+	if receiver.Auth.ClientToken == "" {
+		return "", errors.New("error logging in to Vault with AppRole: no token returned")
+	}
+
+	v.token = receiver.Auth.ClientToken
+	return v.token, nil
+}
+
+func (v *vaultStore) request(method, path, token string, body, receiver interface{}) (int, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return 0, fmt.Errorf("error encoding Vault request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, v.address+path, reader)
+	if err != nil {
+		return 0, fmt.Errorf("error creating Vault request: %w", err)
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if v.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.namespace)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("error calling Vault: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, fmt.Errorf("error reading Vault response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("Vault %s %s failed with status %d: %s", method, path, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	if receiver != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, receiver); err != nil {
+			return resp.StatusCode, fmt.Errorf("error decoding Vault response: %w", err)
+		}
+	}
+
+	return resp.StatusCode, nil
+}
+
+func withDefault(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
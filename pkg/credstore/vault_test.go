@@ -0,0 +1,171 @@
+package credstore_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/cloudfoundry/cloud-service-broker/pkg/config"
+	"github.com/cloudfoundry/cloud-service-broker/pkg/credstore"
+	"github.com/cloudfoundry/cloud-service-broker/pkg/credstore/credstorefakes"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// fakeVault is a minimal Vault server with a KV version 2 secrets engine and AppRole auth
+type fakeVault struct {
+	lock       sync.Mutex
+	secrets    map[string]map[string]interface{}
+	tokens     map[string]bool
+	namespaces []string
+	logins     int
+}
+
+func (f *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.namespaces = append(f.namespaces, r.Header.Get("X-Vault-Namespace"))
+
+	if r.URL.Path == "/v1/auth/approle/login" {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["role_id"] != "my-role" || body["secret_id"] != "my-secret" {
+			http.Error(w, `{"errors":["invalid role or secret ID"]}`, http.StatusBadRequest)
+			return
+		}
+		f.logins++
+		f.tokens["approle-token"] = true
+		w.Write([]byte(`{"auth":{"client_token":"approle-token"}}`))
+		return
+	}
+
+	if !f.tokens[r.Header.Get("X-Vault-Token")] {
+		http.Error(w, `{"errors":["permission denied"]}`, http.StatusForbidden)
+		return
+	}
+
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/v1/secret/data/"):
+		key := strings.TrimPrefix(r.URL.Path, "/v1/secret/data/")
+		switch r.Method {
+		case http.MethodPost:
+			data, _ := io.ReadAll(r.Body)
+			var body struct {
+				Data map[string]interface{} `json:"data"`
+			}
+			json.Unmarshal(data, &body)
+			f.secrets[key] = body.Data
+			w.Write([]byte(`{"data":{"version":1}}`))
+		case http.MethodGet:
+			secret, ok := f.secrets[key]
+			if !ok {
+				http.Error(w, `{"errors":[]}`, http.StatusNotFound)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"data": secret}})
+		}
+	case strings.HasPrefix(r.URL.Path, "/v1/secret/metadata/") && r.Method == http.MethodDelete:
+		delete(f.secrets, strings.TrimPrefix(r.URL.Path, "/v1/secret/metadata/"))
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+var _ = Describe("Vault Store", func() {
+	var (
+		logger lager.Logger
+		vault  *fakeVault
+		server *httptest.Server
+	)
+
+	BeforeEach(func() {
+		logger = lager.NewLogger("test")
+		vault = &fakeVault{
+			secrets: make(map[string]map[string]interface{}),
+			tokens:  map[string]bool{"my-token": true},
+		}
+		server = httptest.NewServer(vault)
+		DeferCleanup(server.Close)
+	})
+
+	It("stores, reads and deletes credentials with a token", func() {
+		store, err := credstore.NewVaultStore(&config.VaultConfig{Enabled: true, Address: server.URL, Token: "my-token", Namespace: "team"}, logger)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = store.Put("/c/csb/service/binding/secrets-and-services", map[string]interface{}{"username": "admin"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(vault.secrets).To(HaveKeyWithValue("c/csb/service/binding/secrets-and-services", map[string]interface{}{"username": "admin"}))
+
+		Expect(store.Get("/c/csb/service/binding/secrets-and-services")).To(Equal(map[string]interface{}{"username": "admin"}))
+
+		Expect(store.Delete("/c/csb/service/binding/secrets-and-services")).To(Succeed())
+		Expect(vault.secrets).To(BeEmpty())
+
+		Expect(vault.namespaces).To(HaveEach("team"))
+	})
+
+	It("stores and reads values", func() {
+		store, err := credstore.NewVaultStore(&config.VaultConfig{Enabled: true, Address: server.URL, Token: "my-token"}, logger)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = store.PutValue("/c/csb/value", "some-value")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(store.GetValue("/c/csb/value")).To(Equal("some-value"))
+	})
+
+	It("logs in with AppRole, and logs in again when the token expires", func() {
+		store, err := credstore.NewVaultStore(&config.VaultConfig{Enabled: true, Address: server.URL, RoleID: "my-role", SecretID: "my-secret"}, logger)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = store.Put("/c/csb/creds", map[string]interface{}{"foo": "bar"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(vault.logins).To(Equal(1))
+
+		delete(vault.tokens, "approle-token")
+		Expect(store.Get("/c/csb/creds")).To(Equal(map[string]interface{}{"foo": "bar"}))
+		Expect(vault.logins).To(Equal(2))
+	})
+
+	It("refers to credentials with a vault reference", func() {
+		store, err := credstore.NewVaultStore(&config.VaultConfig{Enabled: true, Address: server.URL, Token: "my-token"}, logger)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(credstore.Reference(store, "/c/csb/creds")).To(Equal(map[string]interface{}{"vault-ref": "/c/csb/creds"}))
+		Expect(credstore.Reference(&credstorefakes.FakeCredStore{}, "/c/csb/creds")).To(Equal(map[string]interface{}{"credhub-ref": "/c/csb/creds"}))
+	})
+
+	It("returns errors from Vault", func() {
+		store, err := credstore.NewVaultStore(&config.VaultConfig{Enabled: true, Address: server.URL, Token: "wrong-token"}, logger)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = store.Get("/c/csb/creds")
+		Expect(err).To(MatchError(`Vault GET /v1/secret/data/c/csb/creds failed with status 403: {"errors":["permission denied"]}`))
+	})
+
+	It("fails when the AppRole login fails", func() {
+		store, err := credstore.NewVaultStore(&config.VaultConfig{Enabled: true, Address: server.URL, RoleID: "my-role", SecretID: "wrong"}, logger)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = store.Get("/c/csb/creds")
+		Expect(err).To(MatchError(ContainSubstring("error logging in to Vault with AppRole")))
+	})
+
+	DescribeTable(
+		"invalid config",
+		func(c config.VaultConfig, expected string) {
+			_, err := credstore.NewVaultStore(&c, logger)
+			Expect(err).To(MatchError(expected))
+		},
+		Entry("not enabled", config.VaultConfig{Address: "https://vault.example.com", Token: "my-token"}, "VaultConfig not found"),
+		Entry("no address", config.VaultConfig{Enabled: true, Token: "my-token"}, "Vault requires an address"),
+		Entry("no auth", config.VaultConfig{Enabled: true, Address: "https://vault.example.com"}, "Vault requires either a token, or an AppRole role ID and secret ID"),
+		Entry("no secret ID", config.VaultConfig{Enabled: true, Address: "https://vault.example.com", RoleID: "my-role"}, "Vault requires either a token, or an AppRole role ID and secret ID"),
+		Entry("both", config.VaultConfig{Enabled: true, Address: "https://vault.example.com", Token: "my-token", RoleID: "my-role", SecretID: "my-secret"}, "Vault requires either a token, or an AppRole role ID and secret ID, not both"),
+	)
+})
@@ -3,8 +3,10 @@ package featureflags
 import "github.com/spf13/viper"
 
 const (
-	TfUpgradeEnabled  = "brokerpak.terraform.upgrades.enabled"
-	DynamicHCLEnabled = "brokerpak.updates.enabled"
+	TfUpgradeEnabled            = "brokerpak.terraform.upgrades.enabled"
+	DynamicHCLEnabled           = "brokerpak.updates.enabled"
+	PersistentWorkspacesEnabled = "brokerpak.terraform.persistent_workspaces.enabled"
+	TerraformHTTPBackendEnabled = "brokerpak.terraform.http_backend.enabled"
 )
 
 func init() {
@@ -13,4 +15,10 @@ func init() {
 
 	viper.BindEnv(DynamicHCLEnabled, "BROKERPAK_UPDATES_ENABLED")
 	viper.SetDefault(DynamicHCLEnabled, false)
+
+	viper.BindEnv(PersistentWorkspacesEnabled, "TERRAFORM_PERSISTENT_WORKSPACES_ENABLED")
+	viper.SetDefault(PersistentWorkspacesEnabled, false)
+
+	viper.BindEnv(TerraformHTTPBackendEnabled, "TERRAFORM_HTTP_BACKEND_ENABLED")
+	viper.SetDefault(TerraformHTTPBackendEnabled, false)
 }
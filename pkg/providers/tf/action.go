@@ -0,0 +1,179 @@
+package tf
+
+import (
+	"context"
+	"fmt"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/cloudfoundry/cloud-service-broker/dbservice/models"
+	"github.com/cloudfoundry/cloud-service-broker/internal/storage"
+	"github.com/cloudfoundry/cloud-service-broker/pkg/providers/tf/workspace"
+	"github.com/cloudfoundry/cloud-service-broker/pkg/varcontext"
+	"github.com/cloudfoundry/cloud-service-broker/utils/correlation"
+)
+
+// RunAction starts a custom action on a service instance. Actions that replace resources
+// apply the instance workspace, and actions with a template apply it in their own workspace.
+func (provider *TerraformProvider) RunAction(ctx context.Context, instanceGUID, actionName string, vc *varcontext.VarContext) (string, error) {
+	provider.logger.Debug("terraform-action", correlation.ID(ctx), lager.Data{
+		"instance": instanceGUID,
+		"action":   actionName,
+	})
+
+	action, err := provider.findAction(actionName)
+	if err != nil {
+		return "", err
+	}
+
+	if action.IsReplace() {
+		return provider.replace(ctx, instanceGUID, action.Replace)
+	}
+
+	return provider.applyAction(ctx, vc, action)
+}
+
+// PollAction returns the status of the last run of a custom action.
+func (provider *TerraformProvider) PollAction(ctx context.Context, instanceGUID, actionName string) (bool, string, error) {
+	action, err := provider.findAction(actionName)
+	if err != nil {
+		return true, "", err
+	}
+
+	return provider.OperationStatus(actionTfID(instanceGUID, action))
+}
+
+func (provider *TerraformProvider) findAction(name string) (TfServiceDefinitionV1InstanceAction, error) {
+	for _, action := range provider.serviceDefinition.Actions {
+		if action.Name == name {
+			return action, nil
+		}
+	}
+	return TfServiceDefinitionV1InstanceAction{}, fmt.Errorf("action %q could not be found", name)
+}
+
+func (provider *TerraformProvider) replace(ctx context.Context, instanceGUID string, addresses []string) (string, error) {
+	tfID := generateTfID(instanceGUID, "")
+
+	deployment, err := provider.GetTerraformDeployment(tfID)
+	if err != nil {
+		return tfID, err
+	}
+
+	if deployment.LastOperationState == InProgress {
+		return tfID, fmt.Errorf("%s operation is in progress for deployment %q", deployment.LastOperationType, tfID)
+	}
+
+	if err := provider.MarkOperationStarted(&deployment, models.ActionOperationType); err != nil {
+		return tfID, fmt.Errorf("error marking job started: %w", err)
+	}
+
+	go func() {
+		ctx := provider.reportRetries(ctx, &deployment)
+		err := provider.DefaultInvoker().Replace(ctx, deployment.Workspace, addresses)
+		provider.MarkOperationFinished(&deployment, err)
+	}()
+
+	return tfID, nil
+}
+
+func (provider *TerraformProvider) applyAction(ctx context.Context, vars *varcontext.VarContext, action TfServiceDefinitionV1InstanceAction) (string, error) {
+	tfID := vars.GetString("tf_id")
+	if err := vars.Error(); err != nil {
+		return "", err
+	}
+
+	workspace, err := workspace.NewWorkspace(vars.ToMap(), action.Template, nil, []workspace.ParameterMapping{}, []string{}, []workspace.ParameterMapping{})
+	if err != nil {
+		return tfID, fmt.Errorf("error creating workspace: %w", err)
+	}
+
+	deployment, err := provider.CreateOrUpdateDeployment(tfID, workspace)
+	if err != nil {
+		return tfID, err
+	}
+
+	if err := provider.MarkOperationStarted(&deployment, models.ActionOperationType); err != nil {
+		return tfID, fmt.Errorf("error marking job started: %w", err)
+	}
+
+	go func() {
+		ctx := provider.reportRetries(ctx, &deployment)
+		err := provider.DefaultInvoker().Apply(ctx, workspace)
+		provider.MarkOperationFinished(&deployment, err)
+	}()
+
+	return tfID, nil
+}
+
+// actionTfID is the deployment that an action operates on
+func actionTfID(instanceGUID string, action TfServiceDefinitionV1InstanceAction) string {
+	if action.IsReplace() {
+		return generateTfID(instanceGUID, "")
+	}
+	return generateActionTfID(instanceGUID, action.Name)
+}
+
+// actionDeployments returns the deployments of the instance's actions that have a template.
+// They hold resources of the instance, so are upgraded and destroyed along with it.
+func (provider *TerraformProvider) actionDeployments(instanceGUID string) ([]storage.TerraformDeployment, error) {
+	var deployments []storage.TerraformDeployment
+	for _, action := range provider.serviceDefinition.Actions {
+		if action.IsReplace() {
+			continue
+		}
+
+		tfID := generateActionTfID(instanceGUID, action.Name)
+		exists, err := provider.ExistsTerraformDeployment(tfID)
+		switch {
+		case err != nil:
+			return nil, err
+		case !exists:
+			continue
+		}
+
+		deployment, err := provider.GetTerraformDeployment(tfID)
+		if err != nil {
+			return nil, err
+		}
+		deployments = append(deployments, deployment)
+	}
+
+	return deployments, nil
+}
+
+// idleActionDeployments returns the action deployments of the instance, and fails when an action is running
+func (provider *TerraformProvider) idleActionDeployments(instanceGUID string) ([]storage.TerraformDeployment, error) {
+	deployments, err := provider.actionDeployments(instanceGUID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, deployment := range deployments {
+		if deployment.LastOperationState == InProgress {
+			return nil, fmt.Errorf("%s operation is in progress for deployment %q", deployment.LastOperationType, deployment.ID)
+		}
+	}
+
+	return deployments, nil
+}
+
+// runOnActionDeployments runs an operation on each of the action deployments in turn, recording the
+// operation in each deployment. It stops at the first operation that fails.
+func (provider *TerraformProvider) runOnActionDeployments(ctx context.Context, deployments []storage.TerraformDeployment, operationType string, operation func(context.Context, workspace.Workspace) error) error {
+	for i := range deployments {
+		deployment := &deployments[i]
+		if err := provider.MarkOperationStarted(deployment, operationType); err != nil {
+			return err
+		}
+
+		err := operation(provider.reportRetries(ctx, deployment), deployment.Workspace)
+		if finishErr := provider.MarkOperationFinished(deployment, err); err == nil {
+			err = finishErr
+		}
+		if err != nil {
+			return fmt.Errorf("%s of deployment %q failed: %w", operationType, deployment.ID, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,159 @@
+package tf_test
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cloudfoundry/cloud-service-broker/dbservice/models"
+	"github.com/cloudfoundry/cloud-service-broker/internal/storage"
+	"github.com/cloudfoundry/cloud-service-broker/pkg/providers/tf"
+	"github.com/cloudfoundry/cloud-service-broker/pkg/providers/tf/executor"
+	"github.com/cloudfoundry/cloud-service-broker/pkg/providers/tf/tffakes"
+	"github.com/cloudfoundry/cloud-service-broker/pkg/providers/tf/workspace"
+	"github.com/cloudfoundry/cloud-service-broker/pkg/providers/tf/workspace/workspacefakes"
+	"github.com/cloudfoundry/cloud-service-broker/pkg/varcontext"
+	"github.com/cloudfoundry/cloud-service-broker/utils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Actions", func() {
+	const instanceGUID = "a3b2ac0e-d68a-11ec-a5b6-367dda7ea869"
+
+	var (
+		fakeDeploymentManager *tffakes.FakeDeploymentManagerInterface
+		fakeWorkspace         *workspacefakes.FakeWorkspace
+		fakeInvokerBuilder    *tffakes.FakeTerraformInvokerBuilder
+		fakeDefaultInvoker    *tffakes.FakeTerraformInvoker
+		deployment            storage.TerraformDeployment
+		provider              *tf.TerraformProvider
+		genericError          = fmt.Errorf("genericError")
+	)
+
+	BeforeEach(func() {
+		fakeDeploymentManager = &tffakes.FakeDeploymentManagerInterface{}
+		fakeWorkspace = &workspacefakes.FakeWorkspace{}
+		fakeInvokerBuilder = &tffakes.FakeTerraformInvokerBuilder{}
+		fakeDefaultInvoker = &tffakes.FakeTerraformInvoker{}
+		fakeInvokerBuilder.VersionedTerraformInvokerReturns(fakeDefaultInvoker)
+
+		deployment = storage.TerraformDeployment{
+			ID:        "tf:" + instanceGUID + ":",
+			Workspace: fakeWorkspace,
+		}
+
+		definition := tf.TfServiceDefinitionV1{
+			Actions: []tf.TfServiceDefinitionV1InstanceAction{
+				{
+					Name:    "rotate-password",
+					Replace: []string{"module.instance.random_password.admin"},
+				},
+				{
+					Name:     "snapshot",
+					Template: `variable "snapshot_name" { type = string }`,
+				},
+			},
+		}
+
+		provider = tf.NewTerraformProvider(executor.TFBinariesContext{DefaultTfVersion: newVersion("1.1")}, fakeInvokerBuilder, utils.NewLogger("test"), definition, fakeDeploymentManager)
+	})
+
+	Describe("RunAction", func() {
+		When("the action replaces resources", func() {
+			It("applies the instance workspace with the resources to replace", func() {
+				fakeDeploymentManager.GetTerraformDeploymentReturns(deployment, nil)
+
+				operationID, err := provider.RunAction(context.TODO(), instanceGUID, "rotate-password", nil)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(operationID).To(Equal("tf:" + instanceGUID + ":"))
+
+				Expect(fakeDeploymentManager.GetTerraformDeploymentArgsForCall(0)).To(Equal("tf:" + instanceGUID + ":"))
+				_, operationType := fakeDeploymentManager.MarkOperationStartedArgsForCall(0)
+				Expect(operationType).To(Equal(models.ActionOperationType))
+
+				Eventually(operationWasFinishedForDeployment(fakeDeploymentManager)).Should(Equal(deployment))
+				Expect(operationWasFinishedWithError(fakeDeploymentManager)()).To(BeNil())
+				_, ws, addresses := fakeDefaultInvoker.ReplaceArgsForCall(0)
+				Expect(ws).To(Equal(fakeWorkspace))
+				Expect(addresses).To(Equal([]string{"module.instance.random_password.admin"}))
+			})
+
+			It("returns the error in last operation, if terraform fails", func() {
+				fakeDeploymentManager.GetTerraformDeploymentReturns(deployment, nil)
+				fakeDefaultInvoker.ReplaceReturns(genericError)
+
+				_, err := provider.RunAction(context.TODO(), instanceGUID, "rotate-password", nil)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(operationWasFinishedForDeployment(fakeDeploymentManager)).Should(Equal(deployment))
+				Expect(operationWasFinishedWithError(fakeDeploymentManager)()).To(MatchError(genericError))
+			})
+
+			It("fails when an operation is in progress", func() {
+				deployment.LastOperationType = models.UpdateOperationType
+				deployment.LastOperationState = tf.InProgress
+				fakeDeploymentManager.GetTerraformDeploymentReturns(deployment, nil)
+
+				_, err := provider.RunAction(context.TODO(), instanceGUID, "rotate-password", nil)
+				Expect(err).To(MatchError(fmt.Sprintf(`update operation is in progress for deployment "tf:%s:"`, instanceGUID)))
+				Expect(fakeDeploymentManager.MarkOperationStartedCallCount()).To(BeZero())
+			})
+		})
+
+		When("the action has a template", func() {
+			It("applies the template in the deployment of the action", func() {
+				actionDeployment := storage.TerraformDeployment{ID: "tf:" + instanceGUID + ":action:snapshot", Workspace: fakeWorkspace}
+				fakeDeploymentManager.CreateOrUpdateDeploymentReturns(actionDeployment, nil)
+				vc, err := varcontext.Builder().MergeMap(map[string]interface{}{
+					"tf_id":         "tf:" + instanceGUID + ":action:snapshot",
+					"snapshot_name": "nightly",
+				}).Build()
+				Expect(err).NotTo(HaveOccurred())
+
+				operationID, err := provider.RunAction(context.TODO(), instanceGUID, "snapshot", vc)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(operationID).To(Equal("tf:" + instanceGUID + ":action:snapshot"))
+
+				deploymentID, ws := fakeDeploymentManager.CreateOrUpdateDeploymentArgsForCall(0)
+				Expect(deploymentID).To(Equal("tf:" + instanceGUID + ":action:snapshot"))
+				Expect(ws.Instances[0].Configuration).To(Equal(map[string]interface{}{"snapshot_name": "nightly"}))
+
+				Eventually(operationWasFinishedForDeployment(fakeDeploymentManager)).Should(Equal(actionDeployment))
+				Expect(fakeDefaultInvoker.ApplyCallCount()).To(Equal(1))
+				_, applied := fakeDefaultInvoker.ApplyArgsForCall(0)
+				Expect(applied).To(BeAssignableToTypeOf(&workspace.TerraformWorkspace{}))
+			})
+
+			It("fails when the deployment cannot be saved", func() {
+				fakeDeploymentManager.CreateOrUpdateDeploymentReturns(storage.TerraformDeployment{}, genericError)
+				vc, err := varcontext.Builder().MergeMap(map[string]interface{}{"tf_id": "tf:" + instanceGUID + ":action:snapshot"}).Build()
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = provider.RunAction(context.TODO(), instanceGUID, "snapshot", vc)
+				Expect(err).To(MatchError(genericError))
+				Expect(fakeDeploymentManager.MarkOperationStartedCallCount()).To(BeZero())
+			})
+		})
+
+		It("fails when the action does not exist", func() {
+			_, err := provider.RunAction(context.TODO(), instanceGUID, "restart", nil)
+			Expect(err).To(MatchError(`action "restart" could not be found`))
+		})
+	})
+
+	Describe("PollAction", func() {
+		It("returns the status of the deployment that the action operates on", func() {
+			fakeDeploymentManager.OperationStatusReturns(true, "action succeeded", nil)
+
+			done, message, err := provider.PollAction(context.TODO(), instanceGUID, "snapshot")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(done).To(BeTrue())
+			Expect(message).To(Equal("action succeeded"))
+			Expect(fakeDeploymentManager.OperationStatusArgsForCall(0)).To(Equal("tf:" + instanceGUID + ":action:snapshot"))
+
+			_, _, err = provider.PollAction(context.TODO(), instanceGUID, "rotate-password")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeDeploymentManager.OperationStatusArgsForCall(1)).To(Equal("tf:" + instanceGUID + ":"))
+		})
+	})
+})
@@ -0,0 +1,71 @@
+package tf
+
+import (
+	"context"
+	"fmt"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/cloudfoundry/cloud-service-broker/dbservice/models"
+	"github.com/cloudfoundry/cloud-service-broker/pkg/providers/tf/workspace"
+	"github.com/cloudfoundry/cloud-service-broker/pkg/varcontext"
+	"github.com/cloudfoundry/cloud-service-broker/utils/correlation"
+)
+
+// Adopt brings existing resources under the management of the broker. The resources are imported
+// into a new workspace built from the provision template, and the output of `terraform plan` is passed
+// to confirm. Only when confirm returns true is the deployment saved and the plan applied, so that
+// the resources match the template. It waits for the apply to finish.
+func (provider *TerraformProvider) Adopt(ctx context.Context, provisionContext *varcontext.VarContext, resources map[string]string, confirm func(plan string) bool) (bool, error) {
+	provider.logger.Debug("terraform-adopt", correlation.ID(ctx), lager.Data{
+		"resources": resources,
+	})
+
+	if len(resources) == 0 {
+		return false, fmt.Errorf("no resources to adopt")
+	}
+
+	tfID := provisionContext.GetString("tf_id")
+	if err := provisionContext.Error(); err != nil {
+		return false, err
+	}
+
+	action := provider.serviceDefinition.ProvisionSettings
+	workspace, err := workspace.NewWorkspace(provisionContext.ToMap(), action.Template, action.Templates, []workspace.ParameterMapping{}, []string{}, []workspace.ParameterMapping{})
+	if err != nil {
+		return false, fmt.Errorf("error creating workspace: %w", err)
+	}
+	workspace.AppliedStateMigrations = action.stateMigrationVersions()
+
+	invoker := provider.DefaultInvoker()
+	if err := invoker.Import(ctx, workspace, resources); err != nil {
+		return false, fmt.Errorf("error importing resources: %w", err)
+	}
+
+	plan, err := invoker.Plan(ctx, workspace)
+	if err != nil {
+		return false, fmt.Errorf("error planning changes to the imported resources: %w", err)
+	}
+
+	if !confirm(plan.StdOut) {
+		return false, nil
+	}
+
+	deployment, err := provider.CreateAndSaveDeployment(tfID, workspace)
+	if err != nil {
+		return false, fmt.Errorf("terraform provider create failed: %w", err)
+	}
+
+	if err := provider.MarkOperationStarted(&deployment, models.ProvisionOperationType); err != nil {
+		return false, fmt.Errorf("error marking job started: %w", err)
+	}
+
+	applyErr := invoker.Apply(ctx, workspace)
+	if err := provider.MarkOperationFinished(&deployment, applyErr); err != nil {
+		return false, fmt.Errorf("error marking job finished: %w", err)
+	}
+	if applyErr != nil {
+		return false, fmt.Errorf("error applying changes to the imported resources: %w", applyErr)
+	}
+
+	return true, nil
+}
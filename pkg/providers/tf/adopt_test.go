@@ -0,0 +1,121 @@
+package tf_test
+
+import (
+	"context"
+	"errors"
+
+	"github.com/cloudfoundry/cloud-service-broker/internal/storage"
+	"github.com/cloudfoundry/cloud-service-broker/pkg/providers/tf"
+	"github.com/cloudfoundry/cloud-service-broker/pkg/providers/tf/executor"
+	"github.com/cloudfoundry/cloud-service-broker/pkg/providers/tf/tffakes"
+	"github.com/cloudfoundry/cloud-service-broker/pkg/providers/tf/workspace"
+	"github.com/cloudfoundry/cloud-service-broker/pkg/varcontext"
+	"github.com/cloudfoundry/cloud-service-broker/utils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Adopt", func() {
+	const expectedTfID = "tf:instance:"
+
+	var (
+		fakeDeploymentManager *tffakes.FakeDeploymentManagerInterface
+		fakeInvokerBuilder    *tffakes.FakeTerraformInvokerBuilder
+		fakeDefaultInvoker    *tffakes.FakeTerraformInvoker
+		provider              *tf.TerraformProvider
+		provisionContext      *varcontext.VarContext
+		deployment            storage.TerraformDeployment
+		resources             = map[string]string{"aws_db_instance.db": "existing-db"}
+		template              = `variable username {type = string}`
+	)
+
+	BeforeEach(func() {
+		fakeDeploymentManager = &tffakes.FakeDeploymentManagerInterface{}
+		fakeDefaultInvoker = &tffakes.FakeTerraformInvoker{}
+		fakeInvokerBuilder = &tffakes.FakeTerraformInvokerBuilder{}
+		fakeInvokerBuilder.VersionedTerraformInvokerReturns(fakeDefaultInvoker)
+		fakeDefaultInvoker.PlanReturns(executor.ExecutionOutput{StdOut: "Plan: 0 to add, 1 to change, 0 to destroy."}, nil)
+
+		deployment = storage.TerraformDeployment{ID: expectedTfID}
+		fakeDeploymentManager.CreateAndSaveDeploymentReturns(deployment, nil)
+
+		serviceDefinition := tf.TfServiceDefinitionV1{
+			ProvisionSettings: tf.TfServiceDefinitionV1Action{
+				Template:  template,
+				Templates: map[string]string{"first": template},
+			},
+		}
+		provider = tf.NewTerraformProvider(executor.TFBinariesContext{}, fakeInvokerBuilder, utils.NewLogger("test"), serviceDefinition, fakeDeploymentManager)
+
+		var err error
+		provisionContext, err = varcontext.Builder().MergeMap(map[string]interface{}{"tf_id": expectedTfID, "username": "some-user"}).Build()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("imports the resources, and applies the plan once it is confirmed", func() {
+		var confirmedPlan string
+		adopted, err := provider.Adopt(context.TODO(), provisionContext, resources, func(plan string) bool {
+			confirmedPlan = plan
+			return true
+		})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(adopted).To(BeTrue())
+		Expect(confirmedPlan).To(Equal("Plan: 0 to add, 1 to change, 0 to destroy."))
+
+		By("importing into a workspace built from the provision template")
+		Expect(fakeDefaultInvoker.ImportCallCount()).To(Equal(1))
+		_, importWorkspace, importResources := fakeDefaultInvoker.ImportArgsForCall(0)
+		Expect(importResources).To(Equal(resources))
+		tfWorkspace := importWorkspace.(*workspace.TerraformWorkspace)
+		Expect(tfWorkspace.Modules[0].Definitions).To(Equal(map[string]string{"first": template}))
+		Expect(tfWorkspace.Instances[0].Configuration).To(Equal(map[string]interface{}{"username": "some-user"}))
+
+		By("saving the deployment and applying the plan")
+		Expect(fakeDeploymentManager.CreateAndSaveDeploymentCallCount()).To(Equal(1))
+		actualTfID, actualWorkspace := fakeDeploymentManager.CreateAndSaveDeploymentArgsForCall(0)
+		Expect(actualTfID).To(Equal(expectedTfID))
+		Expect(actualWorkspace).To(BeIdenticalTo(tfWorkspace))
+		Expect(fakeDeploymentManager.MarkOperationStartedCallCount()).To(Equal(1))
+		_, operationType := fakeDeploymentManager.MarkOperationStartedArgsForCall(0)
+		Expect(operationType).To(Equal("provision"))
+		Expect(fakeDefaultInvoker.ApplyCallCount()).To(Equal(1))
+		Expect(operationWasFinishedWithError(fakeDeploymentManager)()).To(BeNil())
+	})
+
+	It("does nothing more when the plan is not confirmed", func() {
+		adopted, err := provider.Adopt(context.TODO(), provisionContext, resources, func(string) bool { return false })
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(adopted).To(BeFalse())
+		Expect(fakeDefaultInvoker.ImportCallCount()).To(Equal(1))
+		Expect(fakeDeploymentManager.CreateAndSaveDeploymentCallCount()).To(BeZero())
+		Expect(fakeDefaultInvoker.ApplyCallCount()).To(BeZero())
+	})
+
+	It("fails when there are no resources", func() {
+		_, err := provider.Adopt(context.TODO(), provisionContext, nil, func(string) bool { return true })
+
+		Expect(err).To(MatchError("no resources to adopt"))
+		Expect(fakeDefaultInvoker.ImportCallCount()).To(BeZero())
+	})
+
+	It("fails when the import fails", func() {
+		fakeDefaultInvoker.ImportReturns(errors.New("cannot import"))
+
+		_, err := provider.Adopt(context.TODO(), provisionContext, resources, func(string) bool { return true })
+
+		Expect(err).To(MatchError("error importing resources: cannot import"))
+		Expect(fakeDefaultInvoker.PlanCallCount()).To(BeZero())
+	})
+
+	It("marks the operation as failed when the apply fails", func() {
+		fakeDefaultInvoker.ApplyReturns(errors.New("cannot apply"))
+
+		adopted, err := provider.Adopt(context.TODO(), provisionContext, resources, func(string) bool { return true })
+
+		Expect(err).To(MatchError("error applying changes to the imported resources: cannot apply"))
+		Expect(adopted).To(BeFalse())
+		Expect(operationWasFinishedWithError(fakeDeploymentManager)()).To(MatchError("cannot apply"))
+	})
+})
@@ -37,6 +37,22 @@ func (apply) Command() []string {
 	return []string{"apply", "-auto-approve", "-no-color"}
 }
 
+func NewApplyReplace(addresses []string) TerraformCommand {
+	return applyReplace{addresses: addresses}
+}
+
+type applyReplace struct {
+	addresses []string
+}
+
+func (cmd applyReplace) Command() []string {
+	command := []string{"apply", "-auto-approve", "-no-color"}
+	for _, addr := range cmd.addresses {
+		command = append(command, fmt.Sprintf("-replace=%s", addr))
+	}
+	return command
+}
+
 func NewDestroy() TerraformCommand {
 	return destroy{}
 }
@@ -80,6 +96,18 @@ func (cmd importCmd) Command() []string {
 	return []string{"import", cmd.Addr, cmd.ID}
 }
 
+func NewTaint(addr string) TerraformCommand {
+	return taint{addr: addr}
+}
+
+type taint struct {
+	addr string
+}
+
+func (cmd taint) Command() []string {
+	return []string{"taint", "-no-color", cmd.addr}
+}
+
 type renameProvider struct {
 	oldProviderName string
 	newProviderName string
@@ -92,3 +120,48 @@ func (cmd renameProvider) Command() []string {
 func NewRenameProvider(oldProviderName, newProviderName string) TerraformCommand {
 	return renameProvider{oldProviderName: oldProviderName, newProviderName: newProviderName}
 }
+
+func NewStatePull() TerraformCommand {
+	return statePull{}
+}
+
+type statePull struct{}
+
+func (statePull) Command() []string {
+	return []string{"state", "pull"}
+}
+
+func NewStateList() TerraformCommand {
+	return stateList{}
+}
+
+type stateList struct{}
+
+func (stateList) Command() []string {
+	return []string{"state", "list"}
+}
+
+func NewStateMove(from, to string) TerraformCommand {
+	return stateMove{from: from, to: to}
+}
+
+type stateMove struct {
+	from string
+	to   string
+}
+
+func (cmd stateMove) Command() []string {
+	return []string{"state", "mv", cmd.from, cmd.to}
+}
+
+func NewStateRemove(addr string) TerraformCommand {
+	return stateRemove{addr: addr}
+}
+
+type stateRemove struct {
+	addr string
+}
+
+func (cmd stateRemove) Command() []string {
+	return []string{"state", "rm", cmd.addr}
+}
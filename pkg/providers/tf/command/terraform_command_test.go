@@ -28,6 +28,48 @@ var _ = Context("Terraform Commands", func() {
 		})
 	})
 
+	Context("applyReplace", func() {
+		It("calls apply with a replace option for each address", func() {
+			apply := command.NewApplyReplace([]string{"aws_db_instance.db", "random_password.pw"})
+			Expect(apply.Command()).To(Equal([]string{"apply", "-auto-approve", "-no-color", "-replace=aws_db_instance.db", "-replace=random_password.pw"}))
+		})
+	})
+
+	Context("taint", func() {
+		It("calls taint with the address", func() {
+			taint := command.NewTaint("aws_db_instance.db")
+			Expect(taint.Command()).To(Equal([]string{"taint", "-no-color", "aws_db_instance.db"}))
+		})
+	})
+
+	Context("state mv", func() {
+		It("calls state mv with the addresses", func() {
+			stateMove := command.NewStateMove("random_string.name", "module.names.random_string.name")
+			Expect(stateMove.Command()).To(Equal([]string{"state", "mv", "random_string.name", "module.names.random_string.name"}))
+		})
+	})
+
+	Context("state rm", func() {
+		It("calls state rm with the address", func() {
+			stateRemove := command.NewStateRemove("random_string.name")
+			Expect(stateRemove.Command()).To(Equal([]string{"state", "rm", "random_string.name"}))
+		})
+	})
+
+	Context("state list", func() {
+		It("calls state list", func() {
+			stateList := command.NewStateList()
+			Expect(stateList.Command()).To(Equal([]string{"state", "list"}))
+		})
+	})
+
+	Context("state pull", func() {
+		It("calls state pull", func() {
+			statePull := command.NewStatePull()
+			Expect(statePull.Command()).To(Equal([]string{"state", "pull"}))
+		})
+	})
+
 	Context("Destroy", func() {
 		It("calls init with the plugin directory", func() {
 			apply := command.NewDestroy()
@@ -18,6 +18,10 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/cloudfoundry/cloud-service-broker/pkg/providers/tf/executor"
 
@@ -28,7 +32,9 @@ import (
 	"github.com/cloudfoundry/cloud-service-broker/pkg/broker"
 	"github.com/cloudfoundry/cloud-service-broker/pkg/validation"
 	"github.com/cloudfoundry/cloud-service-broker/pkg/varcontext"
+	"github.com/cloudfoundry/cloud-service-broker/pkg/varcontext/interpolation"
 	"github.com/cloudfoundry/cloud-service-broker/utils"
+	"github.com/hashicorp/go-version"
 	"github.com/pivotal-cf/brokerapi/v8/domain"
 	"github.com/spf13/viper"
 )
@@ -141,22 +147,27 @@ func NewExampleTfServiceDefinition() TfServiceDefinitionV1 {
 
 // TfServiceDefinitionV1 is the first version of user defined services.
 type TfServiceDefinitionV1 struct {
-	Version           int                         `yaml:"version"`
-	Name              string                      `yaml:"name"`
-	ID                string                      `yaml:"id"`
-	Description       string                      `yaml:"description"`
-	DisplayName       string                      `yaml:"display_name"`
-	ImageURL          string                      `yaml:"image_url"`
-	DocumentationURL  string                      `yaml:"documentation_url"`
-	SupportURL        string                      `yaml:"support_url"`
-	Tags              []string                    `yaml:"tags,flow"`
-	Plans             []TfServiceDefinitionV1Plan `yaml:"plans"`
-	ProvisionSettings TfServiceDefinitionV1Action `yaml:"provision"`
-	BindSettings      TfServiceDefinitionV1Action `yaml:"bind"`
-	Examples          []broker.ServiceExample     `yaml:"examples"`
-	PlanUpdateable    bool                        `yaml:"plan_updateable"`
+	Version           int                                   `yaml:"version"`
+	Name              string                                `yaml:"name"`
+	ID                string                                `yaml:"id"`
+	Description       string                                `yaml:"description"`
+	DisplayName       string                                `yaml:"display_name"`
+	ImageURL          string                                `yaml:"image_url"`
+	DocumentationURL  string                                `yaml:"documentation_url"`
+	SupportURL        string                                `yaml:"support_url"`
+	Tags              []string                              `yaml:"tags,flow"`
+	Plans             []TfServiceDefinitionV1Plan           `yaml:"plans"`
+	ProvisionSettings TfServiceDefinitionV1Action           `yaml:"provision"`
+	BindSettings      TfServiceDefinitionV1Action           `yaml:"bind"`
+	Actions           []TfServiceDefinitionV1InstanceAction `yaml:"actions"`
+	Retry             TfServiceDefinitionV1Retry            `yaml:"retry"`
+	Examples          []broker.ServiceExample               `yaml:"examples"`
+	PlanUpdateable    bool                                  `yaml:"plan_updateable"`
 
 	RequiredEnvVars []string
+
+	// BrokerpakVersion is the version of the brokerpak that the service definition was read from
+	BrokerpakVersion string
 }
 
 var _ validation.Validatable = (*TfServiceDefinitionV1)(nil)
@@ -191,6 +202,16 @@ func (tfb *TfServiceDefinitionV1) Validate() (errs *validation.FieldError) {
 	errs = errs.Also(tfb.ProvisionSettings.Validate().ViaField("provision"))
 	errs = errs.Also(tfb.BindSettings.Validate().ViaField("bind"))
 
+	actionNames := make(map[string]struct{})
+	for i, v := range tfb.Actions {
+		errs = errs.Also(
+			v.Validate().ViaFieldIndex("actions", i),
+			validation.ErrIfDuplicate(v.Name, "name", actionNames).ViaFieldIndex("actions", i),
+		)
+	}
+
+	errs = errs.Also(tfb.Retry.Validate().ViaField("retry"))
+
 	for i, v := range tfb.Examples {
 		errs = errs.Also(v.Validate().ViaFieldIndex("examples", i))
 	}
@@ -203,7 +224,7 @@ func (tfb *TfServiceDefinitionV1) resolveEnvVars() (map[string]string, error) {
 	for _, v := range tfb.RequiredEnvVars {
 		viper.BindEnv(v, v)
 		if !viper.IsSet(v) {
-			return vars, fmt.Errorf(fmt.Sprintf("missing required env var %s", v))
+			return vars, fmt.Errorf("missing required env var %s", v)
 		}
 		vars[v] = viper.GetString(v)
 	}
@@ -217,6 +238,12 @@ func (tfb *TfServiceDefinitionV1) loadTemplates() error {
 		err = tfb.ProvisionSettings.LoadTemplate(".")
 	}
 
+	for i := range tfb.Actions {
+		if err == nil {
+			err = tfb.Actions[i].LoadTemplate(".")
+		}
+	}
+
 	return err
 }
 
@@ -262,6 +289,16 @@ func (tfb *TfServiceDefinitionV1) ToService(tfBinContext executor.TFBinariesCont
 		Overwrite: true,
 	})
 
+	retryPolicy, err := tfb.Retry.ToRetryPolicy()
+	if err != nil {
+		return nil, err
+	}
+
+	var actions []broker.InstanceAction
+	for _, action := range tfb.Actions {
+		actions = append(actions, action.ToInstanceAction())
+	}
+
 	constDefn := *tfb
 	return &broker.ServiceDefinition{
 		ID:               tfb.ID,
@@ -288,9 +325,10 @@ func (tfb *TfServiceDefinitionV1) ToService(tfBinContext executor.TFBinariesCont
 		BindOutputVariables:   append(tfb.ProvisionSettings.Outputs, tfb.BindSettings.Outputs...),
 		PlanVariables:         append(tfb.ProvisionSettings.PlanInputs, tfb.BindSettings.PlanInputs...),
 		Examples:              tfb.Examples,
+		Actions:               actions,
 		ProviderBuilder: func(logger lager.Logger, store broker.ServiceProviderStorage) broker.ServiceProvider {
-			executorFactory := executor.NewExecutorFactory(tfBinContext.Dir, tfBinContext.Params, envVars)
-			return NewTerraformProvider(tfBinContext, invoker.NewTerraformInvokerFactory(executorFactory, tfBinContext.Dir, tfBinContext.ProviderReplacements), logger, constDefn, NewDeploymentManager(store))
+			executorFactory := executor.NewExecutorFactory(tfBinContext, envVars, retryPolicy)
+			return NewTerraformProvider(tfBinContext, invoker.NewTerraformInvokerFactory(executorFactory, tfBinContext), logger, constDefn, NewDeploymentManager(store, constDefn.BrokerpakVersion))
 		},
 	}, nil
 }
@@ -370,6 +408,7 @@ type TfServiceDefinitionV1Action struct {
 	ImportParameterMappings  []ImportParameterMapping     `yaml:"import_parameter_mappings"`
 	ImportParametersToDelete []string                     `yaml:"import_parameters_to_delete"`
 	ImportParametersToAdd    []ImportParameterMapping     `yaml:"import_parameters_to_add"`
+	StateMigrations          []StateMigration             `yaml:"state_migrations"`
 }
 
 var _ validation.Validatable = (*TfServiceDefinitionV1Action)(nil)
@@ -453,9 +492,58 @@ func (action *TfServiceDefinitionV1Action) Validate() (errs *validation.FieldErr
 		errs = errs.Also(v.Validate().ViaFieldIndex("outputs", i))
 	}
 
+	versions := make(map[string]struct{})
+	for i, v := range action.StateMigrations {
+		errs = errs.Also(
+			v.Validate().ViaFieldIndex("state_migrations", i),
+			validation.ErrIfDuplicate(v.Version, fmt.Sprintf("state_migrations[%d].version", i), versions),
+		)
+	}
+
 	return errs
 }
 
+// stateMigrationVersions are the versions of all the state migrations. They are recorded as applied in
+// new workspaces, because the resources of new workspaces are already where the migrations would move them.
+func (action *TfServiceDefinitionV1Action) stateMigrationVersions() []string {
+	var versions []string
+	for _, m := range action.StateMigrations {
+		versions = append(versions, m.Version)
+	}
+	return versions
+}
+
+// workspaceStateMigrations converts the state migrations for a workspace, in version order. Import IDs
+// may be HIL expressions, which are evaluated with the template variables of the workspace.
+func (action *TfServiceDefinitionV1Action) workspaceStateMigrations(templateVars map[string]interface{}) ([]workspace.StateMigration, error) {
+	migrations := make([]StateMigration, len(action.StateMigrations))
+	copy(migrations, action.StateMigrations)
+	sort.SliceStable(migrations, func(i, j int) bool {
+		return version.Must(version.NewVersion(migrations[i].Version)).LessThan(version.Must(version.NewVersion(migrations[j].Version)))
+	})
+
+	var result []workspace.StateMigration
+	for _, m := range migrations {
+		converted := workspace.StateMigration{Version: m.Version, Removes: m.Removes}
+		for _, move := range m.Moves {
+			converted.Moves = append(converted.Moves, workspace.StateMove{From: move.From, To: move.To})
+		}
+		for _, imp := range m.Imports {
+			id := imp.ID
+			if interpolation.IsHILExpression(id) {
+				evaluated, err := interpolation.Eval(id, templateVars)
+				if err != nil {
+					return nil, fmt.Errorf("error evaluating import id for %q in state migration %q: %w", imp.Address, m.Version, err)
+				}
+				id = fmt.Sprint(evaluated)
+			}
+			converted.Imports = append(converted.Imports, workspace.StateImport{Address: imp.Address, ID: id})
+		}
+		result = append(result, converted)
+	}
+	return result, nil
+}
+
 func (action *TfServiceDefinitionV1Action) ValidateTemplateIO() (errs *validation.FieldError) {
 	return errs.Also(
 		action.validateTemplateInputs().ViaField("template"),
@@ -525,6 +613,167 @@ func (action *TfServiceDefinitionV1Action) validateTemplateOutputs() (errs *vali
 	return nil
 }
 
+// TfServiceDefinitionV1InstanceAction is a named action that can be run on an existing
+// service instance. It either applies its own Terraform template, or re-creates resources
+// of the instance by applying the provision template with the -replace option.
+type TfServiceDefinitionV1InstanceAction struct {
+	Name        string                       `yaml:"name"`
+	Description string                       `yaml:"description"`
+	UserInputs  []broker.BrokerVariable      `yaml:"user_inputs"`
+	Computed    []varcontext.DefaultVariable `yaml:"computed_inputs"`
+	Template    string                       `yaml:"template"`
+	TemplateRef string                       `yaml:"template_ref"`
+	Replace     []string                     `yaml:"replace"`
+}
+
+var _ validation.Validatable = (*TfServiceDefinitionV1InstanceAction)(nil)
+
+// LoadTemplate loads template ref into template if provided
+func (action *TfServiceDefinitionV1InstanceAction) LoadTemplate(srcDir string) (err error) {
+	if action.TemplateRef != "" {
+		action.Template, err = loadTemplate(path.Join(srcDir, action.TemplateRef))
+	}
+	return err
+}
+
+// Validate implements validation.Validatable.
+func (action *TfServiceDefinitionV1InstanceAction) Validate() (errs *validation.FieldError) {
+	errs = errs.Also(
+		validation.ErrIfNotOSBName(action.Name, "name"),
+		validation.ErrIfBlank(action.Description, "description"),
+	)
+
+	for i, v := range action.UserInputs {
+		errs = errs.Also(v.Validate().ViaFieldIndex("user_inputs", i))
+	}
+
+	for i, v := range action.Computed {
+		errs = errs.Also(v.Validate().ViaFieldIndex("computed_inputs", i))
+	}
+
+	if action.TemplateRef != "" {
+		errs = errs.Also(validation.ErrIfBlank(action.Template, "template not loaded from template ref"))
+	}
+
+	switch {
+	case action.Template == "" && len(action.Replace) == 0:
+		errs = errs.Also(validation.ErrMissingOneOf("template", "replace"))
+	case action.Template != "" && len(action.Replace) > 0:
+		errs = errs.Also(validation.ErrMultipleOneOf("template", "replace"))
+	case action.Template != "":
+		errs = errs.Also(validation.ErrIfNotHCL(action.Template, "template"))
+	case len(action.UserInputs) > 0:
+		errs = errs.Also(validation.ErrDisallowedFields("user_inputs"))
+	}
+
+	for i, addr := range action.Replace {
+		errs = errs.Also(validation.ErrIfBlank(addr, fmt.Sprintf("replace[%d]", i)))
+	}
+
+	return errs
+}
+
+// IsReplace is true when the action re-creates resources of the service instance
+// rather than applying its own template
+func (action *TfServiceDefinitionV1InstanceAction) IsReplace() bool {
+	return len(action.Replace) > 0
+}
+
+// ToInstanceAction converts the action into a broker.InstanceAction that the registry can use.
+func (action *TfServiceDefinitionV1InstanceAction) ToInstanceAction() broker.InstanceAction {
+	return broker.InstanceAction{
+		Name:           action.Name,
+		Description:    action.Description,
+		InputVariables: action.UserInputs,
+		ComputedVariables: append(action.Computed, varcontext.DefaultVariable{
+			Name:      "tf_id",
+			Default:   generateActionTfID("${request.instance_id}", action.Name),
+			Overwrite: true,
+		}),
+	}
+}
+
+// TfServiceDefinitionV1Retry configures retries of Terraform commands that fail with
+// transient errors, such as cloud API throttling or eventual-consistency errors.
+type TfServiceDefinitionV1Retry struct {
+	MaxAttempts int      `yaml:"max_attempts"`
+	Backoff     string   `yaml:"backoff"`
+	MaxBackoff  string   `yaml:"max_backoff"`
+	Errors      []string `yaml:"errors"`
+}
+
+const (
+	defaultRetryBackoff    = 10 * time.Second
+	defaultRetryMaxBackoff = 5 * time.Minute
+)
+
+var _ validation.Validatable = (*TfServiceDefinitionV1Retry)(nil)
+
+// Validate implements validation.Validatable.
+func (retry *TfServiceDefinitionV1Retry) Validate() (errs *validation.FieldError) {
+	if retry.MaxAttempts < 0 {
+		errs = errs.Also(validation.ErrInvalidValue(retry.MaxAttempts, "max_attempts"))
+	}
+
+	if retry.MaxAttempts > 1 && len(retry.Errors) == 0 {
+		errs = errs.Also(validation.ErrMissingField("errors"))
+	}
+
+	errs = errs.Also(
+		errIfNotDuration(retry.Backoff, "backoff"),
+		errIfNotDuration(retry.MaxBackoff, "max_backoff"),
+	)
+
+	for i, e := range retry.Errors {
+		if _, err := regexp.Compile(e); err != nil {
+			errs = errs.Also(validation.ErrInvalidArrayValue(e, "errors", i))
+		}
+	}
+
+	return errs
+}
+
+// ToRetryPolicy converts the configuration into a policy for the Terraform executor
+func (retry *TfServiceDefinitionV1Retry) ToRetryPolicy() (executor.RetryPolicy, error) {
+	policy := executor.RetryPolicy{
+		MaxAttempts: retry.MaxAttempts,
+		Backoff:     defaultRetryBackoff,
+		MaxBackoff:  defaultRetryMaxBackoff,
+	}
+
+	var err error
+	if retry.Backoff != "" {
+		if policy.Backoff, err = time.ParseDuration(retry.Backoff); err != nil {
+			return executor.RetryPolicy{}, fmt.Errorf("invalid retry backoff: %w", err)
+		}
+	}
+	if retry.MaxBackoff != "" {
+		if policy.MaxBackoff, err = time.ParseDuration(retry.MaxBackoff); err != nil {
+			return executor.RetryPolicy{}, fmt.Errorf("invalid retry max_backoff: %w", err)
+		}
+	}
+
+	for _, e := range retry.Errors {
+		r, err := regexp.Compile(e)
+		if err != nil {
+			return executor.RetryPolicy{}, fmt.Errorf("invalid retry error pattern: %w", err)
+		}
+		policy.Errors = append(policy.Errors, r)
+	}
+
+	return policy, nil
+}
+
+func errIfNotDuration(value, field string) *validation.FieldError {
+	if value == "" {
+		return nil
+	}
+	if d, err := time.ParseDuration(value); err != nil || d < 0 {
+		return validation.ErrInvalidValue(value, field)
+	}
+	return nil
+}
+
 // generateTfID creates a unique id for a given provision/bind combination that
 // will be consistent across calls. This ID will be used in LastOperation polls
 // as well as to uniquely identify the workspace.
@@ -532,6 +781,83 @@ func generateTfID(instanceID, bindingID string) string {
 	return fmt.Sprintf("tf:%s:%s", instanceID, bindingID)
 }
 
+const actionTfIDPrefix = "action:"
+
+// generateActionTfID creates the id of the deployment that holds the workspace of an action
+// with its own template. Each action of an instance gets its own deployment, so that the
+// state of the action's resources is kept separately from the state of the instance.
+func generateActionTfID(instanceID, actionName string) string {
+	return generateTfID(instanceID, actionTfIDPrefix+actionName)
+}
+
+// parseTfID is the inverse of generateTfID. The deployment of an action belongs to the
+// instance, so has no binding ID.
+func parseTfID(tfID string) (instanceID, bindingID string) {
+	parts := strings.SplitN(tfID, ":", 3)
+	if len(parts) != 3 {
+		return "", ""
+	}
+	if strings.HasPrefix(parts[2], actionTfIDPrefix) {
+		return parts[1], ""
+	}
+	return parts[1], parts[2]
+}
+
+// StateMigration lists changes to the Terraform state that are needed when the brokerpak
+// of the given version renames resources or moves them into modules. Without them, Terraform
+// would destroy and recreate the resources of existing deployments.
+type StateMigration struct {
+	Version string                 `yaml:"version"`
+	Moves   []StateMigrationMove   `yaml:"moves"`
+	Removes []string               `yaml:"removes"`
+	Imports []StateMigrationImport `yaml:"imports"`
+}
+
+// StateMigrationMove moves a resource to a new address with `terraform state mv`
+type StateMigrationMove struct {
+	From string `yaml:"from"`
+	To   string `yaml:"to"`
+}
+
+// StateMigrationImport imports a resource with `terraform import`
+type StateMigrationImport struct {
+	Address string `yaml:"address"`
+	ID      string `yaml:"id"`
+}
+
+var _ validation.Validatable = (*StateMigration)(nil)
+
+// Validate implements validation.Validatable.
+func (m *StateMigration) Validate() (errs *validation.FieldError) {
+	if _, err := version.NewVersion(m.Version); err != nil {
+		errs = errs.Also(validation.ErrInvalidValue(m.Version, "version"))
+	}
+
+	if len(m.Moves) == 0 && len(m.Removes) == 0 && len(m.Imports) == 0 {
+		errs = errs.Also(validation.ErrMissingOneOf("moves", "removes", "imports"))
+	}
+
+	for i, move := range m.Moves {
+		errs = errs.Also(
+			validation.ErrIfBlank(move.From, fmt.Sprintf("moves[%d].from", i)),
+			validation.ErrIfBlank(move.To, fmt.Sprintf("moves[%d].to", i)),
+		)
+	}
+
+	for i, addr := range m.Removes {
+		errs = errs.Also(validation.ErrIfBlank(addr, fmt.Sprintf("removes[%d]", i)))
+	}
+
+	for i, imp := range m.Imports {
+		errs = errs.Also(
+			validation.ErrIfBlank(imp.Address, fmt.Sprintf("imports[%d].address", i)),
+			validation.ErrIfBlank(imp.ID, fmt.Sprintf("imports[%d].id", i)),
+		)
+	}
+
+	return errs
+}
+
 // ImportParameterMapping mapping for tf variable to service parameter
 type ImportParameterMapping struct {
 	TfVariable    string `yaml:"tf_variable"`
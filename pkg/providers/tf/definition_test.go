@@ -1,7 +1,11 @@
 package tf_test
 
 import (
+	"time"
+
+	"github.com/cloudfoundry/cloud-service-broker/pkg/broker"
 	"github.com/cloudfoundry/cloud-service-broker/pkg/providers/tf"
+	"github.com/cloudfoundry/cloud-service-broker/pkg/varcontext"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/pivotal-cf/brokerapi/v8/domain"
@@ -64,4 +68,154 @@ var _ = Describe("Definition", func() {
 			})
 		})
 	})
+
+	Describe("TfServiceDefinitionV1InstanceAction", func() {
+		var action tf.TfServiceDefinitionV1InstanceAction
+
+		BeforeEach(func() {
+			action = tf.TfServiceDefinitionV1InstanceAction{
+				Name:        "rotate-password",
+				Description: "Creates a new admin password",
+				Replace:     []string{"module.instance.random_password.admin"},
+			}
+		})
+
+		It("accepts an action that replaces resources", func() {
+			Expect(action.Validate()).To(BeNil())
+		})
+
+		It("accepts an action with a template", func() {
+			action.Replace = nil
+			action.Template = `variable "snapshot_name" { type = string }`
+			Expect(action.Validate()).To(BeNil())
+		})
+
+		It("requires either a template or resources to replace", func() {
+			action.Replace = nil
+			Expect(action.Validate()).To(MatchError("expected exactly one, got neither: replace, template"))
+
+			action.Replace = []string{"module.instance.random_password.admin"}
+			action.Template = `variable "snapshot_name" { type = string }`
+			Expect(action.Validate()).To(MatchError("expected exactly one, got both: replace, template"))
+		})
+
+		It("does not allow user inputs when replacing resources", func() {
+			action.UserInputs = []broker.BrokerVariable{{FieldName: "length", Type: broker.JSONTypeInteger, Details: "length"}}
+			Expect(action.Validate()).To(MatchError("must not set the field(s): user_inputs"))
+		})
+
+		It("requires a valid name", func() {
+			action.Name = "rotate password"
+			Expect(action.Validate()).To(MatchError(ContainSubstring("field must match '^[a-zA-Z0-9-\\.]+$': name")))
+		})
+
+		It("converts to a broker action that runs on its own deployment", func() {
+			instanceAction := action.ToInstanceAction()
+
+			Expect(instanceAction.Name).To(Equal("rotate-password"))
+			Expect(instanceAction.Description).To(Equal("Creates a new admin password"))
+			Expect(instanceAction.ComputedVariables).To(ContainElement(varcontext.DefaultVariable{
+				Name:      "tf_id",
+				Default:   "tf:${request.instance_id}:action:rotate-password",
+				Overwrite: true,
+			}))
+		})
+	})
+
+	Describe("StateMigration", func() {
+		var migration tf.StateMigration
+
+		BeforeEach(func() {
+			migration = tf.StateMigration{
+				Version: "1.2.0",
+				Moves:   []tf.StateMigrationMove{{From: "random_string.name", To: "module.names.random_string.name"}},
+				Removes: []string{"random_pet.name"},
+				Imports: []tf.StateMigrationImport{{Address: "random_string.password", ID: "${instance_name}"}},
+			}
+		})
+
+		It("accepts a valid migration", func() {
+			Expect(migration.Validate()).To(BeNil())
+		})
+
+		It("requires at least one change", func() {
+			migration = tf.StateMigration{Version: "1.2.0"}
+			Expect(migration.Validate()).To(MatchError("expected exactly one, got neither: imports, moves, removes"))
+		})
+
+		It("rejects invalid values", func() {
+			migration.Version = "latest"
+			migration.Moves[0].To = ""
+			migration.Imports[0].ID = ""
+
+			err := migration.Validate()
+			Expect(err).To(MatchError(ContainSubstring("invalid value: latest: version")))
+			Expect(err).To(MatchError(ContainSubstring("missing field(s): imports[0].id, moves[0].to")))
+		})
+
+		It("rejects duplicate versions in an action", func() {
+			action := tf.TfServiceDefinitionV1Action{StateMigrations: []tf.StateMigration{migration, migration}}
+			Expect(action.Validate()).To(MatchError(ContainSubstring("duplicated value, must be unique: 1.2.0: state_migrations[1].version")))
+		})
+	})
+
+	Describe("TfServiceDefinitionV1Retry", func() {
+		var retry tf.TfServiceDefinitionV1Retry
+
+		BeforeEach(func() {
+			retry = tf.TfServiceDefinitionV1Retry{
+				MaxAttempts: 3,
+				Backoff:     "30s",
+				MaxBackoff:  "2m",
+				Errors:      []string{`RequestLimitExceeded`, `InvalidParameterValue: .* not found`},
+			}
+		})
+
+		It("accepts a valid retry policy", func() {
+			Expect(retry.Validate()).To(BeNil())
+		})
+
+		It("accepts no retry policy", func() {
+			retry = tf.TfServiceDefinitionV1Retry{}
+			Expect(retry.Validate()).To(BeNil())
+		})
+
+		It("requires errors to match when retries are enabled", func() {
+			retry.Errors = nil
+			Expect(retry.Validate()).To(MatchError("missing field(s): errors"))
+		})
+
+		It("rejects invalid values", func() {
+			retry.MaxAttempts = -1
+			retry.Backoff = "soon"
+			retry.Errors = []string{`(unclosed`}
+
+			err := retry.Validate()
+			Expect(err).To(MatchError(ContainSubstring("invalid value: -1: max_attempts")))
+			Expect(err).To(MatchError(ContainSubstring("invalid value: soon: backoff")))
+			Expect(err).To(MatchError(ContainSubstring("invalid value: (unclosed: errors[0]")))
+		})
+
+		It("converts to a retry policy", func() {
+			policy, err := retry.ToRetryPolicy()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(policy.Enabled()).To(BeTrue())
+			Expect(policy.MaxAttempts).To(Equal(3))
+			Expect(policy.Backoff).To(Equal(30 * time.Second))
+			Expect(policy.MaxBackoff).To(Equal(2 * time.Minute))
+			Expect(policy.Errors).To(HaveLen(2))
+			Expect(policy.Errors[1].MatchString("InvalidParameterValue: subnet not found")).To(BeTrue())
+		})
+
+		It("uses the default backoff", func() {
+			retry.Backoff = ""
+			retry.MaxBackoff = ""
+
+			policy, err := retry.ToRetryPolicy()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(policy.Backoff).To(Equal(10 * time.Second))
+			Expect(policy.MaxBackoff).To(Equal(5 * time.Minute))
+		})
+	})
 })
@@ -3,7 +3,10 @@ package tf
 import (
 	"errors"
 	"fmt"
+	"time"
 
+	"github.com/cloudfoundry/cloud-service-broker/internal/tfstatebackend"
+	"github.com/cloudfoundry/cloud-service-broker/internal/webhook"
 	"github.com/cloudfoundry/cloud-service-broker/pkg/featureflags"
 
 	"github.com/cloudfoundry/cloud-service-broker/internal/storage"
@@ -13,12 +16,14 @@ import (
 )
 
 type DeploymentManager struct {
-	store broker.ServiceProviderStorage
+	store            broker.ServiceProviderStorage
+	brokerpakVersion string
 }
 
-func NewDeploymentManager(store broker.ServiceProviderStorage) *DeploymentManager {
+func NewDeploymentManager(store broker.ServiceProviderStorage, brokerpakVersion string) *DeploymentManager {
 	return &DeploymentManager{
-		store: store,
+		store:            store,
+		brokerpakVersion: brokerpakVersion,
 	}
 }
 
@@ -37,7 +42,32 @@ func (d *DeploymentManager) CreateAndSaveDeployment(deploymentID string, workspa
 
 	deployment.Workspace = workspace
 
-	return deployment, d.store.StoreTerraformDeployment(deployment)
+	return deployment, d.storeDeployment(&deployment)
+}
+
+// CreateOrUpdateDeployment stores the workspace in the deployment, creating the deployment if needed.
+// Unlike CreateAndSaveDeployment, the Terraform state of an existing deployment is kept, and it fails
+// when an operation is in progress on the deployment.
+func (d *DeploymentManager) CreateOrUpdateDeployment(deploymentID string, workspace *workspace.TerraformWorkspace) (storage.TerraformDeployment, error) {
+	deployment := storage.TerraformDeployment{ID: deploymentID}
+	exists, err := d.store.ExistsTerraformDeployment(deploymentID)
+	switch {
+	case err != nil:
+		return deployment, err
+	case exists:
+		deployment, err = d.store.GetTerraformDeployment(deploymentID)
+		if err != nil {
+			return deployment, err
+		}
+		if deployment.LastOperationState == InProgress {
+			return deployment, fmt.Errorf("%s operation is in progress for deployment %q", deployment.LastOperationType, deploymentID)
+		}
+		workspace.State = deployment.TFWorkspace().State
+	}
+
+	deployment.Workspace = workspace
+
+	return deployment, d.storeDeployment(&deployment)
 }
 
 func (d *DeploymentManager) MarkOperationStarted(deployment *storage.TerraformDeployment, operationType string) error {
@@ -45,18 +75,107 @@ func (d *DeploymentManager) MarkOperationStarted(deployment *storage.TerraformDe
 	deployment.LastOperationState = InProgress
 	deployment.LastOperationMessage = fmt.Sprintf("%s %s", operationType, InProgress)
 
-	if err := d.store.StoreTerraformDeployment(*deployment); err != nil {
+	if err := d.storeDeployment(deployment); err != nil {
 		return err
 	}
 
+	instanceID, _ := parseTfID(deployment.ID)
+	err := d.store.StoreOperation(storage.Operation{
+		DeploymentID:        deployment.ID,
+		ServiceInstanceGUID: instanceID,
+		Type:                operationType,
+		StartedAt:           time.Now(),
+		BrokerpakVersion:    d.brokerpakVersion,
+		State:               InProgress,
+		Message:             deployment.LastOperationMessage,
+	})
+	if err != nil {
+		return err
+	}
+
+	// The workspace directory is kept for the length of the operation, so that Terraform is
+	// only initialized again when the modules change. It is removed when the operation finishes.
+	ws, ok := deployment.Workspace.(*workspace.TerraformWorkspace)
+	if !ok {
+		return nil
+	}
+	if viper.GetBool(featureflags.PersistentWorkspacesEnabled) {
+		ws.KeepDirectory()
+	}
+	if backend := tfstatebackend.Backend(deployment.ID); backend != nil {
+		if err := d.prepareHTTPBackend(deployment.ID, ws); err != nil {
+			return err
+		}
+		ws.UseHTTPBackend(backend)
+	}
+
 	return nil
 }
 
+// prepareHTTPBackend puts the state of the workspace in the HTTP backend, so that Terraform can
+// save the state while the operation runs
+func (d *DeploymentManager) prepareHTTPBackend(deploymentID string, ws *workspace.TerraformWorkspace) error {
+	// A state that is already in the backend was left by an operation that was interrupted,
+	// and is newer than the state in the workspace.
+	state, err := d.store.GetTerraformState(deploymentID)
+	switch {
+	case err != nil:
+		return err
+	case state != nil:
+		ws.State = state
+	case ws.HasState():
+		if err := d.store.StoreTerraformState(deploymentID, ws.State); err != nil {
+			return err
+		}
+	}
+
+	// There is only one operation at a time on a deployment, so a lock that is held was
+	// left by an operation that was interrupted
+	return d.store.UnlockTerraformState(deploymentID, "")
+}
+
+// finishHTTPBackend moves the state from the HTTP backend back to the workspace
+func (d *DeploymentManager) finishHTTPBackend(deploymentID string, ws *workspace.TerraformWorkspace) error {
+	ws.UseHTTPBackend(nil)
+	tfstatebackend.Revoke(deploymentID)
+
+	state, err := d.store.GetTerraformState(deploymentID)
+	switch {
+	case err != nil:
+		return err
+	case state != nil:
+		ws.State = state
+	}
+
+	return d.store.DeleteTerraformState(deploymentID)
+}
+
+// MarkOperationRetrying records in the operation status that an attempt at a Terraform
+// command failed, and that the command will be retried
+func (d *DeploymentManager) MarkOperationRetrying(deployment *storage.TerraformDeployment, attempt, maxAttempts int, err error) error {
+	deployment.LastOperationMessage = fmt.Sprintf("%s %s: attempt %d of %d failed, retrying: %s", deployment.LastOperationType, InProgress, attempt, maxAttempts, err)
+
+	return d.storeDeployment(deployment)
+}
+
 func (d *DeploymentManager) MarkOperationFinished(deployment *storage.TerraformDeployment, err error) error {
+	if ws, ok := deployment.Workspace.(*workspace.TerraformWorkspace); ok {
+		ws.RemoveDirectory()
+
+		// The backend is only finished when the operation started with it: otherwise Terraform wrote
+		// the state to the workspace, and a state in the backend belongs to an interrupted operation.
+		// When the state cannot be moved back, it stays in the backend for the next operation.
+		if ws.UsesHTTPBackend() {
+			if backendErr := d.finishHTTPBackend(deployment.ID, ws); backendErr != nil && err == nil {
+				err = backendErr
+			}
+		}
+	}
+
 	if err == nil {
 		lastOperationMessage := fmt.Sprintf("%s %s", deployment.LastOperationType, Succeeded)
 		workspace := deployment.Workspace
-		outputs, err := workspace.Outputs(workspace.ModuleInstances()[0].InstanceName)
+		outputs, err := workspace.RedactedOutputs(workspace.ModuleInstances()[0].InstanceName)
 		if err == nil {
 			if status, ok := outputs["status"]; ok {
 				lastOperationMessage = fmt.Sprintf("%s %s: %v", deployment.LastOperationType, Succeeded, status)
@@ -69,7 +188,112 @@ func (d *DeploymentManager) MarkOperationFinished(deployment *storage.TerraformD
 		deployment.LastOperationMessage = fmt.Errorf("%s %s: %w", deployment.LastOperationType, Failed, err).Error()
 	}
 
-	return d.store.StoreTerraformDeployment(*deployment)
+	// The result of the operation must not be lost, so a deployment that was changed while the
+	// operation ran is read again, and the result of the operation is applied to the current copy
+	err = d.storeDeployment(deployment)
+	if storage.IsConflict(err) {
+		var current storage.TerraformDeployment
+		if current, err = d.store.GetTerraformDeployment(deployment.ID); err == nil {
+			applyOperationResult(&current, *deployment)
+			if err = d.storeDeployment(&current); err == nil {
+				*deployment = current
+			}
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := d.finishOperation(*deployment); err != nil {
+		return err
+	}
+
+	return d.notifyOperationFinished(*deployment)
+}
+
+// applyOperationResult applies the result of an operation to a copy of the deployment that was changed while the
+// operation ran: the last operation, and the Terraform state and state migrations of the workspace. The other
+// changes to the deployment, such as a new template, are kept.
+func applyOperationResult(current *storage.TerraformDeployment, finished storage.TerraformDeployment) {
+	current.LastOperationType = finished.LastOperationType
+	current.LastOperationState = finished.LastOperationState
+	current.LastOperationMessage = finished.LastOperationMessage
+
+	currentWorkspace, ok := current.Workspace.(*workspace.TerraformWorkspace)
+	finishedWorkspace, finishedOK := finished.Workspace.(*workspace.TerraformWorkspace)
+	if !ok || !finishedOK {
+		current.Workspace = finished.Workspace
+		return
+	}
+
+	currentWorkspace.State = finishedWorkspace.State
+	currentWorkspace.AppliedStateMigrations = finishedWorkspace.AppliedStateMigrations
+	currentWorkspace.PendingStateMigrations = finishedWorkspace.PendingStateMigrations
+}
+
+// finishOperation records the result of the operation in the operation history
+func (d *DeploymentManager) finishOperation(deployment storage.TerraformDeployment) error {
+	instanceID, _ := parseTfID(deployment.ID)
+	operation := storage.Operation{
+		DeploymentID:        deployment.ID,
+		ServiceInstanceGUID: instanceID,
+		Type:                deployment.LastOperationType,
+		StartedAt:           time.Now(),
+		FinishedAt:          time.Now(),
+		BrokerpakVersion:    d.brokerpakVersion,
+		State:               deployment.LastOperationState,
+		Message:             deployment.LastOperationMessage,
+	}
+
+	// There is no state when the operation failed before Terraform ran
+	if tfVersion, err := deployment.Workspace.StateVersion(); err == nil {
+		operation.TerraformVersion = tfVersion.String()
+	}
+
+	return d.store.FinishOperation(operation)
+}
+
+// storeDeployment stores the deployment, and records in it the version that is now current.
+// It fails with a storage.ConflictError when the deployment was changed since it was read.
+func (d *DeploymentManager) storeDeployment(deployment *storage.TerraformDeployment) error {
+	if err := d.store.StoreTerraformDeployment(*deployment); err != nil {
+		return err
+	}
+	deployment.Version++
+	return nil
+}
+
+// notifyOperationFinished queues a webhook for each configured target
+func (d *DeploymentManager) notifyOperationFinished(deployment storage.TerraformDeployment) error {
+	targets, err := webhook.ParseTargets()
+	switch {
+	case err != nil:
+		return fmt.Errorf("error queueing webhooks: %w", err)
+	case len(targets) == 0:
+		return nil
+	}
+
+	instanceID, bindingID := parseTfID(deployment.ID)
+	event := webhook.Event{
+		InstanceID:    instanceID,
+		BindingID:     bindingID,
+		OperationType: deployment.LastOperationType,
+		State:         deployment.LastOperationState,
+		Message:       deployment.LastOperationMessage,
+		Time:          time.Now(),
+	}
+
+	// The instance details may not have been stored yet if the operation failed quickly
+	if instance, err := d.store.GetServiceInstanceDetails(instanceID); err == nil {
+		event.ServiceID = instance.ServiceGUID
+		event.PlanID = instance.PlanGUID
+	}
+
+	if err := webhook.Enqueue(d.store, targets, event); err != nil {
+		return fmt.Errorf("error queueing webhooks: %w", err)
+	}
+
+	return nil
 }
 
 func (d *DeploymentManager) OperationStatus(deploymentID string) (bool, string, error) {
@@ -88,6 +312,10 @@ func (d *DeploymentManager) OperationStatus(deploymentID string) (bool, string,
 	}
 }
 
+// UpdateWorkspaceHCL replaces the template of a deployment with the template of the service definition, and
+// queues the state migrations that the deployment has not had. It does nothing unless brokerpak updates are
+// enabled: state migrations move resources to the addresses of the new template, so they must not be run on
+// a deployment that keeps its old template.
 func (d *DeploymentManager) UpdateWorkspaceHCL(deploymentID string, serviceDefinitionAction TfServiceDefinitionV1Action, templateVars map[string]interface{}) error {
 	if !viper.GetBool(featureflags.DynamicHCLEnabled) {
 		return nil
@@ -108,9 +336,17 @@ func (d *DeploymentManager) UpdateWorkspaceHCL(deploymentID string, serviceDefin
 	}
 
 	workspace.State = currentWorkspace.State
+	workspace.AppliedStateMigrations = currentWorkspace.AppliedStateMigrations
+	workspace.PendingStateMigrations = currentWorkspace.PendingStateMigrations
+
+	migrations, err := serviceDefinitionAction.workspaceStateMigrations(templateVars)
+	if err != nil {
+		return err
+	}
+	workspace.AddStateMigrations(migrations)
 
 	deployment.Workspace = workspace
-	if err := d.store.StoreTerraformDeployment(deployment); err != nil {
+	if err := d.storeDeployment(&deployment); err != nil {
 		return fmt.Errorf("terraform provider create failed: %w", err)
 	}
 
@@ -120,3 +356,11 @@ func (d *DeploymentManager) UpdateWorkspaceHCL(deploymentID string, serviceDefin
 func (d *DeploymentManager) GetTerraformDeployment(deploymentID string) (storage.TerraformDeployment, error) {
 	return d.store.GetTerraformDeployment(deploymentID)
 }
+
+func (d *DeploymentManager) ExistsTerraformDeployment(deploymentID string) (bool, error) {
+	return d.store.ExistsTerraformDeployment(deploymentID)
+}
+
+func (d *DeploymentManager) DeleteTerraformDeployment(deploymentID string) error {
+	return d.store.DeleteTerraformDeployment(deploymentID)
+}
@@ -1,19 +1,32 @@
 package tf_test
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
 
 	"github.com/cloudfoundry/cloud-service-broker/pkg/featureflags"
 
 	"github.com/cloudfoundry/cloud-service-broker/pkg/providers/tf/workspace/workspacefakes"
 
 	"github.com/cloudfoundry/cloud-service-broker/internal/storage"
+	"github.com/cloudfoundry/cloud-service-broker/internal/tfstatebackend"
 	"github.com/cloudfoundry/cloud-service-broker/pkg/broker"
 	"github.com/cloudfoundry/cloud-service-broker/pkg/broker/brokerfakes"
 	"github.com/cloudfoundry/cloud-service-broker/pkg/providers/tf"
+	"github.com/cloudfoundry/cloud-service-broker/pkg/providers/tf/command"
+	"github.com/cloudfoundry/cloud-service-broker/pkg/providers/tf/executor"
+	"github.com/cloudfoundry/cloud-service-broker/pkg/providers/tf/executor/executorfakes"
 	"github.com/cloudfoundry/cloud-service-broker/pkg/providers/tf/workspace"
+	"github.com/cloudfoundry/cloud-service-broker/utils"
+	"github.com/hashicorp/go-version"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
 	"github.com/spf13/viper"
 )
 
@@ -28,7 +41,7 @@ var _ = Describe("DeploymentManager", func() {
 
 		BeforeEach(func() {
 			fakeStore = brokerfakes.FakeServiceProviderStorage{}
-			deploymentManager = tf.NewDeploymentManager(&fakeStore)
+			deploymentManager = tf.NewDeploymentManager(&fakeStore, "")
 			ws = &workspace.TerraformWorkspace{
 				Modules: []workspace.ModuleDefinition{{
 					Name:       "fake module name",
@@ -52,6 +65,9 @@ var _ = Describe("DeploymentManager", func() {
 			By("validating a call to store was made")
 			Expect(fakeStore.StoreTerraformDeploymentCallCount()).To(Equal(1))
 			storedDeployment := fakeStore.StoreTerraformDeploymentArgsForCall(0)
+			Expect(storedDeployment.Version).To(Equal(0))
+			Expect(actualDeployment.Version).To(Equal(1))
+			storedDeployment.Version = actualDeployment.Version
 			Expect(storedDeployment).To(Equal(actualDeployment))
 		})
 
@@ -68,6 +84,7 @@ var _ = Describe("DeploymentManager", func() {
 					LastOperationType:    "provision",
 					LastOperationState:   "in progress",
 					LastOperationMessage: "test",
+					Version:              3,
 				}
 				fakeStore.ExistsTerraformDeploymentReturns(true, nil)
 				fakeStore.GetTerraformDeploymentReturns(existingDeployment, nil)
@@ -86,6 +103,9 @@ var _ = Describe("DeploymentManager", func() {
 				By("validating a call to store was made")
 				Expect(fakeStore.StoreTerraformDeploymentCallCount()).To(Equal(1))
 				storedDeployment := fakeStore.StoreTerraformDeploymentArgsForCall(0)
+				Expect(storedDeployment.Version).To(Equal(3))
+				Expect(actualDeployment.Version).To(Equal(4))
+				storedDeployment.Version = actualDeployment.Version
 				Expect(storedDeployment).To(Equal(actualDeployment))
 			})
 		})
@@ -109,6 +129,84 @@ var _ = Describe("DeploymentManager", func() {
 
 	})
 
+	Describe("CreateOrUpdateDeployment", func() {
+		var (
+			fakeStore         brokerfakes.FakeServiceProviderStorage
+			deploymentManager *tf.DeploymentManager
+			ws                *workspace.TerraformWorkspace
+			deploymentID      string
+		)
+
+		BeforeEach(func() {
+			fakeStore = brokerfakes.FakeServiceProviderStorage{}
+			deploymentManager = tf.NewDeploymentManager(&fakeStore, "")
+			ws = &workspace.TerraformWorkspace{
+				Modules: []workspace.ModuleDefinition{{
+					Name:       "fake module name",
+					Definition: "fake definition",
+				}},
+			}
+			deploymentID = "tf:instance:action:snapshot"
+		})
+
+		It("stores a new deployment", func() {
+			actualDeployment, err := deploymentManager.CreateOrUpdateDeployment(deploymentID, ws)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(actualDeployment.ID).To(Equal(deploymentID))
+			Expect(actualDeployment.Workspace).To(Equal(ws))
+			Expect(fakeStore.StoreTerraformDeploymentCallCount()).To(Equal(1))
+			storedDeployment := fakeStore.StoreTerraformDeploymentArgsForCall(0)
+			Expect(actualDeployment.Version).To(Equal(storedDeployment.Version + 1))
+			storedDeployment.Version = actualDeployment.Version
+			Expect(storedDeployment).To(Equal(actualDeployment))
+		})
+
+		It("keeps the state of an existing deployment", func() {
+			fakeStore.ExistsTerraformDeploymentReturns(true, nil)
+			fakeStore.GetTerraformDeploymentReturns(storage.TerraformDeployment{
+				ID:                 deploymentID,
+				Workspace:          &workspace.TerraformWorkspace{State: []byte("existing state")},
+				LastOperationType:  "action",
+				LastOperationState: "succeeded",
+			}, nil)
+
+			actualDeployment, err := deploymentManager.CreateOrUpdateDeployment(deploymentID, ws)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(actualDeployment.Workspace).To(Equal(ws))
+			Expect(ws.Modules[0].Name).To(Equal("fake module name"))
+			Expect(ws.State).To(Equal([]byte("existing state")))
+			storedDeployment := fakeStore.StoreTerraformDeploymentArgsForCall(0)
+			Expect(actualDeployment.Version).To(Equal(storedDeployment.Version + 1))
+			storedDeployment.Version = actualDeployment.Version
+			Expect(storedDeployment).To(Equal(actualDeployment))
+		})
+
+		It("fails, when an operation is in progress", func() {
+			fakeStore.ExistsTerraformDeploymentReturns(true, nil)
+			fakeStore.GetTerraformDeploymentReturns(storage.TerraformDeployment{
+				ID:                 deploymentID,
+				Workspace:          &workspace.TerraformWorkspace{},
+				LastOperationType:  "action",
+				LastOperationState: "in progress",
+			}, nil)
+
+			_, err := deploymentManager.CreateOrUpdateDeployment(deploymentID, ws)
+
+			Expect(err).To(MatchError(`action operation is in progress for deployment "tf:instance:action:snapshot"`))
+			Expect(fakeStore.StoreTerraformDeploymentCallCount()).To(BeZero())
+		})
+
+		It("fails, when checking if deployment exists fails", func() {
+			fakeStore.ExistsTerraformDeploymentReturns(true, errors.New("failed to check"))
+
+			_, err := deploymentManager.CreateOrUpdateDeployment(deploymentID, ws)
+
+			Expect(err).To(MatchError("failed to check"))
+		})
+	})
+
 	Describe("MarkOperationStarted", func() {
 		var (
 			fakeStore          brokerfakes.FakeServiceProviderStorage
@@ -118,7 +216,7 @@ var _ = Describe("DeploymentManager", func() {
 
 		BeforeEach(func() {
 			fakeStore = brokerfakes.FakeServiceProviderStorage{}
-			deploymentManager = tf.NewDeploymentManager(&fakeStore)
+			deploymentManager = tf.NewDeploymentManager(&fakeStore, "")
 			existingDeployment = storage.TerraformDeployment{
 				ID: "tf:instance:binding",
 				Workspace: &workspace.TerraformWorkspace{
@@ -142,6 +240,32 @@ var _ = Describe("DeploymentManager", func() {
 			Expect(storedDeployment.LastOperationType).To(Equal("provision"))
 			Expect(storedDeployment.LastOperationState).To(Equal("in progress"))
 			Expect(storedDeployment.LastOperationMessage).To(Equal("provision in progress"))
+			Expect(existingDeployment.Version).To(Equal(storedDeployment.Version + 1))
+		})
+
+		It("adds the operation to the history", func() {
+			deploymentManager = tf.NewDeploymentManager(&fakeStore, "1.2.3")
+
+			err := deploymentManager.MarkOperationStarted(&existingDeployment, "bind")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeStore.StoreOperationCallCount()).To(Equal(1))
+			operation := fakeStore.StoreOperationArgsForCall(0)
+			Expect(operation.DeploymentID).To(Equal("tf:instance:binding"))
+			Expect(operation.ServiceInstanceGUID).To(Equal("instance"))
+			Expect(operation.Type).To(Equal("bind"))
+			Expect(operation.StartedAt).To(BeTemporally("~", time.Now(), time.Second))
+			Expect(operation.FinishedAt).To(BeZero())
+			Expect(operation.BrokerpakVersion).To(Equal("1.2.3"))
+			Expect(operation.State).To(Equal("in progress"))
+		})
+
+		It("fails, when the operation cannot be added to the history", func() {
+			fakeStore.StoreOperationReturns(errors.New("boom"))
+
+			err := deploymentManager.MarkOperationStarted(&existingDeployment, "provision")
+
+			Expect(err).To(MatchError("boom"))
 		})
 
 		It("fails, when storing deployment fails", func() {
@@ -149,6 +273,206 @@ var _ = Describe("DeploymentManager", func() {
 
 			err := deploymentManager.MarkOperationStarted(&existingDeployment, "provision")
 
+			Expect(err).To(MatchError("couldn't store deployment"))
+			Expect(existingDeployment.Version).To(Equal(0))
+		})
+
+		It("fails, when the deployment was changed since it was read", func() {
+			fakeStore.StoreTerraformDeploymentReturns(&storage.ConflictError{Kind: "terraform deployment", ID: "tf:instance:binding"})
+
+			err := deploymentManager.MarkOperationStarted(&existingDeployment, "provision")
+
+			Expect(storage.IsConflict(err)).To(BeTrue())
+			Expect(fakeStore.GetTerraformDeploymentCallCount()).To(BeZero())
+		})
+
+		When("persistent workspaces are enabled", func() {
+			BeforeEach(func() {
+				viper.Set(featureflags.PersistentWorkspacesEnabled, true)
+			})
+
+			AfterEach(func() {
+				viper.Set(featureflags.PersistentWorkspacesEnabled, false)
+			})
+
+			It("keeps the workspace directory until the operation finishes", func() {
+				ws, err := workspace.NewWorkspace(map[string]interface{}{}, `variable "name" { type = string }`, nil, nil, nil, nil)
+				Expect(err).NotTo(HaveOccurred())
+				existingDeployment.Workspace = ws
+
+				var dirs []string
+				fakeExecutor := &executorfakes.FakeTerraformExecutor{}
+				fakeExecutor.ExecuteStub = func(_ context.Context, c *exec.Cmd) (executor.ExecutionOutput, error) {
+					dirs = append(dirs, c.Dir)
+					return executor.ExecutionOutput{}, os.WriteFile(filepath.Join(c.Dir, "terraform.tfstate"), []byte(`{}`), 0644)
+				}
+
+				Expect(deploymentManager.MarkOperationStarted(&existingDeployment, "provision")).To(Succeed())
+				_, err = ws.Execute(context.TODO(), fakeExecutor, command.NewApply())
+				Expect(err).NotTo(HaveOccurred())
+				_, err = ws.Execute(context.TODO(), fakeExecutor, command.NewApply())
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(dirs).To(HaveLen(2))
+				Expect(dirs[1]).To(Equal(dirs[0]))
+				Expect(dirs[0]).To(BeADirectory())
+
+				Expect(deploymentManager.MarkOperationFinished(&existingDeployment, errors.New("boom"))).To(Succeed())
+				Expect(dirs[0]).NotTo(BeADirectory())
+			})
+		})
+
+		When("the Terraform HTTP backend is enabled", func() {
+			BeforeEach(func() {
+				viper.Set(featureflags.TerraformHTTPBackendEnabled, true)
+				viper.Set("api.port", "8080")
+				viper.Set("api.user", "fake-user")
+				viper.Set("api.password", "fake-password")
+				tfstatebackend.NewHandler(nil, utils.NewLogger("deployment-manager-test"))
+			})
+
+			AfterEach(func() {
+				viper.Set(featureflags.TerraformHTTPBackendEnabled, false)
+				viper.Set("api.port", "")
+				viper.Set("api.user", "")
+				viper.Set("api.password", "")
+			})
+
+			It("keeps the state in the backend until the operation finishes", func() {
+				ws, err := workspace.NewWorkspace(map[string]interface{}{}, `variable "name" { type = string }`, nil, nil, nil, nil)
+				Expect(err).NotTo(HaveOccurred())
+				ws.State = []byte(`{"serial":1}`)
+				existingDeployment.Workspace = ws
+
+				var backendConfig []byte
+				fakeExecutor := &executorfakes.FakeTerraformExecutor{}
+				fakeExecutor.ExecuteStub = func(_ context.Context, c *exec.Cmd) (executor.ExecutionOutput, error) {
+					if c.Args[1] == "state" {
+						return executor.ExecutionOutput{StdOut: `{"serial":2}`}, nil
+					}
+					Expect(filepath.Join(c.Dir, "terraform.tfstate")).NotTo(BeAnExistingFile())
+					backendConfig, err = os.ReadFile(filepath.Join(c.Dir, "csb_backend.tf.json"))
+					return executor.ExecutionOutput{}, err
+				}
+
+				Expect(deploymentManager.MarkOperationStarted(&existingDeployment, "provision")).To(Succeed())
+				Expect(ws.UsesHTTPBackend()).To(BeTrue())
+				Expect(fakeStore.StoreTerraformStateCallCount()).To(Equal(1))
+				id, state := fakeStore.StoreTerraformStateArgsForCall(0)
+				Expect(id).To(Equal("tf:instance:binding"))
+				Expect(state).To(Equal([]byte(`{"serial":1}`)))
+				Expect(fakeStore.UnlockTerraformStateCallCount()).To(Equal(1))
+				id, lockID := fakeStore.UnlockTerraformStateArgsForCall(0)
+				Expect(id).To(Equal("tf:instance:binding"))
+				Expect(lockID).To(BeEmpty())
+
+				_, err = ws.Execute(context.TODO(), fakeExecutor, command.NewApply())
+				Expect(err).NotTo(HaveOccurred())
+				var receiver struct {
+					Terraform struct {
+						Backend struct {
+							HTTP map[string]string `json:"http"`
+						} `json:"backend"`
+					} `json:"terraform"`
+				}
+				Expect(json.Unmarshal(backendConfig, &receiver)).To(Succeed())
+				Expect(receiver.Terraform.Backend.HTTP).To(MatchAllKeys(Keys{
+					"address":        Equal("http://127.0.0.1:8080/tfstate/tf:instance:binding"),
+					"lock_address":   Equal("http://127.0.0.1:8080/tfstate/tf:instance:binding"),
+					"unlock_address": Equal("http://127.0.0.1:8080/tfstate/tf:instance:binding"),
+					"username":       Equal("deployment"),
+					"password":       MatchRegexp(`^[0-9a-f]{64}$`),
+				}))
+				Expect(ws.State).To(Equal([]byte(`{"serial":2}`)))
+
+				fakeStore.GetTerraformStateReturns([]byte(`{"serial":3}`), nil)
+				Expect(deploymentManager.MarkOperationFinished(&existingDeployment, nil)).To(Succeed())
+				Expect(ws.State).To(Equal([]byte(`{"serial":3}`)))
+				Expect(fakeStore.DeleteTerraformStateCallCount()).To(Equal(1))
+				Expect(fakeStore.DeleteTerraformStateArgsForCall(0)).To(Equal("tf:instance:binding"))
+			})
+
+			It("keeps the state in the workspace when the backend is not served", func() {
+				tfstatebackend.Stop()
+				ws := &workspace.TerraformWorkspace{
+					State:     []byte(`{"serial":1}`),
+					Instances: []workspace.ModuleInstance{{InstanceName: "instance"}},
+				}
+				existingDeployment.Workspace = ws
+				fakeStore.GetTerraformStateReturns([]byte(`{"serial":5}`), nil)
+
+				Expect(deploymentManager.MarkOperationStarted(&existingDeployment, "update")).To(Succeed())
+				Expect(ws.UsesHTTPBackend()).To(BeFalse())
+
+				ws.State = []byte(`{"serial":2}`)
+				Expect(deploymentManager.MarkOperationFinished(&existingDeployment, nil)).To(Succeed())
+
+				Expect(ws.State).To(Equal([]byte(`{"serial":2}`)))
+				Expect(fakeStore.GetTerraformStateCallCount()).To(BeZero())
+				Expect(fakeStore.StoreTerraformStateCallCount()).To(BeZero())
+				Expect(fakeStore.DeleteTerraformStateCallCount()).To(BeZero())
+			})
+
+			It("recovers the state left in the backend by an interrupted operation", func() {
+				ws := &workspace.TerraformWorkspace{State: []byte(`{"serial":1}`)}
+				existingDeployment.Workspace = ws
+				fakeStore.GetTerraformStateReturns([]byte(`{"serial":5}`), nil)
+
+				Expect(deploymentManager.MarkOperationStarted(&existingDeployment, "update")).To(Succeed())
+
+				Expect(ws.State).To(Equal([]byte(`{"serial":5}`)))
+				Expect(fakeStore.StoreTerraformStateCallCount()).To(BeZero())
+				Expect(fakeStore.UnlockTerraformStateCallCount()).To(Equal(1))
+			})
+
+			It("fails the operation when the state cannot be read from the backend", func() {
+				existingDeployment.Workspace = &workspace.TerraformWorkspace{
+					Instances: []workspace.ModuleInstance{{InstanceName: "instance"}},
+				}
+				Expect(deploymentManager.MarkOperationStarted(&existingDeployment, "validation")).To(Succeed())
+				fakeStore.GetTerraformStateReturns(nil, errors.New("boom"))
+
+				Expect(deploymentManager.MarkOperationFinished(&existingDeployment, nil)).To(Succeed())
+
+				Expect(existingDeployment.LastOperationState).To(Equal("failed"))
+				Expect(existingDeployment.LastOperationMessage).To(Equal("validation failed: boom"))
+				Expect(fakeStore.DeleteTerraformStateCallCount()).To(BeZero())
+			})
+		})
+	})
+
+	Describe("MarkOperationRetrying", func() {
+		var (
+			fakeStore          brokerfakes.FakeServiceProviderStorage
+			deploymentManager  *tf.DeploymentManager
+			existingDeployment storage.TerraformDeployment
+		)
+
+		BeforeEach(func() {
+			fakeStore = brokerfakes.FakeServiceProviderStorage{}
+			deploymentManager = tf.NewDeploymentManager(&fakeStore, "")
+			existingDeployment = storage.TerraformDeployment{
+				ID:                 "tf:instance:",
+				LastOperationType:  "provision",
+				LastOperationState: "in progress",
+			}
+		})
+
+		It("records the failed attempt in the last operation message", func() {
+			err := deploymentManager.MarkOperationRetrying(&existingDeployment, 1, 3, errors.New("RequestLimitExceeded"))
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeStore.StoreTerraformDeploymentCallCount()).To(Equal(1))
+			storedDeployment := fakeStore.StoreTerraformDeploymentArgsForCall(0)
+			Expect(storedDeployment.LastOperationState).To(Equal("in progress"))
+			Expect(storedDeployment.LastOperationMessage).To(Equal("provision in progress: attempt 1 of 3 failed, retrying: RequestLimitExceeded"))
+		})
+
+		It("fails, when storing deployment fails", func() {
+			fakeStore.StoreTerraformDeploymentReturns(errors.New("couldn't store deployment"))
+
+			err := deploymentManager.MarkOperationRetrying(&existingDeployment, 1, 3, errors.New("RequestLimitExceeded"))
+
 			Expect(err).To(MatchError("couldn't store deployment"))
 		})
 	})
@@ -165,6 +489,7 @@ var _ = Describe("DeploymentManager", func() {
 			fakeWorkspace = &workspacefakes.FakeWorkspace{}
 			fakeWorkspace.ModuleInstancesReturns([]workspace.ModuleInstance{{InstanceName: "test-name"}})
 			fakeWorkspace.OutputsReturns(map[string]interface{}{}, nil)
+			fakeWorkspace.StateVersionReturns(version.Must(version.NewVersion("1.1.6")), nil)
 			existingDeployment = storage.TerraformDeployment{
 				ID:                   "deploymentID",
 				Workspace:            fakeWorkspace,
@@ -173,7 +498,7 @@ var _ = Describe("DeploymentManager", func() {
 				LastOperationMessage: "test",
 			}
 			fakeStore = brokerfakes.FakeServiceProviderStorage{}
-			deploymentManager = tf.NewDeploymentManager(&fakeStore)
+			deploymentManager = tf.NewDeploymentManager(&fakeStore, "")
 		})
 
 		When("operation finished successfully", func() {
@@ -191,8 +516,35 @@ var _ = Describe("DeploymentManager", func() {
 				Expect(storedDeployment.LastOperationMessage).To(Equal("provision succeeded"))
 			})
 
+			It("records the result in the operation history", func() {
+				deploymentManager = tf.NewDeploymentManager(&fakeStore, "1.2.3")
+				existingDeployment.ID = "tf:instance-id:"
+
+				err := deploymentManager.MarkOperationFinished(&existingDeployment, nil)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fakeStore.FinishOperationCallCount()).To(Equal(1))
+				operation := fakeStore.FinishOperationArgsForCall(0)
+				Expect(operation.DeploymentID).To(Equal("tf:instance-id:"))
+				Expect(operation.ServiceInstanceGUID).To(Equal("instance-id"))
+				Expect(operation.Type).To(Equal("provision"))
+				Expect(operation.FinishedAt).To(BeTemporally("~", time.Now(), time.Second))
+				Expect(operation.TerraformVersion).To(Equal("1.1.6"))
+				Expect(operation.BrokerpakVersion).To(Equal("1.2.3"))
+				Expect(operation.State).To(Equal("succeeded"))
+				Expect(operation.Message).To(Equal("provision succeeded"))
+			})
+
+			It("fails, when the result cannot be recorded in the operation history", func() {
+				fakeStore.FinishOperationReturns(errors.New("boom"))
+
+				err := deploymentManager.MarkOperationFinished(&existingDeployment, nil)
+
+				Expect(err).To(MatchError("boom"))
+			})
+
 			It("sets the last operation message from the TF output status", func() {
-				fakeWorkspace.OutputsReturns(map[string]interface{}{"status": "apply completed successfully"}, nil)
+				fakeWorkspace.RedactedOutputsReturns(map[string]interface{}{"status": "apply completed successfully"}, nil)
 
 				err := deploymentManager.MarkOperationFinished(&existingDeployment, nil)
 
@@ -203,6 +555,84 @@ var _ = Describe("DeploymentManager", func() {
 				Expect(storedDeployment.LastOperationState).To(Equal("succeeded"))
 				Expect(storedDeployment.LastOperationMessage).To(Equal("provision succeeded: apply completed successfully"))
 			})
+
+			It("does not reveal a sensitive TF output status", func() {
+				fakeWorkspace.OutputsReturns(map[string]interface{}{"status": "password is hunter2"}, nil)
+				fakeWorkspace.RedactedOutputsReturns(map[string]interface{}{"status": workspace.RedactedValue}, nil)
+
+				err := deploymentManager.MarkOperationFinished(&existingDeployment, nil)
+
+				Expect(err).NotTo(HaveOccurred())
+
+				storedDeployment := fakeStore.StoreTerraformDeploymentArgsForCall(0)
+				Expect(storedDeployment.LastOperationMessage).To(Equal("provision succeeded: (sensitive value)"))
+			})
+		})
+
+		When("the deployment was changed while the operation ran", func() {
+			BeforeEach(func() {
+				existingDeployment.Version = 2
+				fakeStore.StoreTerraformDeploymentReturnsOnCall(0, &storage.ConflictError{Kind: "terraform deployment", ID: "deploymentID", Version: 2})
+				fakeStore.GetTerraformDeploymentReturns(storage.TerraformDeployment{ID: "deploymentID", Version: 5}, nil)
+			})
+
+			It("stores the result over the current version", func() {
+				err := deploymentManager.MarkOperationFinished(&existingDeployment, nil)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fakeStore.GetTerraformDeploymentArgsForCall(0)).To(Equal("deploymentID"))
+				Expect(fakeStore.StoreTerraformDeploymentCallCount()).To(Equal(2))
+				Expect(fakeStore.StoreTerraformDeploymentArgsForCall(0).Version).To(Equal(2))
+				storedDeployment := fakeStore.StoreTerraformDeploymentArgsForCall(1)
+				Expect(storedDeployment.Version).To(Equal(5))
+				Expect(storedDeployment.LastOperationState).To(Equal("succeeded"))
+				Expect(existingDeployment.Version).To(Equal(6))
+			})
+
+			It("keeps the changes that were made while the operation ran", func() {
+				existingDeployment.Workspace = &workspace.TerraformWorkspace{
+					Modules:                []workspace.ModuleDefinition{{Name: "old-module"}},
+					Instances:              []workspace.ModuleInstance{{InstanceName: "instance"}},
+					State:                  []byte(`{"serial":2}`),
+					AppliedStateMigrations: []string{"1.0.0"},
+				}
+				fakeStore.GetTerraformDeploymentReturns(storage.TerraformDeployment{
+					ID:      "deploymentID",
+					Version: 5,
+					Workspace: &workspace.TerraformWorkspace{
+						Modules:                []workspace.ModuleDefinition{{Name: "new-module"}},
+						Instances:              []workspace.ModuleInstance{{InstanceName: "instance"}},
+						State:                  []byte(`{"serial":1}`),
+						PendingStateMigrations: []workspace.StateMigration{{Version: "1.0.0"}},
+					},
+					LastOperationType:  "update",
+					LastOperationState: "in progress",
+				}, nil)
+
+				err := deploymentManager.MarkOperationFinished(&existingDeployment, nil)
+
+				Expect(err).NotTo(HaveOccurred())
+				storedDeployment := fakeStore.StoreTerraformDeploymentArgsForCall(1)
+				Expect(storedDeployment.Version).To(Equal(5))
+				Expect(storedDeployment.LastOperationType).To(Equal("provision"))
+				Expect(storedDeployment.LastOperationState).To(Equal("succeeded"))
+				storedWorkspace := storedDeployment.Workspace.(*workspace.TerraformWorkspace)
+				Expect(storedWorkspace.Modules).To(Equal([]workspace.ModuleDefinition{{Name: "new-module"}}))
+				Expect(storedWorkspace.State).To(Equal([]byte(`{"serial":2}`)))
+				Expect(storedWorkspace.AppliedStateMigrations).To(Equal([]string{"1.0.0"}))
+				Expect(storedWorkspace.PendingStateMigrations).To(BeEmpty())
+				Expect(existingDeployment.Version).To(Equal(6))
+				Expect(existingDeployment.Workspace).To(BeIdenticalTo(storedDeployment.Workspace))
+			})
+
+			It("fails when the current version cannot be read", func() {
+				fakeStore.GetTerraformDeploymentReturns(storage.TerraformDeployment{}, errors.New("boom"))
+
+				err := deploymentManager.MarkOperationFinished(&existingDeployment, nil)
+
+				Expect(err).To(MatchError("boom"))
+				Expect(fakeStore.StoreTerraformDeploymentCallCount()).To(Equal(1))
+			})
 		})
 
 		When("operation finished with an error", func() {
@@ -220,6 +650,79 @@ var _ = Describe("DeploymentManager", func() {
 				Expect(storedDeployment.LastOperationMessage).To(Equal("provision failed: operation failed dramatically"))
 			})
 		})
+
+		When("webhooks are configured", func() {
+			BeforeEach(func() {
+				existingDeployment.ID = "tf:instance-id:"
+				viper.Set("webhooks", `[{"url":"https://example.com/one","secret":"s1"},{"url":"https://example.com/two","secret":"s2"}]`)
+				fakeStore.GetServiceInstanceDetailsReturns(storage.ServiceInstanceDetails{ServiceGUID: "service-id", PlanGUID: "plan-id"}, nil)
+			})
+
+			AfterEach(func() {
+				viper.Set("webhooks", "")
+			})
+
+			It("queues a webhook delivery for each target", func() {
+				err := deploymentManager.MarkOperationFinished(&existingDeployment, nil)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeStore.GetServiceInstanceDetailsArgsForCall(0)).To(Equal("instance-id"))
+				Expect(fakeStore.StoreWebhookDeliveryCallCount()).To(Equal(2))
+				Expect(fakeStore.StoreWebhookDeliveryArgsForCall(0).URL).To(Equal("https://example.com/one"))
+				Expect(fakeStore.StoreWebhookDeliveryArgsForCall(1).URL).To(Equal("https://example.com/two"))
+
+				payload := fakeStore.StoreWebhookDeliveryArgsForCall(0).Payload
+				Expect(payload).To(MatchJSON(fakeStore.StoreWebhookDeliveryArgsForCall(1).Payload))
+				var event map[string]interface{}
+				Expect(json.Unmarshal(payload, &event)).To(Succeed())
+				Expect(event).To(MatchKeys(IgnoreExtras, Keys{
+					"instance_id":    Equal("instance-id"),
+					"service_id":     Equal("service-id"),
+					"plan_id":        Equal("plan-id"),
+					"operation_type": Equal("provision"),
+					"state":          Equal("succeeded"),
+					"message":        Equal("provision succeeded"),
+				}))
+				Expect(event).NotTo(HaveKey("binding_id"))
+			})
+
+			It("does not report the deployment of an action as a binding", func() {
+				existingDeployment.ID = "tf:instance-id:action:snapshot"
+				existingDeployment.LastOperationType = "action"
+
+				err := deploymentManager.MarkOperationFinished(&existingDeployment, nil)
+				Expect(err).NotTo(HaveOccurred())
+
+				var event map[string]interface{}
+				Expect(json.Unmarshal(fakeStore.StoreWebhookDeliveryArgsForCall(0).Payload, &event)).To(Succeed())
+				Expect(event).To(HaveKeyWithValue("instance_id", "instance-id"))
+				Expect(event).NotTo(HaveKey("binding_id"))
+			})
+
+			It("does not queue webhooks when the deployment cannot be stored", func() {
+				fakeStore.StoreTerraformDeploymentReturns(errors.New("boom"))
+
+				err := deploymentManager.MarkOperationFinished(&existingDeployment, nil)
+				Expect(err).To(MatchError("boom"))
+				Expect(fakeStore.StoreWebhookDeliveryCallCount()).To(BeZero())
+			})
+
+			It("returns an error when a delivery cannot be queued", func() {
+				fakeStore.StoreWebhookDeliveryReturns(errors.New("bang"))
+
+				err := deploymentManager.MarkOperationFinished(&existingDeployment, nil)
+				Expect(err).To(MatchError("error queueing webhooks: bang"))
+			})
+		})
+
+		When("no webhooks are configured", func() {
+			It("does not queue webhooks", func() {
+				err := deploymentManager.MarkOperationFinished(&existingDeployment, nil)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fakeStore.StoreWebhookDeliveryCallCount()).To(BeZero())
+				Expect(fakeStore.GetServiceInstanceDetailsCallCount()).To(BeZero())
+			})
+		})
 	})
 
 	Describe("OperationStatus", func() {
@@ -233,7 +736,7 @@ var _ = Describe("DeploymentManager", func() {
 
 		BeforeEach(func() {
 			fakeStore = brokerfakes.FakeServiceProviderStorage{}
-			deploymentManager = tf.NewDeploymentManager(&fakeStore)
+			deploymentManager = tf.NewDeploymentManager(&fakeStore, "")
 		})
 
 		When("last operation has succeeded", func() {
@@ -335,7 +838,7 @@ var _ = Describe("DeploymentManager", func() {
 			By("setting up fakes", func() {
 				viper.Reset()
 				store = &brokerfakes.FakeServiceProviderStorage{}
-				deploymentManager = tf.NewDeploymentManager(store)
+				deploymentManager = tf.NewDeploymentManager(store, "")
 				templateVars = map[string]interface{}{}
 			})
 
@@ -429,6 +932,49 @@ var _ = Describe("DeploymentManager", func() {
 				Expect(actualTerraformDeployment.Workspace).To(Equal(expectedWorkspace))
 			})
 
+			It("queues the state migrations that have not been applied", func() {
+				store.GetTerraformDeploymentReturns(storage.TerraformDeployment{
+					ID: id,
+					Workspace: &workspace.TerraformWorkspace{
+						State:                  []byte(terraformState),
+						AppliedStateMigrations: []string{"1.1.0"},
+					},
+				}, nil)
+				templateVars["instance_name"] = "fake-instance-name"
+				updatedProvisionSettings.StateMigrations = []tf.StateMigration{
+					{
+						Version: "1.10.0",
+						Imports: []tf.StateMigrationImport{{Address: "random_string.password", ID: "${instance_name}-password"}},
+					},
+					{
+						Version: "1.2.0",
+						Moves:   []tf.StateMigrationMove{{From: "random_string.name", To: "random_string.username"}},
+						Removes: []string{"random_pet.name"},
+					},
+					{
+						Version: "1.1.0",
+						Removes: []string{"random_pet.old"},
+					},
+				}
+
+				err := deploymentManager.UpdateWorkspaceHCL(id, updatedProvisionSettings, templateVars)
+				Expect(err).NotTo(HaveOccurred())
+
+				actualWorkspace := store.StoreTerraformDeploymentArgsForCall(0).Workspace.(*workspace.TerraformWorkspace)
+				Expect(actualWorkspace.AppliedStateMigrations).To(Equal([]string{"1.1.0"}))
+				Expect(actualWorkspace.PendingStateMigrations).To(Equal([]workspace.StateMigration{
+					{
+						Version: "1.2.0",
+						Moves:   []workspace.StateMove{{From: "random_string.name", To: "random_string.username"}},
+						Removes: []string{"random_pet.name"},
+					},
+					{
+						Version: "1.10.0",
+						Imports: []workspace.StateImport{{Address: "random_string.password", ID: "fake-instance-name-password"}},
+					},
+				}))
+			})
+
 			When("getting deployment fails", func() {
 				BeforeEach(func() {
 					store.GetTerraformDeploymentReturns(storage.TerraformDeployment{}, errors.New("boom"))
@@ -473,6 +1019,19 @@ var _ = Describe("DeploymentManager", func() {
 
 				Expect(store.StoreTerraformDeploymentCallCount()).To(BeZero())
 			})
+
+			It("does not queue state migrations, because the template is not updated", func() {
+				updatedProvisionSettings.StateMigrations = []tf.StateMigration{{
+					Version: "1.2.0",
+					Moves:   []tf.StateMigrationMove{{From: "random_string.name", To: "random_string.username"}},
+				}}
+
+				err := deploymentManager.UpdateWorkspaceHCL(id, updatedProvisionSettings, templateVars)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(store.GetTerraformDeploymentCallCount()).To(BeZero())
+				Expect(store.StoreTerraformDeploymentCallCount()).To(BeZero())
+			})
 		})
 	})
 
@@ -487,7 +1046,7 @@ var _ = Describe("DeploymentManager", func() {
 
 		BeforeEach(func() {
 			fakeStore = brokerfakes.FakeServiceProviderStorage{}
-			deploymentManager = tf.NewDeploymentManager(&fakeStore)
+			deploymentManager = tf.NewDeploymentManager(&fakeStore, "")
 			existingDeployment = storage.TerraformDeployment{
 				ID:                existingDeploymentID,
 				LastOperationType: "validation",
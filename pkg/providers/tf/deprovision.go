@@ -11,7 +11,7 @@ import (
 	"github.com/pivotal-cf/brokerapi/v8/domain"
 )
 
-// Deprovision performs a terraform destroy on the instance.
+// Deprovision performs a terraform destroy on the instance, and on the deployments of its actions.
 func (provider *TerraformProvider) Deprovision(ctx context.Context, instanceGUID string, details domain.DeprovisionDetails, vc *varcontext.VarContext) (operationID *string, err error) {
 	provider.logger.Debug("terraform-deprovision", correlation.ID(ctx), lager.Data{
 		"instance": instanceGUID,
@@ -23,7 +23,12 @@ func (provider *TerraformProvider) Deprovision(ctx context.Context, instanceGUID
 		return nil, err
 	}
 
-	if err := provider.destroy(ctx, tfID, vc.ToMap(), models.DeprovisionOperationType); err != nil {
+	actionDeployments, err := provider.idleActionDeployments(instanceGUID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := provider.destroy(ctx, tfID, vc.ToMap(), models.DeprovisionOperationType, actionDeployments...); err != nil {
 		return nil, err
 	}
 
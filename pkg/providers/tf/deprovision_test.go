@@ -145,4 +145,84 @@ var _ = Describe("Deprovision", func() {
 		Eventually(operationWasFinishedForDeployment(fakeDeploymentManager)).Should(Equal(deployment))
 		Expect(operationWasFinishedWithError(fakeDeploymentManager)()).To(MatchError(expectedError))
 	})
+
+	When("the instance has deployments of actions", func() {
+		var (
+			provider         *tf.TerraformProvider
+			actionDeployment storage.TerraformDeployment
+		)
+		actionTFID := fmt.Sprintf("tf:%s:action:snapshot", instanceGUID)
+
+		BeforeEach(func() {
+			actionDeployment = storage.TerraformDeployment{
+				ID:        actionTFID,
+				Workspace: &workspace.TerraformWorkspace{State: []byte(`{"terraform_version":"1"}`)},
+			}
+			fakeDeploymentManager.ExistsTerraformDeploymentStub = func(id string) (bool, error) {
+				return id == actionTFID, nil
+			}
+			fakeDeploymentManager.GetTerraformDeploymentStub = func(id string) (storage.TerraformDeployment, error) {
+				if id == actionTFID {
+					return actionDeployment, nil
+				}
+				return deployment, nil
+			}
+			fakeInvokerBuilder.VersionedTerraformInvokerReturns(fakeDefaultInvoker)
+
+			definition := tf.TfServiceDefinitionV1{
+				Actions: []tf.TfServiceDefinitionV1InstanceAction{
+					{Name: "rotate-password", Replace: []string{"random_password.admin"}},
+					{Name: "snapshot", Template: `variable "snapshot_name" { type = string }`},
+					{Name: "never-run", Template: `variable "name" { type = string }`},
+				},
+			}
+			provider = tf.NewTerraformProvider(executor.TFBinariesContext{DefaultTfVersion: version.Must(version.NewVersion("1"))}, fakeInvokerBuilder, fakeLogger, definition, fakeDeploymentManager)
+		})
+
+		It("destroys and deletes the action deployments before destroying the instance", func() {
+			_, err := provider.Deprovision(context.TODO(), instanceGUID, domain.DeprovisionDetails{}, deprovisionContext)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(operationWasFinishedForDeployment(fakeDeploymentManager)).Should(Equal(deployment))
+			Expect(operationWasFinishedWithError(fakeDeploymentManager)()).To(BeNil())
+
+			Expect(fakeDeploymentManager.ExistsTerraformDeploymentCallCount()).To(Equal(2))
+			Expect(fakeDeploymentManager.ExistsTerraformDeploymentArgsForCall(0)).To(Equal(actionTFID))
+
+			Expect(fakeDefaultInvoker.DestroyCallCount()).To(Equal(2))
+			_, destroyed := fakeDefaultInvoker.DestroyArgsForCall(0)
+			Expect(destroyed).To(Equal(actionDeployment.Workspace))
+			_, destroyed = fakeDefaultInvoker.DestroyArgsForCall(1)
+			Expect(destroyed).To(Equal(deployment.Workspace))
+
+			Expect(fakeDeploymentManager.MarkOperationStartedCallCount()).To(Equal(2))
+			started, operationType := fakeDeploymentManager.MarkOperationStartedArgsForCall(1)
+			Expect(started.ID).To(Equal(actionTFID))
+			Expect(operationType).To(Equal("deprovision"))
+
+			Expect(fakeDeploymentManager.DeleteTerraformDeploymentCallCount()).To(Equal(1))
+			Expect(fakeDeploymentManager.DeleteTerraformDeploymentArgsForCall(0)).To(Equal(actionTFID))
+		})
+
+		It("does not destroy the instance when destroying an action deployment fails", func() {
+			fakeDefaultInvoker.DestroyReturns(fmt.Errorf(expectedError))
+
+			_, err := provider.Deprovision(context.TODO(), instanceGUID, domain.DeprovisionDetails{}, deprovisionContext)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(operationWasFinishedForDeployment(fakeDeploymentManager)).Should(Equal(deployment))
+			Expect(operationWasFinishedWithError(fakeDeploymentManager)()).To(MatchError(fmt.Sprintf(`deprovision of deployment %q failed: %s`, actionTFID, expectedError)))
+			Expect(fakeDefaultInvoker.DestroyCallCount()).To(Equal(1))
+			Expect(fakeDeploymentManager.DeleteTerraformDeploymentCallCount()).To(BeZero())
+		})
+
+		It("fails when an action is running", func() {
+			actionDeployment.LastOperationType = "action"
+			actionDeployment.LastOperationState = "in progress"
+
+			_, err := provider.Deprovision(context.TODO(), instanceGUID, domain.DeprovisionDetails{}, deprovisionContext)
+			Expect(err).To(MatchError(fmt.Sprintf(`action operation is in progress for deployment %q`, actionTFID)))
+			Expect(fakeDeploymentManager.MarkOperationStartedCallCount()).To(BeZero())
+		})
+	})
 })
@@ -72,9 +72,10 @@ func (defaultExecutor) Execute(ctx context.Context, c *exec.Cmd) (ExecutionOutpu
 		})
 	}
 
-	logger.Info("finished process")
-	logger.Debug("terraform output", lager.Data{
-		"output": string(output),
+	// The output is not logged, because commands such as `terraform state pull` and `terraform output`
+	// print the values of sensitive outputs and resource attributes.
+	logger.Info("finished process", lager.Data{
+		"output-bytes": len(output),
 	})
 
 	if err != nil {
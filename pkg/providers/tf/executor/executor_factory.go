@@ -14,23 +14,66 @@ type TFBinariesContext struct {
 	DefaultTfVersion *version.Version
 	Params           map[string]string
 
+	// PluginCacheDir is the shared Terraform plugin cache, if one is configured
+	PluginCacheDir string
+
+	// Binaries are the releases of Terraform and OpenTofu in the brokerpak. A version
+	// that is not listed is run by Terraform.
+	Binaries []TerraformBinary
+
 	TfUpgradePath        []*version.Version
 	ProviderReplacements map[string]string
+
+	// OpenTofuProviderReplacements are made in the state before OpenTofu runs, in addition
+	// to the ProviderReplacements, to move providers to the OpenTofu registry
+	OpenTofuProviderReplacements map[string]string
+}
+
+// TerraformBinary is a release of Terraform or OpenTofu. The state of a deployment only records
+// a version, so a version is run by only one binary.
+type TerraformBinary struct {
+	Name    string
+	Version *version.Version
+}
+
+const (
+	TerraformBinaryName = "terraform"
+	OpenTofuBinaryName  = "tofu"
+)
+
+// Binary returns the binary that runs a version
+func (tfBinContext TFBinariesContext) Binary(tfVersion *version.Version) TerraformBinary {
+	return findBinary(tfBinContext.Binaries, tfVersion)
 }
 
-func NewExecutorFactory(dir string, params map[string]string, envVars map[string]string) ExecutorBuilder {
+func findBinary(binaries []TerraformBinary, tfVersion *version.Version) TerraformBinary {
+	for _, b := range binaries {
+		if b.Version.Equal(tfVersion) {
+			return b
+		}
+	}
+	return TerraformBinary{Name: TerraformBinaryName, Version: tfVersion}
+}
+
+func NewExecutorFactory(tfBinContext TFBinariesContext, envVars map[string]string, retryPolicy RetryPolicy) ExecutorBuilder {
 	return ExecutorFactory{
-		Dir:     dir,
-		Params:  params,
-		EnvVars: envVars,
+		Dir:            tfBinContext.Dir,
+		PluginCacheDir: tfBinContext.PluginCacheDir,
+		Binaries:       tfBinContext.Binaries,
+		Params:         tfBinContext.Params,
+		EnvVars:        envVars,
+		RetryPolicy:    retryPolicy,
 	}
 }
 
 type ExecutorFactory struct {
 	Dir              string
+	PluginCacheDir   string
+	Binaries         []TerraformBinary
 	DefaultTfVersion *version.Version
 	Params           map[string]string
 	EnvVars          map[string]string
+	RetryPolicy      RetryPolicy
 }
 
 //go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 -generate
@@ -44,12 +87,30 @@ func (executorFactory ExecutorFactory) VersionedExecutor(tfVersion *version.Vers
 	return CustomEnvironmentExecutor(executorFactory.EnvVars,
 		CustomEnvironmentExecutor(
 			executorFactory.Params,
-			CustomTerraformExecutor(
-				filepath.Join(executorFactory.Dir, "versions", tfVersion.String(), "terraform"),
-				executorFactory.Dir,
-				tfVersion,
-				DefaultExecutor(),
+			CustomEnvironmentExecutor(
+				executorFactory.pluginCacheEnvironment(),
+				CustomTerraformExecutor(
+					executorFactory.binary(tfVersion),
+					executorFactory.Dir,
+					tfVersion,
+					SkipUnchangedInitExecutor(
+						RetryingExecutor(executorFactory.RetryPolicy, DefaultExecutor()),
+					),
+				),
 			),
 		),
 	)
 }
+
+func (executorFactory ExecutorFactory) pluginCacheEnvironment() map[string]string {
+	if executorFactory.PluginCacheDir == "" {
+		return nil
+	}
+	return map[string]string{"TF_PLUGIN_CACHE_DIR": executorFactory.PluginCacheDir}
+}
+
+// binary is the path of the binary that runs a version
+func (executorFactory ExecutorFactory) binary(tfVersion *version.Version) string {
+	b := findBinary(executorFactory.Binaries, tfVersion)
+	return filepath.Join(executorFactory.Dir, "versions", b.Version.String(), b.Name)
+}
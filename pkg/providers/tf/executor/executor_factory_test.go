@@ -0,0 +1,64 @@
+package executor_test
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/cloudfoundry/cloud-service-broker/pkg/providers/tf/executor"
+	"github.com/hashicorp/go-version"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ExecutorFactory", func() {
+	var dir string
+
+	fakeBinary := func(tfVersion, name string) {
+		binary := filepath.Join(dir, "versions", tfVersion, name)
+		Expect(os.MkdirAll(filepath.Dir(binary), 0755)).To(Succeed())
+		Expect(os.WriteFile(binary, []byte("#!/bin/sh\necho "+name+" $TF_PLUGIN_CACHE_DIR\n"), 0755)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		fakeBinary("1.5.7", "terraform")
+		fakeBinary("1.6.0", "tofu")
+	})
+
+	run := func(factory executor.ExecutorBuilder, tfVersion string) string {
+		output, err := factory.VersionedExecutor(version.Must(version.NewVersion(tfVersion))).Execute(context.TODO(), exec.Command("terraform", "version"))
+		Expect(err).NotTo(HaveOccurred())
+		return output.StdOut
+	}
+
+	It("runs the binary of the engine of each version", func() {
+		factory := executor.NewExecutorFactory(executor.TFBinariesContext{
+			Dir: dir,
+			Binaries: []executor.TerraformBinary{
+				{Name: "terraform", Version: version.Must(version.NewVersion("1.5.7"))},
+				{Name: "tofu", Version: version.Must(version.NewVersion("1.6.0"))},
+			},
+		}, nil, executor.RetryPolicy{})
+
+		Expect(run(factory, "1.5.7")).To(HavePrefix("terraform"))
+		Expect(run(factory, "1.6.0")).To(HavePrefix("tofu"))
+		Expect(run(factory, "1.6")).To(HavePrefix("tofu"))
+	})
+
+	It("runs Terraform for versions that are not listed", func() {
+		factory := executor.NewExecutorFactory(executor.TFBinariesContext{Dir: dir}, nil, executor.RetryPolicy{})
+
+		Expect(run(factory, "1.5.7")).To(HavePrefix("terraform"))
+	})
+
+	It("sets the plugin cache directory", func() {
+		factory := executor.NewExecutorFactory(executor.TFBinariesContext{
+			Dir:            dir,
+			PluginCacheDir: "/var/cache/plugins",
+		}, nil, executor.RetryPolicy{})
+
+		Expect(run(factory, "1.5.7")).To(Equal("terraform /var/cache/plugins\n"))
+	})
+})
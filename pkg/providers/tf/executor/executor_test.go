@@ -0,0 +1,40 @@
+package executor_test
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/cloudfoundry/cloud-service-broker/pkg/providers/tf/executor"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DefaultExecutor", func() {
+	It("returns the output without logging it", func() {
+		os.Setenv("GSB_DEBUG", "true")
+		DeferCleanup(os.Unsetenv, "GSB_DEBUG")
+
+		r, w, err := os.Pipe()
+		Expect(err).NotTo(HaveOccurred())
+		stdout := os.Stdout
+		os.Stdout = w
+		DeferCleanup(func() { os.Stdout = stdout })
+
+		file := filepath.Join(GinkgoT().TempDir(), "output")
+		Expect(os.WriteFile(file, []byte("fake-secret-value\n"), 0600)).To(Succeed())
+
+		output, err := executor.DefaultExecutor().Execute(context.TODO(), exec.Command("cat", file))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(output.StdOut).To(Equal("fake-secret-value\n"))
+
+		os.Stdout = stdout
+		Expect(w.Close()).To(Succeed())
+		logs, err := io.ReadAll(r)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(logs)).To(ContainSubstring("finished process"))
+		Expect(string(logs)).NotTo(ContainSubstring("fake-secret-value"))
+	})
+})
@@ -0,0 +1,103 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/cloudfoundry/cloud-service-broker/utils"
+	"github.com/cloudfoundry/cloud-service-broker/utils/correlation"
+)
+
+// initFingerprintFile records what a directory was last initialized with
+const initFingerprintFile = "csb-init-fingerprint"
+
+// SkipUnchangedInitExecutor skips `terraform init` in a directory that was already initialized
+// by the same Terraform binary, when the modules and the dependency lock file have not changed
+// since. This only has an effect for workspace directories that are kept between commands.
+func SkipUnchangedInitExecutor(wrapped TerraformExecutor) TerraformExecutor {
+	return skipUnchangedInitExecutor{wrapped: wrapped}
+}
+
+type skipUnchangedInitExecutor struct {
+	wrapped TerraformExecutor
+}
+
+func (executor skipUnchangedInitExecutor) Execute(ctx context.Context, c *exec.Cmd) (ExecutionOutput, error) {
+	if len(c.Args) < 2 || c.Args[1] != "init" || c.Dir == "" {
+		return executor.wrapped.Execute(ctx, c)
+	}
+
+	logger := utils.NewLogger("terraform-init@" + c.Dir).WithData(correlation.ID(ctx))
+	fingerprintPath := filepath.Join(c.Dir, ".terraform", initFingerprintFile)
+
+	if fingerprint, err := initFingerprint(c); err == nil {
+		if previous, err := os.ReadFile(fingerprintPath); err == nil && string(previous) == fingerprint {
+			logger.Info("skipping unchanged init")
+			return ExecutionOutput{}, nil
+		}
+	}
+
+	output, err := executor.wrapped.Execute(ctx, c)
+	if err != nil {
+		return output, err
+	}
+
+	// init may have created or updated the lock file, so the fingerprint is taken afterwards
+	fingerprint, err := initFingerprint(c)
+	if err == nil {
+		err = os.WriteFile(fingerprintPath, []byte(fingerprint), 0644)
+	}
+	if err != nil {
+		logger.Error("failed to record init fingerprint", err, lager.Data{"path": fingerprintPath})
+	}
+
+	return output, nil
+}
+
+// initFingerprint is a hash of the inputs to `terraform init`: the binary and its arguments,
+// the Terraform files of the root and child modules, and the dependency lock file.
+func initFingerprint(c *exec.Cmd) (string, error) {
+	h := sha256.New()
+	fmt.Fprintln(h, c.Path)
+	fmt.Fprintln(h, strings.Join(c.Args[1:], " "))
+
+	err := filepath.WalkDir(c.Dir, func(p string, d fs.DirEntry, err error) error {
+		switch {
+		case err != nil:
+			return err
+		case d.IsDir() && d.Name() == ".terraform":
+			return filepath.SkipDir
+		case d.IsDir() || !isInitInput(d.Name()):
+			return nil
+		}
+
+		contents, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+
+		rel, _ := filepath.Rel(c.Dir, p)
+		fmt.Fprintf(h, "%s %d\n", rel, len(contents))
+		_, err = io.Copy(h, bytes.NewReader(contents))
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func isInitInput(name string) bool {
+	return name == ".terraform.lock.hcl" || strings.HasSuffix(name, ".tf") || strings.HasSuffix(name, ".tf.json")
+}
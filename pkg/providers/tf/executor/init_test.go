@@ -0,0 +1,107 @@
+package executor_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/cloudfoundry/cloud-service-broker/pkg/providers/tf/executor"
+	"github.com/cloudfoundry/cloud-service-broker/pkg/providers/tf/executor/executorfakes"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SkipUnchangedInitExecutor", func() {
+	var (
+		fakeExecutor *executorfakes.FakeTerraformExecutor
+		dir          string
+	)
+
+	command := func(path string, args ...string) *exec.Cmd {
+		c := exec.Command(path, args...)
+		c.Dir = dir
+		return c
+	}
+
+	initCommand := func() *exec.Cmd {
+		return command("/versions/1.1.4/terraform", "init", "-plugin-dir=/plugins", "-no-color")
+	}
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		Expect(os.WriteFile(filepath.Join(dir, "main.tf"), []byte(`resource "random_string" "name" {}`), 0644)).To(Succeed())
+
+		fakeExecutor = &executorfakes.FakeTerraformExecutor{}
+		fakeExecutor.ExecuteStub = func(_ context.Context, c *exec.Cmd) (executor.ExecutionOutput, error) {
+			if c.Args[1] == "init" {
+				Expect(os.MkdirAll(filepath.Join(c.Dir, ".terraform"), 0755)).To(Succeed())
+			}
+			return executor.ExecutionOutput{StdOut: "done"}, nil
+		}
+	})
+
+	It("skips init when nothing has changed since the last init", func() {
+		e := executor.SkipUnchangedInitExecutor(fakeExecutor)
+
+		_, err := e.Execute(context.TODO(), initCommand())
+		Expect(err).NotTo(HaveOccurred())
+		_, err = e.Execute(context.TODO(), initCommand())
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(fakeExecutor.ExecuteCallCount()).To(Equal(1))
+	})
+
+	It("runs init when the modules have changed", func() {
+		e := executor.SkipUnchangedInitExecutor(fakeExecutor)
+
+		e.Execute(context.TODO(), initCommand())
+		Expect(os.WriteFile(filepath.Join(dir, "main.tf"), []byte(`module "db" { source = "./db" }`), 0644)).To(Succeed())
+		e.Execute(context.TODO(), initCommand())
+
+		Expect(fakeExecutor.ExecuteCallCount()).To(Equal(2))
+	})
+
+	It("runs init when the lock file has changed", func() {
+		e := executor.SkipUnchangedInitExecutor(fakeExecutor)
+
+		e.Execute(context.TODO(), initCommand())
+		Expect(os.WriteFile(filepath.Join(dir, ".terraform.lock.hcl"), []byte(`provider "registry.terraform.io/hashicorp/random" {}`), 0644)).To(Succeed())
+		e.Execute(context.TODO(), initCommand())
+
+		Expect(fakeExecutor.ExecuteCallCount()).To(Equal(2))
+	})
+
+	It("runs init with a different Terraform binary", func() {
+		e := executor.SkipUnchangedInitExecutor(fakeExecutor)
+
+		e.Execute(context.TODO(), initCommand())
+		e.Execute(context.TODO(), command("/versions/1.2.0/terraform", "init", "-plugin-dir=/plugins", "-no-color"))
+
+		Expect(fakeExecutor.ExecuteCallCount()).To(Equal(2))
+	})
+
+	It("runs init again after a failed init", func() {
+		fakeExecutor.ExecuteStub = nil
+		fakeExecutor.ExecuteReturnsOnCall(0, executor.ExecutionOutput{}, errors.New("failed to install provider"))
+		Expect(os.MkdirAll(filepath.Join(dir, ".terraform"), 0755)).To(Succeed())
+		e := executor.SkipUnchangedInitExecutor(fakeExecutor)
+
+		_, err := e.Execute(context.TODO(), initCommand())
+		Expect(err).To(MatchError("failed to install provider"))
+		_, err = e.Execute(context.TODO(), initCommand())
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(fakeExecutor.ExecuteCallCount()).To(Equal(2))
+	})
+
+	It("always runs other commands", func() {
+		e := executor.SkipUnchangedInitExecutor(fakeExecutor)
+
+		e.Execute(context.TODO(), command("terraform", "apply", "-auto-approve", "-no-color"))
+		e.Execute(context.TODO(), command("terraform", "apply", "-auto-approve", "-no-color"))
+
+		Expect(fakeExecutor.ExecuteCallCount()).To(Equal(2))
+	})
+})
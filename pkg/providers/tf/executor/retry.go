@@ -0,0 +1,124 @@
+package executor
+
+import (
+	"context"
+	"os/exec"
+	"regexp"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/cloudfoundry/cloud-service-broker/utils"
+	"github.com/cloudfoundry/cloud-service-broker/utils/correlation"
+)
+
+// retryableCommands are the Terraform commands that are safe to run again after a failure
+var retryableCommands = map[string]bool{
+	"init":    true,
+	"apply":   true,
+	"destroy": true,
+}
+
+// RetryPolicy describes when and how often a failed Terraform command is retried.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of times a command is run. Values below 2 disable retries.
+	MaxAttempts int
+
+	// Backoff is the wait before the first retry. It doubles for each further retry, up to MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+
+	// Errors are matched against the error of a failed command. Only matching errors are retried.
+	Errors []*regexp.Regexp
+}
+
+// Enabled is true when the policy allows commands to be retried
+func (p RetryPolicy) Enabled() bool {
+	return p.MaxAttempts > 1 && len(p.Errors) > 0
+}
+
+func (p RetryPolicy) retryable(err error) bool {
+	for _, r := range p.Errors {
+		if r.MatchString(err.Error()) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	backoff := p.Backoff
+	for i := 1; i < attempt; i++ {
+		backoff *= 2
+		if p.MaxBackoff > 0 && backoff >= p.MaxBackoff {
+			return p.MaxBackoff
+		}
+	}
+	return backoff
+}
+
+// RetryObserver is told about each failed attempt that will be retried
+type RetryObserver func(attempt, maxAttempts int, err error)
+
+type retryObserverKey struct{}
+
+// WithRetryObserver returns a context that reports retries to the observer, so that
+// they can be recorded in the status of the operation that the command is part of.
+func WithRetryObserver(ctx context.Context, observer RetryObserver) context.Context {
+	return context.WithValue(ctx, retryObserverKey{}, observer)
+}
+
+// RetryingExecutor runs init, apply and destroy commands again when they fail with an
+// error that matches the policy, waiting for the backoff between attempts.
+func RetryingExecutor(policy RetryPolicy, wrapped TerraformExecutor) TerraformExecutor {
+	if !policy.Enabled() {
+		return wrapped
+	}
+	return retryingExecutor{policy: policy, wrapped: wrapped}
+}
+
+type retryingExecutor struct {
+	policy  RetryPolicy
+	wrapped TerraformExecutor
+}
+
+func (executor retryingExecutor) Execute(ctx context.Context, c *exec.Cmd) (ExecutionOutput, error) {
+	if len(c.Args) < 2 || !retryableCommands[c.Args[1]] {
+		return executor.wrapped.Execute(ctx, c)
+	}
+
+	logger := utils.NewLogger("terraform-retry@" + c.Dir).WithData(correlation.ID(ctx))
+	observer, _ := ctx.Value(retryObserverKey{}).(RetryObserver)
+
+	for attempt := 1; ; attempt++ {
+		output, err := executor.wrapped.Execute(ctx, cloneCommand(c))
+		if err == nil || attempt >= executor.policy.MaxAttempts || !executor.policy.retryable(err) {
+			return output, err
+		}
+
+		backoff := executor.policy.backoff(attempt)
+		logger.Info("retrying", lager.Data{
+			"command":      c.Args[1],
+			"attempt":      attempt,
+			"max_attempts": executor.policy.MaxAttempts,
+			"backoff":      backoff.String(),
+			"error":        err.Error(),
+		})
+		if observer != nil {
+			observer(attempt, executor.policy.MaxAttempts, err)
+		}
+
+		// The context is not used to cancel the wait, as operations run in the background
+		// with the context of the request that started them, which ends before they do
+		time.Sleep(backoff)
+	}
+}
+
+// cloneCommand creates an unstarted copy of the command, as a command can only be run once
+func cloneCommand(c *exec.Cmd) *exec.Cmd {
+	return &exec.Cmd{
+		Path: c.Path,
+		Args: c.Args,
+		Dir:  c.Dir,
+		Env:  append([]string(nil), c.Env...),
+	}
+}
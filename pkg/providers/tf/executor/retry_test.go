@@ -0,0 +1,120 @@
+package executor_test
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"regexp"
+	"time"
+
+	"github.com/cloudfoundry/cloud-service-broker/pkg/providers/tf/executor"
+	"github.com/cloudfoundry/cloud-service-broker/pkg/providers/tf/executor/executorfakes"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RetryingExecutor", func() {
+	var (
+		fakeExecutor *executorfakes.FakeTerraformExecutor
+		policy       executor.RetryPolicy
+		throttled    = errors.New("Error: creating instance: RequestLimitExceeded: rate exceeded")
+		invalid      = errors.New("Error: InvalidParameterValue: bad instance class")
+	)
+
+	BeforeEach(func() {
+		fakeExecutor = &executorfakes.FakeTerraformExecutor{}
+		policy = executor.RetryPolicy{
+			MaxAttempts: 3,
+			Backoff:     time.Millisecond,
+			Errors:      []*regexp.Regexp{regexp.MustCompile(`RequestLimitExceeded`)},
+		}
+	})
+
+	applyCommand := func() *exec.Cmd {
+		c := exec.Command("terraform", "apply", "-auto-approve", "-no-color")
+		c.Dir = "/tmp/workspace"
+		c.Env = []string{"PATH=/bin"}
+		return c
+	}
+
+	It("retries a command that fails with a matching error", func() {
+		fakeExecutor.ExecuteReturnsOnCall(0, executor.ExecutionOutput{}, throttled)
+		fakeExecutor.ExecuteReturnsOnCall(1, executor.ExecutionOutput{StdOut: "Apply complete!"}, nil)
+
+		output, err := executor.RetryingExecutor(policy, fakeExecutor).Execute(context.TODO(), applyCommand())
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(output.StdOut).To(Equal("Apply complete!"))
+		Expect(fakeExecutor.ExecuteCallCount()).To(Equal(2))
+
+		By("running a fresh copy of the command each time")
+		_, first := fakeExecutor.ExecuteArgsForCall(0)
+		_, second := fakeExecutor.ExecuteArgsForCall(1)
+		Expect(first).NotTo(BeIdenticalTo(second))
+		Expect(second.Args).To(Equal([]string{"terraform", "apply", "-auto-approve", "-no-color"}))
+		Expect(second.Dir).To(Equal("/tmp/workspace"))
+		Expect(second.Env).To(Equal([]string{"PATH=/bin"}))
+	})
+
+	It("gives up after the maximum number of attempts", func() {
+		fakeExecutor.ExecuteReturns(executor.ExecutionOutput{}, throttled)
+
+		_, err := executor.RetryingExecutor(policy, fakeExecutor).Execute(context.TODO(), applyCommand())
+
+		Expect(err).To(MatchError(throttled))
+		Expect(fakeExecutor.ExecuteCallCount()).To(Equal(3))
+	})
+
+	It("does not retry errors that do not match", func() {
+		fakeExecutor.ExecuteReturns(executor.ExecutionOutput{}, invalid)
+
+		_, err := executor.RetryingExecutor(policy, fakeExecutor).Execute(context.TODO(), applyCommand())
+
+		Expect(err).To(MatchError(invalid))
+		Expect(fakeExecutor.ExecuteCallCount()).To(Equal(1))
+	})
+
+	It("does not retry commands that are not safe to repeat", func() {
+		fakeExecutor.ExecuteReturns(executor.ExecutionOutput{}, throttled)
+
+		_, err := executor.RetryingExecutor(policy, fakeExecutor).Execute(context.TODO(), exec.Command("terraform", "import", "aws_db_instance.db", "db-1"))
+
+		Expect(err).To(MatchError(throttled))
+		Expect(fakeExecutor.ExecuteCallCount()).To(Equal(1))
+	})
+
+	It("reports each failed attempt that is retried to the observer in the context", func() {
+		fakeExecutor.ExecuteReturns(executor.ExecutionOutput{}, throttled)
+
+		type attempt struct {
+			number, max int
+			err         error
+		}
+		var attempts []attempt
+		ctx := executor.WithRetryObserver(context.TODO(), func(number, max int, err error) {
+			attempts = append(attempts, attempt{number: number, max: max, err: err})
+		})
+
+		executor.RetryingExecutor(policy, fakeExecutor).Execute(ctx, applyCommand())
+
+		Expect(attempts).To(Equal([]attempt{{1, 3, throttled}, {2, 3, throttled}}))
+	})
+
+	It("does not wrap the executor when retries are not configured", func() {
+		Expect(executor.RetryingExecutor(executor.RetryPolicy{}, fakeExecutor)).To(BeIdenticalTo(fakeExecutor))
+	})
+
+	Describe("backoff", func() {
+		It("doubles the wait between attempts up to the maximum", func() {
+			policy.MaxAttempts = 4
+			policy.Backoff = 20 * time.Millisecond
+			policy.MaxBackoff = 30 * time.Millisecond
+			fakeExecutor.ExecuteReturns(executor.ExecutionOutput{}, throttled)
+
+			start := time.Now()
+			executor.RetryingExecutor(policy, fakeExecutor).Execute(context.TODO(), applyCommand())
+
+			Expect(time.Since(start)).To(BeNumerically(">=", 80*time.Millisecond))
+		})
+	})
+})
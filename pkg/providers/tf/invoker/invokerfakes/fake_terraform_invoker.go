@@ -62,6 +62,19 @@ type FakeTerraformInvoker struct {
 		result1 executor.ExecutionOutput
 		result2 error
 	}
+	ReplaceStub        func(context.Context, workspace.Workspace, []string) error
+	replaceMutex       sync.RWMutex
+	replaceArgsForCall []struct {
+		arg1 context.Context
+		arg2 workspace.Workspace
+		arg3 []string
+	}
+	replaceReturns struct {
+		result1 error
+	}
+	replaceReturnsOnCall map[int]struct {
+		result1 error
+	}
 	ShowStub        func(context.Context, workspace.Workspace) (string, error)
 	showMutex       sync.RWMutex
 	showArgsForCall []struct {
@@ -332,6 +345,74 @@ func (fake *FakeTerraformInvoker) PlanReturnsOnCall(i int, result1 executor.Exec
 	}{result1, result2}
 }
 
+func (fake *FakeTerraformInvoker) Replace(arg1 context.Context, arg2 workspace.Workspace, arg3 []string) error {
+	var arg3Copy []string
+	if arg3 != nil {
+		arg3Copy = make([]string, len(arg3))
+		copy(arg3Copy, arg3)
+	}
+	fake.replaceMutex.Lock()
+	ret, specificReturn := fake.replaceReturnsOnCall[len(fake.replaceArgsForCall)]
+	fake.replaceArgsForCall = append(fake.replaceArgsForCall, struct {
+		arg1 context.Context
+		arg2 workspace.Workspace
+		arg3 []string
+	}{arg1, arg2, arg3Copy})
+	stub := fake.ReplaceStub
+	fakeReturns := fake.replaceReturns
+	fake.recordInvocation("Replace", []interface{}{arg1, arg2, arg3Copy})
+	fake.replaceMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeTerraformInvoker) ReplaceCallCount() int {
+	fake.replaceMutex.RLock()
+	defer fake.replaceMutex.RUnlock()
+	return len(fake.replaceArgsForCall)
+}
+
+func (fake *FakeTerraformInvoker) ReplaceCalls(stub func(context.Context, workspace.Workspace, []string) error) {
+	fake.replaceMutex.Lock()
+	defer fake.replaceMutex.Unlock()
+	fake.ReplaceStub = stub
+}
+
+func (fake *FakeTerraformInvoker) ReplaceArgsForCall(i int) (context.Context, workspace.Workspace, []string) {
+	fake.replaceMutex.RLock()
+	defer fake.replaceMutex.RUnlock()
+	argsForCall := fake.replaceArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeTerraformInvoker) ReplaceReturns(result1 error) {
+	fake.replaceMutex.Lock()
+	defer fake.replaceMutex.Unlock()
+	fake.ReplaceStub = nil
+	fake.replaceReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeTerraformInvoker) ReplaceReturnsOnCall(i int, result1 error) {
+	fake.replaceMutex.Lock()
+	defer fake.replaceMutex.Unlock()
+	fake.ReplaceStub = nil
+	if fake.replaceReturnsOnCall == nil {
+		fake.replaceReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.replaceReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeTerraformInvoker) Show(arg1 context.Context, arg2 workspace.Workspace) (string, error) {
 	fake.showMutex.Lock()
 	ret, specificReturn := fake.showReturnsOnCall[len(fake.showArgsForCall)]
@@ -408,6 +489,8 @@ func (fake *FakeTerraformInvoker) Invocations() map[string][][]interface{} {
 	defer fake.importMutex.RUnlock()
 	fake.planMutex.RLock()
 	defer fake.planMutex.RUnlock()
+	fake.replaceMutex.RLock()
+	defer fake.replaceMutex.RUnlock()
 	fake.showMutex.RLock()
 	defer fake.showMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
@@ -56,3 +56,17 @@ func (cmd Terraform012Invoker) Import(ctx context.Context, workspace workspace.W
 	_, err := workspace.Execute(ctx, cmd.executor, commands...)
 	return err
 }
+
+// Replace taints the resources, because the -replace option of apply is not available in Terraform 0.12
+func (cmd Terraform012Invoker) Replace(ctx context.Context, workspace workspace.Workspace, addresses []string) error {
+	commands := []command.TerraformCommand{
+		command.NewInit012(cmd.pluginDirectory),
+	}
+	for _, addr := range addresses {
+		commands = append(commands, command.NewTaint(addr))
+	}
+	commands = append(commands, command.NewApply())
+
+	_, err := workspace.Execute(ctx, cmd.executor, commands...)
+	return err
+}
@@ -41,4 +41,20 @@ var _ = Context("Terraform012Invoker", func() {
 			}))
 		})
 	})
+
+	Context("Replace", func() {
+		It("initializes the workspace, taints the resources and applies", func() {
+			Expect(
+				invokerUnderTest.Replace(expectedContext, fakeWorkspace, []string{"random_password.admin"}),
+			).To(Succeed())
+
+			Expect(fakeWorkspace.ExecuteCallCount()).To(Equal(1))
+			_, _, actualCommands := fakeWorkspace.ExecuteArgsForCall(0)
+			Expect(actualCommands).To(Equal([]command.TerraformCommand{
+				command.NewInit012(pluginDirectory),
+				command.NewTaint("random_password.admin"),
+				command.NewApply(),
+			}))
+		})
+	})
 })
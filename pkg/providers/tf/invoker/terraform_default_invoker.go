@@ -30,6 +30,13 @@ func (cmd TerraformDefaultInvoker) Apply(ctx context.Context, workspace workspac
 	return err
 }
 
+func (cmd TerraformDefaultInvoker) Replace(ctx context.Context, workspace workspace.Workspace, addresses []string) error {
+	commands := append(cmd.ReplacementCommands(), command.NewInit(cmd.pluginDirectory), command.NewApplyReplace(addresses))
+
+	_, err := workspace.Execute(ctx, cmd.executor, commands...)
+	return err
+}
+
 func (cmd TerraformDefaultInvoker) Show(ctx context.Context, workspace workspace.Workspace) (string, error) {
 	output, err := workspace.Execute(ctx, cmd.executor,
 		command.NewInit(cmd.pluginDirectory),
@@ -100,4 +100,20 @@ var _ = Context("TerraformDefaultInvoker", func() {
 			})
 		})
 	})
+
+	Context("Replace", func() {
+		It("renames providers before, initializing the workspace and applies with the resources to replace", func() {
+			invokerUnderTest.Replace(expectedContext, fakeWorkspace, []string{"random_password.admin"})
+
+			Expect(fakeWorkspace.ExecuteCallCount()).To(Equal(1))
+			actualContext, actualExecutor, actualCommands := fakeWorkspace.ExecuteArgsForCall(0)
+			Expect(actualContext).To(Equal(expectedContext))
+			Expect(actualExecutor).To(Equal(fakeExecutor))
+			Expect(actualCommands).To(Equal([]command.TerraformCommand{
+				command.NewRenameProvider("old_provider_1", "new_provider_1"),
+				command.NewInit(pluginDirectory),
+				command.NewApplyReplace([]string{"random_password.admin"}),
+			}))
+		})
+	})
 })
@@ -23,4 +23,5 @@ type TerraformInvoker interface {
 	Show(ctx context.Context, workspace workspace.Workspace) (string, error)
 	Plan(ctx context.Context, workspace workspace.Workspace) (executor.ExecutionOutput, error)
 	Import(ctx context.Context, workspace workspace.Workspace, resources map[string]string) error
+	Replace(ctx context.Context, workspace workspace.Workspace, addresses []string) error
 }
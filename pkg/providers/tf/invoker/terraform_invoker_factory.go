@@ -5,20 +5,36 @@ import (
 	"github.com/hashicorp/go-version"
 )
 
-func NewTerraformInvokerFactory(executorBuilder executor.ExecutorBuilder, terraformPluginsDirectory string, pluginRenames map[string]string) TerraformInvokerBuilder {
-	return TerraformInvokerFactory{executorBuilder: executorBuilder, terraformPluginsDirectory: terraformPluginsDirectory, pluginRenames: pluginRenames}
+func NewTerraformInvokerFactory(executorBuilder executor.ExecutorBuilder, tfBinContext executor.TFBinariesContext) TerraformInvokerBuilder {
+	return TerraformInvokerFactory{executorBuilder: executorBuilder, tfBinContext: tfBinContext}
 }
 
 type TerraformInvokerFactory struct {
-	executorBuilder           executor.ExecutorBuilder
-	terraformPluginsDirectory string
-	pluginRenames             map[string]string
+	executorBuilder executor.ExecutorBuilder
+	tfBinContext    executor.TFBinariesContext
 }
 
 func (factory TerraformInvokerFactory) VersionedTerraformInvoker(tfVersion *version.Version) TerraformInvoker {
 	if tfVersion.LessThan(version.Must(version.NewVersion("0.13.0"))) {
-		return NewTerraform012Invoker(factory.executorBuilder.VersionedExecutor(tfVersion), factory.terraformPluginsDirectory)
+		return NewTerraform012Invoker(factory.executorBuilder.VersionedExecutor(tfVersion), factory.tfBinContext.Dir)
 	} else {
-		return NewTerraformDefaultInvoker(factory.executorBuilder.VersionedExecutor(tfVersion), factory.terraformPluginsDirectory, factory.pluginRenames)
+		return NewTerraformDefaultInvoker(factory.executorBuilder.VersionedExecutor(tfVersion), factory.tfBinContext.Dir, factory.pluginRenames(tfVersion))
 	}
 }
+
+// pluginRenames are the provider replacements for a version. OpenTofu also moves providers
+// from the Terraform registry, for deployments that were made by Terraform.
+func (factory TerraformInvokerFactory) pluginRenames(tfVersion *version.Version) map[string]string {
+	if factory.tfBinContext.Binary(tfVersion).Name != executor.OpenTofuBinaryName || len(factory.tfBinContext.OpenTofuProviderReplacements) == 0 {
+		return factory.tfBinContext.ProviderReplacements
+	}
+
+	renames := make(map[string]string)
+	for old, new := range factory.tfBinContext.OpenTofuProviderReplacements {
+		renames[old] = new
+	}
+	for old, new := range factory.tfBinContext.ProviderReplacements {
+		renames[old] = new
+	}
+	return renames
+}
@@ -1,6 +1,7 @@
 package invoker_test
 
 import (
+	"github.com/cloudfoundry/cloud-service-broker/pkg/providers/tf/executor"
 	"github.com/cloudfoundry/cloud-service-broker/pkg/providers/tf/executor/executorfakes"
 	"github.com/cloudfoundry/cloud-service-broker/pkg/providers/tf/invoker"
 	"github.com/hashicorp/go-version"
@@ -20,7 +21,14 @@ var _ = Context("TerraformInvokerFactory", func() {
 		fakeExecutor = &executorfakes.FakeTerraformExecutor{}
 		fakeBuilder.VersionedExecutorReturns(fakeExecutor)
 
-		invokerFactory = invoker.NewTerraformInvokerFactory(fakeBuilder, expectedTerraformPluginDir, expectedProviderRenames)
+		invokerFactory = invoker.NewTerraformInvokerFactory(fakeBuilder, executor.TFBinariesContext{
+			Dir:                  expectedTerraformPluginDir,
+			ProviderReplacements: expectedProviderRenames,
+			Binaries:             []executor.TerraformBinary{{Name: "tofu", Version: newVersion("1.6.0")}},
+			OpenTofuProviderReplacements: map[string]string{
+				"registry.terraform.io/hashicorp/random": "registry.opentofu.org/hashicorp/random",
+			},
+		})
 	})
 	Context("0.12", func() {
 		It("should return invoker for 0.12, for terraform version 0.12.0", func() {
@@ -56,6 +64,17 @@ var _ = Context("TerraformInvokerFactory", func() {
 			Expect(fakeBuilder.VersionedExecutorArgsForCall(0)).To(Equal(newVersion("1.0.4")))
 		})
 	})
+	Context("OpenTofu", func() {
+		It("should move providers to the OpenTofu registry", func() {
+			Expect(
+				invokerFactory.VersionedTerraformInvoker(newVersion("1.6.0")),
+			).To(Equal(invoker.NewTerraformDefaultInvoker(fakeExecutor, expectedTerraformPluginDir, map[string]string{
+				"from":                                   "to",
+				"registry.terraform.io/hashicorp/random": "registry.opentofu.org/hashicorp/random",
+			})))
+			Expect(fakeBuilder.VersionedExecutorArgsForCall(0)).To(Equal(newVersion("1.6.0")))
+		})
+	})
 })
 
 func newVersion(v string) *version.Version {
@@ -19,6 +19,39 @@ func (provider *TerraformProvider) GetTerraformOutputs(ctx context.Context, inst
 	return outs, nil
 }
 
+// GetInstanceState gets a view of the Terraform state of the instance from the stored state. It is a flat map, so
+// that it can be indexed in HIL expressions: the attributes of resources are keyed by the address of the resource
+// and the name of the attribute, for example `aws_db_instance.db.endpoint`, and the outputs by `output.<name>`.
+// The view is empty when the instance has no state.
+func (provider *TerraformProvider) GetInstanceState(ctx context.Context, instanceGUID string) (storage.JSONObject, error) {
+	deployment, err := provider.GetTerraformDeployment(generateTfID(instanceGUID, ""))
+	if err != nil {
+		return nil, fmt.Errorf("error getting TF deployment: %w", err)
+	}
+
+	view := make(storage.JSONObject)
+	ws, ok := deployment.Workspace.(*workspace.TerraformWorkspace)
+	if !ok || !ws.HasState() {
+		return view, nil
+	}
+
+	state, err := workspace.NewTfstate(ws.State)
+	if err != nil {
+		return nil, fmt.Errorf("error creating TF state: %w", err)
+	}
+
+	for address, attributes := range state.GetResources() {
+		for name, value := range attributes {
+			view[address+"."+name] = value
+		}
+	}
+	for name, value := range state.GetOutputs() {
+		view["output."+name] = value
+	}
+
+	return view, nil
+}
+
 // Outputs gets the output variables for the given module instance in the workspace.
 func (provider *TerraformProvider) outputs(deploymentID, instanceName string) (map[string]interface{}, error) {
 	deployment, err := provider.GetTerraformDeployment(deploymentID)
@@ -8,6 +8,7 @@ import (
 	"github.com/cloudfoundry/cloud-service-broker/pkg/providers/tf"
 	"github.com/cloudfoundry/cloud-service-broker/pkg/providers/tf/executor"
 	"github.com/cloudfoundry/cloud-service-broker/pkg/providers/tf/tffakes"
+	"github.com/cloudfoundry/cloud-service-broker/pkg/providers/tf/workspace"
 	"github.com/cloudfoundry/cloud-service-broker/pkg/providers/tf/workspace/workspacefakes"
 	"github.com/cloudfoundry/cloud-service-broker/utils"
 	. "github.com/onsi/ginkgo/v2"
@@ -72,4 +73,72 @@ var _ = Describe("Outputs", func() {
 			Expect(err).To(MatchError("cant get outputs"))
 		})
 	})
+
+	Describe("GetInstanceState", func() {
+		var (
+			fakeDeploymentManager *tffakes.FakeDeploymentManagerInterface
+			provider              *tf.TerraformProvider
+		)
+
+		BeforeEach(func() {
+			fakeDeploymentManager = &tffakes.FakeDeploymentManagerInterface{}
+			provider = tf.NewTerraformProvider(executor.TFBinariesContext{}, &tffakes.FakeTerraformInvokerBuilder{}, utils.NewLogger("test"), tf.TfServiceDefinitionV1{}, fakeDeploymentManager)
+		})
+
+		It("returns the resource attributes and outputs keyed by their address", func() {
+			fakeDeploymentManager.GetTerraformDeploymentReturns(storage.TerraformDeployment{
+				Workspace: &workspace.TerraformWorkspace{State: []byte(`{
+					"version": 4,
+					"outputs": {"status": {"type": "string", "value": "created"}},
+					"resources": [
+						{"mode": "managed", "type": "aws_db_instance", "name": "db", "instances": [{"attributes": {"endpoint": "db.example.com", "port": 3306}}]},
+						{"module": "module.users", "mode": "data", "type": "aws_iam_user", "name": "admin", "instances": [{"index_key": "a", "attributes": {"arn": "arn:a"}}]}
+					]
+				}`)},
+			}, nil)
+
+			state, err := provider.GetInstanceState(context.TODO(), "instance-guid")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state).To(Equal(storage.JSONObject{
+				"aws_db_instance.db.endpoint":                   "db.example.com",
+				"aws_db_instance.db.port":                       float64(3306),
+				`module.users.data.aws_iam_user.admin["a"].arn`: "arn:a",
+				"output.status":                                 "created",
+			}))
+			Expect(fakeDeploymentManager.GetTerraformDeploymentArgsForCall(0)).To(Equal("tf:instance-guid:"))
+		})
+
+		It("returns an empty view when the instance has no state", func() {
+			fakeDeploymentManager.GetTerraformDeploymentReturns(storage.TerraformDeployment{
+				Workspace: &workspace.TerraformWorkspace{},
+			}, nil)
+
+			state, err := provider.GetInstanceState(context.TODO(), "instance-guid")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state).To(BeEmpty())
+			Expect(state).NotTo(BeNil())
+		})
+
+		It("returns an empty view when the instance has no state", func() {
+			fakeDeploymentManager.GetTerraformDeploymentReturns(storage.TerraformDeployment{
+				Workspace: &workspace.TerraformWorkspace{},
+			}, nil)
+
+			state, err := provider.GetInstanceState(context.TODO(), "instance-guid")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state).To(BeEmpty())
+			Expect(state).NotTo(BeNil())
+		})
+
+		It("fails, when it cant get the terraform deployment", func() {
+			fakeDeploymentManager.GetTerraformDeploymentReturns(storage.TerraformDeployment{}, errors.New("cant get deployment now"))
+
+			_, err := provider.GetInstanceState(context.TODO(), "instance-guid")
+
+			Expect(err).To(MatchError("error getting TF deployment: cant get deployment now"))
+		})
+	})
 })
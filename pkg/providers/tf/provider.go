@@ -78,6 +78,7 @@ func (provider *TerraformProvider) create(ctx context.Context, vars *varcontext.
 	if err != nil {
 		return tfID, fmt.Errorf("error creating workspace: %w", err)
 	}
+	workspace.AppliedStateMigrations = action.stateMigrationVersions()
 
 	deployment, err := provider.CreateAndSaveDeployment(tfID, workspace)
 	if err != nil {
@@ -90,6 +91,7 @@ func (provider *TerraformProvider) create(ctx context.Context, vars *varcontext.
 	}
 
 	go func() {
+		ctx := provider.reportRetries(ctx, &deployment)
 		err := provider.DefaultInvoker().Apply(ctx, workspace)
 		provider.MarkOperationFinished(&deployment, err)
 	}()
@@ -97,7 +99,9 @@ func (provider *TerraformProvider) create(ctx context.Context, vars *varcontext.
 	return tfID, nil
 }
 
-func (provider *TerraformProvider) destroy(ctx context.Context, deploymentID string, templateVars map[string]interface{}, operationType string) error {
+// destroy destroys the resources of the deployment. The resources of the action deployments are
+// destroyed first, as they may depend on the deployment, and the action deployments are deleted.
+func (provider *TerraformProvider) destroy(ctx context.Context, deploymentID string, templateVars map[string]interface{}, operationType string, actionDeployments ...storage.TerraformDeployment) error {
 	deployment, err := provider.GetTerraformDeployment(deploymentID)
 	if err != nil {
 		return err
@@ -126,13 +130,48 @@ func (provider *TerraformProvider) destroy(ctx context.Context, deploymentID str
 	}
 
 	go func() {
-		err = provider.DefaultInvoker().Destroy(ctx, workspace)
+		ctx := provider.reportRetries(ctx, &deployment)
+		err := provider.destroyActionDeployments(ctx, actionDeployments, operationType)
+		if err == nil {
+			err = provider.DefaultInvoker().Destroy(ctx, workspace)
+		}
 		provider.MarkOperationFinished(&deployment, err)
 	}()
 
 	return nil
 }
 
+func (provider *TerraformProvider) destroyActionDeployments(ctx context.Context, deployments []storage.TerraformDeployment, operationType string) error {
+	destroy := func(ctx context.Context, ws workspace.Workspace) error {
+		if tfWorkspace, ok := ws.(*workspace.TerraformWorkspace); ok {
+			if err := tfWorkspace.RemovePreventDestroy(); err != nil {
+				return err
+			}
+		}
+		return provider.DefaultInvoker().Destroy(ctx, ws)
+	}
+	if err := provider.runOnActionDeployments(ctx, deployments, operationType, destroy); err != nil {
+		return err
+	}
+
+	for _, deployment := range deployments {
+		if err := provider.DeleteTerraformDeployment(deployment.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// reportRetries returns a context that records retries of Terraform commands in the status of the deployment's operation
+func (provider *TerraformProvider) reportRetries(ctx context.Context, deployment *storage.TerraformDeployment) context.Context {
+	return executor.WithRetryObserver(ctx, func(attempt, maxAttempts int, err error) {
+		if err := provider.MarkOperationRetrying(deployment, attempt, maxAttempts, err); err != nil {
+			provider.logger.Error("mark-operation-retrying", err)
+		}
+	})
+}
+
 func (provider *TerraformProvider) Wait(ctx context.Context, id string) error {
 	for {
 		select {
@@ -152,8 +191,12 @@ func (provider *TerraformProvider) Wait(ctx context.Context, id string) error {
 //counterfeiter:generate . DeploymentManagerInterface
 type DeploymentManagerInterface interface {
 	GetTerraformDeployment(deploymentID string) (storage.TerraformDeployment, error)
+	ExistsTerraformDeployment(deploymentID string) (bool, error)
+	DeleteTerraformDeployment(deploymentID string) error
 	CreateAndSaveDeployment(deploymentID string, workspace *workspace.TerraformWorkspace) (storage.TerraformDeployment, error)
+	CreateOrUpdateDeployment(deploymentID string, workspace *workspace.TerraformWorkspace) (storage.TerraformDeployment, error)
 	MarkOperationStarted(deployment *storage.TerraformDeployment, operationType string) error
+	MarkOperationRetrying(deployment *storage.TerraformDeployment, attempt, maxAttempts int, err error) error
 	MarkOperationFinished(deployment *storage.TerraformDeployment, err error) error
 	OperationStatus(deploymentID string) (bool, string, error)
 	UpdateWorkspaceHCL(deploymentID string, serviceDefinitionAction TfServiceDefinitionV1Action, templateVars map[string]interface{}) error
@@ -72,6 +72,7 @@ func (provider *TerraformProvider) importCreate(ctx context.Context, vars *varco
 	if err != nil {
 		return tfID, fmt.Errorf("error creating workspace: %w", err)
 	}
+	workspace.AppliedStateMigrations = action.stateMigrationVersions()
 
 	deployment, err := provider.CreateAndSaveDeployment(tfID, workspace)
 	if err != nil {
@@ -84,6 +85,7 @@ func (provider *TerraformProvider) importCreate(ctx context.Context, vars *varco
 	}
 
 	go func() {
+		ctx := provider.reportRetries(ctx, &deployment)
 		logger := utils.NewLogger("Import").WithData(correlation.ID(ctx))
 		resources := make(map[string]string)
 		for _, resource := range importParams {
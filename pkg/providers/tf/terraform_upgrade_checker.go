@@ -1,8 +1,7 @@
 package tf
 
 import (
-	"errors"
-
+	"github.com/cloudfoundry/cloud-service-broker/pkg/broker"
 	"github.com/cloudfoundry/cloud-service-broker/pkg/providers/tf/workspace"
 )
 
@@ -19,6 +18,22 @@ func (provider *TerraformProvider) CheckUpgradeAvailable(deploymentGUID string)
 		return err
 	}
 
+	// The deployments of an instance's actions are upgraded with the instance
+	instanceGUID, _ := parseTfID(deploymentGUID)
+	if deploymentGUID != generateTfID(instanceGUID, "") {
+		return nil
+	}
+
+	actionDeployments, err := provider.actionDeployments(instanceGUID)
+	if err != nil {
+		return err
+	}
+	for _, actionDeployment := range actionDeployments {
+		if err := provider.checkTerraformVersion(actionDeployment.Workspace); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -28,7 +43,7 @@ func (provider *TerraformProvider) checkTerraformVersion(workspace workspace.Wor
 		return err
 	}
 	if currentTfVersion.LessThan(provider.tfBinContext.DefaultTfVersion) {
-		return errors.New("operation attempted with newer version of Terraform than current state, upgrade the service before retrying operation")
+		return broker.ErrUpgradeRequired
 	}
 	return nil
 }
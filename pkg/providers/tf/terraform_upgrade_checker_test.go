@@ -6,6 +6,7 @@ import (
 	"github.com/cloudfoundry/cloud-service-broker/pkg/providers/tf"
 
 	"github.com/cloudfoundry/cloud-service-broker/internal/storage"
+	"github.com/cloudfoundry/cloud-service-broker/pkg/broker"
 	"github.com/cloudfoundry/cloud-service-broker/pkg/providers/tf/executor"
 	"github.com/cloudfoundry/cloud-service-broker/pkg/providers/tf/tffakes"
 	"github.com/cloudfoundry/cloud-service-broker/pkg/providers/tf/workspace"
@@ -59,6 +60,7 @@ var _ = Describe("CheckUpgradeAvailable", func() {
 
 			err := provider.CheckUpgradeAvailable(tfInstanceID)
 			Expect(err).To(MatchError("operation attempted with newer version of Terraform than current state, upgrade the service before retrying operation"))
+			Expect(err).To(MatchError(broker.ErrUpgradeRequired))
 		})
 	})
 
@@ -101,4 +103,39 @@ var _ = Describe("CheckUpgradeAvailable", func() {
 		})
 	})
 
+	When("the deployment of an action is behind", func() {
+		var provider *tf.TerraformProvider
+
+		BeforeEach(func() {
+			deployment = storage.TerraformDeployment{
+				ID: tfInstanceID,
+				Workspace: &workspace.TerraformWorkspace{
+					State: []byte(fmt.Sprintf(`{"terraform_version": "%s" }`, defaultTerraformVersion.String())),
+				},
+			}
+			actionDeployment := storage.TerraformDeployment{
+				ID: tfInstanceID + "action:snapshot",
+				Workspace: &workspace.TerraformWorkspace{
+					State: []byte(fmt.Sprintf(`{"terraform_version": "%s" }`, oldTerraformVersion.String())),
+				},
+			}
+			fakeDeploymentManager.ExistsTerraformDeploymentReturns(true, nil)
+			fakeDeploymentManager.GetTerraformDeploymentReturnsOnCall(0, deployment, nil)
+			fakeDeploymentManager.GetTerraformDeploymentReturnsOnCall(1, actionDeployment, nil)
+			definition := tf.TfServiceDefinitionV1{
+				Actions: []tf.TfServiceDefinitionV1InstanceAction{{Name: "snapshot", Template: "template"}},
+			}
+			provider = tf.NewTerraformProvider(tfBinContext, fakeInvokerBuilder, fakeLogger, definition, fakeDeploymentManager)
+		})
+
+		It("returns an error for the instance", func() {
+			Expect(provider.CheckUpgradeAvailable(tfInstanceID)).To(MatchError(broker.ErrUpgradeRequired))
+			Expect(fakeDeploymentManager.GetTerraformDeploymentArgsForCall(1)).To(Equal(tfInstanceID + "action:snapshot"))
+		})
+
+		It("does not check the deployments of actions for a binding", func() {
+			Expect(provider.CheckUpgradeAvailable(tfInstanceID + "binding-id")).To(Succeed())
+			Expect(fakeDeploymentManager.ExistsTerraformDeploymentCallCount()).To(BeZero())
+		})
+	})
 })
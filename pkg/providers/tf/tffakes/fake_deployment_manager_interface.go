@@ -24,6 +24,44 @@ type FakeDeploymentManagerInterface struct {
 		result1 storage.TerraformDeployment
 		result2 error
 	}
+	CreateOrUpdateDeploymentStub        func(string, *workspace.TerraformWorkspace) (storage.TerraformDeployment, error)
+	createOrUpdateDeploymentMutex       sync.RWMutex
+	createOrUpdateDeploymentArgsForCall []struct {
+		arg1 string
+		arg2 *workspace.TerraformWorkspace
+	}
+	createOrUpdateDeploymentReturns struct {
+		result1 storage.TerraformDeployment
+		result2 error
+	}
+	createOrUpdateDeploymentReturnsOnCall map[int]struct {
+		result1 storage.TerraformDeployment
+		result2 error
+	}
+	DeleteTerraformDeploymentStub        func(string) error
+	deleteTerraformDeploymentMutex       sync.RWMutex
+	deleteTerraformDeploymentArgsForCall []struct {
+		arg1 string
+	}
+	deleteTerraformDeploymentReturns struct {
+		result1 error
+	}
+	deleteTerraformDeploymentReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ExistsTerraformDeploymentStub        func(string) (bool, error)
+	existsTerraformDeploymentMutex       sync.RWMutex
+	existsTerraformDeploymentArgsForCall []struct {
+		arg1 string
+	}
+	existsTerraformDeploymentReturns struct {
+		result1 bool
+		result2 error
+	}
+	existsTerraformDeploymentReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
 	GetTerraformDeploymentStub        func(string) (storage.TerraformDeployment, error)
 	getTerraformDeploymentMutex       sync.RWMutex
 	getTerraformDeploymentArgsForCall []struct {
@@ -49,6 +87,20 @@ type FakeDeploymentManagerInterface struct {
 	markOperationFinishedReturnsOnCall map[int]struct {
 		result1 error
 	}
+	MarkOperationRetryingStub        func(*storage.TerraformDeployment, int, int, error) error
+	markOperationRetryingMutex       sync.RWMutex
+	markOperationRetryingArgsForCall []struct {
+		arg1 *storage.TerraformDeployment
+		arg2 int
+		arg3 int
+		arg4 error
+	}
+	markOperationRetryingReturns struct {
+		result1 error
+	}
+	markOperationRetryingReturnsOnCall map[int]struct {
+		result1 error
+	}
 	MarkOperationStartedStub        func(*storage.TerraformDeployment, string) error
 	markOperationStartedMutex       sync.RWMutex
 	markOperationStartedArgsForCall []struct {
@@ -158,6 +210,196 @@ func (fake *FakeDeploymentManagerInterface) CreateAndSaveDeploymentReturnsOnCall
 	}{result1, result2}
 }
 
+func (fake *FakeDeploymentManagerInterface) CreateOrUpdateDeployment(arg1 string, arg2 *workspace.TerraformWorkspace) (storage.TerraformDeployment, error) {
+	fake.createOrUpdateDeploymentMutex.Lock()
+	ret, specificReturn := fake.createOrUpdateDeploymentReturnsOnCall[len(fake.createOrUpdateDeploymentArgsForCall)]
+	fake.createOrUpdateDeploymentArgsForCall = append(fake.createOrUpdateDeploymentArgsForCall, struct {
+		arg1 string
+		arg2 *workspace.TerraformWorkspace
+	}{arg1, arg2})
+	stub := fake.CreateOrUpdateDeploymentStub
+	fakeReturns := fake.createOrUpdateDeploymentReturns
+	fake.recordInvocation("CreateOrUpdateDeployment", []interface{}{arg1, arg2})
+	fake.createOrUpdateDeploymentMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeDeploymentManagerInterface) CreateOrUpdateDeploymentCallCount() int {
+	fake.createOrUpdateDeploymentMutex.RLock()
+	defer fake.createOrUpdateDeploymentMutex.RUnlock()
+	return len(fake.createOrUpdateDeploymentArgsForCall)
+}
+
+func (fake *FakeDeploymentManagerInterface) CreateOrUpdateDeploymentCalls(stub func(string, *workspace.TerraformWorkspace) (storage.TerraformDeployment, error)) {
+	fake.createOrUpdateDeploymentMutex.Lock()
+	defer fake.createOrUpdateDeploymentMutex.Unlock()
+	fake.CreateOrUpdateDeploymentStub = stub
+}
+
+func (fake *FakeDeploymentManagerInterface) CreateOrUpdateDeploymentArgsForCall(i int) (string, *workspace.TerraformWorkspace) {
+	fake.createOrUpdateDeploymentMutex.RLock()
+	defer fake.createOrUpdateDeploymentMutex.RUnlock()
+	argsForCall := fake.createOrUpdateDeploymentArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeDeploymentManagerInterface) CreateOrUpdateDeploymentReturns(result1 storage.TerraformDeployment, result2 error) {
+	fake.createOrUpdateDeploymentMutex.Lock()
+	defer fake.createOrUpdateDeploymentMutex.Unlock()
+	fake.CreateOrUpdateDeploymentStub = nil
+	fake.createOrUpdateDeploymentReturns = struct {
+		result1 storage.TerraformDeployment
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDeploymentManagerInterface) CreateOrUpdateDeploymentReturnsOnCall(i int, result1 storage.TerraformDeployment, result2 error) {
+	fake.createOrUpdateDeploymentMutex.Lock()
+	defer fake.createOrUpdateDeploymentMutex.Unlock()
+	fake.CreateOrUpdateDeploymentStub = nil
+	if fake.createOrUpdateDeploymentReturnsOnCall == nil {
+		fake.createOrUpdateDeploymentReturnsOnCall = make(map[int]struct {
+			result1 storage.TerraformDeployment
+			result2 error
+		})
+	}
+	fake.createOrUpdateDeploymentReturnsOnCall[i] = struct {
+		result1 storage.TerraformDeployment
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDeploymentManagerInterface) DeleteTerraformDeployment(arg1 string) error {
+	fake.deleteTerraformDeploymentMutex.Lock()
+	ret, specificReturn := fake.deleteTerraformDeploymentReturnsOnCall[len(fake.deleteTerraformDeploymentArgsForCall)]
+	fake.deleteTerraformDeploymentArgsForCall = append(fake.deleteTerraformDeploymentArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.DeleteTerraformDeploymentStub
+	fakeReturns := fake.deleteTerraformDeploymentReturns
+	fake.recordInvocation("DeleteTerraformDeployment", []interface{}{arg1})
+	fake.deleteTerraformDeploymentMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeDeploymentManagerInterface) DeleteTerraformDeploymentCallCount() int {
+	fake.deleteTerraformDeploymentMutex.RLock()
+	defer fake.deleteTerraformDeploymentMutex.RUnlock()
+	return len(fake.deleteTerraformDeploymentArgsForCall)
+}
+
+func (fake *FakeDeploymentManagerInterface) DeleteTerraformDeploymentCalls(stub func(string) error) {
+	fake.deleteTerraformDeploymentMutex.Lock()
+	defer fake.deleteTerraformDeploymentMutex.Unlock()
+	fake.DeleteTerraformDeploymentStub = stub
+}
+
+func (fake *FakeDeploymentManagerInterface) DeleteTerraformDeploymentArgsForCall(i int) string {
+	fake.deleteTerraformDeploymentMutex.RLock()
+	defer fake.deleteTerraformDeploymentMutex.RUnlock()
+	argsForCall := fake.deleteTerraformDeploymentArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeDeploymentManagerInterface) DeleteTerraformDeploymentReturns(result1 error) {
+	fake.deleteTerraformDeploymentMutex.Lock()
+	defer fake.deleteTerraformDeploymentMutex.Unlock()
+	fake.DeleteTerraformDeploymentStub = nil
+	fake.deleteTerraformDeploymentReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDeploymentManagerInterface) DeleteTerraformDeploymentReturnsOnCall(i int, result1 error) {
+	fake.deleteTerraformDeploymentMutex.Lock()
+	defer fake.deleteTerraformDeploymentMutex.Unlock()
+	fake.DeleteTerraformDeploymentStub = nil
+	if fake.deleteTerraformDeploymentReturnsOnCall == nil {
+		fake.deleteTerraformDeploymentReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteTerraformDeploymentReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDeploymentManagerInterface) ExistsTerraformDeployment(arg1 string) (bool, error) {
+	fake.existsTerraformDeploymentMutex.Lock()
+	ret, specificReturn := fake.existsTerraformDeploymentReturnsOnCall[len(fake.existsTerraformDeploymentArgsForCall)]
+	fake.existsTerraformDeploymentArgsForCall = append(fake.existsTerraformDeploymentArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.ExistsTerraformDeploymentStub
+	fakeReturns := fake.existsTerraformDeploymentReturns
+	fake.recordInvocation("ExistsTerraformDeployment", []interface{}{arg1})
+	fake.existsTerraformDeploymentMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeDeploymentManagerInterface) ExistsTerraformDeploymentCallCount() int {
+	fake.existsTerraformDeploymentMutex.RLock()
+	defer fake.existsTerraformDeploymentMutex.RUnlock()
+	return len(fake.existsTerraformDeploymentArgsForCall)
+}
+
+func (fake *FakeDeploymentManagerInterface) ExistsTerraformDeploymentCalls(stub func(string) (bool, error)) {
+	fake.existsTerraformDeploymentMutex.Lock()
+	defer fake.existsTerraformDeploymentMutex.Unlock()
+	fake.ExistsTerraformDeploymentStub = stub
+}
+
+func (fake *FakeDeploymentManagerInterface) ExistsTerraformDeploymentArgsForCall(i int) string {
+	fake.existsTerraformDeploymentMutex.RLock()
+	defer fake.existsTerraformDeploymentMutex.RUnlock()
+	argsForCall := fake.existsTerraformDeploymentArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeDeploymentManagerInterface) ExistsTerraformDeploymentReturns(result1 bool, result2 error) {
+	fake.existsTerraformDeploymentMutex.Lock()
+	defer fake.existsTerraformDeploymentMutex.Unlock()
+	fake.ExistsTerraformDeploymentStub = nil
+	fake.existsTerraformDeploymentReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDeploymentManagerInterface) ExistsTerraformDeploymentReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.existsTerraformDeploymentMutex.Lock()
+	defer fake.existsTerraformDeploymentMutex.Unlock()
+	fake.ExistsTerraformDeploymentStub = nil
+	if fake.existsTerraformDeploymentReturnsOnCall == nil {
+		fake.existsTerraformDeploymentReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.existsTerraformDeploymentReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeDeploymentManagerInterface) GetTerraformDeployment(arg1 string) (storage.TerraformDeployment, error) {
 	fake.getTerraformDeploymentMutex.Lock()
 	ret, specificReturn := fake.getTerraformDeploymentReturnsOnCall[len(fake.getTerraformDeploymentArgsForCall)]
@@ -284,6 +526,70 @@ func (fake *FakeDeploymentManagerInterface) MarkOperationFinishedReturnsOnCall(i
 	}{result1}
 }
 
+func (fake *FakeDeploymentManagerInterface) MarkOperationRetrying(arg1 *storage.TerraformDeployment, arg2 int, arg3 int, arg4 error) error {
+	fake.markOperationRetryingMutex.Lock()
+	ret, specificReturn := fake.markOperationRetryingReturnsOnCall[len(fake.markOperationRetryingArgsForCall)]
+	fake.markOperationRetryingArgsForCall = append(fake.markOperationRetryingArgsForCall, struct {
+		arg1 *storage.TerraformDeployment
+		arg2 int
+		arg3 int
+		arg4 error
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.MarkOperationRetryingStub
+	fakeReturns := fake.markOperationRetryingReturns
+	fake.recordInvocation("MarkOperationRetrying", []interface{}{arg1, arg2, arg3, arg4})
+	fake.markOperationRetryingMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeDeploymentManagerInterface) MarkOperationRetryingCallCount() int {
+	fake.markOperationRetryingMutex.RLock()
+	defer fake.markOperationRetryingMutex.RUnlock()
+	return len(fake.markOperationRetryingArgsForCall)
+}
+
+func (fake *FakeDeploymentManagerInterface) MarkOperationRetryingCalls(stub func(*storage.TerraformDeployment, int, int, error) error) {
+	fake.markOperationRetryingMutex.Lock()
+	defer fake.markOperationRetryingMutex.Unlock()
+	fake.MarkOperationRetryingStub = stub
+}
+
+func (fake *FakeDeploymentManagerInterface) MarkOperationRetryingArgsForCall(i int) (*storage.TerraformDeployment, int, int, error) {
+	fake.markOperationRetryingMutex.RLock()
+	defer fake.markOperationRetryingMutex.RUnlock()
+	argsForCall := fake.markOperationRetryingArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeDeploymentManagerInterface) MarkOperationRetryingReturns(result1 error) {
+	fake.markOperationRetryingMutex.Lock()
+	defer fake.markOperationRetryingMutex.Unlock()
+	fake.MarkOperationRetryingStub = nil
+	fake.markOperationRetryingReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDeploymentManagerInterface) MarkOperationRetryingReturnsOnCall(i int, result1 error) {
+	fake.markOperationRetryingMutex.Lock()
+	defer fake.markOperationRetryingMutex.Unlock()
+	fake.MarkOperationRetryingStub = nil
+	if fake.markOperationRetryingReturnsOnCall == nil {
+		fake.markOperationRetryingReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.markOperationRetryingReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeDeploymentManagerInterface) MarkOperationStarted(arg1 *storage.TerraformDeployment, arg2 string) error {
 	fake.markOperationStartedMutex.Lock()
 	ret, specificReturn := fake.markOperationStartedReturnsOnCall[len(fake.markOperationStartedArgsForCall)]
@@ -481,10 +787,18 @@ func (fake *FakeDeploymentManagerInterface) Invocations() map[string][][]interfa
 	defer fake.invocationsMutex.RUnlock()
 	fake.createAndSaveDeploymentMutex.RLock()
 	defer fake.createAndSaveDeploymentMutex.RUnlock()
+	fake.createOrUpdateDeploymentMutex.RLock()
+	defer fake.createOrUpdateDeploymentMutex.RUnlock()
+	fake.deleteTerraformDeploymentMutex.RLock()
+	defer fake.deleteTerraformDeploymentMutex.RUnlock()
+	fake.existsTerraformDeploymentMutex.RLock()
+	defer fake.existsTerraformDeploymentMutex.RUnlock()
 	fake.getTerraformDeploymentMutex.RLock()
 	defer fake.getTerraformDeploymentMutex.RUnlock()
 	fake.markOperationFinishedMutex.RLock()
 	defer fake.markOperationFinishedMutex.RUnlock()
+	fake.markOperationRetryingMutex.RLock()
+	defer fake.markOperationRetryingMutex.RUnlock()
 	fake.markOperationStartedMutex.RLock()
 	defer fake.markOperationStartedMutex.RUnlock()
 	fake.operationStatusMutex.RLock()
@@ -41,6 +41,7 @@ func (provider *TerraformProvider) Update(ctx context.Context, updateContext *va
 	}
 
 	go func() {
+		ctx := provider.reportRetries(ctx, &deployment)
 		err = workspace.UpdateInstanceConfiguration(updateContext.ToMap())
 		if err != nil {
 			provider.MarkOperationFinished(&deployment, err)
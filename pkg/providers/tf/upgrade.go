@@ -11,7 +11,8 @@ import (
 	"github.com/cloudfoundry/cloud-service-broker/utils/correlation"
 )
 
-// Upgrade makes necessary updates to resources so they match plan configuration
+// Upgrade makes necessary updates to resources so they match plan configuration. The deployments of
+// the instance's actions are upgraded after the instance.
 func (provider *TerraformProvider) Upgrade(ctx context.Context, upgradeContext *varcontext.VarContext) (models.ServiceInstanceDetails, error) {
 	provider.logger.Debug("upgrade", correlation.ID(ctx), lager.Data{
 		"context": upgradeContext.ToMap(),
@@ -33,12 +34,22 @@ func (provider *TerraformProvider) Upgrade(ctx context.Context, upgradeContext *
 
 	workspace := deployment.Workspace
 
+	instanceGUID, _ := parseTfID(tfID)
+	actionDeployments, err := provider.idleActionDeployments(instanceGUID)
+	if err != nil {
+		return models.ServiceInstanceDetails{}, err
+	}
+
 	if err := provider.MarkOperationStarted(&deployment, models.UpgradeOperationType); err != nil {
 		return models.ServiceInstanceDetails{}, err
 	}
 
 	go func() {
-		err = provider.performTerraformUpgrade(ctx, workspace)
+		ctx := provider.reportRetries(ctx, &deployment)
+		err := provider.performTerraformUpgrade(ctx, workspace)
+		if err == nil {
+			err = provider.runOnActionDeployments(ctx, actionDeployments, models.UpgradeOperationType, provider.performTerraformUpgrade)
+		}
 		provider.MarkOperationFinished(&deployment, err)
 	}()
 
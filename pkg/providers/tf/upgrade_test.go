@@ -203,4 +203,54 @@ var _ = Describe("Upgrade", func() {
 			Expect(err).To(MatchError(genericError))
 		})
 	})
+
+	When("the instance has deployments of actions", func() {
+		It("upgrades the action deployments after the instance", func() {
+			const instanceGUID = "567c6af0-d68a-11ec-a5b6-367dda7ea869"
+			instanceTFID := "tf:" + instanceGUID + ":"
+			actionTFID := "tf:" + instanceGUID + ":action:snapshot"
+			varContext, err := varcontext.Builder().MergeMap(map[string]interface{}{"tf_id": instanceTFID}).Build()
+			Expect(err).NotTo(HaveOccurred())
+
+			deployment.Workspace = fakeWorkspace
+			fakeWorkspace.StateVersionReturns(newVersion("0.0.1"), nil)
+			fakeActionWorkspace := &workspacefakes.FakeWorkspace{}
+			fakeActionWorkspace.StateVersionReturns(newVersion("0.0.1"), nil)
+			actionDeployment := storage.TerraformDeployment{ID: actionTFID, Workspace: fakeActionWorkspace}
+
+			fakeDeploymentManager.ExistsTerraformDeploymentReturns(true, nil)
+			fakeDeploymentManager.GetTerraformDeploymentStub = func(id string) (storage.TerraformDeployment, error) {
+				if id == actionTFID {
+					return actionDeployment, nil
+				}
+				return deployment, nil
+			}
+			fakeInvokerBuilder.VersionedTerraformInvokerReturns(fakeDefaultInvoker)
+
+			definition := tf.TfServiceDefinitionV1{
+				Actions: []tf.TfServiceDefinitionV1InstanceAction{{Name: "snapshot", Template: `variable "snapshot_name" { type = string }`}},
+			}
+			tfBinContext := executor.TFBinariesContext{
+				DefaultTfVersion: newVersion("0.1.0"),
+				TfUpgradePath:    []*version.Version{newVersion("0.1.0")},
+			}
+			provider := tf.NewTerraformProvider(tfBinContext, fakeInvokerBuilder, fakeLogger, definition, fakeDeploymentManager)
+
+			_, err = provider.Upgrade(context.TODO(), varContext)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(operationWasFinishedForDeployment(fakeDeploymentManager)).Should(Equal(deployment))
+			Expect(operationWasFinishedWithError(fakeDeploymentManager)()).To(BeNil())
+
+			Expect(fakeDeploymentManager.ExistsTerraformDeploymentArgsForCall(0)).To(Equal(actionTFID))
+			Expect(fakeDefaultInvoker.ApplyCallCount()).To(Equal(2))
+			Expect(getWorkspace(fakeDefaultInvoker, 0)).To(Equal(fakeWorkspace))
+			Expect(getWorkspace(fakeDefaultInvoker, 1)).To(Equal(fakeActionWorkspace))
+
+			Expect(fakeDeploymentManager.MarkOperationStartedCallCount()).To(Equal(2))
+			started, operationType := fakeDeploymentManager.MarkOperationStartedArgsForCall(1)
+			Expect(started.ID).To(Equal(actionTFID))
+			Expect(operationType).To(Equal("upgrade"))
+		})
+	})
 })
@@ -15,6 +15,7 @@
 package workspace
 
 import (
+	"errors"
 	"fmt"
 	"sort"
 
@@ -48,7 +49,7 @@ func decode(body string) (hcl.Blocks, error) {
 	parser := hclparse.NewParser()
 	f, diags := parser.ParseHCL([]byte(body), "")
 	if diags.HasErrors() {
-		return hcl.Blocks{}, fmt.Errorf(diags.Error())
+		return hcl.Blocks{}, errors.New(diags.Error())
 	}
 	schema := hcl.BodySchema{
 		Blocks: []hcl.BlockHeaderSchema{
@@ -64,7 +65,7 @@ func decode(body string) (hcl.Blocks, error) {
 	}
 	content, _, diags := f.Body.PartialContent(&schema)
 	if diags.HasErrors() {
-		return hcl.Blocks{}, fmt.Errorf(diags.Error())
+		return hcl.Blocks{}, errors.New(diags.Error())
 	}
 
 	return content.Blocks, nil
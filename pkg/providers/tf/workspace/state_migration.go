@@ -0,0 +1,147 @@
+package workspace
+
+import (
+	"strings"
+
+	"github.com/cloudfoundry/cloud-service-broker/pkg/providers/tf/command"
+	"github.com/cloudfoundry/cloud-service-broker/pkg/providers/tf/executor"
+)
+
+// StateMigration changes the Terraform state so that resources that are renamed or moved
+// in the modules are not recreated. It is identified by the version of the brokerpak
+// that made the change.
+type StateMigration struct {
+	Version string        `json:"version"`
+	Moves   []StateMove   `json:"moves,omitempty"`
+	Removes []string      `json:"removes,omitempty"`
+	Imports []StateImport `json:"imports,omitempty"`
+}
+
+// StateMove moves a resource to a new address in the state
+type StateMove struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// StateImport imports an existing resource into the state
+type StateImport struct {
+	Address string `json:"address"`
+	ID      string `json:"id"`
+}
+
+// AddStateMigrations queues the migrations that have not yet been applied to the workspace.
+// They run after `terraform init` the next time the workspace executes commands.
+func (workspace *TerraformWorkspace) AddStateMigrations(migrations []StateMigration) {
+	applied := make(map[string]bool)
+	for _, v := range workspace.AppliedStateMigrations {
+		applied[v] = true
+	}
+	for _, m := range workspace.PendingStateMigrations {
+		applied[m.Version] = true
+	}
+
+	for _, m := range migrations {
+		if !applied[m.Version] {
+			workspace.PendingStateMigrations = append(workspace.PendingStateMigrations, m)
+		}
+	}
+}
+
+// runStateMigrations runs the pending migrations, and records each one that succeeds as applied.
+//
+// Deployments created before a migration was recorded may already have their resources at the new
+// addresses, so the state is listed before each migration, and a move or removal whose resource is
+// not in the state, or an import whose resource already is, is skipped.
+func (workspace *TerraformWorkspace) runStateMigrations(run func(command.TerraformCommand) (executor.ExecutionOutput, error)) error {
+	for len(workspace.PendingStateMigrations) > 0 {
+		m := workspace.PendingStateMigrations[0]
+
+		addresses := make(stateAddresses)
+		if workspace.HasState() {
+			output, err := run(command.NewStateList())
+			if err != nil {
+				return err
+			}
+			addresses = newStateAddresses(output.StdOut)
+		}
+
+		for _, c := range m.pendingCommands(addresses) {
+			if _, err := run(c); err != nil {
+				return err
+			}
+		}
+
+		workspace.AppliedStateMigrations = append(workspace.AppliedStateMigrations, m.Version)
+		workspace.PendingStateMigrations = workspace.PendingStateMigrations[1:]
+	}
+	return nil
+}
+
+// pendingCommands are the Terraform commands of the migration that still need to run on a state with
+// the given addresses: the moves, then the removals, then the imports
+func (m StateMigration) pendingCommands(addresses stateAddresses) []command.TerraformCommand {
+	var commands []command.TerraformCommand
+	for _, move := range m.Moves {
+		if addresses.has(move.From) {
+			commands = append(commands, command.NewStateMove(move.From, move.To))
+			addresses.move(move.From, move.To)
+		}
+	}
+	for _, addr := range m.Removes {
+		if addresses.has(addr) {
+			commands = append(commands, command.NewStateRemove(addr))
+			addresses.move(addr, "")
+		}
+	}
+	for _, imp := range m.Imports {
+		if !addresses.has(imp.Address) {
+			commands = append(commands, command.NewImport(imp.Address, imp.ID))
+			addresses[imp.Address] = true
+		}
+	}
+	return commands
+}
+
+// stateAddresses are the resource addresses listed by `terraform state list`
+type stateAddresses map[string]bool
+
+func newStateAddresses(list string) stateAddresses {
+	addresses := make(stateAddresses)
+	for _, line := range strings.Split(list, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			addresses[line] = true
+		}
+	}
+	return addresses
+}
+
+// has tells whether the state has a resource at the address, or within the module or resource that the address refers to
+func (s stateAddresses) has(addr string) bool {
+	for a := range s {
+		if within(a, addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// move moves the resources at or within an address to another address, or removes them when the other address is empty
+func (s stateAddresses) move(from, to string) {
+	var moved []string
+	for a := range s {
+		if within(a, from) {
+			moved = append(moved, a)
+		}
+	}
+
+	for _, a := range moved {
+		delete(s, a)
+		if to != "" {
+			s[to+strings.TrimPrefix(a, from)] = true
+		}
+	}
+}
+
+func within(addr, prefix string) bool {
+	return addr == prefix || strings.HasPrefix(addr, prefix+".") || strings.HasPrefix(addr, prefix+"[")
+}
@@ -21,6 +21,9 @@ import (
 
 const (
 	supportedTfStateVersion = 4
+
+	// RedactedValue replaces the value of sensitive outputs when they are displayed
+	RedactedValue = "(sensitive value)"
 )
 
 // NewTfstate deserializes a tfstate file.
@@ -42,9 +45,20 @@ type Tfstate struct {
 	Version          int    `json:"version"`
 	TerraformVersion string `json:"terraform_version"`
 	Outputs          map[string]struct {
-		Type  string      `json:"type"`
-		Value interface{} `json:"value"`
+		Type      string      `json:"type"`
+		Value     interface{} `json:"value"`
+		Sensitive bool        `json:"sensitive"`
 	} `json:"outputs"`
+	Resources []struct {
+		Module    string `json:"module"`
+		Mode      string `json:"mode"`
+		Type      string `json:"type"`
+		Name      string `json:"name"`
+		Instances []struct {
+			IndexKey   interface{}            `json:"index_key"`
+			Attributes map[string]interface{} `json:"attributes"`
+		} `json:"instances"`
+	} `json:"resources"`
 }
 
 // GetOutputs gets the key/value outputs defined for a module.
@@ -57,3 +71,78 @@ func (module *Tfstate) GetOutputs() map[string]interface{} {
 
 	return out
 }
+
+// GetResources gets the attributes of each resource instance in the state, keyed by the address
+// of the resource instance, for example `module.instance.aws_db_instance.db` or `random_password.password[0]`.
+func (module *Tfstate) GetResources() map[string]map[string]interface{} {
+	out := make(map[string]map[string]interface{})
+
+	for _, resource := range module.Resources {
+		address := fmt.Sprintf("%s.%s", resource.Type, resource.Name)
+		if resource.Mode == "data" {
+			address = "data." + address
+		}
+		if resource.Module != "" {
+			address = resource.Module + "." + address
+		}
+
+		for _, instance := range resource.Instances {
+			switch key := instance.IndexKey.(type) {
+			case nil:
+				out[address] = instance.Attributes
+			case string:
+				out[fmt.Sprintf("%s[%q]", address, key)] = instance.Attributes
+			default:
+				out[fmt.Sprintf("%s[%v]", address, key)] = instance.Attributes
+			}
+		}
+	}
+
+	return out
+}
+
+// GetRedactedOutputs gets the key/value outputs defined for a module, with the values of
+// the outputs that are marked as sensitive replaced by RedactedValue.
+func (module *Tfstate) GetRedactedOutputs() map[string]interface{} {
+	out := module.GetOutputs()
+
+	for outputName, tfOutput := range module.Outputs {
+		if tfOutput.Sensitive {
+			out[outputName] = RedactedValue
+		}
+	}
+
+	return out
+}
+
+// RedactState replaces the values of the sensitive outputs in a tfstate file with RedactedValue.
+// The rest of the file is left as it is.
+func RedactState(stateFile []byte) ([]byte, error) {
+	var state map[string]json.RawMessage
+	if err := json.Unmarshal(stateFile, &state); err != nil {
+		return nil, fmt.Errorf("error unmarshalling JSON state: %w", err)
+	}
+
+	var outputs map[string]map[string]interface{}
+	if raw, ok := state["outputs"]; ok {
+		if err := json.Unmarshal(raw, &outputs); err != nil {
+			return nil, fmt.Errorf("error unmarshalling JSON state outputs: %w", err)
+		}
+	}
+
+	for _, output := range outputs {
+		if sensitive, ok := output["sensitive"].(bool); ok && sensitive {
+			output["value"] = RedactedValue
+		}
+	}
+
+	if outputs != nil {
+		raw, err := json.Marshal(outputs)
+		if err != nil {
+			return nil, fmt.Errorf("error marshalling JSON state outputs: %w", err)
+		}
+		state["outputs"] = raw
+	}
+
+	return json.MarshalIndent(state, "", "  ")
+}
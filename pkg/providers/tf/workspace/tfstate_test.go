@@ -110,3 +110,90 @@ func ExampleTfstate_GetOutputs() {
 
 	// Output: map[hostname:somehost]
 }
+
+func ExampleTfstate_GetRedactedOutputs() {
+	state := `{
+    "version": 4,
+    "terraform_version": "0.12.20",
+    "serial": 2,
+    "outputs": {
+        "hostname": {
+          "value": "somehost",
+          "type": "string"
+        },
+        "password": {
+          "value": "hunter2",
+          "type": "string",
+          "sensitive": true
+        }
+    }
+  }`
+
+	tfstate, _ := NewTfstate([]byte(state))
+	fmt.Printf("%v\n", tfstate.GetRedactedOutputs())
+	fmt.Printf("%v\n", tfstate.GetOutputs())
+
+	// Output: map[hostname:somehost password:(sensitive value)]
+	// map[hostname:somehost password:hunter2]
+}
+
+func ExampleRedactState() {
+	state := `{
+    "version": 4,
+    "outputs": {
+        "hostname": {
+          "value": "somehost",
+          "type": "string"
+        },
+        "password": {
+          "value": "hunter2",
+          "type": "string",
+          "sensitive": true
+        }
+    }
+  }`
+
+	redacted, err := RedactState([]byte(state))
+	fmt.Printf("%s %v\n", redacted, err)
+
+	// Output: {
+	//   "outputs": {
+	//     "hostname": {
+	//       "type": "string",
+	//       "value": "somehost"
+	//     },
+	//     "password": {
+	//       "sensitive": true,
+	//       "type": "string",
+	//       "value": "(sensitive value)"
+	//     }
+	//   },
+	//   "version": 4
+	// } <nil>
+}
+
+func ExampleTfstate_GetResources() {
+	state := `{
+    "version": 4,
+    "resources": [
+        {
+          "module": "module.instance",
+          "mode": "managed",
+          "type": "random_password",
+          "name": "password",
+          "instances": [{"index_key": 0, "attributes": {"length": 16}}]
+        },
+        {
+          "mode": "data",
+          "type": "aws_vpc",
+          "name": "default",
+          "instances": [{"attributes": {"id": "vpc-1234"}}]
+        }
+    ]
+  }`
+
+	tfstate, _ := NewTfstate([]byte(state))
+	fmt.Printf("%v\n", tfstate.GetResources())
+
+	// Output: map[data.aws_vpc.default:map[id:vpc-1234] module.instance.random_password.password[0]:map[length:16]]
+}
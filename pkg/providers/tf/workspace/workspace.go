@@ -35,6 +35,16 @@ const (
 	DefaultInstanceName = "instance"
 )
 
+// backendFileName is the file that configures the HTTP backend
+const backendFileName = "csb_backend.tf.json"
+
+// HTTPBackend is the location of a Terraform "http" backend that stores the state of a workspace
+type HTTPBackend struct {
+	Address  string
+	Username string
+	Password string
+}
+
 // NewWorkspace creates a new TerraformWorkspace from a given template and variables to populate an instance of it.
 // The created instance will have the name specified by the DefaultInstanceName constant.
 func NewWorkspace(templateVars map[string]interface{},
@@ -100,7 +110,10 @@ func DeserializeWorkspace(definition []byte) (*TerraformWorkspace, error) {
 // All public functions that shell out to Terraform maintain the following invariants:
 // - The function blocks if another terraform shell is running.
 // - The function updates the tfstate once finished.
-// - The function creates and destroys its own dir.
+// - The function creates and destroys its own dir, unless the dir is kept with KeepDirectory.
+//
+// When an HTTP backend is set with UseHTTPBackend, Terraform reads and writes the state
+// through the backend, and the state is pulled from the backend once the commands finish.
 type TerraformWorkspace struct {
 	Modules   []ModuleDefinition `json:"modules"`
 	Instances []ModuleInstance   `json:"instances"`
@@ -108,8 +121,59 @@ type TerraformWorkspace struct {
 
 	Transformer TfTransformer `json:"transform"`
 
-	dirLock sync.Mutex
-	dir     string
+	// PendingStateMigrations are run after `terraform init`, and their versions are then recorded
+	// in AppliedStateMigrations, so that each migration is only applied once
+	PendingStateMigrations []StateMigration `json:"pending_state_migrations,omitempty"`
+	AppliedStateMigrations []string         `json:"applied_state_migrations,omitempty"`
+
+	dirLock     sync.Mutex
+	dir         string
+	keepDir     bool
+	httpBackend *HTTPBackend
+}
+
+// UseHTTPBackend makes Terraform keep the state in an HTTP backend rather than in a local file,
+// so that the state is saved while a command runs. The backend must already hold the state.
+// A nil backend goes back to using a local file.
+func (workspace *TerraformWorkspace) UseHTTPBackend(backend *HTTPBackend) {
+	workspace.dirLock.Lock()
+	defer workspace.dirLock.Unlock()
+
+	workspace.httpBackend = backend
+}
+
+// UsesHTTPBackend tells whether Terraform keeps the state in an HTTP backend set with UseHTTPBackend
+func (workspace *TerraformWorkspace) UsesHTTPBackend() bool {
+	workspace.dirLock.Lock()
+	defer workspace.dirLock.Unlock()
+
+	return workspace.httpBackend != nil
+}
+
+// KeepDirectory keeps the directory that Terraform runs in between commands, so that
+// the providers and modules installed by `terraform init` can be reused.
+// The directory is removed by RemoveDirectory.
+func (workspace *TerraformWorkspace) KeepDirectory() {
+	workspace.dirLock.Lock()
+	defer workspace.dirLock.Unlock()
+
+	workspace.keepDir = true
+}
+
+// RemoveDirectory removes a directory kept by KeepDirectory, and goes back to
+// creating a new directory for each command.
+func (workspace *TerraformWorkspace) RemoveDirectory() error {
+	workspace.dirLock.Lock()
+	defer workspace.dirLock.Unlock()
+
+	workspace.keepDir = false
+	if workspace.dir == "" {
+		return nil
+	}
+
+	err := os.RemoveAll(workspace.dir)
+	workspace.dir = ""
+	return err
 }
 
 func (workspace *TerraformWorkspace) StateVersion() (*version.Version, error) {
@@ -127,8 +191,15 @@ func (workspace *TerraformWorkspace) HasState() bool {
 }
 
 // String returns a human-friendly representation of the workspace suitable for
-// printing to the console.
+// printing to the console. The values of sensitive outputs are redacted.
 func (workspace *TerraformWorkspace) String() string {
+	return workspace.Describe(false)
+}
+
+// Describe returns a human-friendly representation of the workspace suitable for
+// printing to the console. The values of sensitive outputs are only shown when
+// showSensitive is true.
+func (workspace *TerraformWorkspace) Describe(showSensitive bool) string {
 	var b strings.Builder
 
 	b.WriteString("# Terraform Workspace\n")
@@ -144,7 +215,11 @@ func (workspace *TerraformWorkspace) String() string {
 			fmt.Fprintf(&b, "input.%s = %#v\n", k, v)
 		}
 
-		if outputs, err := workspace.Outputs(instance.InstanceName); err != nil {
+		outputs, err := workspace.RedactedOutputs(instance.InstanceName)
+		if showSensitive {
+			outputs, err = workspace.Outputs(instance.InstanceName)
+		}
+		if err == nil {
 			for k, v := range outputs {
 				fmt.Fprintf(&b, "output.%s = %#v\n", k, v)
 			}
@@ -234,15 +309,16 @@ func (workspace *TerraformWorkspace) initializeFsModules() error {
 
 func (workspace *TerraformWorkspace) initializeFsWithoutTerraformInit() error {
 	workspace.dirLock.Lock()
-	// create a temp directory
-	if dir, err := os.MkdirTemp("", "gsb"); err == nil {
-		workspace.dir = dir
-	} else {
+	var err error
+	if workspace.dir == "" {
+		// create a temp directory
+		if workspace.dir, err = os.MkdirTemp("", "gsb"); err != nil {
+			return err
+		}
+	} else if err = workspace.cleanKeptDir(); err != nil {
 		return err
 	}
 
-	var err error
-
 	terraformLen := 0
 	for _, module := range workspace.Modules {
 		terraformLen += len(module.Definition)
@@ -261,6 +337,10 @@ func (workspace *TerraformWorkspace) initializeFsWithoutTerraformInit() error {
 		return err
 	}
 
+	if workspace.httpBackend != nil {
+		return workspace.writeHTTPBackend()
+	}
+
 	// write the state if it exists
 	if len(workspace.State) > 0 {
 		if err = os.WriteFile(workspace.tfStatePath(), workspace.State, 0755); err != nil {
@@ -273,19 +353,81 @@ func (workspace *TerraformWorkspace) initializeFsWithoutTerraformInit() error {
 // TeardownFs removes the directory we executed Terraform in and updates the
 // state from it.
 func (workspace *TerraformWorkspace) teardownFs() error {
-	bytes, err := os.ReadFile(workspace.tfStatePath())
+	if workspace.httpBackend == nil {
+		bytes, err := os.ReadFile(workspace.tfStatePath())
+		if err != nil {
+			return err
+		}
+
+		workspace.State = bytes
+	}
+
+	if !workspace.keepDir {
+		if err := os.RemoveAll(workspace.dir); err != nil {
+			return err
+		}
+
+		workspace.dir = ""
+	}
+
+	workspace.dirLock.Unlock()
+	return nil
+}
+
+// writeHTTPBackend writes the configuration of the HTTP backend to the root module
+func (workspace *TerraformWorkspace) writeHTTPBackend() error {
+	config := map[string]interface{}{
+		"terraform": map[string]interface{}{
+			"backend": map[string]interface{}{
+				"http": map[string]interface{}{
+					"address":        workspace.httpBackend.Address,
+					"lock_address":   workspace.httpBackend.Address,
+					"unlock_address": workspace.httpBackend.Address,
+					"username":       workspace.httpBackend.Username,
+					"password":       workspace.httpBackend.Password,
+				},
+			},
+		},
+	}
+
+	contents, err := json.MarshalIndent(config, "", "  ")
 	if err != nil {
 		return err
 	}
 
-	workspace.State = bytes
+	return os.WriteFile(path.Join(workspace.dir, backendFileName), contents, 0600)
+}
+
+// pullState reads the state from the HTTP backend. When the commands did not get as far as
+// initializing the backend, the state cannot be read, and the state that was there before is kept.
+func (workspace *TerraformWorkspace) pullState(ctx context.Context, terraformExecutor executor.TerraformExecutor) {
+	c := exec.Command("terraform", command.NewStatePull().Command()...)
+	c.Env = os.Environ()
+	c.Dir = workspace.dir
 
-	if err := os.RemoveAll(workspace.dir); err != nil {
+	output, err := terraformExecutor.Execute(ctx, c)
+	if err == nil && len(output.StdOut) > 0 {
+		workspace.State = []byte(output.StdOut)
+	}
+}
+
+// cleanKeptDir removes the files written by the previous command from a kept directory,
+// leaving what `terraform init` installed so that the files can be written again
+func (workspace *TerraformWorkspace) cleanKeptDir() error {
+	entries, err := os.ReadDir(workspace.dir)
+	if err != nil {
 		return err
 	}
 
-	workspace.dir = ""
-	workspace.dirLock.Unlock()
+	for _, entry := range entries {
+		switch entry.Name() {
+		case ".terraform", ".terraform.lock.hcl":
+		default:
+			if err := os.RemoveAll(path.Join(workspace.dir, entry.Name())); err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
 
@@ -303,23 +445,49 @@ func (workspace *TerraformWorkspace) Outputs(instance string) (map[string]interf
 	return state.GetOutputs(), nil
 }
 
+// RedactedOutputs gets the Terraform outputs in the same way as Outputs, but with the values
+// of the outputs that are marked as sensitive replaced by RedactedValue. It should be used
+// whenever outputs are logged or displayed.
+func (workspace *TerraformWorkspace) RedactedOutputs(instance string) (map[string]interface{}, error) {
+	state, err := NewTfstate(workspace.State)
+	if err != nil {
+		return nil, fmt.Errorf("error creating TF state: %w", err)
+	}
+
+	return state.GetRedactedOutputs(), nil
+}
+
 func (workspace *TerraformWorkspace) Execute(ctx context.Context, terraformExecutor executor.TerraformExecutor, commands ...command.TerraformCommand) (executor.ExecutionOutput, error) {
 	err := workspace.initializeFsWithoutTerraformInit()
 	defer workspace.teardownFs()
 	if err != nil {
 		return executor.ExecutionOutput{}, err
 	}
+	if workspace.httpBackend != nil {
+		// runs before teardownFs, while the directory still exists
+		defer workspace.pullState(ctx, terraformExecutor)
+	}
 	var lastExecutionOutput executor.ExecutionOutput
 
-	for _, command := range commands {
-		c := exec.Command("terraform", command.Command()...)
+	run := func(cmd command.TerraformCommand) (executor.ExecutionOutput, error) {
+		c := exec.Command("terraform", cmd.Command()...)
 		c.Env = os.Environ()
 		c.Dir = workspace.dir
 
 		lastExecutionOutput, err = terraformExecutor.Execute(ctx, c)
-		if err != nil {
+		return lastExecutionOutput, err
+	}
+
+	for _, cmd := range commands {
+		if _, err := run(cmd); err != nil {
 			return executor.ExecutionOutput{}, err
 		}
+
+		if cmd.Command()[0] == "init" {
+			if err := workspace.runStateMigrations(run); err != nil {
+				return executor.ExecutionOutput{}, fmt.Errorf("error migrating state: %w", err)
+			}
+		}
 	}
 	return lastExecutionOutput, nil
 }
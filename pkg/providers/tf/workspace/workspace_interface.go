@@ -17,6 +17,7 @@ type Workspace interface {
 
 	StateVersion() (*version.Version, error)
 	Outputs(instance string) (map[string]interface{}, error)
+	RedactedOutputs(instance string) (map[string]interface{}, error)
 	ModuleDefinitions() []ModuleDefinition
 	ModuleInstances() []ModuleInstance
 	UpdateInstanceConfiguration(vars map[string]interface{}) error
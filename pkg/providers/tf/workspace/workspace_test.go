@@ -16,10 +16,12 @@ package workspace
 
 import (
 	"context"
+	"errors"
 	"os"
 	"os/exec"
 	"path"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/cloudfoundry/cloud-service-broker/pkg/providers/tf/executor"
@@ -177,6 +179,232 @@ func TestTerraformWorkspace_InvariantsFlat(t *testing.T) {
 	}
 }
 
+func TestTerraformWorkspace_KeepDirectory(t *testing.T) {
+	ws, err := NewWorkspace(map[string]interface{}{}, ``, map[string]string{"main": "variable azure_tenant_id { type = string }"}, []ParameterMapping{}, []string{}, []ParameterMapping{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ws.KeepDirectory()
+
+	var dirs []string
+	executor := newTestExecutor(func(ctx context.Context, cmd *exec.Cmd) (executor.ExecutionOutput, error) {
+		dirs = append(dirs, cmd.Dir)
+
+		// simulate what init installs, and a file left behind by a command
+		if err := os.MkdirAll(path.Join(cmd.Dir, ".terraform", "providers"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path.Join(cmd.Dir, "terraform.tfstate.backup"), []byte("backup"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path.Join(cmd.Dir, "terraform.tfstate"), []byte("state"), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		return executor.ExecutionOutput{}, nil
+	})
+
+	if _, err := ws.Execute(context.TODO(), executor, command.NewApply()); err != nil {
+		t.Fatal(err)
+	}
+
+	ws.Modules[0].Definitions = map[string]string{"variables": "variable region { type = string }"}
+	if _, err := ws.Execute(context.TODO(), executor, command.NewApply()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(dirs) != 2 || dirs[0] != dirs[1] {
+		t.Fatalf("expected both commands to run in the same directory, got %v", dirs)
+	}
+
+	if _, err := os.Stat(path.Join(dirs[0], ".terraform", "providers")); err != nil {
+		t.Fatalf("expected the .terraform directory to be kept: %v", err)
+	}
+
+	if _, err := os.Stat(path.Join(dirs[0], "main.tf")); !os.IsNotExist(err) {
+		t.Fatalf("expected files of the previous command to be removed: %v", err)
+	}
+
+	if _, err := os.Stat(path.Join(dirs[0], "variables.tf")); err != nil {
+		t.Fatalf("expected the workspace files to be written again: %v", err)
+	}
+
+	if !reflect.DeepEqual(ws.State, []byte("state")) {
+		t.Fatalf("Expected state %v got %v", []byte("state"), ws.State)
+	}
+
+	if err := ws.RemoveDirectory(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(dirs[0]); !os.IsNotExist(err) {
+		t.Fatalf("command directory %q didn't get removed %v", dirs[0], err)
+	}
+}
+
+func TestTerraformWorkspace_UseHTTPBackend(t *testing.T) {
+	ws, err := NewWorkspace(map[string]interface{}{}, ``, map[string]string{"main": "variable azure_tenant_id { type = string }"}, []ParameterMapping{}, []string{}, []ParameterMapping{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ws.State = []byte("old state")
+	ws.UseHTTPBackend(&HTTPBackend{Address: "http://127.0.0.1:8080/tfstate/tf:instance:"})
+
+	var commands [][]string
+	executor := newTestExecutor(func(ctx context.Context, cmd *exec.Cmd) (executor.ExecutionOutput, error) {
+		commands = append(commands, cmd.Args[1:])
+
+		if _, err := os.Stat(path.Join(cmd.Dir, "terraform.tfstate")); !os.IsNotExist(err) {
+			t.Fatalf("expected no local state file: %v", err)
+		}
+		if _, err := os.Stat(path.Join(cmd.Dir, backendFileName)); err != nil {
+			t.Fatalf("expected the backend to be configured: %v", err)
+		}
+
+		if cmd.Args[1] == "state" {
+			return executor.ExecutionOutput{}, errors.New("backend initialization required")
+		}
+		return executor.ExecutionOutput{}, nil
+	})
+
+	if _, err := ws.Execute(context.TODO(), executor, command.NewApply()); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := [][]string{{"apply", "-auto-approve", "-no-color"}, {"state", "pull"}}
+	if !reflect.DeepEqual(commands, expected) {
+		t.Fatalf("Expected commands %v got %v", expected, commands)
+	}
+
+	// the state cannot be pulled, so the state from before is kept
+	if !reflect.DeepEqual(ws.State, []byte("old state")) {
+		t.Fatalf("Expected state %v got %v", []byte("old state"), ws.State)
+	}
+}
+
+func TestTerraformWorkspace_Describe(t *testing.T) {
+	ws, err := NewWorkspace(map[string]interface{}{}, ``, map[string]string{"main": "variable azure_tenant_id { type = string }"}, []ParameterMapping{}, []string{}, []ParameterMapping{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ws.State = []byte(`{"version":4,"outputs":{"password":{"type":"string","value":"hunter2","sensitive":true}}}`)
+
+	if actual := ws.String(); !strings.Contains(actual, `output.password = "(sensitive value)"`) || strings.Contains(actual, "hunter2") {
+		t.Fatalf("Expected the sensitive output to be redacted, got %s", actual)
+	}
+
+	if actual := ws.Describe(true); !strings.Contains(actual, `output.password = "hunter2"`) {
+		t.Fatalf("Expected the sensitive output to be shown, got %s", actual)
+	}
+}
+
+func TestTerraformWorkspace_StateMigrations(t *testing.T) {
+	ws, err := NewWorkspace(map[string]interface{}{}, ``, map[string]string{"main": "variable azure_tenant_id { type = string }"}, []ParameterMapping{}, []string{}, []ParameterMapping{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ws.State = []byte("state")
+	ws.AppliedStateMigrations = []string{"1.0.0"}
+	ws.AddStateMigrations([]StateMigration{
+		{Version: "1.0.0", Removes: []string{"random_pet.old"}},
+		{Version: "1.1.0", Moves: []StateMove{{From: "random_pet.a", To: "random_pet.b"}}, Removes: []string{"random_pet.c"}},
+		{Version: "1.2.0", Imports: []StateImport{{Address: "random_pet.d", ID: "d"}}},
+	})
+
+	var commands [][]string
+	failImport := true
+	executor := newTestExecutor(func(ctx context.Context, cmd *exec.Cmd) (executor.ExecutionOutput, error) {
+		commands = append(commands, cmd.Args[1:])
+		switch {
+		case cmd.Args[1] == "import" && failImport:
+			return executor.ExecutionOutput{}, errors.New("import failed")
+		case cmd.Args[1] == "state" && cmd.Args[2] == "list":
+			return executor.ExecutionOutput{StdOut: "random_pet.a\nrandom_pet.c\n"}, nil
+		}
+		return executor.ExecutionOutput{}, os.WriteFile(path.Join(cmd.Dir, "terraform.tfstate"), []byte("state"), 0755)
+	})
+
+	_, err = ws.Execute(context.TODO(), executor, command.NewInit("plugins"), command.NewApply())
+	if err == nil || err.Error() != "error migrating state: import failed" {
+		t.Fatalf("expected the import to fail, got %v", err)
+	}
+
+	expected := [][]string{
+		{"init", "-plugin-dir=plugins", "-no-color"},
+		{"state", "list"},
+		{"state", "mv", "random_pet.a", "random_pet.b"},
+		{"state", "rm", "random_pet.c"},
+		{"state", "list"},
+		{"import", "random_pet.d", "d"},
+	}
+	if !reflect.DeepEqual(commands, expected) {
+		t.Fatalf("Expected commands %v got %v", expected, commands)
+	}
+	if !reflect.DeepEqual(ws.AppliedStateMigrations, []string{"1.0.0", "1.1.0"}) {
+		t.Fatalf("expected the successful migration to be recorded, got %v", ws.AppliedStateMigrations)
+	}
+
+	commands = nil
+	failImport = false
+	if _, err := ws.Execute(context.TODO(), executor, command.NewInit("plugins"), command.NewApply()); err != nil {
+		t.Fatal(err)
+	}
+
+	expected = [][]string{
+		{"init", "-plugin-dir=plugins", "-no-color"},
+		{"state", "list"},
+		{"import", "random_pet.d", "d"},
+		{"apply", "-auto-approve", "-no-color"},
+	}
+	if !reflect.DeepEqual(commands, expected) {
+		t.Fatalf("Expected commands %v got %v", expected, commands)
+	}
+	if !reflect.DeepEqual(ws.AppliedStateMigrations, []string{"1.0.0", "1.1.0", "1.2.0"}) || len(ws.PendingStateMigrations) != 0 {
+		t.Fatalf("expected all migrations to be applied, got %v pending %v", ws.AppliedStateMigrations, ws.PendingStateMigrations)
+	}
+}
+
+func TestTerraformWorkspace_StateMigrationsAlreadyInState(t *testing.T) {
+	ws, err := NewWorkspace(map[string]interface{}{}, ``, map[string]string{"main": "variable azure_tenant_id { type = string }"}, []ParameterMapping{}, []string{}, []ParameterMapping{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ws.State = []byte("state")
+
+	// a deployment that has no applied migrations recorded, but that already has its resources where the
+	// migrations put them
+	ws.AddStateMigrations([]StateMigration{
+		{Version: "1.0.0", Moves: []StateMove{{From: "random_pet.a", To: "module.pets.random_pet.a"}}, Removes: []string{"random_pet.old"}},
+		{Version: "1.1.0", Moves: []StateMove{{From: "module.pets", To: "module.animals"}}, Imports: []StateImport{{Address: "random_pet.d", ID: "d"}}},
+	})
+
+	var commands [][]string
+	executor := newTestExecutor(func(ctx context.Context, cmd *exec.Cmd) (executor.ExecutionOutput, error) {
+		commands = append(commands, cmd.Args[1:])
+		if cmd.Args[1] == "state" && cmd.Args[2] == "list" {
+			return executor.ExecutionOutput{StdOut: "module.pets.random_pet.a\nmodule.pets.random_pet.b[0]\nrandom_pet.d\n"}, nil
+		}
+		return executor.ExecutionOutput{}, os.WriteFile(path.Join(cmd.Dir, "terraform.tfstate"), []byte("state"), 0755)
+	})
+
+	if _, err := ws.Execute(context.TODO(), executor, command.NewInit("plugins")); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := [][]string{
+		{"init", "-plugin-dir=plugins", "-no-color"},
+		{"state", "list"},
+		{"state", "list"},
+		{"state", "mv", "module.pets", "module.animals"},
+	}
+	if !reflect.DeepEqual(commands, expected) {
+		t.Fatalf("Expected commands %v got %v", expected, commands)
+	}
+	if !reflect.DeepEqual(ws.AppliedStateMigrations, []string{"1.0.0", "1.1.0"}) {
+		t.Fatalf("expected the migrations to be recorded, got %v", ws.AppliedStateMigrations)
+	}
+}
+
 func TestCustomTerraformExecutor012(t *testing.T) {
 	customBinary := "/path/to/terraform"
 	customPlugins := "/path/to/terraform-plugins"
@@ -70,6 +70,19 @@ type FakeWorkspace struct {
 		result1 map[string]interface{}
 		result2 error
 	}
+	RedactedOutputsStub        func(string) (map[string]interface{}, error)
+	redactedOutputsMutex       sync.RWMutex
+	redactedOutputsArgsForCall []struct {
+		arg1 string
+	}
+	redactedOutputsReturns struct {
+		result1 map[string]interface{}
+		result2 error
+	}
+	redactedOutputsReturnsOnCall map[int]struct {
+		result1 map[string]interface{}
+		result2 error
+	}
 	SerializeStub        func() (string, error)
 	serializeMutex       sync.RWMutex
 	serializeArgsForCall []struct {
@@ -398,6 +411,70 @@ func (fake *FakeWorkspace) OutputsReturnsOnCall(i int, result1 map[string]interf
 	}{result1, result2}
 }
 
+func (fake *FakeWorkspace) RedactedOutputs(arg1 string) (map[string]interface{}, error) {
+	fake.redactedOutputsMutex.Lock()
+	ret, specificReturn := fake.redactedOutputsReturnsOnCall[len(fake.redactedOutputsArgsForCall)]
+	fake.redactedOutputsArgsForCall = append(fake.redactedOutputsArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.RedactedOutputsStub
+	fakeReturns := fake.redactedOutputsReturns
+	fake.recordInvocation("RedactedOutputs", []interface{}{arg1})
+	fake.redactedOutputsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeWorkspace) RedactedOutputsCallCount() int {
+	fake.redactedOutputsMutex.RLock()
+	defer fake.redactedOutputsMutex.RUnlock()
+	return len(fake.redactedOutputsArgsForCall)
+}
+
+func (fake *FakeWorkspace) RedactedOutputsCalls(stub func(string) (map[string]interface{}, error)) {
+	fake.redactedOutputsMutex.Lock()
+	defer fake.redactedOutputsMutex.Unlock()
+	fake.RedactedOutputsStub = stub
+}
+
+func (fake *FakeWorkspace) RedactedOutputsArgsForCall(i int) string {
+	fake.redactedOutputsMutex.RLock()
+	defer fake.redactedOutputsMutex.RUnlock()
+	argsForCall := fake.redactedOutputsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeWorkspace) RedactedOutputsReturns(result1 map[string]interface{}, result2 error) {
+	fake.redactedOutputsMutex.Lock()
+	defer fake.redactedOutputsMutex.Unlock()
+	fake.RedactedOutputsStub = nil
+	fake.redactedOutputsReturns = struct {
+		result1 map[string]interface{}
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeWorkspace) RedactedOutputsReturnsOnCall(i int, result1 map[string]interface{}, result2 error) {
+	fake.redactedOutputsMutex.Lock()
+	defer fake.redactedOutputsMutex.Unlock()
+	fake.RedactedOutputsStub = nil
+	if fake.redactedOutputsReturnsOnCall == nil {
+		fake.redactedOutputsReturnsOnCall = make(map[int]struct {
+			result1 map[string]interface{}
+			result2 error
+		})
+	}
+	fake.redactedOutputsReturnsOnCall[i] = struct {
+		result1 map[string]interface{}
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeWorkspace) Serialize() (string, error) {
 	fake.serializeMutex.Lock()
 	ret, specificReturn := fake.serializeReturnsOnCall[len(fake.serializeArgsForCall)]
@@ -584,6 +661,8 @@ func (fake *FakeWorkspace) Invocations() map[string][][]interface{} {
 	defer fake.moduleInstancesMutex.RUnlock()
 	fake.outputsMutex.RLock()
 	defer fake.outputsMutex.RUnlock()
+	fake.redactedOutputsMutex.RLock()
+	defer fake.redactedOutputsMutex.RUnlock()
 	fake.serializeMutex.RLock()
 	defer fake.serializeMutex.RUnlock()
 	fake.stateVersionMutex.RLock()
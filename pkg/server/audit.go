@@ -0,0 +1,183 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/cloudfoundry/cloud-service-broker/dbservice/models"
+	"github.com/cloudfoundry/cloud-service-broker/internal/storage"
+	"github.com/cloudfoundry/cloud-service-broker/utils/request"
+	"github.com/pivotal-cf/brokerapi/v8/domain"
+)
+
+//counterfeiter:generate . AuditStore
+
+// AuditStore records audit events and looks up the instances they refer to.
+type AuditStore interface {
+	StoreAuditEvent(event storage.AuditEvent) error
+	GetServiceInstanceDetails(guid string) (storage.ServiceInstanceDetails, error)
+}
+
+// Auditor records audit events for operations on service instances and bindings.
+type Auditor struct {
+	store  AuditStore
+	logger lager.Logger
+	clock  func() time.Time
+}
+
+// NewAuditor creates an Auditor that records events in the audit store.
+func NewAuditor(store AuditStore, logger lager.Logger) *Auditor {
+	return &Auditor{
+		store:  store,
+		logger: logger.Session("audit"),
+		clock:  time.Now,
+	}
+}
+
+// Audit records an audit event for an operation that the broker starts itself rather than
+// through the OSBAPI endpoints, such as an upgrade, an instance action or an adoption.
+func (a *Auditor) Audit(ctx context.Context, operation, instanceID string, params json.RawMessage, run func() (isAsync bool, err error)) error {
+	event := a.newEvent(ctx, operation, instanceID, "", params)
+
+	isAsync, err := run()
+	// The location is looked up afterwards, as an adoption creates the instance
+	a.addInstanceLocation(&event)
+	a.record(event, isAsync, err)
+	return err
+}
+
+// AuditWrapper records an audit event for every request that creates, changes or
+// deletes a service instance or service binding.
+type AuditWrapper struct {
+	domain.ServiceBroker
+	*Auditor
+}
+
+// NewAuditWrapper wraps the given servicebroker so that requests which change
+// service instances or bindings are recorded in the audit store.
+func NewAuditWrapper(wrapped domain.ServiceBroker, auditor *Auditor) domain.ServiceBroker {
+	return &AuditWrapper{
+		ServiceBroker: wrapped,
+		Auditor:       auditor,
+	}
+}
+
+// Provision records an audit event for the wrapped Provision.
+func (w *AuditWrapper) Provision(ctx context.Context, instanceID string, details domain.ProvisionDetails, asyncAllowed bool) (domain.ProvisionedServiceSpec, error) {
+	event := w.newEvent(ctx, models.ProvisionOperationType, instanceID, "", details.RawParameters)
+	event.OrganizationGUID = details.OrganizationGUID
+	event.SpaceGUID = details.SpaceGUID
+
+	spec, err := w.ServiceBroker.Provision(ctx, instanceID, details, asyncAllowed)
+	w.record(event, spec.IsAsync, err)
+	return spec, err
+}
+
+// Update records an audit event for the wrapped Update.
+func (w *AuditWrapper) Update(ctx context.Context, instanceID string, details domain.UpdateDetails, asyncAllowed bool) (domain.UpdateServiceSpec, error) {
+	event := w.newEvent(ctx, models.UpdateOperationType, instanceID, "", details.RawParameters)
+	w.addInstanceLocation(&event)
+
+	spec, err := w.ServiceBroker.Update(ctx, instanceID, details, asyncAllowed)
+	w.record(event, spec.IsAsync, err)
+	return spec, err
+}
+
+// Deprovision records an audit event for the wrapped Deprovision.
+func (w *AuditWrapper) Deprovision(ctx context.Context, instanceID string, details domain.DeprovisionDetails, asyncAllowed bool) (domain.DeprovisionServiceSpec, error) {
+	event := w.newEvent(ctx, models.DeprovisionOperationType, instanceID, "", nil)
+	w.addInstanceLocation(&event)
+
+	spec, err := w.ServiceBroker.Deprovision(ctx, instanceID, details, asyncAllowed)
+	w.record(event, spec.IsAsync, err)
+	return spec, err
+}
+
+// Bind records an audit event for the wrapped Bind.
+func (w *AuditWrapper) Bind(ctx context.Context, instanceID, bindingID string, details domain.BindDetails, asyncAllowed bool) (domain.Binding, error) {
+	event := w.newEvent(ctx, models.BindOperationType, instanceID, bindingID, details.RawParameters)
+	w.addInstanceLocation(&event)
+
+	binding, err := w.ServiceBroker.Bind(ctx, instanceID, bindingID, details, asyncAllowed)
+	w.record(event, binding.IsAsync, err)
+	return binding, err
+}
+
+// Unbind records an audit event for the wrapped Unbind.
+func (w *AuditWrapper) Unbind(ctx context.Context, instanceID, bindingID string, details domain.UnbindDetails, asyncAllowed bool) (domain.UnbindSpec, error) {
+	event := w.newEvent(ctx, models.UnbindOperationType, instanceID, bindingID, nil)
+	w.addInstanceLocation(&event)
+
+	spec, err := w.ServiceBroker.Unbind(ctx, instanceID, bindingID, details, asyncAllowed)
+	w.record(event, spec.IsAsync, err)
+	return spec, err
+}
+
+func (a *Auditor) newEvent(ctx context.Context, operation, instanceID, bindingID string, params json.RawMessage) storage.AuditEvent {
+	return storage.AuditEvent{
+		Time:                a.clock(),
+		Operation:           operation,
+		ServiceInstanceGUID: instanceID,
+		ServiceBindingGUID:  bindingID,
+		OriginatingIdentity: originatingIdentity(ctx),
+		ParametersHash:      parametersHash(params),
+	}
+}
+
+// addInstanceLocation fills in the organization and space from the stored instance,
+// as only the provision request carries them.
+func (a *Auditor) addInstanceLocation(event *storage.AuditEvent) {
+	instance, err := a.store.GetServiceInstanceDetails(event.ServiceInstanceGUID)
+	if err != nil {
+		return
+	}
+	event.OrganizationGUID = instance.OrganizationGUID
+	event.SpaceGUID = instance.SpaceGUID
+}
+
+func (a *Auditor) record(event storage.AuditEvent, isAsync bool, err error) {
+	event.Duration = a.clock().Sub(event.Time)
+	switch {
+	case err != nil:
+		event.Result = string(domain.Failed)
+		event.Message = err.Error()
+	case isAsync:
+		event.Result = string(domain.InProgress)
+	default:
+		event.Result = string(domain.Succeeded)
+	}
+
+	if err := a.store.StoreAuditEvent(event); err != nil {
+		a.logger.Error("store-audit-event", err, lager.Data{
+			"operation":   event.Operation,
+			"instance-id": event.ServiceInstanceGUID,
+			"binding-id":  event.ServiceBindingGUID,
+		})
+	}
+}
+
+func originatingIdentity(ctx context.Context) string {
+	identity := request.DecodeOriginatingIdentityHeader(ctx)
+	if identity == nil {
+		return ""
+	}
+
+	encoded, err := json.Marshal(identity)
+	if err != nil {
+		return ""
+	}
+	return string(encoded)
+}
+
+func parametersHash(params json.RawMessage) string {
+	if len(params) == 0 {
+		return ""
+	}
+
+	sum := sha256.Sum256(params)
+	return hex.EncodeToString(sum[:])
+}
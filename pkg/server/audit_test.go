@@ -0,0 +1,200 @@
+package server_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"code.cloudfoundry.org/lager/lagertest"
+	"github.com/cloudfoundry/cloud-service-broker/internal/storage"
+	"github.com/cloudfoundry/cloud-service-broker/pkg/server"
+	"github.com/cloudfoundry/cloud-service-broker/pkg/server/fakes"
+	"github.com/cloudfoundry/cloud-service-broker/pkg/server/serverfakes"
+	"github.com/pivotal-cf/brokerapi/v8/domain"
+	"github.com/pivotal-cf/brokerapi/v8/middlewares"
+)
+
+func TestAuditWrapper(t *testing.T) {
+	const identityHeader = "cloudfoundry eyJ1c2VyX2lkIjoiZmFrZS11c2VyIn0="
+
+	ctx := context.WithValue(context.Background(), middlewares.OriginatingIdentityKey, identityHeader)
+	params := []byte(`{"foo":"bar"}`)
+	sum := sha256.Sum256(params)
+	paramsHash := hex.EncodeToString(sum[:])
+
+	setup := func() (*fakes.FakeServiceBroker, *serverfakes.FakeAuditStore, domain.ServiceBroker) {
+		wrapped := &fakes.FakeServiceBroker{}
+		store := &serverfakes.FakeAuditStore{}
+		store.GetServiceInstanceDetailsReturns(storage.ServiceInstanceDetails{OrganizationGUID: "stored-org", SpaceGUID: "stored-space"}, nil)
+		return wrapped, store, server.NewAuditWrapper(wrapped, server.NewAuditor(store, lagertest.NewTestLogger("audit-test")))
+	}
+
+	t.Run("provision", func(t *testing.T) {
+		wrapped, store, w := setup()
+		wrapped.ProvisionReturns(domain.ProvisionedServiceSpec{IsAsync: true, OperationData: "op"}, nil)
+
+		spec, err := w.Provision(ctx, "instance-id", domain.ProvisionDetails{
+			OrganizationGUID: "org-guid",
+			SpaceGUID:        "space-guid",
+			RawParameters:    params,
+		}, true)
+		if err != nil || spec.OperationData != "op" {
+			t.Fatalf("unexpected result: %v %v", spec, err)
+		}
+
+		if store.StoreAuditEventCallCount() != 1 {
+			t.Fatalf("expected one audit event, got %d", store.StoreAuditEventCallCount())
+		}
+		event := store.StoreAuditEventArgsForCall(0)
+		expectEqual(t, event.Operation, "provision")
+		expectEqual(t, event.ServiceInstanceGUID, "instance-id")
+		expectEqual(t, event.OrganizationGUID, "org-guid")
+		expectEqual(t, event.SpaceGUID, "space-guid")
+		expectEqual(t, event.ParametersHash, paramsHash)
+		expectEqual(t, event.OriginatingIdentity, `{"platform":"cloudfoundry","value":{"user_id":"fake-user"}}`)
+		expectEqual(t, event.Result, "in progress")
+		if event.Time.IsZero() {
+			t.Error("expected event time to be set")
+		}
+		if store.GetServiceInstanceDetailsCallCount() != 0 {
+			t.Error("expected provision not to look up the instance")
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		wrapped, store, w := setup()
+		wrapped.UpdateReturns(domain.UpdateServiceSpec{}, nil)
+
+		if _, err := w.Update(ctx, "instance-id", domain.UpdateDetails{RawParameters: params}, true); err != nil {
+			t.Fatal(err)
+		}
+
+		event := store.StoreAuditEventArgsForCall(0)
+		expectEqual(t, event.Operation, "update")
+		expectEqual(t, event.OrganizationGUID, "stored-org")
+		expectEqual(t, event.SpaceGUID, "stored-space")
+		expectEqual(t, event.ParametersHash, paramsHash)
+		expectEqual(t, event.Result, "succeeded")
+		expectEqual(t, store.GetServiceInstanceDetailsArgsForCall(0), "instance-id")
+	})
+
+	t.Run("deprovision failure", func(t *testing.T) {
+		wrapped, store, w := setup()
+		wrapped.DeprovisionReturns(domain.DeprovisionServiceSpec{}, errors.New("boom"))
+
+		if _, err := w.Deprovision(ctx, "instance-id", domain.DeprovisionDetails{}, true); err == nil {
+			t.Fatal("expected the error to be passed through")
+		}
+
+		event := store.StoreAuditEventArgsForCall(0)
+		expectEqual(t, event.Operation, "deprovision")
+		expectEqual(t, event.ParametersHash, "")
+		expectEqual(t, event.Result, "failed")
+		expectEqual(t, event.Message, "boom")
+	})
+
+	t.Run("bind and unbind", func(t *testing.T) {
+		wrapped, store, w := setup()
+		wrapped.BindReturns(domain.Binding{}, nil)
+		wrapped.UnbindReturns(domain.UnbindSpec{}, nil)
+
+		if _, err := w.Bind(ctx, "instance-id", "binding-id", domain.BindDetails{RawParameters: params}, true); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Unbind(ctx, "instance-id", "binding-id", domain.UnbindDetails{}, true); err != nil {
+			t.Fatal(err)
+		}
+
+		bind := store.StoreAuditEventArgsForCall(0)
+		expectEqual(t, bind.Operation, "bind")
+		expectEqual(t, bind.ServiceBindingGUID, "binding-id")
+		expectEqual(t, bind.ParametersHash, paramsHash)
+		expectEqual(t, bind.OrganizationGUID, "stored-org")
+
+		unbind := store.StoreAuditEventArgsForCall(1)
+		expectEqual(t, unbind.Operation, "unbind")
+		expectEqual(t, unbind.ServiceBindingGUID, "binding-id")
+		expectEqual(t, unbind.Result, "succeeded")
+	})
+
+	t.Run("store failure does not fail the request", func(t *testing.T) {
+		wrapped, store, w := setup()
+		wrapped.UnbindReturns(domain.UnbindSpec{OperationData: "op"}, nil)
+		store.StoreAuditEventReturns(errors.New("database gone"))
+
+		spec, err := w.Unbind(ctx, "instance-id", "binding-id", domain.UnbindDetails{}, true)
+		if err != nil || spec.OperationData != "op" {
+			t.Fatalf("unexpected result: %v %v", spec, err)
+		}
+	})
+
+	t.Run("read-only calls are not recorded", func(t *testing.T) {
+		wrapped, store, w := setup()
+		wrapped.GetInstanceReturns(domain.GetInstanceDetailsSpec{}, nil)
+
+		if _, err := w.GetInstance(ctx, "instance-id", domain.FetchInstanceDetails{}); err != nil {
+			t.Fatal(err)
+		}
+		if store.StoreAuditEventCallCount() != 0 {
+			t.Error("expected no audit events")
+		}
+	})
+}
+
+func TestAuditor(t *testing.T) {
+	ctx := context.WithValue(context.Background(), middlewares.OriginatingIdentityKey, "cloudfoundry eyJ1c2VyX2lkIjoiZmFrZS11c2VyIn0=")
+
+	setup := func() (*serverfakes.FakeAuditStore, *server.Auditor) {
+		store := &serverfakes.FakeAuditStore{}
+		store.GetServiceInstanceDetailsReturns(storage.ServiceInstanceDetails{OrganizationGUID: "stored-org", SpaceGUID: "stored-space"}, nil)
+		return store, server.NewAuditor(store, lagertest.NewTestLogger("audit-test"))
+	}
+
+	t.Run("operation started by the broker", func(t *testing.T) {
+		store, a := setup()
+
+		ran := false
+		err := a.Audit(ctx, "upgrade", "instance-id", nil, func() (bool, error) {
+			ran = true
+			if store.GetServiceInstanceDetailsCallCount() != 0 {
+				t.Error("expected the instance to be looked up after the operation")
+			}
+			return false, nil
+		})
+		if err != nil || !ran {
+			t.Fatalf("expected the operation to run: %v", err)
+		}
+
+		event := store.StoreAuditEventArgsForCall(0)
+		expectEqual(t, event.Operation, "upgrade")
+		expectEqual(t, event.ServiceInstanceGUID, "instance-id")
+		expectEqual(t, event.OrganizationGUID, "stored-org")
+		expectEqual(t, event.OriginatingIdentity, `{"platform":"cloudfoundry","value":{"user_id":"fake-user"}}`)
+		expectEqual(t, event.Result, "succeeded")
+	})
+
+	t.Run("failed operation", func(t *testing.T) {
+		store, a := setup()
+
+		err := a.Audit(ctx, "action", "instance-id", []byte(`{"foo":"bar"}`), func() (bool, error) {
+			return true, errors.New("boom")
+		})
+		if err == nil || err.Error() != "boom" {
+			t.Fatalf("expected the error to be passed through, got: %v", err)
+		}
+
+		event := store.StoreAuditEventArgsForCall(0)
+		expectEqual(t, event.Operation, "action")
+		expectEqual(t, event.Result, "failed")
+		expectEqual(t, event.Message, "boom")
+	})
+}
+
+func expectEqual(t *testing.T, actual, expected string) {
+	t.Helper()
+	if actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+}